@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+
+	"yunion.io/x/code-generator/pkg/common"
+	// generators register their annotations
+	_ "yunion.io/x/code-generator/pkg/model-api-gen/generators"
+	_ "yunion.io/x/code-generator/pkg/swagger-gen/generators"
+)
+
+type annotationsOption struct {
+	OutputFile string
+}
+
+func newAnnotationsCmd() *cobra.Command {
+	cfg := new(annotationsOption)
+	cmd := &cobra.Command{
+		Use:   "annotations",
+		Short: "print json catalog of +onecloud annotations with their value syntax and targets, for editor completion and validation",
+		Run: func(_ *cobra.Command, _ []string) {
+			checkErr(doAnnotations(cfg))
+		},
+	}
+	initAnnotationsCmdOpts(cmd.PersistentFlags(), cfg)
+	return cmd
+}
+
+func initAnnotationsCmdOpts(flagSet *flag.FlagSet, cfg *annotationsOption) {
+	flagSet.StringVarP(&cfg.OutputFile, "output", "o", "", "output json file, stdout if empty")
+}
+
+func doAnnotations(cfg *annotationsOption) error {
+	var w io.Writer = os.Stdout
+	if cfg.OutputFile != "" {
+		f, err := os.Create(cfg.OutputFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return common.WriteAnnotationCatalog(w)
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"yunion.io/x/log"
+)
+
+func NewRootCmd() *cobra.Command {
+	cmds := &cobra.Command{
+		Use:   "codegen",
+		Short: "tools of onecloud code generator annotations",
+	}
+	cmds.AddCommand(newLintCmd())
+	cmds.AddCommand(newAnnotationsCmd())
+	cmds.AddCommand(newServeDocsCmd())
+	return cmds
+}
+
+func checkErr(err error) {
+	if err != nil {
+		log.Errorf("%v", err)
+		os.Exit(1)
+	}
+}
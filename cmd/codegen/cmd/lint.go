@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+
+	"yunion.io/x/code-generator/pkg/common"
+	"yunion.io/x/code-generator/pkg/swagger-gen/generators"
+)
+
+type lintOption struct {
+	InputDirs []string
+	Loader    common.LoaderArgs
+}
+
+func newLintCmd() *cobra.Command {
+	cfg := new(lintOption)
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "report malformed +onecloud:swagger-gen tags of input packages with file:line, without generating anything",
+		Run: func(_ *cobra.Command, _ []string) {
+			issues, err := doLint(cfg)
+			checkErr(err)
+			for _, issue := range issues {
+				fmt.Println(issue.String())
+			}
+			if len(issues) != 0 {
+				os.Exit(1)
+			}
+		},
+	}
+	initLintCmdOpts(cmd.PersistentFlags(), cfg)
+	return cmd
+}
+
+func initLintCmdOpts(flagSet *flag.FlagSet, cfg *lintOption) {
+	flagSet.StringSliceVarP(&cfg.InputDirs, "input-dirs", "i", nil, "Comma-separated input packages to lint, e.g. yunion.io/x/onecloud/pkg/compute/models or ./pkg/...")
+	flagSet.StringSliceVar(&cfg.Loader.Tags, "build-tags", nil, "Comma-separated list of build tags used when loading input packages")
+	flagSet.StringSliceVar(&cfg.Loader.Modules, "modules", nil, "Comma-separated root directories of go modules which input packages span")
+}
+
+func doLint(cfg *lintOption) ([]generators.LintIssue, error) {
+	if len(cfg.InputDirs) == 0 {
+		return nil, errors.New("input dirs are required")
+	}
+	pkgFiles, err := common.SourceFiles(cfg.InputDirs, &cfg.Loader)
+	if err != nil {
+		return nil, errors.Wrap(err, "load input packages")
+	}
+	files := make([]string, 0)
+	for _, fs := range pkgFiles {
+		files = append(files, fs...)
+	}
+	sort.Strings(files)
+	return generators.LintFiles(files)
+}
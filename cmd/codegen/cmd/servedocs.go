@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/skratchdot/open-golang/open"
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+
+	"yunion.io/x/log"
+
+	"yunion.io/x/code-generator/pkg/common"
+	"yunion.io/x/code-generator/pkg/models"
+	"yunion.io/x/code-generator/pkg/swagger-gen/command"
+	"yunion.io/x/code-generator/pkg/swagger-gen/generators"
+	"yunion.io/x/code-generator/pkg/swaggerserve"
+)
+
+type serveDocsOption struct {
+	arguments  *common.GeneratorArgs
+	customArgs *generators.CustomArgs
+	loaderArgs *common.LoaderArgs
+
+	Redoc     bool
+	RedocJS   string
+	CDNPrefix string
+	UIVersion string
+	NoOpen    bool
+	ServeAddr string
+	ServePort int
+}
+
+func newServeDocsCmd() *cobra.Command {
+	arguments, customArgs := generators.NewDefaults()
+	cfg := &serveDocsOption{
+		arguments:  arguments,
+		customArgs: customArgs,
+		loaderArgs: &common.LoaderArgs{},
+	}
+	cmd := &cobra.Command{
+		Use:   "serve-docs",
+		Short: "generate swagger spec of model packages in process and serve docs UI to preview annotations",
+		Run: func(_ *cobra.Command, _ []string) {
+			checkErr(doServeDocs(cfg))
+		},
+	}
+	initServeDocsCmdOpts(cmd.PersistentFlags(), cfg)
+	return cmd
+}
+
+func initServeDocsCmdOpts(flagSet *flag.FlagSet, cfg *serveDocsOption) {
+	// the swagger-gen flags, the spec is scanned from its output package
+	cfg.arguments.AddFlags(flagSet)
+	cfg.customArgs.AddFlags(flagSet)
+	cfg.loaderArgs.AddFlags(flagSet)
+	flagSet.BoolVar(&cfg.Redoc, "redoc", false, "serve Redoc instead of swagger UI")
+	flagSet.StringVar(&cfg.RedocJS, "redoc-js", "https://cdn.jsdelivr.net/npm/redoc/bundles/redoc.standalone.js", "redoc standalone bundle url")
+	flagSet.StringVar(&cfg.CDNPrefix, "cdn", "https://cdnjs.cloudflare.com/ajax/libs/swagger-ui", "swagger-ui cdn prefix")
+	flagSet.StringVar(&cfg.UIVersion, "ui-version", "3.23.11", "swagger ui version")
+	flagSet.BoolVar(&cfg.NoOpen, "no-open", false, "Not open UI in browser")
+	flagSet.StringVar(&cfg.ServeAddr, "serve-addr", "localhost", "server listen address")
+	flagSet.IntVar(&cfg.ServePort, "serve-port", 0, "server listen port, random defaultly")
+}
+
+func (o *serveDocsOption) ui() (swaggerserve.DocsUI, error) {
+	u, err := url.Parse(o.CDNPrefix)
+	if err != nil {
+		return swaggerserve.DocsUI{}, errors.Wrapf(err, "invalid cdn %s", o.CDNPrefix)
+	}
+	asset := func(name string) string {
+		a := *u
+		a.Path = path.Join(u.Path, o.UIVersion, name)
+		return a.String()
+	}
+	return swaggerserve.DocsUI{
+		Redoc:              o.Redoc,
+		RedocJS:            o.RedocJS,
+		UICss:              asset("swagger-ui.css"),
+		BundleJS:           asset("swagger-ui-bundle.js"),
+		StandalonePresetJS: asset("swagger-ui-standalone-preset.js"),
+	}, nil
+}
+
+// generateSpec runs swagger-gen on inputs and scans spec of its output package into docs
+func (o *serveDocsOption) generateSpec(inputs []string, docs *swaggerserve.Docs) error {
+	o.arguments.InputDirs = inputs
+	if err := command.Generate(o.arguments, o.customArgs, o.loaderArgs); err != nil {
+		return err
+	}
+	pkgPath := common.VersionedPackagePath(o.arguments.OutputPackagePath, o.customArgs.APIVersion)
+	spec, err := swaggerserve.BuildSpec(filepath.Join(o.arguments.OutputBase, pkgPath), []string{"."}, o.loaderArgs.Tags)
+	if err != nil {
+		return err
+	}
+	return docs.SetSpec(spec)
+}
+
+func doServeDocs(cfg *serveDocsOption) error {
+	if len(cfg.arguments.InputDirs) == 0 {
+		return errors.New("input dirs are required")
+	}
+	if cfg.arguments.OutputPackagePath == "" {
+		return errors.New("output package is required")
+	}
+	common.SetupLogging(&cfg.loaderArgs.Log)
+	ui, err := cfg.ui()
+	if err != nil {
+		return err
+	}
+	if err := models.Initialize(cfg.customArgs.LoadServices, cfg.customArgs.DuplicateManagers); err != nil {
+		return errors.Wrap(err, "initialize models")
+	}
+	docs := swaggerserve.NewDocs(ui)
+	inputs := cfg.arguments.InputDirs
+	if err := cfg.generateSpec(inputs, docs); err != nil {
+		return err
+	}
+	if cfg.loaderArgs.Watch.Enabled {
+		dirs, err := common.PackageDirs(inputs, cfg.loaderArgs)
+		if err != nil {
+			return err
+		}
+		// input packages are generated into one output package, all of them are regenerated,
+		// reload page to preview
+		go cfg.loaderArgs.Watch.Watch(dirs, func([]string) error {
+			if err := models.Reinitialize(cfg.customArgs.LoadServices, cfg.customArgs.DuplicateManagers); err != nil {
+				return errors.Wrap(err, "initialize models")
+			}
+			return cfg.generateSpec(inputs, docs)
+		})
+	}
+	return serveDocs(docs, cfg.ServeAddr, cfg.ServePort, cfg.NoOpen)
+}
+
+// serveDocs serves docs on addr and port, and opens it in browser unless noOpen
+func serveDocs(docs http.Handler, addr string, port int, noOpen bool) error {
+	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", addr, port))
+	if err != nil {
+		return err
+	}
+	u := "http://" + listener.Addr().String()
+	log.Infof("Serve docs at address: %s", u)
+	if !noOpen {
+		if err := open.Run(u); err != nil {
+			log.Errorf("open %s: %v", u, err)
+		}
+	}
+	return http.Serve(listener, docs)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	goflag "flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/pflag"
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/namer"
+	"k8s.io/klog/v2"
+
+	"yunion.io/x/code-generator/pkg/common"
+	"yunion.io/x/code-generator/pkg/graphql-gen/generators"
+	"yunion.io/x/code-generator/pkg/models"
+	"yunion.io/x/code-generator/pkg/models/registry"
+	"yunion.io/x/code-generator/pkg/swaggergen"
+)
+
+func main() {
+	klog.InitFlags(nil)
+	arguments := common.NewGeneratorArgs()
+	loaderArgs := &common.LoaderArgs{}
+	var (
+		services   []string
+		dedup      registry.Dedup
+		outputFile string
+	)
+	arguments.AddFlags(pflag.CommandLine)
+	loaderArgs.AddFlags(pflag.CommandLine)
+	pflag.StringSliceVar(&services, "load-services", nil, fmt.Sprintf("Onecloud services to load model managers from, choices: %v", models.ServiceNames()))
+	dedup.AddFlags(pflag.CommandLine)
+	pflag.StringVar(&outputFile, "output-file", "schema.graphql", "Write GraphQL SDL schema of models to this file")
+	pflag.CommandLine.AddGoFlagSet(goflag.CommandLine)
+	pflag.Parse()
+	common.SetupLogging(&loaderArgs.Log)
+	if loaderArgs.Watch.Enabled {
+		klog.Errorf("--watch is not supported by graphql-gen")
+		os.Exit(1)
+	}
+
+	if err := models.Initialize(services, dedup); err != nil {
+		klog.Errorf("Initialize models: %v", err)
+		os.Exit(1)
+	}
+	if err := generate(arguments, loaderArgs, outputFile); err != nil {
+		klog.Errorf("Error: %v", err)
+		os.Exit(1)
+	}
+}
+
+// generate renders operations of registered models in input packages to GraphQL schema file
+func generate(arguments *common.GeneratorArgs, loaderArgs *common.LoaderArgs, outputFile string) error {
+	p, err := common.NewParser(arguments, loaderArgs)
+	if err != nil {
+		return fmt.Errorf("Failed making a parser: %v", err)
+	}
+	c, err := generator.NewContext(p, namer.NameSystems{"raw": namer.NewRawNamer("", nil)}, "raw")
+	if err != nil {
+		return fmt.Errorf("Failed making a context: %v", err)
+	}
+	// operations of all inputs are added at once, type names don't depend on the order of inputs
+	ops := make([]swaggergen.Operation, 0)
+	for _, pkg := range arguments.InputDirs {
+		pkgOps, err := swaggergen.ParseModels(pkg, c.Order, swaggergen.Options{})
+		if err != nil {
+			return fmt.Errorf("parse models of %s: %v", pkg, err)
+		}
+		ops = append(ops, pkgOps...)
+	}
+	schema := generators.NewSchema(c.Universe)
+	schema.AddOperations(ops)
+	return ioutil.WriteFile(outputFile, []byte(schema.Render()), 0644)
+}
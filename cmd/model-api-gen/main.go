@@ -1,30 +1,65 @@
 package main
 
 import (
+	goflag "flag"
 	"os"
-	"path/filepath"
 
-	"k8s.io/gengo/args"
-	"k8s.io/klog"
+	"github.com/spf13/pflag"
+	"k8s.io/klog/v2"
 
+	"yunion.io/x/code-generator/pkg/common"
 	"yunion.io/x/code-generator/pkg/model-api-gen/generators"
+	"yunion.io/x/code-generator/pkg/models/registry"
+	"yunion.io/x/code-generator/pkg/onecloudshim"
 )
 
 func main() {
 	klog.InitFlags(nil)
-	arguments := args.Default()
+	arguments, customArgs := generators.NewDefaults()
+	loaderArgs := &common.LoaderArgs{}
 
-	// Override defaults.
-	arguments.OutputFileBaseName = "zz_generated.model"
-	arguments.GoHeaderFilePath = filepath.Join(args.DefaultSourceTree(), "yunion.io/x/code-generator/boilerplate/boilerplate.go.txt")
+	arguments.AddFlags(pflag.CommandLine)
+	customArgs.AddFlags(pflag.CommandLine)
+	loaderArgs.AddFlags(pflag.CommandLine)
+	pflag.CommandLine.AddGoFlagSet(goflag.CommandLine)
+	pflag.Parse()
+	common.SetupLogging(&loaderArgs.Log)
+	customArgs.BuildTags = loaderArgs.Tags
+	// columns are read from tables of managers, services are linked by the columndocs build only
+	if customArgs.ColumnDocs {
+		if len(onecloudshim.ServiceNames()) == 0 {
+			klog.Errorf("--column-docs requires onecloud services, build model-api-gen with -tags columndocs")
+			os.Exit(1)
+		}
+		if err := registry.Load(customArgs.LoadServices, customArgs.DuplicateManagers); err != nil {
+			klog.Errorf("Initialize models: %v", err)
+			os.Exit(1)
+		}
+	}
 
-	if err := arguments.Execute(
-		generators.NameSystems(),
-		generators.DefaultNameSystem(),
-		generators.Packages,
-	); err != nil {
+	generate := func(arguments *common.GeneratorArgs, loaderArgs *common.LoaderArgs) error {
+		return common.Execute(
+			arguments,
+			loaderArgs,
+			generators.NameSystems(),
+			generators.DefaultNameSystem(),
+			generators.Packages,
+		)
+	}
+	if err := generate(arguments, loaderArgs); err != nil {
 		klog.Errorf("Error: %v", err)
 		os.Exit(1)
 	}
 	klog.V(2).Info("Completed successfully.")
+
+	if !loaderArgs.Watch.Enabled {
+		return
+	}
+	dirs, err := common.PackageDirs(arguments.InputDirs, loaderArgs)
+	if err != nil {
+		klog.Errorf("Watch: %v", err)
+		os.Exit(1)
+	}
+	// outputs are generated per input package, only the changed ones are regenerated
+	loaderArgs.Watch.Watch(dirs, common.RegenerateChanged(arguments, loaderArgs, generate))
 }
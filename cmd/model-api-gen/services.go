@@ -0,0 +1,9 @@
+//go:build columndocs
+
+package main
+
+import (
+	// registers onecloud services, whose managers' tables are documented by --column-docs,
+	// linked by the columndocs build only since generation itself only parses source
+	_ "yunion.io/x/code-generator/pkg/models"
+)
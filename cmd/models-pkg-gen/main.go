@@ -1,24 +1,57 @@
 package main
 
 import (
+	goflag "flag"
 	"os"
 	"path/filepath"
 
-	"k8s.io/gengo/args"
-	"k8s.io/klog"
+	"github.com/spf13/pflag"
+	"k8s.io/klog/v2"
 
+	"yunion.io/x/code-generator/pkg/common"
 	"yunion.io/x/code-generator/pkg/models-pkg-gen/generators"
 )
 
 func main() {
 	klog.InitFlags(nil)
-	arguments := args.Default()
+	arguments := common.NewGeneratorArgs()
+	loaderArgs := &common.LoaderArgs{}
+	var services []string
 
 	// Override defaults.
 	arguments.OutputFileBaseName = "zz_generated.models"
-	arguments.GoHeaderFilePath = filepath.Join(args.DefaultSourceTree(), "yunion.io/x/code-generator/boilerplate/boilerplate.go.txt")
+	arguments.GoHeaderFilePath = filepath.Join(common.DefaultSourceTree(), "yunion.io/x/code-generator/boilerplate/boilerplate.go.txt")
 
-	if err := arguments.Execute(
+	arguments.AddFlags(pflag.CommandLine)
+	loaderArgs.AddFlags(pflag.CommandLine)
+	pflag.StringSliceVar(&services, "service", nil, "Out-of-tree onecloud style services registered by a generated file of output package, which is imported by main of the swagger-gen build, e.g. mysvc=example.com/mysvc/pkg/mysvc/service.InitHandlers")
+	pflag.CommandLine.AddGoFlagSet(goflag.CommandLine)
+	pflag.Parse()
+	common.SetupLogging(&loaderArgs.Log)
+	if loaderArgs.Watch.Enabled {
+		klog.Errorf("--watch is not supported by models-pkg-gen")
+		os.Exit(1)
+	}
+
+	if len(services) != 0 {
+		// registering into code-generator source tree forks it, the registration belongs to the caller
+		if arguments.OutputPackagePath == "yunion.io/x/code-generator/pkg/models" {
+			klog.Errorf("--service should be registered by a package of the swagger-gen build, not %s", arguments.OutputPackagePath)
+			os.Exit(1)
+		}
+		dir := filepath.Join(arguments.OutputBase, arguments.OutputPackagePath)
+		if err := generators.WriteServiceRegistration(dir, arguments.OutputFileBaseName+"_services", services); err != nil {
+			klog.Errorf("Error: %v", err)
+			os.Exit(1)
+		}
+		if len(arguments.InputDirs) == 0 {
+			return
+		}
+	}
+
+	if err := common.Execute(
+		arguments,
+		loaderArgs,
 		generators.NameSystems(),
 		generators.DefaultNameSystem(),
 		generators.Packages,
@@ -18,6 +18,8 @@ import (
 	flag "github.com/spf13/pflag"
 
 	"yunion.io/x/log"
+
+	"yunion.io/x/code-generator/pkg/swaggerserve"
 )
 
 func NewRootCmd() *cobra.Command {
@@ -26,6 +28,9 @@ func NewRootCmd() *cobra.Command {
 		Short: "swagger serve for onecloud project",
 	}
 	cmds.AddCommand(newGenerateCmd())
+	cmds.AddCommand(newValidateCmd())
+	cmds.AddCommand(newLinkCmd())
+	cmds.AddCommand(newSplitCmd())
 	return cmds
 }
 
@@ -67,12 +72,12 @@ func (o generateOption) UICss() string {
 	return o.urlJoin("swagger-ui.css")
 }
 
-func (o generateOption) newUIIndexHTMLConfig() (*UIIndexHTMLConfig, error) {
-	config := &UIIndexHTMLConfig{
+func (o generateOption) newUIIndexHTMLConfig() (*swaggerserve.UIIndexHTMLConfig, error) {
+	config := &swaggerserve.UIIndexHTMLConfig{
 		UICss:              o.UICss(),
 		BundleJS:           o.BundleJS(),
 		StandalonePresetJS: o.StandalonePresetJS(),
-		URLs:               make([]*SwaggerFile, 0),
+		URLs:               make([]*swaggerserve.SwaggerFile, 0),
 	}
 	loads.AddLoader(fmts.YAMLMatcher, fmts.YAMLDoc)
 	for _, spec := range o.SpecFiles {
@@ -118,7 +123,7 @@ func initGenerateCmdOpts(flagSet *flag.FlagSet, cfg *generateOption) {
 	flagSet.IntVarP(&cfg.ServePort, "serve-port", "p", 0, "server listen port, random defaultly")
 }
 
-func newSwaggerFile(specPath string) (*SwaggerFile, error) {
+func newSwaggerFile(specPath string) (*swaggerserve.SwaggerFile, error) {
 	spec, err := loads.Spec(specPath)
 	if err != nil {
 		return nil, errors.Wrapf(err, "load swagger spec %s", specPath)
@@ -128,7 +133,7 @@ func newSwaggerFile(specPath string) (*SwaggerFile, error) {
 	if name == "" {
 		name = info.Description
 	}
-	return &SwaggerFile{
+	return &swaggerserve.SwaggerFile{
 		Name: name,
 		Path: fmt.Sprintf("%s", filepath.Base(specPath)),
 	}, nil
@@ -186,15 +191,16 @@ func serveHTTP(cfg *generateOption) error {
 	if !cfg.Serve {
 		return nil
 	}
-	fs := http.FileServer(http.Dir(cfg.OutputDir))
-	http.Handle("/", fs)
+	// net/http/pprof registers itself to http.DefaultServeMux, never serve it on the network
+	mux := http.NewServeMux()
+	mux.Handle("/", http.FileServer(http.Dir(cfg.OutputDir)))
 	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", cfg.ServeAddr, cfg.ServePort))
 	if err != nil {
 		return err
 	}
 	ch := make(chan bool, 0)
 	go func() {
-		http.Serve(listener, nil)
+		http.Serve(listener, mux)
 		ch <- true
 	}()
 	url := "http://" + listener.Addr().String()
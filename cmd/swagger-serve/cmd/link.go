@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/go-openapi/loads"
+	"github.com/go-openapi/loads/fmts"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+
+	"yunion.io/x/log"
+
+	"yunion.io/x/code-generator/pkg/swaggerserve"
+)
+
+type linkOption struct {
+	SpecFile        string
+	DefinitionsFile string
+	OutputFile      string
+}
+
+func newLinkCmd() *cobra.Command {
+	cfg := new(linkOption)
+	cmd := &cobra.Command{
+		Use:   "link",
+		Short: "fill placeholder definitions of swagger-gen --external-definitions with shared definitions spec",
+		Run: func(_ *cobra.Command, _ []string) {
+			checkErr(doLink(cfg))
+		},
+	}
+	initLinkCmdOpts(cmd.PersistentFlags(), cfg)
+	return cmd
+}
+
+func initLinkCmdOpts(flagSet *flag.FlagSet, cfg *linkOption) {
+	flagSet.StringVarP(&cfg.SpecFile, "input", "i", "", "input service swagger spec yaml or json file")
+	flagSet.StringVarP(&cfg.DefinitionsFile, "definitions", "d", "", "shared definitions swagger spec generated from swagger-gen --external-definitions-file")
+	flagSet.StringVarP(&cfg.OutputFile, "output", "o", "", "output linked swagger spec json file")
+}
+
+func doLink(cfg *linkOption) error {
+	if cfg.SpecFile == "" || cfg.DefinitionsFile == "" || cfg.OutputFile == "" {
+		return errors.New("input, definitions and output files are required")
+	}
+	loads.AddLoader(fmts.YAMLMatcher, fmts.YAMLDoc)
+	svc, err := loads.Spec(cfg.SpecFile)
+	if err != nil {
+		return errors.Wrapf(err, "load swagger spec %s", cfg.SpecFile)
+	}
+	shared, err := loads.Spec(cfg.DefinitionsFile)
+	if err != nil {
+		return errors.Wrapf(err, "load definitions spec %s", cfg.DefinitionsFile)
+	}
+	for _, name := range swaggerserve.LinkDefinitions(svc.Spec(), shared.Spec()) {
+		log.Warningf("placeholder definition %s is not found in %s", name, cfg.DefinitionsFile)
+	}
+	content, err := json.MarshalIndent(svc.Spec(), "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "marshal linked spec")
+	}
+	if err := ioutil.WriteFile(cfg.OutputFile, content, 0644); err != nil {
+		return err
+	}
+	log.Infof("write linked swagger spec to %s", cfg.OutputFile)
+	return nil
+}
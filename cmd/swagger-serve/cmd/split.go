@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"github.com/go-openapi/loads"
+	"github.com/go-openapi/loads/fmts"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+
+	"yunion.io/x/log"
+
+	"yunion.io/x/code-generator/pkg/swaggerserve"
+)
+
+type splitOption struct {
+	SpecFile  string
+	OutputDir string
+}
+
+func newSplitCmd() *cobra.Command {
+	cfg := new(splitOption)
+	cmd := &cobra.Command{
+		Use:   "split",
+		Short: "split swagger spec to fragments of operations by tag plus an index, to lazy load per resource specs",
+		Run: func(_ *cobra.Command, _ []string) {
+			checkErr(doSplit(cfg))
+		},
+	}
+	initSplitCmdOpts(cmd.PersistentFlags(), cfg)
+	return cmd
+}
+
+func initSplitCmdOpts(flagSet *flag.FlagSet, cfg *splitOption) {
+	flagSet.StringVarP(&cfg.SpecFile, "input", "i", "", "input swagger spec yaml or json file")
+	flagSet.StringVarP(&cfg.OutputDir, "output", "o", "", "output directory of fragment json files and index.json")
+}
+
+func doSplit(cfg *splitOption) error {
+	if cfg.SpecFile == "" || cfg.OutputDir == "" {
+		return errors.New("input file and output directory are required")
+	}
+	loads.AddLoader(fmts.YAMLMatcher, fmts.YAMLDoc)
+	doc, err := loads.Spec(cfg.SpecFile)
+	if err != nil {
+		return errors.Wrapf(err, "load swagger spec %s", cfg.SpecFile)
+	}
+	index, err := swaggerserve.Split(doc.Spec(), cfg.OutputDir)
+	if err != nil {
+		return err
+	}
+	log.Infof("split swagger spec %s to %d fragments in %s", cfg.SpecFile, len(index.Specs), cfg.OutputDir)
+	return nil
+}
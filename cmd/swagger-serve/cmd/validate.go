@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"github.com/go-openapi/loads"
+	"github.com/go-openapi/loads/fmts"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+
+	"yunion.io/x/log"
+
+	"yunion.io/x/code-generator/pkg/swaggerserve"
+)
+
+type validateOption struct {
+	SpecFile  string
+	SourceMap string
+}
+
+func newValidateCmd() *cobra.Command {
+	cfg := new(validateOption)
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "validate swagger spec and map errors back to source methods",
+		Run: func(_ *cobra.Command, _ []string) {
+			checkErr(doValidate(cfg))
+		},
+	}
+	initValidateCmdOpts(cmd.PersistentFlags(), cfg)
+	return cmd
+}
+
+func initValidateCmdOpts(flagSet *flag.FlagSet, cfg *validateOption) {
+	flagSet.StringVarP(&cfg.SpecFile, "input", "i", "", "input swagger spec yaml or json file")
+	flagSet.StringVarP(&cfg.SourceMap, "source-map", "m", "", "operation id to source mapping file written by swagger-gen --source-map")
+}
+
+func doValidate(cfg *validateOption) error {
+	if cfg.SpecFile == "" {
+		return errors.New("input spec file is required")
+	}
+	loads.AddLoader(fmts.YAMLMatcher, fmts.YAMLDoc)
+	doc, err := loads.Spec(cfg.SpecFile)
+	if err != nil {
+		return errors.Wrapf(err, "load swagger spec %s", cfg.SpecFile)
+	}
+	sourceMap, err := swaggerserve.LoadSourceMap(cfg.SourceMap)
+	if err != nil {
+		return err
+	}
+	errs := swaggerserve.Validate(doc, sourceMap)
+	if len(errs) == 0 {
+		log.Infof("swagger spec %s is valid", cfg.SpecFile)
+		return nil
+	}
+	for _, e := range errs {
+		log.Errorf("%s", e)
+	}
+	return errors.Errorf("swagger spec %s has %d errors", cfg.SpecFile, len(errs))
+}
@@ -1,25 +1,59 @@
 module yunion.io/x/code-generator
 
-go 1.13
+go 1.23
 
 require (
-	github.com/go-openapi/loads v0.19.4
+	github.com/go-logr/logr v1.2.0
+	github.com/go-openapi/errors v0.19.4
+	github.com/go-openapi/loads v0.19.5
+	github.com/go-openapi/spec v0.19.7
+	github.com/go-openapi/strfmt v0.19.5
+	github.com/go-openapi/validate v0.19.7
+	github.com/go-swagger/go-swagger v0.23.0
 	github.com/minio/highwayhash v1.0.0 // indirect
-	github.com/pkg/errors v0.8.1
+	github.com/pkg/errors v0.9.1
 	github.com/serialx/hashring v0.0.0-20190515033939-7706f26af194 // indirect
+	github.com/sirupsen/logrus v1.4.2
 	github.com/skratchdot/open-golang v0.0.0-20190402232053-79abb63cd66e
 	github.com/spf13/cobra v0.0.3
 	github.com/spf13/pflag v1.0.5
 	github.com/tredoe/osutil v0.0.0-20191018075336-e272fdda81c8 // indirect
-	golang.org/x/tools v0.0.0-20191112005509-a3f652f18032
-	k8s.io/gengo v0.0.0-20191120174120-e74f70b9b27e
-	k8s.io/klog v1.0.0
+	golang.org/x/tools v0.30.0
+	gopkg.in/yaml.v2 v2.2.8
+	k8s.io/gengo/v2 v2.0.0-20250922181213-ec3ebc5fd46b
+	k8s.io/klog/v2 v2.80.1
 	yunion.io/x/log v0.0.0-20190629062853-9f6483a7103d
 	yunion.io/x/onecloud v0.0.0-00010101000000-000000000000
 	yunion.io/x/pkg v0.0.0-20200103043034-27c6f82160fa
 )
 
+require (
+	github.com/PuerkitoBio/purell v1.1.1 // indirect
+	github.com/PuerkitoBio/urlesc v0.0.0-20170810143723-de5bf2ad4578 // indirect
+	github.com/asaskevich/govalidator v0.0.0-20200108200545-475eaeb16496 // indirect
+	github.com/go-openapi/analysis v0.19.10 // indirect
+	github.com/go-openapi/jsonpointer v0.19.3 // indirect
+	github.com/go-openapi/jsonreference v0.19.3 // indirect
+	github.com/go-openapi/runtime v0.19.12 // indirect
+	github.com/go-openapi/swag v0.19.8 // indirect
+	github.com/go-stack/stack v1.8.0 // indirect
+	github.com/inconshreveable/mousetrap v1.1.0 // indirect
+	github.com/konsorten/go-windows-terminal-sequences v1.0.2 // indirect
+	github.com/mailru/easyjson v0.7.1 // indirect
+	github.com/mattn/go-colorable v0.1.2 // indirect
+	github.com/mattn/go-isatty v0.0.8 // indirect
+	github.com/mgutz/ansi v0.0.0-20170206155736-9520e82c474b // indirect
+	github.com/mitchellh/mapstructure v1.1.2 // indirect
+	go.mongodb.org/mongo-driver v1.3.1 // indirect
+	golang.org/x/crypto v0.33.0 // indirect
+	golang.org/x/mod v0.23.0 // indirect
+	golang.org/x/net v0.35.0 // indirect
+	golang.org/x/sync v0.11.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+	golang.org/x/term v0.29.0 // indirect
+	golang.org/x/text v0.22.0 // indirect
+)
+
 replace (
-	golang.org/x/tools => github.com/yousong/tools v0.0.0-20191028041046-a3599409985d
 	yunion.io/x/onecloud => ../onecloud
 )
@@ -6,6 +6,7 @@
     #--output-package yunion.io/x/onecloud/pkg/generated/swagger/compute
 
 ./_output/bin/swagger-gen \
+    --load-services identity \
     --input-dirs yunion.io/x/onecloud/pkg/keystone/tokens \
     --input-dirs yunion.io/x/onecloud/pkg/keystone/models \
     --output-package yunion.io/x/onecloud/pkg/generated/swagger/identity
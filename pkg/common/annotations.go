@@ -0,0 +1,107 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// annotation targets, the declarations annotation comment is put on
+	TargetType     = "type"
+	TargetModel    = "model"
+	TargetManager  = "manager"
+	TargetMethod   = "method"
+	TargetFunction = "function"
+	TargetField    = "field"
+	TargetConst    = "const"
+)
+
+// Annotation describes a +<name>=<value> comment tag read by generators, it's registered
+// next to the tag constant and exported to editors by WriteAnnotationCatalog
+type Annotation struct {
+	// Name is the tag name without leading +, e.g. onecloud:swagger-gen-route-path,
+	// it's the name prefix if Prefix is true
+	Name string `json:"name"`
+	// Generator is the command reading the tag, e.g. swagger-gen
+	Generator string `json:"generator"`
+	// Syntax is the value syntax, empty if the tag has no value
+	Syntax string `json:"syntax,omitempty"`
+	// Choices are the allowed values
+	Choices []string `json:"choices,omitempty"`
+	// Targets are the declarations tag is put on
+	Targets []string `json:"targets"`
+	// Description is the one line usage of tag
+	Description string `json:"description"`
+	// Repeatable is true if the tag may be written more than once
+	Repeatable bool `json:"repeatable,omitempty"`
+	// Indexed is true if the tag may be suffixed by index like [0] to declare several values
+	Indexed bool `json:"indexed,omitempty"`
+	// Prefix is true if Name is suffixed by a variant, e.g. language of onecloud:swagger-gen-summary-
+	Prefix bool `json:"prefix,omitempty"`
+}
+
+// AnnotationCatalog is the machine readable catalog of annotations
+type AnnotationCatalog struct {
+	Annotations []Annotation `json:"annotations"`
+}
+
+var (
+	annotations = make(map[string]Annotation)
+
+	annotationIndexRegexp = regexp.MustCompile(`^([^\[]+)\[[^\]]*\]$`)
+)
+
+// RegisterAnnotations adds annotations to the catalog, it panics on duplicated names,
+// the result is used to register in package variable declaration
+func RegisterAnnotations(as ...Annotation) bool {
+	for _, a := range as {
+		if _, ok := annotations[a.Name]; ok {
+			panic(fmt.Sprintf("annotation %s is registered twice", a.Name))
+		}
+		annotations[a.Name] = a
+	}
+	return true
+}
+
+// Annotations returns registered annotations sorted by name
+func Annotations() []Annotation {
+	ret := make([]Annotation, 0, len(annotations))
+	for _, a := range annotations {
+		ret = append(ret, a)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+	return ret
+}
+
+// LookupAnnotation returns the annotation of tag name, index suffix of indexed tags
+// and variant of prefix tags are accepted, e.g. onecloud:swagger-gen-route-path[1]
+func LookupAnnotation(name string) (Annotation, bool) {
+	if a, ok := annotations[name]; ok && !a.Prefix {
+		return a, true
+	}
+	if m := annotationIndexRegexp.FindStringSubmatch(name); m != nil {
+		if a, ok := annotations[m[1]]; ok && a.Indexed {
+			return a, true
+		}
+		return Annotation{}, false
+	}
+	for _, a := range annotations {
+		if a.Prefix && strings.HasPrefix(name, a.Name) && len(name) > len(a.Name) {
+			return a, true
+		}
+	}
+	return Annotation{}, false
+}
+
+// WriteAnnotationCatalog writes registered annotations as indented json,
+// <placeholder> of syntax is not escaped
+func WriteAnnotationCatalog(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(AnnotationCatalog{Annotations: Annotations()})
+}
@@ -0,0 +1,56 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestLookupAnnotation(t *testing.T) {
+	RegisterAnnotations(
+		Annotation{Name: "onecloud:test-route", Generator: "test", Indexed: true},
+		Annotation{Name: "onecloud:test-summary-", Generator: "test", Prefix: true},
+	)
+	defer func() {
+		delete(annotations, "onecloud:test-route")
+		delete(annotations, "onecloud:test-summary-")
+	}()
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: TagAPIVersion, want: TagAPIVersion},
+		{name: TagSecret + "[0]"},
+		{name: "onecloud:test-route[1]", want: "onecloud:test-route"},
+		{name: "onecloud:test-summary-zh", want: "onecloud:test-summary-"},
+		{name: "onecloud:test-summary-"},
+		{name: "onecloud:test-unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, ok := LookupAnnotation(tt.name)
+			if ok != (tt.want != "") || a.Name != tt.want {
+				t.Errorf("LookupAnnotation() = %q, %v, want %q", a.Name, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteAnnotationCatalog(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := WriteAnnotationCatalog(buf); err != nil {
+		t.Fatal(err)
+	}
+	catalog := AnnotationCatalog{}
+	if err := json.Unmarshal(buf.Bytes(), &catalog); err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0)
+	for _, a := range catalog.Annotations {
+		names = append(names, a.Name)
+	}
+	want := []string{TagSecret, TagAPIVersion}
+	if len(names) != len(want) || names[0] != want[0] || names[1] != want[1] {
+		t.Errorf("catalog annotations = %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,85 @@
+package common
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// GeneratorArgs are the arguments passed to generators, they keep the flags and defaults of
+// k8s.io/gengo/args, which is dropped by gengo v2, so generator commands keep working unchanged
+type GeneratorArgs struct {
+	// InputDirs are the import paths or patterns of input packages
+	InputDirs []string
+	// OutputBase is the source tree output packages are written to
+	OutputBase string
+	// OutputPackagePath is the output package path within the source tree
+	OutputPackagePath string
+	// OutputFileBaseName is the output file name without .go suffix
+	OutputFileBaseName string
+	// GoHeaderFilePath is the file of boilerplate header text
+	GoHeaderFilePath string
+	// GeneratedByCommentTemplate is the "Code generated by" comment below the boilerplate,
+	// GENERATOR_NAME is replaced by name of the generator command
+	GeneratedByCommentTemplate string
+	// VerifyOnly only verifies existing output, nothing is written
+	VerifyOnly bool
+	// GeneratedBuildTag is the build tag of generated files, which are ignored when loading inputs
+	GeneratedBuildTag string
+	// CustomArgs are the arguments of specific generator
+	CustomArgs interface{}
+}
+
+// NewGeneratorArgs returns arguments of gengo defaults, which may be changed before AddFlags
+func NewGeneratorArgs() *GeneratorArgs {
+	return &GeneratorArgs{
+		OutputBase:                 DefaultSourceTree(),
+		GoHeaderFilePath:           filepath.Join(DefaultSourceTree(), "k8s.io/gengo/boilerplate/boilerplate.go.txt"),
+		GeneratedBuildTag:          "ignore_autogenerated",
+		GeneratedByCommentTemplate: "// Code generated by GENERATOR_NAME. DO NOT EDIT.",
+	}
+}
+
+// AddFlags add generator flags to fs
+func (g *GeneratorArgs) AddFlags(fs *pflag.FlagSet) {
+	fs.StringSliceVarP(&g.InputDirs, "input-dirs", "i", g.InputDirs, "Comma-separated list of import paths to get input types from.")
+	fs.StringVarP(&g.OutputBase, "output-base", "o", g.OutputBase, "Output base; defaults to $GOPATH/src/ or ./ if $GOPATH is not set.")
+	fs.StringVarP(&g.OutputPackagePath, "output-package", "p", g.OutputPackagePath, "Base package path.")
+	fs.StringVarP(&g.OutputFileBaseName, "output-file-base", "O", g.OutputFileBaseName, "Base name (without .go suffix) for output files.")
+	fs.StringVarP(&g.GoHeaderFilePath, "go-header-file", "h", g.GoHeaderFilePath, "File containing boilerplate header text. The string YEAR will be replaced with the current 4-digit year.")
+	fs.BoolVar(&g.VerifyOnly, "verify-only", g.VerifyOnly, "If true, only verify existing output, do not write anything.")
+	fs.StringVar(&g.GeneratedBuildTag, "build-tag", g.GeneratedBuildTag, "A Go build tag to use to identify files generated by this command. Should be unique.")
+}
+
+// LoadGoBoilerplate loads the boilerplate file of --go-header-file, YEAR is replaced by the current year
+// and the generated by comment is appended
+func (g *GeneratorArgs) LoadGoBoilerplate() ([]byte, error) {
+	b, err := ioutil.ReadFile(g.GoHeaderFilePath)
+	if err != nil {
+		return nil, err
+	}
+	b = bytes.Replace(b, []byte("YEAR"), []byte(strconv.Itoa(time.Now().Year())), -1)
+	if g.GeneratedByCommentTemplate != "" {
+		if len(b) != 0 {
+			b = append(b, '\n')
+		}
+		b = append(b, fmt.Sprintf("%s\n\n", GeneratedMarker(g.GeneratedByCommentTemplate))...)
+	}
+	return b, nil
+}
+
+// DefaultSourceTree returns the src directory of the first entry in $GOPATH, ./ if $GOPATH is unset
+func DefaultSourceTree() string {
+	paths := strings.Split(os.Getenv("GOPATH"), string(filepath.ListSeparator))
+	if len(paths) > 0 && len(paths[0]) > 0 {
+		return filepath.Join(paths[0], "src")
+	}
+	return "./"
+}
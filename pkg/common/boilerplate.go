@@ -0,0 +1,138 @@
+package common
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"k8s.io/gengo/v2/generator"
+)
+
+// BoilerplateData are the variables of boilerplate templates, e.g. // Copyright {{.Year}} Yunion
+type BoilerplateData struct {
+	// Year is the current year
+	Year int
+	// Service is the service of input package, e.g. compute, or base name of output package
+	Service string
+	// Package is the output package path
+	Package string
+}
+
+// boilerplateOverride replaces --go-header-file of output packages under prefix
+type boilerplateOverride struct {
+	prefix  string
+	content []byte
+}
+
+// loadBoilerplateOverrides reads --boilerplate values like yunion.io/x/cloudmux=hack/boilerplate.txt
+func loadBoilerplateOverrides(vals []string) ([]boilerplateOverride, error) {
+	ret := make([]boilerplateOverride, 0, len(vals))
+	for _, val := range vals {
+		idx := strings.Index(val, "=")
+		if idx <= 0 || idx == len(val)-1 {
+			return nil, fmt.Errorf("invalid boilerplate %q, should be <package prefix>=<file>", val)
+		}
+		content, err := ioutil.ReadFile(val[idx+1:])
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, boilerplateOverride{prefix: val[:idx], content: content})
+	}
+	return ret, nil
+}
+
+// boilerplateOf returns content of the longest override prefixing output package pkgPath
+func boilerplateOf(overrides []boilerplateOverride, pkgPath string) ([]byte, bool) {
+	var (
+		content []byte
+		matched = -1
+	)
+	for _, o := range overrides {
+		if (pkgPath == o.prefix || strings.HasPrefix(pkgPath, o.prefix+"/")) && len(o.prefix) > matched {
+			content, matched = o.content, len(o.prefix)
+		}
+	}
+	return content, matched >= 0
+}
+
+// renderBoilerplate executes header as template of data, YEAR is still replaced like gengo
+func renderBoilerplate(header []byte, data BoilerplateData) ([]byte, error) {
+	header = bytes.Replace(header, []byte("YEAR"), []byte(strconv.Itoa(data.Year)), -1)
+	if !bytes.Contains(header, []byte("{{")) {
+		return header, nil
+	}
+	tmpl, err := template.New("boilerplate").Option("missingkey=error").Parse(string(header))
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// boilerplatePackage renders header of package files
+type boilerplatePackage struct {
+	generator.Target
+	header []byte
+}
+
+func (p *boilerplatePackage) Header(string) []byte {
+	return p.header
+}
+
+// sourceBoilerplatePackage is boilerplatePackage of source package
+type sourceBoilerplatePackage struct {
+	boilerplatePackage
+	source string
+}
+
+func (p *sourceBoilerplatePackage) InputPackage() string {
+	return p.source
+}
+
+// ApplyBoilerplates renders headers of packages as boilerplate templates, boilerplate of
+// --go-header-file in headers is replaced by the one of --boilerplate matching output package
+func ApplyBoilerplates(pkgs []generator.Target, g *GeneratorArgs, vals []string) ([]generator.Target, error) {
+	overrides, err := loadBoilerplateOverrides(vals)
+	if err != nil {
+		return nil, err
+	}
+	var defaultBoilerplate []byte
+	if len(overrides) != 0 {
+		if defaultBoilerplate, err = ioutil.ReadFile(g.GoHeaderFilePath); err != nil {
+			return nil, err
+		}
+		defaultBoilerplate = bytes.Replace(defaultBoilerplate, []byte("YEAR"), []byte(strconv.Itoa(time.Now().Year())), -1)
+	}
+	ret := make([]generator.Target, 0, len(pkgs))
+	for _, p := range pkgs {
+		// headers of all files in package are the same for gengo SimpleTarget without doc comment
+		header := p.Header("")
+		if content, ok := boilerplateOf(overrides, p.Path()); ok && len(defaultBoilerplate) != 0 {
+			header = bytes.Replace(header, defaultBoilerplate, content, 1)
+		}
+		data := BoilerplateData{Year: time.Now().Year(), Service: path.Base(p.Path()), Package: p.Path()}
+		sp, isSource := p.(SourcePackage)
+		if isSource {
+			data.Service = newOutputFileData("", sp.InputPackage()).Service
+		}
+		header, err := renderBoilerplate(header, data)
+		if err != nil {
+			return nil, fmt.Errorf("boilerplate of package %s: %v", p.Path(), err)
+		}
+		bp := boilerplatePackage{Target: p, header: header}
+		if isSource {
+			ret = append(ret, &sourceBoilerplatePackage{boilerplatePackage: bp, source: sp.InputPackage()})
+		} else {
+			ret = append(ret, &bp)
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,76 @@
+package common
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/gengo/v2/generator"
+)
+
+func TestApplyBoilerplates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boilerplate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defaultFile := filepath.Join(dir, "boilerplate.go.txt")
+	cloudmuxFile := filepath.Join(dir, "cloudmux.go.txt")
+	if err := ioutil.WriteFile(defaultFile, []byte("// Copyright 2019 Yunion\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(cloudmuxFile, []byte("// Copyright 2019 Yunion, {{.Service}} of cloudmux\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	g := &GeneratorArgs{GoHeaderFilePath: defaultFile}
+	const generatedBy = "\n// Code generated by model-api-gen. DO NOT EDIT.\n\n"
+	pkgs := []generator.Target{
+		NewSourcePackage("yunion.io/x/onecloud/pkg/compute/models", &generator.SimpleTarget{
+			PkgPath:       "yunion.io/x/onecloud/pkg/apis/compute",
+			HeaderComment: []byte("// Copyright 2019 Yunion\n" + generatedBy),
+		}),
+		NewSourcePackage("yunion.io/x/cloudmux/pkg/multicloud/esxi", &generator.SimpleTarget{
+			PkgPath:       "yunion.io/x/cloudmux/pkg/apis/esxi",
+			HeaderComment: []byte("// Copyright 2019 Yunion\n" + generatedBy),
+		}),
+		&generator.SimpleTarget{
+			PkgPath:       "yunion.io/x/cloudmux/pkg/generated/swagger",
+			HeaderComment: []byte("// Copyright 2019 Yunion\n" + generatedBy + "// Package swagger"),
+		},
+	}
+	got, err := ApplyBoilerplates(pkgs, g, []string{"yunion.io/x/cloudmux=" + cloudmuxFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"// Copyright 2019 Yunion\n" + generatedBy,
+		"// Copyright 2019 Yunion, esxi of cloudmux\n" + generatedBy,
+		"// Copyright 2019 Yunion, swagger of cloudmux\n" + generatedBy + "// Package swagger",
+	}
+	for i, p := range got {
+		if header := string(p.Header("doc.go")); header != want[i] {
+			t.Errorf("package %s header = %q, want %q", p.Path(), header, want[i])
+		}
+		if _, ok := p.(SourcePackage); ok != (i < 2) {
+			t.Errorf("package %s is source package = %v", p.Path(), ok)
+		}
+	}
+	if _, err := ApplyBoilerplates(pkgs, g, []string{"yunion.io/x/cloudmux"}); err == nil {
+		t.Errorf("ApplyBoilerplates() should fail for boilerplate without file")
+	}
+}
+
+func Test_renderBoilerplate(t *testing.T) {
+	data := BoilerplateData{Year: 2026, Service: "compute", Package: "yunion.io/x/onecloud/pkg/apis/compute"}
+	got, err := renderBoilerplate([]byte("// Copyright YEAR Yunion\n// {{.Service}} apis, {{.Year}}\n"), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "// Copyright 2026 Yunion\n// compute apis, 2026\n"; string(got) != want {
+		t.Errorf("renderBoilerplate() = %q, want %q", got, want)
+	}
+	if _, err := renderBoilerplate([]byte("// {{.Owner}}\n"), data); err == nil {
+		t.Errorf("renderBoilerplate() should fail for unknown variable")
+	}
+}
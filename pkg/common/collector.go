@@ -0,0 +1,80 @@
+package common
+
+import (
+	"strings"
+
+	"k8s.io/gengo/v2/types"
+	"k8s.io/klog/v2"
+
+	"yunion.io/x/pkg/util/sets"
+)
+
+// TypeCollector collects struct types of source package, resource models and the ones accepted
+// by Include are models, structs named <Model>Manager are managers of models
+type TypeCollector struct {
+	// SourcePackage is the package types are collected from, types of other packages are skipped
+	SourcePackage string
+	// CommonDB is true if SourcePackage is cloudcommon/db, its resource bases are models as well
+	CommonDB bool
+	// APIVersion skips types tagged with other api versions, empty collects all versions
+	APIVersion string
+	// Include returns true for structs collected as models besides resource models, e.g. tagged ones
+	Include func(t *types.Type) bool
+
+	// Models are the names of collected models
+	Models sets.String
+	// Managers maps model name to its manager
+	Managers map[string]*types.Type
+}
+
+// NewTypeCollector returns collector of source package srcPkg
+func NewTypeCollector(srcPkg string) *TypeCollector {
+	return &TypeCollector{
+		SourcePackage: srcPkg,
+		Models:        sets.NewString(),
+		Managers:      make(map[string]*types.Type),
+	}
+}
+
+// isModel returns true if t is a model
+func (c *TypeCollector) isModel(t *types.Type) bool {
+	if IsResourceModel(t, c.CommonDB) {
+		return true
+	}
+	return c.Include != nil && c.Include(t)
+}
+
+// Collect adds models and managers of pkgTypes, returns the models added in order of pkgTypes,
+// uninstantiated generic structs are skipped because gengo can't scan their members
+func (c *TypeCollector) Collect(pkgTypes []*types.Type) []*types.Type {
+	models := make([]*types.Type, 0)
+	restTypes := make([]*types.Type, 0)
+	for _, t := range pkgTypes {
+		if t.Kind != types.Struct || !InSourcePackage(t, c.SourcePackage) {
+			continue
+		}
+		if IsGenericDeclaration(t) {
+			klog.Warningf("skip generic type declaration %s, only its instantiations are generated", t.String())
+			continue
+		}
+		if c.APIVersion != "" && !InAPIVersion(t.CommentLines, c.APIVersion) {
+			klog.V(1).Infof("skip type %s not in api version %s", t.String(), c.APIVersion)
+			continue
+		}
+		if c.isModel(t) {
+			c.Models.Insert(t.String())
+			models = append(models, t)
+		} else {
+			restTypes = append(restTypes, t)
+		}
+	}
+	for _, t := range restTypes {
+		if !strings.HasSuffix(t.Name.Name, "Manager") {
+			continue
+		}
+		if modelName := strings.TrimSuffix(t.String(), "Manager"); c.Models.Has(modelName) {
+			c.Managers[modelName] = t
+		}
+	}
+	return models
+}
@@ -0,0 +1,39 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/gengo/v2/types"
+)
+
+func TestTypeCollector(t *testing.T) {
+	pkg := "yunion.io/x/onecloud/pkg/compute/models"
+	base := &types.Type{Name: types.Name{Package: "yunion.io/x/onecloud/pkg/cloudcommon/db", Name: "SStandaloneResourceBase"}, Kind: types.Struct}
+	newStruct := func(name string, comments []string, members ...types.Member) *types.Type {
+		return &types.Type{Name: types.Name{Package: pkg, Name: name}, Kind: types.Struct, CommentLines: comments, Members: members}
+	}
+	resource := types.Member{Name: "SStandaloneResourceBase", Embedded: true, Type: base}
+	guest := newStruct("SGuest", nil, resource)
+	guestManager := newStruct("SGuestManager", nil)
+	disk := newStruct("SDisk", []string{"+onecloud:swagger-gen-version=v2"}, resource)
+	tagged := newStruct("SGuestStatus", []string{"+onecloud:model-api-gen"})
+	generic := newStruct("SPagedList", nil, types.Member{Name: "Items", Type: &types.Type{Kind: types.Unsupported}})
+	other := &types.Type{Name: types.Name{Package: "yunion.io/x/onecloud/pkg/image/models", Name: "SImage"}, Kind: types.Struct, Members: []types.Member{resource}}
+
+	c := NewTypeCollector(pkg)
+	c.APIVersion = DefaultAPIVersion
+	c.Include = func(t *types.Type) bool {
+		return ParseTags(t.CommentLines).Has("onecloud:model-api-gen")
+	}
+	got := c.Collect([]*types.Type{guest, guestManager, disk, tagged, generic, other})
+	if !reflect.DeepEqual(got, []*types.Type{guest, tagged}) {
+		t.Errorf("Collect() = %v, want [%s %s]", got, guest, tagged)
+	}
+	if want := []string{guest.String(), tagged.String()}; !reflect.DeepEqual(c.Models.List(), want) {
+		t.Errorf("Models = %v, want %v", c.Models.List(), want)
+	}
+	if want := map[string]*types.Type{guest.String(): guestManager}; !reflect.DeepEqual(c.Managers, want) {
+		t.Errorf("Managers = %v, want %v", c.Managers, want)
+	}
+}
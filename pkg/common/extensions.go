@@ -0,0 +1,27 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AppendExtension adds vendor extension key: val to go-swagger field annotations lines,
+// without comment marker. go-swagger reads extensions from Extensions: till the end of comment,
+// so the block is started at the end if lines have none; existing key is kept.
+func AppendExtension(lines []string, key, val string) []string {
+	inBlock := false
+	for _, l := range lines {
+		l = strings.TrimSpace(l)
+		if l == "Extensions:" {
+			inBlock = true
+			continue
+		}
+		if inBlock && strings.HasPrefix(l, key+":") {
+			return lines
+		}
+	}
+	if !inBlock {
+		lines = append(lines, "Extensions:")
+	}
+	return append(lines, fmt.Sprintf("  %s: %s", key, val))
+}
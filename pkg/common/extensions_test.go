@@ -0,0 +1,56 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAppendExtension(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  []string
+	}{
+		{
+			name:  "no extensions",
+			lines: []string{"name of server"},
+			want:  []string{"name of server", "Extensions:", "  x-nullable: true"},
+		},
+		{
+			name:  "append to block",
+			lines: []string{"max length: 36", "Extensions:", "  x-charset: ascii"},
+			want:  []string{"max length: 36", "Extensions:", "  x-charset: ascii", "  x-nullable: true"},
+		},
+		{
+			name:  "existing key kept",
+			lines: []string{"Extensions:", "  x-nullable: false"},
+			want:  []string{"Extensions:", "  x-nullable: false"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AppendExtension(tt.lines, "x-nullable", "true"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AppendExtension() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSecretLines(t *testing.T) {
+	if !IsSecret([]string{"password of server", "+onecloud:model-api-gen-secret"}) {
+		t.Errorf("IsSecret() = false, want true")
+	}
+	if IsSecret([]string{"+onecloud:model-api-gen-secret=false"}) {
+		t.Errorf("IsSecret(false) = true, want false")
+	}
+	got := SecretLines([]string{"max length: 36", "Extensions:", "  x-charset: ascii"})
+	want := []string{"max length: 36", "swagger:strfmt password", "Extensions:", "  x-charset: ascii", "  x-writeOnly: true"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SecretLines() = %v, want %v", got, want)
+	}
+	got = SecretLines(nil)
+	want = []string{"swagger:strfmt password", "Extensions:", "  x-writeOnly: true"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SecretLines(nil) = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,125 @@
+package common
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"k8s.io/gengo/v2/generator"
+)
+
+// OutputFileData are the variables of --output-file-template, e.g. for input package
+// yunion.io/x/onecloud/pkg/compute/models and base name zz_generated.model:
+//
+//	Base:       zz_generated.model
+//	SourcePkg:  models
+//	Service:    compute
+//	SourcePath: yunion_io_x_onecloud_pkg_compute_models
+type OutputFileData struct {
+	// Base is the --output-file-base
+	Base string
+	// SourcePkg is the last element of input package path
+	SourcePkg string
+	// Service is the element before trailing models of input package path, or the last one
+	Service string
+	// SourcePath is the input package path with non identifier characters replaced by _
+	SourcePath string
+}
+
+var nonIdentChars = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+func newOutputFileData(base, srcPkg string) OutputFileData {
+	service := path.Base(srcPkg)
+	if service == "models" {
+		service = path.Base(path.Dir(srcPkg))
+	}
+	return OutputFileData{
+		Base:       base,
+		SourcePkg:  path.Base(srcPkg),
+		Service:    service,
+		SourcePath: nonIdentChars.ReplaceAllString(srcPkg, "_"),
+	}
+}
+
+// renderOutputFileName renders output file name of data, .go is appended if missing
+func renderOutputFileName(tmpl *template.Template, data OutputFileData) (string, error) {
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, data); err != nil {
+		return "", err
+	}
+	name := strings.TrimSpace(buf.String())
+	if name == "" || name == ".go" || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("invalid output file name %q", name)
+	}
+	if !strings.HasSuffix(name, ".go") {
+		name += ".go"
+	}
+	return name, nil
+}
+
+// namedGenerator writes the output file of generator to another file name
+type namedGenerator struct {
+	generator.Generator
+	filename string
+}
+
+func (g namedGenerator) Filename() string {
+	return g.filename
+}
+
+// namedPackage renames output files of generators named by base in source package
+type namedPackage struct {
+	SourcePackage
+	base     string
+	filename string
+}
+
+func (p *namedPackage) Generators(c *generator.Context) []generator.Generator {
+	gens := p.SourcePackage.Generators(c)
+	ret := make([]generator.Generator, 0, len(gens))
+	for _, g := range gens {
+		if strings.HasPrefix(g.Filename(), p.base) {
+			g = namedGenerator{Generator: g, filename: p.filename}
+		}
+		ret = append(ret, g)
+	}
+	return ret
+}
+
+// ApplyOutputFileTemplate names output files of source packages by template text, files named
+// by base, the --output-file-base, are renamed. Names of packages sharing output package must differ.
+func ApplyOutputFileTemplate(pkgs []generator.Target, base, text string) ([]generator.Target, error) {
+	if text == "" {
+		return pkgs, nil
+	}
+	tmpl, err := template.New("output-file").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse output file template: %v", err)
+	}
+	// output files by output package path
+	files := make(map[string]map[string]string)
+	ret := make([]generator.Target, 0, len(pkgs))
+	for _, p := range pkgs {
+		sp, ok := p.(SourcePackage)
+		if !ok {
+			ret = append(ret, p)
+			continue
+		}
+		name, err := renderOutputFileName(tmpl, newOutputFileData(base, sp.InputPackage()))
+		if err != nil {
+			return nil, fmt.Errorf("package %s: %v", sp.InputPackage(), err)
+		}
+		if files[p.Path()] == nil {
+			files[p.Path()] = make(map[string]string)
+		}
+		if other, ok := files[p.Path()][name]; ok && other != sp.InputPackage() {
+			return nil, fmt.Errorf("packages %s and %s are both generated to %s/%s", other, sp.InputPackage(), p.Path(), name)
+		}
+		files[p.Path()][name] = sp.InputPackage()
+		ret = append(ret, &namedPackage{SourcePackage: sp, base: base, filename: name})
+	}
+	return ret, nil
+}
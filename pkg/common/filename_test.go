@@ -0,0 +1,86 @@
+package common
+
+import (
+	"testing"
+
+	"k8s.io/gengo/v2/generator"
+)
+
+func TestApplyOutputFileTemplate(t *testing.T) {
+	const base = "zz_generated.model"
+	newPkg := func(src string) generator.Target {
+		return NewSourcePackage(src, &generator.SimpleTarget{
+			PkgPath: "yunion.io/x/onecloud/pkg/apis",
+			GeneratorsFunc: func(*generator.Context) []generator.Generator {
+				return []generator.Generator{
+					generator.GoGenerator{OutputFilename: "doc.go"},
+					generator.GoGenerator{OutputFilename: base + ".go"},
+				}
+			},
+		})
+	}
+	tests := []struct {
+		name    string
+		text    string
+		inputs  []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "not templated",
+			inputs: []string{"yunion.io/x/onecloud/pkg/compute/models"},
+			want:   []string{base + ".go"},
+		},
+		{
+			name:   "service",
+			text:   "{{.Base}}.{{.Service}}",
+			inputs: []string{"yunion.io/x/onecloud/pkg/compute/models", "yunion.io/x/onecloud/pkg/image/models"},
+			want:   []string{"zz_generated.model.compute.go", "zz_generated.model.image.go"},
+		},
+		{
+			name:   "source path",
+			text:   "generated.{{.SourcePath}}.go",
+			inputs: []string{"yunion.io/x/onecloud/pkg/cloudcommon/db"},
+			want:   []string{"generated.yunion_io_x_onecloud_pkg_cloudcommon_db.go"},
+		},
+		{
+			name:    "collision",
+			text:    "generated.{{.SourcePkg}}.go",
+			inputs:  []string{"yunion.io/x/onecloud/pkg/compute/models", "yunion.io/x/onecloud/pkg/image/models"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown variable",
+			text:    "{{.Version}}.go",
+			inputs:  []string{"yunion.io/x/onecloud/pkg/compute/models"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkgs := []generator.Target{}
+			for _, in := range tt.inputs {
+				pkgs = append(pkgs, newPkg(in))
+			}
+			got, err := ApplyOutputFileTemplate(pkgs, base, tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ApplyOutputFileTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			for i, p := range got {
+				gens := p.Generators(nil)
+				if name := gens[0].Filename(); name != "doc.go" {
+					t.Errorf("package %s doc file = %s, should not be renamed", tt.inputs[i], name)
+				}
+				if name := gens[1].Filename(); name != tt.want[i] {
+					t.Errorf("package %s output file = %s, want %s", tt.inputs[i], name, tt.want[i])
+				}
+				if _, ok := p.(SourcePackage); !ok {
+					t.Errorf("package %s is not a source package anymore", tt.inputs[i])
+				}
+			}
+		})
+	}
+}
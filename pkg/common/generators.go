@@ -1,13 +1,16 @@
 package common
 
 import (
+	"go/token"
 	"io"
+	"reflect"
 	"strings"
 
-	"k8s.io/gengo/generator"
-	"k8s.io/gengo/types"
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/types"
 
 	"yunion.io/x/pkg/util/sets"
+	"yunion.io/x/pkg/utils"
 )
 
 func EndWithResourceBase(t *types.Type) bool {
@@ -43,32 +46,127 @@ func IsResourceModel(t *types.Type, isCommonDBPkg bool) bool {
 }
 
 func InSourcePackage(t *types.Type, srcPkg string) bool {
+	if in, ok := ParseInstantiatedType(t); ok {
+		return in.Package == srcPkg
+	}
 	return t.Name.Package == srcPkg
 }
 
-func CollectModelManager(srcPkg string, pkgTypes []*types.Type, modelTypes sets.String, modelManagers map[string]*types.Type) {
-	restTypes := make([]*types.Type, 0)
-	for _, t := range pkgTypes {
-		if t.Kind != types.Struct {
+// MemberJSONName returns json field name of member like jsonutils, which defaults to snake case,
+// "-" if member is not serialized
+func MemberJSONName(m types.Member) string {
+	name := strings.Split(reflect.StructTag(m.Tags).Get("json"), ",")[0]
+	if name == "" {
+		name = utils.CamelSplit(m.Name, "_")
+	}
+	return name
+}
+
+// EmbeddedStruct returns the struct type of embedded member m, pointer is dereferenced
+// and isPtr is true, fields of embedded pointer are optional because it may be nil
+func EmbeddedStruct(m types.Member) (t *types.Type, isPtr bool, ok bool) {
+	if !m.Embedded {
+		return nil, false, false
+	}
+	t = m.Type
+	if t.Kind == types.Pointer {
+		t, isPtr = t.Elem, true
+	}
+	if t.Kind != types.Struct {
+		return nil, false, false
+	}
+	return t, isPtr, true
+}
+
+// JSONFields returns json field names of struct t including the ones of embedded structs,
+// fields skipped by json or unexported are not serialized
+func JSONFields(t *types.Type) sets.String {
+	ret := sets.NewString()
+	if t == nil {
+		return ret
+	}
+	for _, m := range t.Members {
+		if et, _, ok := EmbeddedStruct(m); ok {
+			ret = ret.Union(JSONFields(et))
 			continue
 		}
-		if !InSourcePackage(t, srcPkg) {
+		if !token.IsExported(m.Name) {
 			continue
 		}
-		if IsResourceModel(t, false) {
-			modelTypes.Insert(t.String())
-		} else {
-			restTypes = append(restTypes, t)
+		if name := MemberJSONName(m); name != "-" {
+			ret.Insert(name)
 		}
 	}
-	for _, t := range restTypes {
-		if strings.HasSuffix(t.Name.Name, "Manager") {
-			modelName := strings.TrimSuffix(t.String(), "Manager")
-			if modelTypes.Has(modelName) {
-				modelManagers[modelName] = t
+	return ret
+}
+
+// InstantiatedType is the name of generic type instantiation, e.g. SPagedList[SGuest]
+type InstantiatedType struct {
+	Package  string
+	Name     string
+	TypeArgs []string
+}
+
+// GoName returns a valid go identifier of the instantiation, e.g. SPagedListOfSGuest
+func (in InstantiatedType) GoName() string {
+	args := make([]string, 0, len(in.TypeArgs))
+	for _, arg := range in.TypeArgs {
+		arg = strings.TrimLeft(arg, "*[]")
+		if idx := strings.LastIndex(arg, "."); idx >= 0 {
+			arg = arg[idx+1:]
+		}
+		args = append(args, strings.Title(arg))
+	}
+	return in.Name + "Of" + strings.Join(args, "And")
+}
+
+// ParseInstantiatedType parse generic type instantiation scanned by gengo,
+// gengo splits the full name pkg.SPagedList[pkg.SGuest] at the last dot,
+// so the full name is joined back before parsing.
+func ParseInstantiatedType(t *types.Type) (*InstantiatedType, bool) {
+	full := t.Name.String()
+	start := strings.Index(full, "[")
+	if start <= 0 || !strings.HasSuffix(full, "]") {
+		return nil, false
+	}
+	base := full[:start]
+	dot := strings.LastIndex(base, ".")
+	if dot < 0 {
+		return nil, false
+	}
+	in := &InstantiatedType{
+		Package:  base[:dot],
+		Name:     base[dot+1:],
+		TypeArgs: make([]string, 0),
+	}
+	depth := 0
+	argStart := start + 1
+	for i := start + 1; i < len(full)-1; i++ {
+		switch full[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				in.TypeArgs = append(in.TypeArgs, strings.TrimSpace(full[argStart:i]))
+				argStart = i + 1
 			}
 		}
 	}
+	in.TypeArgs = append(in.TypeArgs, strings.TrimSpace(full[argStart:len(full)-1]))
+	return in, true
+}
+
+// IsGenericDeclaration returns true if t is an uninstantiated generic struct,
+// which members typed by type parameters are unsupported by gengo.
+func IsGenericDeclaration(t *types.Type) bool {
+	for _, m := range t.Members {
+		if m.Type.Kind == types.Unsupported {
+			return true
+		}
+	}
+	return false
 }
 
 func GetArgs(t *types.Type) interface{} {
@@ -0,0 +1,100 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/gengo/v2/types"
+)
+
+func TestParseInstantiatedType(t *testing.T) {
+	tests := []struct {
+		name     string
+		typeName types.Name
+		want     *InstantiatedType
+		wantName string
+	}{
+		{
+			name: "single type argument",
+			typeName: types.Name{
+				Package: "yunion.io/x/onecloud/pkg/compute/models.SPagedList[yunion.io/x/onecloud/pkg/compute/models",
+				Name:    "SGuest]",
+			},
+			want: &InstantiatedType{
+				Package:  "yunion.io/x/onecloud/pkg/compute/models",
+				Name:     "SPagedList",
+				TypeArgs: []string{"yunion.io/x/onecloud/pkg/compute/models.SGuest"},
+			},
+			wantName: "SPagedListOfSGuest",
+		},
+		{
+			name: "multiple type arguments",
+			typeName: types.Name{
+				Package: "yunion.io/x/onecloud/pkg/compute/models.SPair[string, []*yunion.io/x/onecloud/pkg/compute/models",
+				Name:    "SDisk]",
+			},
+			want: &InstantiatedType{
+				Package:  "yunion.io/x/onecloud/pkg/compute/models",
+				Name:     "SPair",
+				TypeArgs: []string{"string", "[]*yunion.io/x/onecloud/pkg/compute/models.SDisk"},
+			},
+			wantName: "SPairOfStringAndSDisk",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseInstantiatedType(&types.Type{Name: tt.typeName})
+			if !ok || !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ParseInstantiatedType() = %v, want %v", got, tt.want)
+			}
+			if got.GoName() != tt.wantName {
+				t.Errorf("GoName() = %s, want %s", got.GoName(), tt.wantName)
+			}
+		})
+	}
+	if _, ok := ParseInstantiatedType(&types.Type{Name: types.Name{Package: "yunion.io/x/onecloud/pkg/compute/models", Name: "SGuest"}}); ok {
+		t.Errorf("plain type parsed as instantiation")
+	}
+}
+
+func TestEmbeddedStruct(t *testing.T) {
+	base := &types.Type{Name: types.Name{Package: "db", Name: "SResourceBase"}, Kind: types.Struct}
+	str := &types.Type{Name: types.Name{Name: "string"}, Kind: types.Builtin}
+	tests := []struct {
+		name      string
+		member    types.Member
+		want      *types.Type
+		wantIsPtr bool
+		wantOk    bool
+	}{
+		{
+			name:   "embedded struct",
+			member: types.Member{Embedded: true, Type: base},
+			want:   base,
+			wantOk: true,
+		},
+		{
+			name:      "embedded pointer",
+			member:    types.Member{Embedded: true, Type: &types.Type{Kind: types.Pointer, Elem: base}},
+			want:      base,
+			wantIsPtr: true,
+			wantOk:    true,
+		},
+		{
+			name:   "not embedded",
+			member: types.Member{Name: "Base", Type: base},
+		},
+		{
+			name:   "embedded non struct",
+			member: types.Member{Embedded: true, Type: str},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, isPtr, ok := EmbeddedStruct(tt.member)
+			if got != tt.want || isPtr != tt.wantIsPtr || ok != tt.wantOk {
+				t.Errorf("EmbeddedStruct() = %v, %v, %v, want %v, %v, %v", got, isPtr, ok, tt.want, tt.wantIsPtr, tt.wantOk)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package common
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/imports"
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/namer"
+	gengoparser "k8s.io/gengo/v2/parser"
+	"k8s.io/klog/v2"
+)
+
+// LocalImportPrefix groups imports of generated go files after third party ones, groups are separated
+// by colon and ordered, prefixes of a group by comma, e.g. yunion.io/x/:yunion.io/x/onecloud puts
+// yunion.io/x/onecloud imports in the last group and other yunion.io/x/ imports before them
+var LocalImportPrefix string
+
+// NewContext is generator.NewContext whose go files are formatted like gengo v1 did,
+// existing files are only compared with the generated ones if verify
+func NewContext(p *gengoparser.Parser, nameSystems namer.NameSystems, canonicalOrderName string, verify bool) (*generator.Context, error) {
+	c, err := generator.NewContext(p, nameSystems, canonicalOrderName)
+	if err != nil {
+		return nil, err
+	}
+	c.FileTypes[generator.GoFileType] = newGoFileType(verify)
+	return c, nil
+}
+
+// goFileType assembles go files by goimports and LocalImportPrefix, gengo v2 formats them by gofmt -s
+// without fixing imports instead, which changes the output
+type goFileType struct {
+	generator.DefaultFileType
+	verify bool
+}
+
+func newGoFileType(verify bool) goFileType {
+	ft := generator.NewGoFile()
+	ft.Format = formatGoFile
+	return goFileType{DefaultFileType: *ft, verify: verify}
+}
+
+func (ft goFileType) AssembleFile(f *generator.File, pathname string) error {
+	if ft.verify {
+		return ft.verifyFile(f, pathname)
+	}
+	return ft.DefaultFileType.AssembleFile(f, pathname)
+}
+
+// verifyFile reports the first difference between existing file of pathname and f
+func (ft goFileType) verifyFile(f *generator.File, pathname string) error {
+	klog.V(2).Infof("Verifying file %q", pathname)
+	friendlyName := filepath.Join(f.PackageName, f.Name)
+	b := &bytes.Buffer{}
+	et := generator.NewErrorTracker(b)
+	ft.Assemble(et, f)
+	if et.Error() != nil {
+		return et.Error()
+	}
+	formatted, err := ft.Format(b.Bytes())
+	if err != nil {
+		return fmt.Errorf("unable to format the output for %q: %v", friendlyName, err)
+	}
+	existing, err := ioutil.ReadFile(pathname)
+	if err != nil {
+		return fmt.Errorf("unable to read file %q for comparison: %v", friendlyName, err)
+	}
+	if bytes.Equal(formatted, existing) {
+		return nil
+	}
+	// Be nice and find the first place where they differ
+	i := 0
+	for i < len(formatted) && i < len(existing) && formatted[i] == existing[i] {
+		i++
+	}
+	eDiff, fDiff := existing[i:], formatted[i:]
+	if len(eDiff) > 100 {
+		eDiff = eDiff[:100]
+	}
+	if len(fDiff) > 100 {
+		fDiff = fDiff[:100]
+	}
+	return fmt.Errorf("output for %q differs; first existing/expected diff: \n  %q\n  %q", friendlyName, string(eDiff), string(fDiff))
+}
+
+// formatGoFile fixes imports of src by goimports and groups them by LocalImportPrefix
+func formatGoFile(src []byte) ([]byte, error) {
+	src, err := imports.Process("", src, nil)
+	if err != nil {
+		return nil, err
+	}
+	if LocalImportPrefix == "" {
+		return src, nil
+	}
+	return groupImports(src, LocalImportPrefix)
+}
+
+// importGroup returns group of import path like goimports, local groups of prefix follow
+// the standard library, third party and appengine ones
+func importGroup(prefix, importPath string) int {
+	groups := strings.Split(prefix, ":")
+	for i := len(groups) - 1; i >= 0; i-- {
+		for _, p := range strings.Split(groups[i], ",") {
+			if p != "" && (strings.HasPrefix(importPath, p) || strings.TrimSuffix(p, "/") == importPath) {
+				return i + 3
+			}
+		}
+	}
+	switch {
+	case strings.HasPrefix(importPath, "appengine"):
+		return 2
+	case strings.Contains(importPath, "."):
+		return 1
+	}
+	return 0
+}
+
+// groupImports sorts parenthesized imports of go source src by group of prefix and path,
+// groups are separated by blank lines
+func groupImports(src []byte, prefix string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments|parser.ImportsOnly)
+	if err != nil {
+		return nil, err
+	}
+	type importLine struct {
+		group int
+		path  string
+		text  string
+	}
+	// declarations are replaced from the last one, so offsets of previous ones are kept
+	for i := len(file.Decls) - 1; i >= 0; i-- {
+		decl, ok := file.Decls[i].(*ast.GenDecl)
+		if !ok || decl.Tok != token.IMPORT || !decl.Lparen.IsValid() {
+			continue
+		}
+		lines := make([]importLine, 0, len(decl.Specs))
+		for _, spec := range decl.Specs {
+			is := spec.(*ast.ImportSpec)
+			path, _ := strconv.Unquote(is.Path.Value)
+			start, end := fset.Position(is.Pos()).Offset, fset.Position(is.End()).Offset
+			if is.Doc != nil {
+				start = fset.Position(is.Doc.Pos()).Offset
+			}
+			if is.Comment != nil {
+				end = fset.Position(is.Comment.End()).Offset
+			}
+			lines = append(lines, importLine{group: importGroup(prefix, path), path: path, text: string(src[start:end])})
+		}
+		sort.SliceStable(lines, func(i, j int) bool {
+			if lines[i].group != lines[j].group {
+				return lines[i].group < lines[j].group
+			}
+			return lines[i].path < lines[j].path
+		})
+		buf := &bytes.Buffer{}
+		buf.WriteString("(\n")
+		for j, line := range lines {
+			if j > 0 && line.group != lines[j-1].group {
+				buf.WriteString("\n")
+			}
+			fmt.Fprintf(buf, "\t%s\n", line.text)
+		}
+		buf.WriteString(")")
+		lparen, rparen := fset.Position(decl.Lparen).Offset, fset.Position(decl.Rparen).Offset
+		src = append(src[:lparen:lparen], append(buf.Bytes(), src[rparen+1:]...)...)
+	}
+	return format.Source(src)
+}
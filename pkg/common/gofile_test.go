@@ -0,0 +1,103 @@
+package common
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/gengo/v2/generator"
+)
+
+func Test_importGroup(t *testing.T) {
+	const prefix = "yunion.io/x/:yunion.io/x/onecloud"
+	for path, want := range map[string]int{
+		"fmt":                                 0,
+		"k8s.io/klog/v2":                      1,
+		"appengine/datastore":                 2,
+		"yunion.io/x/jsonutils":               3,
+		"yunion.io/x/onecloud/pkg/apis":       4,
+		"yunion.io/x/onecloud":                4,
+		"yunion.io/x/onecloud-ee/pkg/apis":    4,
+		"github.com/yunionio/onecloud/pkg/db": 1,
+	} {
+		if got := importGroup(prefix, path); got != want {
+			t.Errorf("importGroup(%s) = %d, want %d", path, got, want)
+		}
+	}
+}
+
+func Test_formatGoFile(t *testing.T) {
+	defer func(prefix string) { LocalImportPrefix = prefix }(LocalImportPrefix)
+	src := `package compute
+
+import (
+	"yunion.io/x/onecloud/pkg/apis"
+	"time"
+	"yunion.io/x/jsonutils"
+	"k8s.io/klog/v2"
+)
+
+var _ = time.Now
+var _ jsonutils.JSONObject
+var _ apis.ResourceBaseDetails
+var _ = klog.Infof
+`
+	for _, tt := range []struct {
+		name   string
+		prefix string
+		want   string
+	}{
+		{
+			name: "goimports",
+			want: "import (\n\t\"time\"\n\n\t\"k8s.io/klog/v2\"\n\t\"yunion.io/x/jsonutils\"\n\t\"yunion.io/x/onecloud/pkg/apis\"\n)\n",
+		},
+		{
+			name:   "local groups",
+			prefix: "yunion.io/x/:yunion.io/x/onecloud",
+			want:   "import (\n\t\"time\"\n\n\t\"k8s.io/klog/v2\"\n\n\t\"yunion.io/x/jsonutils\"\n\n\t\"yunion.io/x/onecloud/pkg/apis\"\n)\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			LocalImportPrefix = tt.prefix
+			got, err := formatGoFile([]byte(src))
+			if err != nil {
+				t.Fatalf("formatGoFile() error: %v", err)
+			}
+			if !strings.Contains(string(got), tt.want) {
+				t.Errorf("formatGoFile() = %s, want imports %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_goFileTypeVerify(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gofile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	newFile := func(body string) *generator.File {
+		f := &generator.File{Name: "zz_generated.go", PackageName: "compute", Header: []byte("// Code generated by test. DO NOT EDIT.\n\n")}
+		f.Body.WriteString(body)
+		return f
+	}
+	file := filepath.Join(dir, "zz_generated.go")
+	if err := newGoFileType(false).AssembleFile(newFile("type A struct{}\n"), file); err != nil {
+		t.Fatalf("AssembleFile() error: %v", err)
+	}
+	if err := newGoFileType(true).AssembleFile(newFile("type A struct{}\n"), file); err != nil {
+		t.Errorf("verify of unchanged output error: %v", err)
+	}
+	if err := newGoFileType(true).AssembleFile(newFile("type B struct{}\n"), file); err == nil {
+		t.Errorf("verify of changed output should fail")
+	}
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "type A struct{}") {
+		t.Errorf("verify changed the existing file: %s", content)
+	}
+}
@@ -0,0 +1,91 @@
+package common
+
+import (
+	"strings"
+	"unicode"
+
+	"k8s.io/gengo/v2/namer"
+	"k8s.io/gengo/v2/types"
+)
+
+// GolintInitialisms is the keyword of --initialisms expanded to commonInitialisms of golint
+const GolintInitialisms = "golint"
+
+// golintInitialisms are the common initialisms of golint
+var golintInitialisms = []string{
+	"ACL", "API", "ASCII", "CPU", "CSS", "DNS", "EOF", "GUID", "HTML", "HTTP", "HTTPS", "ID",
+	"IP", "JSON", "LHS", "QPS", "RAM", "RHS", "RPC", "SLA", "SMTP", "SQL", "SSH", "TCP",
+	"TLS", "TTL", "UDP", "UI", "UID", "UUID", "URI", "URL", "UTF8", "VM", "XML", "XMPP", "XSRF", "XSS",
+}
+
+// Initialisms are upper case words of generated names, e.g. ID makes ServerId as ServerID
+type Initialisms map[string]bool
+
+// NewInitialisms returns initialisms of words, case insensitive, golint expands to golint's list
+func NewInitialisms(words []string) Initialisms {
+	ret := make(Initialisms)
+	for _, w := range words {
+		w = strings.TrimSpace(w)
+		if w == GolintInitialisms {
+			for _, gw := range golintInitialisms {
+				ret[gw] = true
+			}
+			continue
+		}
+		if w != "" {
+			ret[strings.ToUpper(w)] = true
+		}
+	}
+	return ret
+}
+
+// splitWords splits go identifier into camel case words, digits belong to the preceding word,
+// e.g. CdromIpv4Addr as Cdrom, Ipv4, Addr and HTTPServer as HTTP, Server
+func splitWords(name string) []string {
+	runes := []rune(name)
+	words := make([]string, 0)
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		if !unicode.IsUpper(cur) {
+			continue
+		}
+		nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if !unicode.IsUpper(prev) || nextLower {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(words, string(runes[start:]))
+}
+
+// Apply upper cases the initialism words of name
+func (in Initialisms) Apply(name string) string {
+	if len(in) == 0 {
+		return name
+	}
+	words := splitWords(name)
+	for i, w := range words {
+		if upper := strings.ToUpper(w); in[upper] {
+			words[i] = upper
+		}
+	}
+	return strings.Join(words, "")
+}
+
+type initialismNamer struct {
+	namer.Namer
+	initialisms Initialisms
+}
+
+// NewInitialismNamer returns public namer upper casing initialisms of names
+func NewInitialismNamer(in Initialisms) namer.Namer {
+	return &initialismNamer{
+		Namer:       namer.NewPublicNamer(0),
+		initialisms: in,
+	}
+}
+
+func (n *initialismNamer) Name(t *types.Type) string {
+	return n.initialisms.Apply(n.Namer.Name(t))
+}
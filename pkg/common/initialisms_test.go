@@ -0,0 +1,36 @@
+package common
+
+import (
+	"testing"
+
+	"k8s.io/gengo/v2/types"
+)
+
+func TestInitialisms(t *testing.T) {
+	in := NewInitialisms([]string{GolintInitialisms, "cdrom", "IPv4"})
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "ServerId", want: "ServerID"},
+		{name: "Ids", want: "Ids"},
+		{name: "CdromSupport", want: "CDROMSupport"},
+		{name: "Ipv4Addr", want: "IPV4Addr"},
+		{name: "HTTPServer", want: "HTTPServer"},
+		{name: "JsonBody", want: "JSONBody"},
+		{name: "Identity", want: "Identity"},
+		{name: "VmCpuCount", want: "VMCPUCount"},
+	}
+	for _, tt := range tests {
+		if got := in.Apply(tt.name); got != tt.want {
+			t.Errorf("Apply(%s) = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+	if got := Initialisms(nil).Apply("ServerId"); got != "ServerId" {
+		t.Errorf("empty Apply() = %s, want ServerId", got)
+	}
+	n := NewInitialismNamer(in)
+	if got := n.Name(&types.Type{Name: types.Name{Package: "compute", Name: "SGuestCdrom"}}); got != "SGuestCDROM" {
+		t.Errorf("Name() = %s, want SGuestCDROM", got)
+	}
+}
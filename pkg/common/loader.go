@@ -0,0 +1,489 @@
+package common
+
+import (
+	"fmt"
+	"go/build"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/spf13/pflag"
+	"golang.org/x/tools/go/packages"
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/namer"
+	"k8s.io/gengo/v2/parser"
+	"k8s.io/klog/v2"
+
+	"yunion.io/x/log"
+)
+
+// LoaderArgs are the package loading arguments shared by all generators
+type LoaderArgs struct {
+	// Tags are the build tags used to select input packages and parse source files
+	Tags []string
+	// Stream parses and generates input packages one by one, types of a package are released once it's generated
+	Stream bool
+	// Profile are the profiling arguments of generation run
+	Profile ProfileArgs
+	// Log are the logging arguments, applied by SetupLogging
+	Log LogArgs
+	// Watch are the arguments to regenerate on changes of input packages
+	Watch WatchArgs
+	// Modules are root directories of go modules which input packages span
+	Modules []string
+	// OutputFileTemplate names output file of each input package, e.g. generated.{{.Service}}.go
+	OutputFileTemplate string
+	// Boilerplates override --go-header-file of output packages by prefix, like yunion.io/x/cloudmux=hack/boilerplate.txt
+	Boilerplates []string
+	// Prune removes generated files of output packages which are not generated by the run anymore
+	Prune bool
+	// Manifest is the file recording versions, arguments and generated file hashes of the run
+	Manifest string
+}
+
+// AddFlags add package loading flags to fs
+func (la *LoaderArgs) AddFlags(fs *pflag.FlagSet) {
+	fs.StringSliceVar(&la.Tags, "build-tags", la.Tags, "Comma-separated list of build tags used when loading and parsing input packages, should match the service build")
+	// --tags is the flag name before --build-tags, kept working for existing scripts
+	fs.StringSliceVar(&la.Tags, "tags", la.Tags, "Alias of --build-tags")
+	fs.MarkDeprecated("tags", "use --build-tags instead")
+	fs.StringSliceVar(&la.Modules, "modules", la.Modules, "Comma-separated root directories of go modules, e.g. ../onecloud,../cloudmux, inputs under their module paths are loaded in them, packages shared by modules are loaded from the first one")
+	fs.StringVar(&la.OutputFileTemplate, "output-file-template", la.OutputFileTemplate, "Go template naming output file of each input package instead of --output-file-base, e.g. generated.{{.Service}}.go, variables: .Base, .SourcePkg, .Service, .SourcePath")
+	fs.StringSliceVar(&la.Boilerplates, "boilerplate", la.Boilerplates, "Boilerplate files of output packages overriding --go-header-file, like yunion.io/x/cloudmux=hack/boilerplate.go.txt, the longest package prefix wins; boilerplates are go templates of .Year, .Service and .Package")
+	fs.BoolVar(&la.Prune, "prune", la.Prune, "Remove go files generated by the previous run, listed in its --manifest, or named by --output-file-base before --output-file-template is used, which have the generated by header and are not generated anymore")
+	fs.StringVar(&la.Manifest, "manifest", la.Manifest, "Write manifest of generator version, input module versions, arguments and generated file hashes to file, used to detect stale generated code")
+	fs.BoolVar(&la.Stream, "stream-packages", la.Stream, "Parse and generate input packages one by one, types of a package are released once its code is generated, lowers peak memory of large universes at the cost of parsing shared imports for each package")
+	la.Profile.AddFlags(fs)
+	la.Log.AddFlags(fs)
+	la.Watch.AddFlags(fs)
+}
+
+func buildTagsFlag(tags []string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+	return []string{fmt.Sprintf("-tags=%s", strings.Join(tags, " "))}
+}
+
+// Module is a go module which input packages are loaded in
+type Module struct {
+	// Dir is the module root directory containing go.mod
+	Dir string
+	// Path is the module path declared in go.mod, e.g. yunion.io/x/cloudmux
+	Path string
+}
+
+// readModulePath returns module path declared in go.mod content
+func readModulePath(goMod []byte) string {
+	for _, line := range strings.Split(string(goMod), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], "\"`")
+		}
+	}
+	return ""
+}
+
+// ModulePath returns the module path declared in go.mod of the module containing directory dir,
+// which is found in dir or its parents, empty if dir is out of modules, e.g. in GOPATH mode
+func ModulePath(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		if content, err := ioutil.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+			return readModulePath(content)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// LoadModules reads module paths from go.mod of dirs
+func LoadModules(dirs []string) ([]Module, error) {
+	ret := make([]Module, 0, len(dirs))
+	for _, dir := range dirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		content, err := ioutil.ReadFile(filepath.Join(abs, "go.mod"))
+		if err != nil {
+			return nil, fmt.Errorf("read go.mod of module %s: %v", dir, err)
+		}
+		path := readModulePath(content)
+		if path == "" {
+			return nil, fmt.Errorf("module path is not declared in %s/go.mod", dir)
+		}
+		ret = append(ret, Module{Dir: abs, Path: path})
+	}
+	return ret, nil
+}
+
+// moduleInputs are input patterns loaded in module directory dir,
+// dir is empty for the working directory
+type moduleInputs struct {
+	dir      string
+	patterns []string
+}
+
+// groupInputs assigns patterns to the module of the longest matched path in modules order,
+// patterns out of modules, e.g. ./..., are loaded in the working directory at last
+func groupInputs(patterns []string, modules []Module) []moduleInputs {
+	groups := make([]moduleInputs, len(modules)+1)
+	for i, mod := range modules {
+		groups[i].dir = mod.Dir
+	}
+	for _, pattern := range patterns {
+		idx, matched := len(modules), ""
+		for i, mod := range modules {
+			if (pattern == mod.Path || strings.HasPrefix(pattern, mod.Path+"/")) && len(mod.Path) > len(matched) {
+				idx, matched = i, mod.Path
+			}
+		}
+		groups[idx].patterns = append(groups[idx].patterns, pattern)
+	}
+	ret := make([]moduleInputs, 0, len(groups))
+	for _, g := range groups {
+		if len(g.patterns) != 0 {
+			ret = append(ret, g)
+		}
+	}
+	return ret
+}
+
+// loadPackages loads packages matched by patterns in directory dir
+func loadPackages(dir string, patterns []string, tags []string) ([]*packages.Package, error) {
+	cfg := &packages.Config{
+		Mode:       packages.NeedName | packages.NeedFiles,
+		BuildFlags: buildTagsFlag(tags),
+		Dir:        dir,
+	}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("load packages %v: %v", patterns, err)
+	}
+	return pkgs, nil
+}
+
+// resolveInputs expands input patterns, e.g. module import paths or ./...,
+// to import paths of the matched packages with go/packages, so module mode
+// and vendor directories are resolved by the go command itself.
+// Patterns are loaded in their modules, paths are returned by module.
+func resolveInputs(patterns []string, tags []string, modules []Module) ([]moduleInputs, error) {
+	ret := make([]moduleInputs, 0)
+	for _, group := range groupInputs(patterns, modules) {
+		pkgs, err := loadPackages(group.dir, group.patterns, tags)
+		if err != nil {
+			return nil, err
+		}
+		paths := make([]string, 0, len(pkgs))
+		for _, pkg := range pkgs {
+			if len(pkg.Errors) != 0 {
+				return nil, fmt.Errorf("load package %s: %v", pkg.PkgPath, pkg.Errors[0])
+			}
+			if len(pkg.GoFiles) == 0 {
+				klog.Warningf("package %s has no go files with tags %v, skipped", pkg.PkgPath, tags)
+				continue
+			}
+			paths = append(paths, pkg.PkgPath)
+		}
+		sort.Strings(paths)
+		ret = append(ret, moduleInputs{dir: group.dir, patterns: paths})
+	}
+	return ret, nil
+}
+
+// SourceFiles returns go files of packages matched by input patterns, keyed by import path,
+// patterns are loaded in their modules with build tags like generation does
+func SourceFiles(patterns []string, la *LoaderArgs) (map[string][]string, error) {
+	modules, err := LoadModules(la.Modules)
+	if err != nil {
+		return nil, err
+	}
+	ret := make(map[string][]string)
+	for _, group := range groupInputs(patterns, modules) {
+		pkgs, err := loadPackages(group.dir, group.patterns, la.Tags)
+		if err != nil {
+			return nil, err
+		}
+		for _, pkg := range pkgs {
+			if len(pkg.Errors) != 0 {
+				return nil, fmt.Errorf("load package %s: %v", pkg.PkgPath, pkg.Errors[0])
+			}
+			ret[pkg.PkgPath] = append(ret[pkg.PkgPath], pkg.GoFiles...)
+		}
+	}
+	return ret, nil
+}
+
+// inDir calls f in directory dir, the working directory is kept if dir is empty.
+// gengo loads packages and resolves their imports in the working directory.
+func inDir(dir string, f func() error) error {
+	if dir == "" {
+		return f()
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if err := os.Chdir(dir); err != nil {
+		return err
+	}
+	defer os.Chdir(cwd)
+	return f()
+}
+
+// reportIgnoredFiles warns about source files excluded by build constraints,
+// types defined in them are missing from the parsed universe.
+func reportIgnoredFiles(srcDir string, pkgPaths []string, tags []string) {
+	ctx := build.Default
+	ctx.BuildTags = tags
+	// same as gengo parser, cgo is not supported
+	ctx.CgoEnabled = false
+	for _, pkgPath := range pkgPaths {
+		pkg, err := ctx.Import(pkgPath, srcDir, 0)
+		if err != nil {
+			klog.V(2).Infof("import %s for ignored files: %v", pkgPath, err)
+			continue
+		}
+		ignored := make([]string, 0)
+		for _, f := range pkg.IgnoredGoFiles {
+			if !strings.HasSuffix(f, "_test.go") {
+				ignored = append(ignored, f)
+			}
+		}
+		if len(ignored) != 0 {
+			klog.Warningf("package %s files %v are excluded by build constraints with tags %v, set --build-tags to scan them", pkgPath, ignored, tags)
+		}
+	}
+}
+
+// NewParser returns gengo parser loaded with the input dirs, which are resolved by resolveInputs
+func NewParser(g *GeneratorArgs, la *LoaderArgs) (*parser.Parser, error) {
+	groups, err := resolveParserInputs(g, la)
+	if err != nil {
+		return nil, err
+	}
+	return loadParser(groups, g, la)
+}
+
+// resolveParserInputs resolves the input dirs by resolveInputs and reports their files excluded by build tags,
+// g.InputDirs is set to the resolved packages
+func resolveParserInputs(g *GeneratorArgs, la *LoaderArgs) ([]moduleInputs, error) {
+	modules, err := LoadModules(la.Modules)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := resolveInputs(g.InputDirs, la.Tags, modules)
+	if err != nil {
+		return nil, err
+	}
+	inputs := make([]string, 0)
+	for _, group := range groups {
+		klog.V(2).Infof("resolved input packages in module %q: %v", group.dir, group.patterns)
+		srcDir := group.dir
+		if srcDir == "" {
+			srcDir = "."
+		}
+		reportIgnoredFiles(srcDir, group.patterns, la.Tags)
+		inputs = append(inputs, group.patterns...)
+	}
+	// generators match input packages by InputDirs, keep it consistent with what is loaded
+	g.InputDirs = inputs
+	return groups, nil
+}
+
+// loadParser returns gengo parser loaded with the resolved input groups
+func loadParser(groups []moduleInputs, g *GeneratorArgs, la *LoaderArgs) (*parser.Parser, error) {
+	// Ignore all auto-generated files.
+	p := parser.NewWithOptions(parser.Options{BuildTags: append([]string{g.GeneratedBuildTag}, la.Tags...)})
+	// imports are loaded with the packages, so dependencies are resolved
+	// in the module of the first package importing them
+	for _, group := range groups {
+		err := inDir(group.dir, func() error {
+			if err := p.LoadPackages(group.patterns...); err != nil {
+				return fmt.Errorf("unable to load packages %v: %v", group.patterns, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// SourcePackage is a generator target generated from types of a single input package
+type SourcePackage interface {
+	generator.Target
+	// InputPackage returns import path of the input package
+	InputPackage() string
+}
+
+type sourcePackage struct {
+	*generator.SimpleTarget
+	source string
+}
+
+func (p *sourcePackage) InputPackage() string {
+	return p.source
+}
+
+// NewSourcePackage marks pkg generated from input package source,
+// its output file can be named by the input package, see ApplyOutputFileTemplate.
+func NewSourcePackage(source string, pkg *generator.SimpleTarget) generator.Target {
+	return &sourcePackage{
+		SimpleTarget: pkg,
+		source:       source,
+	}
+}
+
+// streamInputs splits resolved input groups to the groups loaded by a parser each, all of them are loaded
+// together unless streaming, which loads each input package by a parser of its own
+func streamInputs(groups []moduleInputs, stream bool) [][]moduleInputs {
+	if !stream || len(groups) == 0 {
+		return [][]moduleInputs{groups}
+	}
+	ret := make([][]moduleInputs, 0)
+	for _, group := range groups {
+		for _, pkg := range group.patterns {
+			ret = append(ret, []moduleInputs{{dir: group.dir, patterns: []string{pkg}}})
+		}
+	}
+	return ret
+}
+
+// sourceTargets keeps the targets generated from source packages, other ones, e.g. doc.go of swagger-gen,
+// don't depend on the inputs and are executed with the first streamed input only
+func sourceTargets(pkgs []generator.Target) []generator.Target {
+	ret := make([]generator.Target, 0, len(pkgs))
+	for _, p := range pkgs {
+		if _, ok := p.(SourcePackage); ok {
+			ret = append(ret, p)
+		}
+	}
+	return ret
+}
+
+// executePackages executes packages in order and logs the progress,
+// packages are written to their import paths under outDir
+func executePackages(c *generator.Context, outDir string, pkgs []generator.Target, produced producedFiles) error {
+	errs := make([]string, 0)
+	for i, p := range pkgs {
+		klog.Infof("[%d/%d] generating package %s", i+1, len(pkgs), p.Path())
+		tracked := &trackedPackage{Target: p, dir: filepath.Join(outDir, p.Path()), produced: produced}
+		if err := c.ExecuteTarget(tracked); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("some packages had errors:\n%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// Execute is like gengo Execute, but the input packages are loaded by NewParser.
+// With --stream-packages, pkgs is called for each input package with a context loading it and its imports only,
+// so state of the run kept by generators must outlive the calls, arguments keep all the input packages.
+// Flags must be parsed by caller.
+func Execute(g *GeneratorArgs, la *LoaderArgs, nameSystems namer.NameSystems, defaultSystem string, pkgs func(*generator.Context, *GeneratorArgs) []generator.Target) error {
+	return ExecuteWithReports(g, la, nameSystems, defaultSystem, pkgs, nil)
+}
+
+// ExecuteWithReports is Execute calling writeReports after the packages are generated, it writes the files
+// of the run besides generated packages, e.g. reports of swagger-gen, and returns them to be recorded in manifest.
+func ExecuteWithReports(g *GeneratorArgs, la *LoaderArgs, nameSystems namer.NameSystems, defaultSystem string, pkgs func(*generator.Context, *GeneratorArgs) []generator.Target, writeReports func() ([]string, error)) error {
+	stop, err := la.Profile.Start()
+	if err != nil {
+		return err
+	}
+	defer stop()
+	startProgress()
+	groups, err := resolveParserInputs(g, la)
+	if err != nil {
+		return fmt.Errorf("Failed making a parser: %v", err)
+	}
+	atomic.StoreInt64(&globalProgress.packages, int64(len(g.InputDirs)))
+	produced := make(producedFiles)
+	errs := make([]string, 0)
+	for i, inputs := range streamInputs(groups, la.Stream) {
+		if la.Stream {
+			klog.V(2).Infof("streaming input package %s", inputs[0].patterns[0])
+		}
+		// the parser and context of streamed inputs are released by the next iteration
+		c, outPkgs, err := newTargets(inputs, g, la, nameSystems, defaultSystem, pkgs)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			outPkgs = sourceTargets(outPkgs)
+		}
+		if err := executePackages(c, g.OutputBase, outPkgs, produced); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	logSummary(log.Logger(), fmt.Sprintf("generation finished: %s", globalProgress))
+	if len(errs) > 0 {
+		return fmt.Errorf("Failed executing generator: %s", strings.Join(errs, "\n"))
+	}
+	files := produced.paths()
+	if writeReports != nil {
+		reports, err := writeReports()
+		if err != nil {
+			return err
+		}
+		files = append(files, reports...)
+	}
+	if g.VerifyOnly {
+		return nil
+	}
+	return finishRun(g, la, produced, files)
+}
+
+// newTargets loads input groups by a parser and returns the context of them with the targets generated by pkgs
+func newTargets(inputs []moduleInputs, g *GeneratorArgs, la *LoaderArgs, nameSystems namer.NameSystems, defaultSystem string, pkgs func(*generator.Context, *GeneratorArgs) []generator.Target) (*generator.Context, []generator.Target, error) {
+	p, err := loadParser(inputs, g, la)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed making a parser: %v", err)
+	}
+	c, err := NewContext(p, nameSystems, defaultSystem, g.VerifyOnly)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed making a context: %v", err)
+	}
+	atomic.AddInt64(&globalProgress.types, int64(len(c.Order)))
+	outPkgs, err := ApplyOutputFileTemplate(pkgs(c, g), g.OutputFileBaseName, la.OutputFileTemplate)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Invalid --output-file-template: %v", err)
+	}
+	outPkgs, err = ApplyBoilerplates(outPkgs, g, la.Boilerplates)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Invalid --boilerplate: %v", err)
+	}
+	return c, outPkgs, nil
+}
+
+// finishRun prunes stale generated files and writes manifest of the run, produced are the generated
+// files and files are all the files written by the run, written after reports so the manifest covers them
+func finishRun(g *GeneratorArgs, la *LoaderArgs, produced producedFiles, files []string) error {
+	previous, err := ReadManifest(la.Manifest)
+	if err != nil {
+		return err
+	}
+	if la.Prune {
+		if err := pruneStale(produced, g.OutputBase, previous, g.OutputFileBaseName, la.OutputFileTemplate, g.GeneratedByCommentTemplate); err != nil {
+			return fmt.Errorf("prune stale generated files: %v", err)
+		}
+	}
+	if err := WriteManifest(la, g.InputDirs, g.OutputBase, files); err != nil {
+		return fmt.Errorf("write manifest: %v", err)
+	}
+	return nil
+}
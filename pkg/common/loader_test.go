@@ -0,0 +1,193 @@
+package common
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/namer"
+)
+
+func Test_buildTagsFlag(t *testing.T) {
+	tests := []struct {
+		tags []string
+		want []string
+	}{
+		{tags: nil, want: nil},
+		{tags: []string{"linux"}, want: []string{"-tags=linux"}},
+		{tags: []string{"linux", "feature_a"}, want: []string{"-tags=linux feature_a"}},
+	}
+	for _, tt := range tests {
+		if got := buildTagsFlag(tt.tags); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("buildTagsFlag(%v) = %v, want %v", tt.tags, got, tt.want)
+		}
+	}
+}
+
+func Test_readModulePath(t *testing.T) {
+	tests := []struct {
+		goMod string
+		want  string
+	}{
+		{"module yunion.io/x/cloudmux\n\ngo 1.18\n", "yunion.io/x/cloudmux"},
+		{"// comment\nmodule \"yunion.io/x/onecloud\"\n", "yunion.io/x/onecloud"},
+		{"go 1.18\n", ""},
+	}
+	for _, tt := range tests {
+		if got := readModulePath([]byte(tt.goMod)); got != tt.want {
+			t.Errorf("readModulePath(%q) = %q, want %q", tt.goMod, got, tt.want)
+		}
+	}
+}
+
+func TestModulePath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "module")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	root := filepath.Join(dir, "meter")
+	pkgDir := filepath.Join(root, "pkg", "models")
+	if err := os.MkdirAll(pkgDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/ops/meter\n\ngo 1.18\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range []string{root, pkgDir} {
+		if got := ModulePath(d); got != "example.com/ops/meter" {
+			t.Errorf("ModulePath(%s) = %q, want example.com/ops/meter", d, got)
+		}
+	}
+	if got := ModulePath(dir); got != "" {
+		t.Errorf("ModulePath(%s) out of modules = %q, want empty", dir, got)
+	}
+}
+
+func Test_groupInputs(t *testing.T) {
+	modules := []Module{
+		{Dir: "/src/onecloud", Path: "yunion.io/x/onecloud"},
+		{Dir: "/src/cloudmux", Path: "yunion.io/x/cloudmux"},
+		{Dir: "/src/onecloud-apis", Path: "yunion.io/x/onecloud/pkg/apis"},
+	}
+	patterns := []string{
+		"yunion.io/x/cloudmux/pkg/apis/...",
+		"yunion.io/x/onecloud/pkg/compute/models",
+		"yunion.io/x/onecloud/pkg/apis/compute",
+		"yunion.io/x/onecloud-kube/pkg/apis",
+		"./pkg/...",
+	}
+	want := []moduleInputs{
+		{dir: "/src/onecloud", patterns: []string{"yunion.io/x/onecloud/pkg/compute/models"}},
+		{dir: "/src/cloudmux", patterns: []string{"yunion.io/x/cloudmux/pkg/apis/..."}},
+		{dir: "/src/onecloud-apis", patterns: []string{"yunion.io/x/onecloud/pkg/apis/compute"}},
+		{dir: "", patterns: []string{"yunion.io/x/onecloud-kube/pkg/apis", "./pkg/..."}},
+	}
+	if got := groupInputs(patterns, modules); !reflect.DeepEqual(got, want) {
+		t.Errorf("groupInputs() = %+v, want %+v", got, want)
+	}
+	if got, want := groupInputs(patterns[:1], nil), []moduleInputs{{patterns: patterns[:1]}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("groupInputs() without modules = %+v, want %+v", got, want)
+	}
+}
+
+func Test_streamInputs(t *testing.T) {
+	groups := []moduleInputs{
+		{dir: "/src/onecloud", patterns: []string{"yunion.io/x/onecloud/pkg/compute/models", "yunion.io/x/onecloud/pkg/image/models"}},
+		{dir: "/src/cloudmux", patterns: []string{"yunion.io/x/cloudmux/pkg/apis/compute"}},
+	}
+	if got, want := streamInputs(groups, false), [][]moduleInputs{groups}; !reflect.DeepEqual(got, want) {
+		t.Errorf("streamInputs() = %+v, want %+v", got, want)
+	}
+	want := [][]moduleInputs{
+		{{dir: "/src/onecloud", patterns: []string{"yunion.io/x/onecloud/pkg/compute/models"}}},
+		{{dir: "/src/onecloud", patterns: []string{"yunion.io/x/onecloud/pkg/image/models"}}},
+		{{dir: "/src/cloudmux", patterns: []string{"yunion.io/x/cloudmux/pkg/apis/compute"}}},
+	}
+	if got := streamInputs(groups, true); !reflect.DeepEqual(got, want) {
+		t.Errorf("streamInputs() streaming = %+v, want %+v", got, want)
+	}
+}
+
+func TestExecuteStream(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"go.mod":       "module example.com/streamed\n\ngo 1.18\n",
+		"compute/a.go": "package compute\n\ntype SGuest struct{}\n",
+		"image/a.go":   "package image\n\ntype SImage struct{}\n",
+	}
+	for name, content := range files {
+		file := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	compute, image := "example.com/streamed/compute", "example.com/streamed/image"
+
+	for _, stream := range []bool{false, true} {
+		// inputs of each call to pkgs, with the input packages in its universe
+		calls := make([][]string, 0)
+		loaded := make([][]string, 0)
+		executed := make(map[string]int)
+		target := func(path string, source string) generator.Target {
+			pkg := &generator.SimpleTarget{
+				PkgName: filepath.Base(path),
+				PkgPath: path,
+				GeneratorsFunc: func(*generator.Context) []generator.Generator {
+					executed[path]++
+					return nil
+				},
+			}
+			if source == "" {
+				return pkg
+			}
+			return NewSourcePackage(source, pkg)
+		}
+		pkgs := func(c *generator.Context, g *GeneratorArgs) []generator.Target {
+			calls = append(calls, c.Inputs)
+			inUniverse := make([]string, 0)
+			for _, pkg := range []string{compute, image} {
+				if p := c.Universe[pkg]; p != nil && len(p.Types) != 0 {
+					inUniverse = append(inUniverse, pkg)
+				}
+			}
+			loaded = append(loaded, inUniverse)
+			ret := []generator.Target{target("doc", "")}
+			for _, input := range c.Inputs {
+				ret = append(ret, target(filepath.Base(input), input))
+			}
+			return ret
+		}
+		g := NewGeneratorArgs()
+		g.InputDirs = []string{image, compute}
+		g.OutputBase = filepath.Join(dir, "out")
+		if err := Execute(g, &LoaderArgs{Modules: []string{dir}, Stream: stream}, namer.NameSystems{"raw": namer.NewRawNamer("", nil)}, "raw", pkgs); err != nil {
+			t.Fatalf("Execute() stream %v error: %v", stream, err)
+		}
+
+		wantCalls := [][]string{{compute, image}}
+		if stream {
+			// each input is loaded by a context of its own, without types of the other one
+			wantCalls = [][]string{{compute}, {image}}
+		}
+		if !reflect.DeepEqual(calls, wantCalls) || !reflect.DeepEqual(loaded, wantCalls) {
+			t.Errorf("stream %v: pkgs called with inputs %v loading %v, want %v", stream, calls, loaded, wantCalls)
+		}
+		if want := map[string]int{"doc": 1, "compute": 1, "image": 1}; !reflect.DeepEqual(executed, want) {
+			t.Errorf("stream %v: executed targets %v, want %v", stream, executed, want)
+		}
+		if want := []string{compute, image}; !reflect.DeepEqual(g.InputDirs, want) {
+			t.Errorf("stream %v: input dirs %v, want %v", stream, g.InputDirs, want)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+	"k8s.io/klog/v2"
+
+	"yunion.io/x/log"
+)
+
+// LogArgs are the logging arguments shared by all generators
+type LogArgs struct {
+	// Quiet only logs warnings and errors
+	Quiet bool
+	// JSON logs entries as json objects
+	JSON bool
+}
+
+// AddFlags add logging flags to fs
+func (la *LogArgs) AddFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&la.Quiet, "quiet", la.Quiet, "Only log warnings and errors")
+	fs.BoolVar(&la.JSON, "json-logs", la.JSON, "Log entries as json objects, e.g. for CI log processing")
+}
+
+// SetupLogging applies la to klog and yunion log, it should be called once after flags are parsed.
+// Both keep their own formats and destinations unless --quiet or --json-logs is set,
+// then klog logs through klogSink and yunion log by its level and jsonFormatter.
+// Warnings of yunion log are counted by a hook for the progress summary, klog counts its own by klog.Stats.
+func SetupLogging(la *LogArgs) {
+	logger := log.Logger()
+	logger.AddHook(warningHook{})
+	if la.Quiet {
+		log.SetLogLevelByString(logger, "warning")
+	}
+	if la.JSON {
+		logger.Formatter = jsonFormatter{}
+	}
+	if la.Quiet || la.JSON {
+		klog.SetLogger(logr.New(&klogSink{out: os.Stderr, quiet: la.Quiet, json: la.JSON}))
+	}
+}
+
+// logEntry is a log entry logged as json object by --json-logs
+type logEntry struct {
+	Level  string                 `json:"level"`
+	Logger string                 `json:"logger"`
+	Source string                 `json:"source,omitempty"`
+	Msg    string                 `json:"msg"`
+	Error  string                 `json:"error,omitempty"`
+	Fields map[string]interface{} `json:"fields,omitempty"`
+}
+
+func (e logEntry) write(out io.Writer) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(append(line, '\n'))
+	return err
+}
+
+// klogSink is the logr sink klog logs through by --quiet or --json-logs.
+// klog passes entries of all severities but errors to Info,
+// so the severity is taken from the klog function called, e.g. Warningf, found in the stack.
+type klogSink struct {
+	out    io.Writer
+	quiet  bool
+	json   bool
+	name   string
+	values []interface{}
+
+	lock *sync.Mutex
+}
+
+func (s *klogSink) Init(info logr.RuntimeInfo) {
+	if s.lock == nil {
+		s.lock = &sync.Mutex{}
+	}
+}
+
+// Enabled returns true for all levels, klog filters them by -v itself
+func (s *klogSink) Enabled(level int) bool {
+	return true
+}
+
+func (s *klogSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	sev, source := klogCaller()
+	s.log(sev, source, msg, nil, keysAndValues)
+}
+
+func (s *klogSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	// fatal entries keep their severity, e.g. of klog.Fatalf
+	sev, source := klogCaller()
+	if sev != "fatal" {
+		sev = "error"
+	}
+	s.log(sev, source, msg, err, keysAndValues)
+}
+
+func (s *klogSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	ret := *s
+	ret.values = append(append([]interface{}{}, s.values...), keysAndValues...)
+	return &ret
+}
+
+func (s *klogSink) WithName(name string) logr.LogSink {
+	ret := *s
+	if ret.name != "" {
+		name = ret.name + "/" + name
+	}
+	ret.name = name
+	return &ret
+}
+
+func (s *klogSink) log(level, source, msg string, err error, keysAndValues []interface{}) {
+	if s.quiet && level == "info" {
+		return
+	}
+	e := logEntry{Level: level, Logger: "klog", Source: source, Msg: strings.TrimSuffix(msg, "\n")}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	kvs := append(append([]interface{}{}, s.values...), keysAndValues...)
+	if s.name != "" {
+		kvs = append([]interface{}{"logger", s.name}, kvs...)
+	}
+	for i := 0; i+1 < len(kvs); i += 2 {
+		if e.Fields == nil {
+			e.Fields = map[string]interface{}{}
+		}
+		e.Fields[fmt.Sprint(kvs[i])] = kvs[i+1]
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.json {
+		e.write(s.out)
+		return
+	}
+	// the format of klog itself, like "W1016 12:00:00.000000   123 gen.go:12] msg"
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, "%c%s %7d %s] %s", strings.ToUpper(level)[0], time.Now().Format("0102 15:04:05.000000"), os.Getpid(), source, e.Msg)
+	if e.Error != "" {
+		fmt.Fprintf(b, " err=%q", e.Error)
+	}
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(b, " %s=%v", k, e.Fields[k])
+	}
+	b.WriteByte('\n')
+	s.out.Write(b.Bytes())
+}
+
+const klogPackage = "k8s.io/klog/v2."
+
+// klogCaller returns the level of the outermost klog function in the stack, e.g. "warning" of klog.Warningf,
+// and the file and line calling it
+func klogCaller() (level, source string) {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	klogFunc := ""
+	for {
+		frame, more := frames.Next()
+		if strings.HasPrefix(frame.Function, klogPackage) {
+			klogFunc = frame.Function[strings.LastIndex(frame.Function, ".")+1:]
+		} else if klogFunc != "" {
+			source = fmt.Sprintf("%s:%d", filepath.Base(frame.File), frame.Line)
+			break
+		}
+		if !more {
+			break
+		}
+	}
+	switch {
+	case strings.HasPrefix(klogFunc, "Warning"):
+		return "warning", source
+	case strings.HasPrefix(klogFunc, "Error"):
+		return "error", source
+	case strings.HasPrefix(klogFunc, "Fatal"), strings.HasPrefix(klogFunc, "Exit"):
+		return "fatal", source
+	}
+	return "info", source
+}
+
+// jsonFormatter formats entries of yunion log as json objects by --json-logs
+type jsonFormatter struct{}
+
+func (jsonFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	e := logEntry{Level: entry.Level.String(), Logger: "log", Msg: entry.Message}
+	for k, v := range entry.Data {
+		if k == "caller" {
+			e.Source = fmt.Sprint(v)
+			continue
+		}
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		if e.Fields == nil {
+			e.Fields = map[string]interface{}{}
+		}
+		e.Fields[k] = v
+	}
+	b := &bytes.Buffer{}
+	if err := e.write(b); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// logSummary logs msg as info entry of yunion log even if --quiet drops info entries,
+// so the progress summary is kept in CI logs. Hooks are skipped, the summary is not a warning.
+func logSummary(logger *logrus.Logger, msg string) {
+	entry := logrus.NewEntry(logger)
+	entry.Time = time.Now()
+	entry.Level = logrus.InfoLevel
+	entry.Message = msg
+	line, err := logger.Formatter.Format(entry)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, msg)
+		return
+	}
+	logger.Out.Write(line)
+}
+
+// warningHook counts warnings and errors logged by yunion log
+type warningHook struct{}
+
+func (warningHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel, logrus.WarnLevel}
+}
+
+func (warningHook) Fire(*logrus.Entry) error {
+	atomic.AddInt64(&yunionWarnings, 1)
+	return nil
+}
+
+// yunionWarnings counts warnings and errors logged by yunion log
+var yunionWarnings int64
+
+// warnings returns the number of warnings and errors logged by klog and yunion log
+func warnings() int64 {
+	return atomic.LoadInt64(&yunionWarnings) + klog.Stats.Warning.Lines() + klog.Stats.Error.Lines()
+}
+
+// progress counts what is done by a generation run
+type progress struct {
+	packages int64
+	types    int64
+	routes   int64
+	// warnings are the warnings logged before the run
+	warnings int64
+	start    time.Time
+}
+
+var globalProgress = &progress{}
+
+// startProgress resets the progress summary for a new run, e.g. regeneration of --watch
+func startProgress() {
+	atomic.StoreInt64(&globalProgress.packages, 0)
+	atomic.StoreInt64(&globalProgress.types, 0)
+	atomic.StoreInt64(&globalProgress.routes, 0)
+	atomic.StoreInt64(&globalProgress.warnings, warnings())
+	globalProgress.start = time.Now()
+}
+
+// CountRoute records a generated route in progress summary
+func CountRoute() {
+	atomic.AddInt64(&globalProgress.routes, 1)
+}
+
+func (p *progress) String() string {
+	return fmt.Sprintf("packages=%d types=%d routes=%d warnings=%d elapsed=%s",
+		atomic.LoadInt64(&p.packages),
+		atomic.LoadInt64(&p.types),
+		atomic.LoadInt64(&p.routes),
+		warnings()-atomic.LoadInt64(&p.warnings),
+		time.Since(p.start).Round(time.Millisecond),
+	)
+}
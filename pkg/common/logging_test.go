@@ -0,0 +1,175 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/sirupsen/logrus"
+	"k8s.io/klog/v2"
+)
+
+// decodeEntries decodes json entries logged to out, sources are checked to be logging_test.go and cleared
+func decodeEntries(t *testing.T, out *bytes.Buffer) []logEntry {
+	got := []logEntry{}
+	dec := json.NewDecoder(out)
+	for dec.More() {
+		e := logEntry{}
+		if err := dec.Decode(&e); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(e.Source, "logging_test.go:") {
+			t.Errorf("entry %q logged from %q, want logging_test.go", e.Msg, e.Source)
+		}
+		e.Source = ""
+		got = append(got, e)
+	}
+	return got
+}
+
+func Test_klogSink(t *testing.T) {
+	exit := klog.OsExit
+	defer func() { klog.OsExit = exit }()
+	// klog must not continue after exiting, stop the fatal call by panic instead
+	klog.OsExit = func(code int) { panic(code) }
+	exitf := func(format string, args ...interface{}) {
+		defer func() { recover() }()
+		klog.Exitf(format, args...)
+	}
+
+	tests := []struct {
+		name  string
+		quiet bool
+		want  []logEntry
+	}{
+		{name: "json", want: []logEntry{
+			{Level: "info", Logger: "klog", Msg: "started"},
+			{Level: "warning", Logger: "klog", Msg: "skipped\nsecond line"},
+			{Level: "error", Logger: "klog", Msg: "parse", Error: "failed", Fields: map[string]interface{}{"pkg": "compute"}},
+			{Level: "fatal", Logger: "klog", Msg: "no input packages"},
+		}},
+		{name: "quiet", quiet: true, want: []logEntry{
+			{Level: "warning", Logger: "klog", Msg: "skipped\nsecond line"},
+			{Level: "error", Logger: "klog", Msg: "parse", Error: "failed", Fields: map[string]interface{}{"pkg": "compute"}},
+			{Level: "fatal", Logger: "klog", Msg: "no input packages"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			klog.SetLogger(logr.New(&klogSink{out: out, quiet: tt.quiet, json: true}))
+			defer klog.ClearLogger()
+			klog.Infof("started")
+			klog.Warningf("skipped\nsecond line")
+			klog.ErrorS(errors.New("failed"), "parse", "pkg", "compute")
+			exitf("no input packages")
+
+			if got := decodeEntries(t, out); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("klog logged %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_klogSinkValues(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := logr.New(&klogSink{out: out, json: true}).WithName("swagger").WithValues("pkg", "compute").WithName("route")
+	klog.SetLogger(logger)
+	defer klog.ClearLogger()
+	klog.InfoS("route added", "route", "list")
+	klog.ErrorS(errors.New("no handler"), "route skipped", "route", "get")
+
+	want := []logEntry{
+		{Level: "info", Logger: "klog", Msg: "route added", Fields: map[string]interface{}{"logger": "swagger/route", "pkg": "compute", "route": "list"}},
+		{Level: "error", Logger: "klog", Msg: "route skipped", Error: "no handler", Fields: map[string]interface{}{"logger": "swagger/route", "pkg": "compute", "route": "get"}},
+	}
+	if got := decodeEntries(t, out); !reflect.DeepEqual(got, want) {
+		t.Errorf("klog logged %+v, want %+v", got, want)
+	}
+}
+
+func Test_klogSinkText(t *testing.T) {
+	out := &bytes.Buffer{}
+	klog.SetLogger(logr.New(&klogSink{out: out, quiet: true}))
+	defer klog.ClearLogger()
+	klog.Infof("started")
+	klog.Warningf("skipped")
+
+	line := out.String()
+	if !strings.HasPrefix(line, "W") || !strings.Contains(line, " logging_test.go:") || !strings.HasSuffix(line, "] skipped\n") {
+		t.Errorf("klog logged %q, want one warning line", line)
+	}
+}
+
+func Test_jsonFormatter(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := logrus.New()
+	logger.Out = out
+	logger.Formatter = jsonFormatter{}
+	logger.AddHook(warningHook{})
+	before := atomic.LoadInt64(&yunionWarnings)
+
+	logger.WithField("caller", "generators.addRoute(gen.go:12)").Warn("route skipped\nby tag")
+	logger.WithField("route", "list").Info("route added")
+	logger.WithError(errors.New("no handler")).Error("route skipped")
+
+	want := `{"level":"warning","logger":"log","source":"generators.addRoute(gen.go:12)","msg":"route skipped\nby tag"}` + "\n" +
+		`{"level":"info","logger":"log","msg":"route added","fields":{"route":"list"}}` + "\n" +
+		`{"level":"error","logger":"log","msg":"route skipped","fields":{"error":"no handler"}}` + "\n"
+	if out.String() != want {
+		t.Errorf("logged %q, want %q", out.String(), want)
+	}
+	if got := atomic.LoadInt64(&yunionWarnings) - before; got != 2 {
+		t.Errorf("counted %d warnings, want 2", got)
+	}
+}
+
+func Test_logSummary(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := logrus.New()
+	logger.Out = out
+	logger.Formatter = jsonFormatter{}
+	logger.AddHook(warningHook{})
+	// --quiet
+	logger.SetLevel(logrus.WarnLevel)
+	before := atomic.LoadInt64(&yunionWarnings)
+
+	logger.Info("dropped")
+	logSummary(logger, "generation finished: packages=1")
+
+	want := `{"level":"info","logger":"log","msg":"generation finished: packages=1"}` + "\n"
+	if out.String() != want {
+		t.Errorf("logged %q, want %q", out.String(), want)
+	}
+	if got := atomic.LoadInt64(&yunionWarnings) - before; got != 0 {
+		t.Errorf("summary counted %d warnings, want 0", got)
+	}
+}
+
+func Test_progress(t *testing.T) {
+	klog.SetLogger(logr.New(&klogSink{out: &bytes.Buffer{}, quiet: true}))
+	defer klog.ClearLogger()
+	klog.Warningf("logged before the run")
+
+	startProgress()
+	atomic.StoreInt64(&globalProgress.packages, 2)
+	atomic.StoreInt64(&globalProgress.types, 30)
+	CountRoute()
+	CountRoute()
+	klog.Warningf("skipped")
+	atomic.AddInt64(&yunionWarnings, 1)
+
+	got := globalProgress.String()
+	if want := "packages=2 types=30 routes=2 warnings=2 elapsed="; !strings.HasPrefix(got, want) {
+		t.Errorf("progress = %q, want prefix %q", got, want)
+	}
+	startProgress()
+	if got, want := globalProgress.String(), "packages=0 types=0 routes=0 warnings=0 elapsed="; !strings.HasPrefix(got, want) {
+		t.Errorf("progress after restart = %q, want prefix %q", got, want)
+	}
+}
@@ -0,0 +1,224 @@
+package common
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// ManifestModule is the version of a go module which input packages are loaded from
+type ManifestModule struct {
+	// Path is the module path, e.g. yunion.io/x/onecloud
+	Path string `json:"path"`
+	// Version is the module version, empty for the main module or modules replaced by directories
+	Version string `json:"version,omitempty"`
+	// Commit is the git HEAD of module directory when it has no version
+	Commit string `json:"commit,omitempty"`
+	// Dirty is true if the module directory has uncommitted changes
+	Dirty bool `json:"dirty,omitempty"`
+}
+
+// Manifest records a generation run, tools compare it with the inputs to detect
+// stale generated code and rerun the generator with the same arguments
+type Manifest struct {
+	// Generator is the command name, e.g. swagger-gen
+	Generator string `json:"generator"`
+	// Version is the module version of the generator binary
+	Version string `json:"version"`
+	// Args are the command line arguments of the run
+	Args []string `json:"args"`
+	// Modules are versions of modules of input packages
+	Modules []ManifestModule `json:"modules"`
+	// Files maps generated files relative to output base to their sha256
+	Files map[string]string `json:"files"`
+}
+
+// generatorVersion returns main module version of the running binary
+func generatorVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	return info.Main.Version
+}
+
+// hashFile returns hex sha256 of file content
+func hashFile(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashFiles hashes files written by the run, keyed by slash separated paths relative to outDir,
+// files not written, e.g. of generators emitting nothing, are skipped
+func hashFiles(outDir string, files []string) (map[string]string, error) {
+	base, err := filepath.Abs(outDir)
+	if err != nil {
+		return nil, err
+	}
+	ret := make(map[string]string)
+	for _, file := range files {
+		sum, err := hashFile(file)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return nil, err
+		}
+		rel, err := filepath.Rel(base, abs)
+		if err != nil {
+			return nil, err
+		}
+		ret[filepath.ToSlash(rel)] = sum
+	}
+	return ret, nil
+}
+
+// ReadManifest reads manifest of the previous run from file, nil if file is empty or not written yet
+func ReadManifest(file string) (*Manifest, error) {
+	if file == "" {
+		return nil, nil
+	}
+	content, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	m := &Manifest{}
+	if err := json.Unmarshal(content, m); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %v", file, err)
+	}
+	return m, nil
+}
+
+// listedPackage is the module part of go list -json output
+type listedPackage struct {
+	Module *struct {
+		Path    string
+		Version string
+		Dir     string
+		Replace *struct {
+			Version string
+			Dir     string
+		}
+	}
+}
+
+// gitCommit returns HEAD of git repository containing dir and whether the worktree is dirty,
+// empty if dir is not in a git repository
+func gitCommit(dir string) (string, bool) {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		klog.V(2).Infof("git commit of %s: %v", dir, err)
+		return "", false
+	}
+	status, err := exec.Command("git", "-C", dir, "status", "--porcelain", "--", ".").Output()
+	return strings.TrimSpace(string(out)), err == nil && len(bytes.TrimSpace(status)) != 0
+}
+
+// decodeModules returns versions of modules in go list -json output, packages
+// out of modules, e.g. loaded in GOPATH mode, are skipped
+func decodeModules(out []byte, modules map[string]ManifestModule) error {
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for dec.More() {
+		pkg := listedPackage{}
+		if err := dec.Decode(&pkg); err != nil {
+			return err
+		}
+		mod := pkg.Module
+		if mod == nil {
+			continue
+		}
+		if _, ok := modules[mod.Path]; ok {
+			continue
+		}
+		m := ManifestModule{Path: mod.Path, Version: mod.Version}
+		dir := mod.Dir
+		if mod.Replace != nil {
+			m.Version, dir = mod.Replace.Version, mod.Replace.Dir
+		}
+		if m.Version == "" && dir != "" {
+			m.Commit, m.Dirty = gitCommit(dir)
+		}
+		modules[mod.Path] = m
+	}
+	return nil
+}
+
+// inputModules lists modules of input packages in their module directories
+func inputModules(inputs []string, la *LoaderArgs) ([]ManifestModule, error) {
+	modules, err := LoadModules(la.Modules)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]ManifestModule)
+	for _, group := range groupInputs(inputs, modules) {
+		args := append([]string{"list", "-e", "-json"}, buildTagsFlag(la.Tags)...)
+		cmd := exec.Command("go", append(args, group.patterns...)...)
+		cmd.Dir = group.dir
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("list modules of %v: %v", group.patterns, err)
+		}
+		if err := decodeModules(out, found); err != nil {
+			return nil, fmt.Errorf("decode modules of %v: %v", group.patterns, err)
+		}
+	}
+	ret := make([]ManifestModule, 0, len(found))
+	for _, m := range found {
+		ret = append(ret, m)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Path < ret[j].Path })
+	return ret, nil
+}
+
+// WriteManifest writes manifest of generation run to la.Manifest, inputs are the resolved input packages
+// and files are all the files written by the run, generated ones and reports
+func WriteManifest(la *LoaderArgs, inputs []string, outDir string, files []string) error {
+	if la.Manifest == "" {
+		return nil
+	}
+	modules, err := inputModules(inputs, la)
+	if err != nil {
+		return err
+	}
+	hashes, err := hashFiles(outDir, files)
+	if err != nil {
+		return fmt.Errorf("hash generated files: %v", err)
+	}
+	m := Manifest{
+		Generator: filepath.Base(os.Args[0]),
+		Version:   generatorVersion(),
+		Args:      os.Args[1:],
+		Modules:   modules,
+		Files:     hashes,
+	}
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(la.Manifest, append(content, '\n'), 0644)
+}
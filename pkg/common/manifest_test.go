@@ -0,0 +1,103 @@
+package common
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_hashFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "manifest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	outDir := filepath.Join(dir, "src")
+	pkgDir := filepath.Join(outDir, "pkg", "apis")
+	if err := os.MkdirAll(pkgDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	// hand written file touched during the run is not recorded
+	if err := ioutil.WriteFile(filepath.Join(pkgDir, "doc.go"), []byte("package apis\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	files := []string{
+		filepath.Join(pkgDir, "zz_generated.model.go"),
+		filepath.Join(dir, "stats.json"),
+		// generator emitting nothing
+		filepath.Join(pkgDir, "zz_generated.empty.go"),
+	}
+	for _, file := range files[:2] {
+		if err := ioutil.WriteFile(file, []byte("package apis\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := hashFiles(outDir, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// sha256 of "package apis\n"
+	const sum = "65ea901a24712b514382df28d8cd6621530e22356d8ab1bd4d1d15cec2debebb"
+	want := map[string]string{
+		"pkg/apis/zz_generated.model.go": sum,
+		"../stats.json":                  sum,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("hashFiles() = %v, want %v", got, want)
+	}
+}
+
+func TestReadManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "manifest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "manifest.json")
+	if m, err := ReadManifest(file); m != nil || err != nil {
+		t.Errorf("ReadManifest() of missing file = %v, %v, want nil", m, err)
+	}
+	if err := ioutil.WriteFile(file, []byte(`{"generator": "model-api-gen", "files": {"pkg/apis/zz_generated.model.go": "abc"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := ReadManifest(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"pkg/apis/zz_generated.model.go": "abc"}; !reflect.DeepEqual(m.Files, want) {
+		t.Errorf("ReadManifest() files = %v, want %v", m.Files, want)
+	}
+}
+
+func Test_decodeModules(t *testing.T) {
+	out := []byte(`{
+	"ImportPath": "yunion.io/x/onecloud/pkg/compute/models",
+	"Module": {"Path": "yunion.io/x/onecloud", "Version": "v3.10.1"}
+}
+{
+	"ImportPath": "yunion.io/x/onecloud/pkg/image/models",
+	"Module": {"Path": "yunion.io/x/onecloud", "Version": "v3.10.1"}
+}
+{
+	"ImportPath": "yunion.io/x/cloudmux/pkg/multicloud/esxi",
+	"Module": {"Path": "yunion.io/x/cloudmux", "Version": "v0.1.0", "Replace": {"Version": "v0.1.1"}}
+}
+{
+	"ImportPath": "example.com/gopath/models"
+}
+`)
+	got := make(map[string]ManifestModule)
+	if err := decodeModules(out, got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]ManifestModule{
+		"yunion.io/x/onecloud": {Path: "yunion.io/x/onecloud", Version: "v3.10.1"},
+		"yunion.io/x/cloudmux": {Path: "yunion.io/x/cloudmux", Version: "v0.1.1"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decodeModules() = %v, want %v", got, want)
+	}
+}
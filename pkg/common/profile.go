@@ -0,0 +1,92 @@
+package common
+
+import (
+	"fmt"
+	"net/http"
+	httppprof "net/http/pprof"
+	"os"
+	"runtime"
+	"runtime/pprof"
+
+	"github.com/spf13/pflag"
+	"k8s.io/klog/v2"
+)
+
+// ProfileArgs are the arguments to profile a generation run.
+// The parsed universe is kept until generation finished unless --stream-packages is set,
+// which releases the types of each input package once it's generated.
+type ProfileArgs struct {
+	// CPUProfile is the file cpu profile written to
+	CPUProfile string
+	// MemProfile is the file heap profile written to when generation finished
+	MemProfile string
+	// PprofAddr is the address pprof http endpoints listen on during generation
+	PprofAddr string
+}
+
+// AddFlags add profiling flags to fs
+func (pa *ProfileArgs) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&pa.CPUProfile, "cpu-profile", pa.CPUProfile, "Write cpu profile of generation to file")
+	fs.StringVar(&pa.MemProfile, "mem-profile", pa.MemProfile, "Write heap profile to file when generation finished")
+	fs.StringVar(&pa.PprofAddr, "pprof-addr", pa.PprofAddr, "Serve pprof endpoints like /debug/pprof/heap on address, e.g. localhost:6060")
+}
+
+// Start starts profiling, the returned stop func must be called when generation finished
+func (pa *ProfileArgs) Start() (func(), error) {
+	if pa.PprofAddr != "" {
+		mux := pprofMux()
+		go func() {
+			klog.Infof("serving pprof on http://%s/debug/pprof/", pa.PprofAddr)
+			if err := http.ListenAndServe(pa.PprofAddr, mux); err != nil {
+				klog.Errorf("serve pprof on %s: %v", pa.PprofAddr, err)
+			}
+		}()
+	}
+	var cpuFile *os.File
+	if pa.CPUProfile != "" {
+		f, err := os.Create(pa.CPUProfile)
+		if err != nil {
+			return nil, fmt.Errorf("create cpu profile: %v", err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("start cpu profile: %v", err)
+		}
+		cpuFile = f
+	}
+	return func() {
+		if cpuFile != nil {
+			pprof.StopCPUProfile()
+			cpuFile.Close()
+		}
+		if pa.MemProfile != "" {
+			if err := writeHeapProfile(pa.MemProfile); err != nil {
+				klog.Errorf("write heap profile: %v", err)
+			}
+		}
+	}, nil
+}
+
+// pprofMux returns a private mux of pprof endpoints served on --pprof-addr only.
+// Importing net/http/pprof also registers them to http.DefaultServeMux,
+// so commands must serve their own mux instead of the default one.
+func pprofMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", httppprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", httppprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", httppprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", httppprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", httppprof.Trace)
+	return mux
+}
+
+func writeHeapProfile(file string) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	// get up-to-date statistics of live objects
+	runtime.GC()
+	return pprof.WriteHeapProfile(f)
+}
@@ -0,0 +1,17 @@
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_pprofMux(t *testing.T) {
+	for _, path := range []string{"/debug/pprof/", "/debug/pprof/heap", "/debug/pprof/symbol"} {
+		rec := httptest.NewRecorder()
+		pprofMux().ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("pprof mux GET %s = %d, want %d", path, rec.Code, http.StatusOK)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package common
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/klog/v2"
+
+	"yunion.io/x/pkg/util/sets"
+)
+
+// producedFiles are the output files written by a run, file names keyed by output directory
+type producedFiles map[string]sets.String
+
+func (f producedFiles) add(dir, name string) {
+	if f[dir] == nil {
+		f[dir] = sets.NewString()
+	}
+	f[dir].Insert(name)
+}
+
+// trackedPackage records output files of generators when package is executed in dir
+type trackedPackage struct {
+	generator.Target
+	dir      string
+	produced producedFiles
+}
+
+func (p *trackedPackage) Dir() string {
+	return p.dir
+}
+
+func (p *trackedPackage) Generators(c *generator.Context) []generator.Generator {
+	gens := p.Target.Generators(c)
+	for _, g := range gens {
+		p.produced.add(p.dir, g.Filename())
+	}
+	return gens
+}
+
+// GeneratedMarker returns the "Code generated" comment gengo writes in headers of files generated
+// by the running generator, empty if GeneratedByCommentTemplate is unset
+func GeneratedMarker(commentTemplate string) string {
+	return strings.Replace(commentTemplate, "GENERATOR_NAME", path.Base(os.Args[0]), -1)
+}
+
+// hasGeneratedMarker returns true if marker line is in the header of go file, before the package clause
+func hasGeneratedMarker(file, marker string) (bool, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == marker {
+			return true, nil
+		}
+		if strings.HasPrefix(line, "package ") {
+			break
+		}
+	}
+	return false, scanner.Err()
+}
+
+// paths returns the produced files in order
+func (f producedFiles) paths() []string {
+	ret := make([]string, 0)
+	for _, dir := range sets.StringKeySet(f).List() {
+		for _, name := range f[dir].List() {
+			ret = append(ret, filepath.Join(dir, name))
+		}
+	}
+	return ret
+}
+
+// has returns true if file is produced by the run
+func (f producedFiles) has(file string) bool {
+	names, ok := f[filepath.Dir(file)]
+	return ok && names.Has(filepath.Base(file))
+}
+
+// pruneCandidates returns the files which previous runs may have generated: files of previous manifest,
+// keyed relative to outDir, and <base>.go of produced directories, which inputs of this run are generated to
+// without --output-file-template
+func pruneCandidates(produced producedFiles, outDir string, previous map[string]string, base string) []string {
+	files := sets.NewString()
+	for rel := range previous {
+		files.Insert(filepath.Join(outDir, filepath.FromSlash(rel)))
+	}
+	if base != "" {
+		for dir := range produced {
+			files.Insert(filepath.Join(dir, base+".go"))
+		}
+	}
+	return files.List()
+}
+
+// pruneFiles removes candidate go files marked by marker which are not produced by the run,
+// removed files are returned
+func pruneFiles(candidates []string, produced producedFiles, marker string) ([]string, error) {
+	removed := make([]string, 0)
+	for _, file := range candidates {
+		if filepath.Ext(file) != ".go" || produced.has(file) {
+			continue
+		}
+		info, err := os.Stat(file)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return removed, err
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+		marked, err := hasGeneratedMarker(file, marker)
+		if err != nil {
+			return removed, err
+		}
+		if !marked {
+			continue
+		}
+		if err := os.Remove(file); err != nil {
+			return removed, err
+		}
+		klog.Infof("pruned stale generated file %s", file)
+		removed = append(removed, file)
+	}
+	return removed, nil
+}
+
+// pruneStale removes stale generated files of the previous run recorded by manifest previous, nil if not recorded,
+// and of this run's inputs named by base without --output-file-template text
+func pruneStale(produced producedFiles, outDir string, previous *Manifest, base, text, commentTemplate string) error {
+	marker := GeneratedMarker(commentTemplate)
+	if marker == "" {
+		return fmt.Errorf("generated files can't be identified without --go-header-file generated by comment")
+	}
+	var files map[string]string
+	if previous != nil {
+		files = previous.Files
+	}
+	if text == "" {
+		// files of inputs are named by base already
+		base = ""
+	}
+	_, err := pruneFiles(pruneCandidates(produced, outDir, files, base), produced, marker)
+	return err
+}
@@ -0,0 +1,68 @@
+package common
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"yunion.io/x/pkg/util/sets"
+)
+
+func Test_pruneFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "prune")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	pkgDir := filepath.Join(dir, "pkg", "generated", "swagger", "compute")
+	if err := os.MkdirAll(pkgDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	const marker = "// Code generated by swagger-gen. DO NOT EDIT."
+	files := map[string]string{
+		// produced by the run
+		"zz_generated.swagger_spec.compute.go": marker + "\n\npackage compute\n",
+		// generated from input removed since the previous run
+		"zz_generated.swagger_spec.image.go": "// Copyright\n\n" + marker + "\n\npackage compute\n",
+		// named by base before --output-file-template is used
+		"zz_generated.swagger_spec.go": marker + "\n\npackage compute\n",
+		// hand written file listed by previous manifest
+		"zz_generated.swagger_spec.notes.go": "package compute\n\n" + marker + "\n",
+		// generated by another run into the same package, not in the manifest of this run
+		"zz_generated.swagger_spec.identity.go": marker + "\n\npackage compute\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(pkgDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	produced := producedFiles{pkgDir: sets.NewString("zz_generated.swagger_spec.compute.go")}
+	previous := map[string]string{
+		"pkg/generated/swagger/compute/zz_generated.swagger_spec.compute.go": "",
+		"pkg/generated/swagger/compute/zz_generated.swagger_spec.image.go":   "",
+		"pkg/generated/swagger/compute/zz_generated.swagger_spec.notes.go":   "",
+		"pkg/generated/swagger/compute/removed.go":                           "",
+		"_output/stats.json": "",
+	}
+	candidates := pruneCandidates(produced, dir, previous, "zz_generated.swagger_spec")
+	removed, err := pruneFiles(candidates, produced, marker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantRemoved := []string{
+		filepath.Join(pkgDir, "zz_generated.swagger_spec.go"),
+		filepath.Join(pkgDir, "zz_generated.swagger_spec.image.go"),
+	}
+	if !reflect.DeepEqual(removed, wantRemoved) {
+		t.Errorf("pruneFiles() = %v, want %v", removed, wantRemoved)
+	}
+	for name := range files {
+		file := filepath.Join(pkgDir, name)
+		_, err := os.Stat(file)
+		if exists := err == nil; exists == sets.NewString(wantRemoved...).Has(file) {
+			t.Errorf("file %s exists = %v after pruning", name, exists)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package common
+
+import (
+	"strings"
+)
+
+// TagSecret marks field as secret, e.g. password of server, it's documented as write only password
+// and omitted if empty, output types like models and Details structs skip it:
+// +onecloud:model-api-gen-secret
+const TagSecret = "onecloud:model-api-gen-secret"
+
+var _ = RegisterAnnotations(Annotation{
+	Name:        TagSecret,
+	Generator:   "model-api-gen",
+	Syntax:      "[false]",
+	Targets:     []string{TargetField},
+	Description: "document field as write only password and omit it if empty, it's skipped in output types",
+})
+
+// IsSecret returns true if comments are tagged by TagSecret, unless its value is false
+func IsSecret(comments []string) bool {
+	val, ok := ParseTags(comments).Value(TagSecret)
+	return ok && val != "false"
+}
+
+// SecretLines adds go-swagger annotations of secret field to lines without comment marker,
+// the password format is put before Extensions: block which lasts till the end of comment
+func SecretLines(lines []string) []string {
+	ret := make([]string, 0, len(lines)+3)
+	inserted := false
+	for _, l := range lines {
+		if !inserted && strings.TrimSpace(l) == "Extensions:" {
+			ret = append(ret, "swagger:strfmt password")
+			inserted = true
+		}
+		ret = append(ret, l)
+	}
+	if !inserted {
+		ret = append(ret, "swagger:strfmt password")
+	}
+	return AppendExtension(ret, "x-writeOnly", "true")
+}
@@ -0,0 +1,89 @@
+package common
+
+import (
+	"strings"
+
+	"yunion.io/x/pkg/util/sets"
+)
+
+// TagParser reads +<name>=<value> tags of comment lines, repeated tags keep all their values
+// in order and tag without value has an empty value, e.g.:
+// +onecloud:swagger-gen-ignore
+// +onecloud:swagger-gen-route-tag=server
+type TagParser struct {
+	tags map[string][]string
+}
+
+// ParseTags parses tags of comment lines, lines of several declarations are joined in order,
+// e.g. comments of a method and its receiver
+func ParseTags(comments ...[]string) TagParser {
+	lines := make([]string, 0)
+	for _, c := range comments {
+		lines = append(lines, c...)
+	}
+	return TagParser{tags: extractCommentTags("+", lines)}
+}
+
+// extractCommentTags returns values of marker<key>=<value> lines by key, like ExtractCommentTags
+// of gengo v1, which gengo v2 deprecates for tags of function style
+func extractCommentTags(marker string, lines []string) map[string][]string {
+	out := make(map[string][]string)
+	for _, line := range lines {
+		line = strings.Trim(line, " ")
+		if !strings.HasPrefix(line, marker) {
+			continue
+		}
+		kv := strings.SplitN(line[len(marker):], "=", 2)
+		if len(kv) == 2 {
+			out[kv[0]] = append(out[kv[0]], kv[1])
+		} else {
+			out[kv[0]] = append(out[kv[0]], "")
+		}
+	}
+	return out
+}
+
+// Values returns values of tag name, nil if not tagged
+func (p TagParser) Values(name string) []string {
+	return p.tags[name]
+}
+
+// Value returns the first value of tag name, false if not tagged
+func (p TagParser) Value(name string) (string, bool) {
+	vals := p.tags[name]
+	if len(vals) == 0 {
+		return "", false
+	}
+	return vals[0], true
+}
+
+// Has returns true if tag name is present, with or without value
+func (p TagParser) Has(name string) bool {
+	_, ok := p.tags[name]
+	return ok
+}
+
+// List returns comma separated values of tag name in order, blanks are trimmed and empty items dropped,
+// e.g. list,delete of +onecloud:swagger-gen-disable=list, delete
+func (p TagParser) List(name string) []string {
+	ret := make([]string, 0)
+	for _, val := range p.tags[name] {
+		for _, item := range strings.Split(val, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				ret = append(ret, item)
+			}
+		}
+	}
+	return ret
+}
+
+// Verbs returns comma separated verbs of tag name like +onecloud:swagger-gen-ignore=delete,update,
+// all is true if the tag has no value, which applies it to the whole declaration
+func (p TagParser) Verbs(name string) (all bool, verbs sets.String) {
+	for _, val := range p.tags[name] {
+		if val == "" {
+			return true, sets.NewString()
+		}
+	}
+	return false, sets.NewString(p.List(name)...)
+}
@@ -0,0 +1,39 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTagParser(t *testing.T) {
+	tags := ParseTags(
+		[]string{
+			"SGuestManager serves servers",
+			"+onecloud:swagger-gen-ignore",
+			"+onecloud:swagger-gen-disable=list, delete",
+		},
+		[]string{
+			"+onecloud:swagger-gen-route-tag=server",
+			"+onecloud:swagger-gen-route-tag=guest",
+			"+onecloud:swagger-gen-disable=,update",
+		},
+	)
+	if got := tags.Values("onecloud:swagger-gen-route-tag"); !reflect.DeepEqual(got, []string{"server", "guest"}) {
+		t.Errorf("Values() = %v, want [server guest]", got)
+	}
+	if got, ok := tags.Value("onecloud:swagger-gen-ignore"); !ok || got != "" {
+		t.Errorf("Value() = %q, %v, want empty value", got, ok)
+	}
+	if _, ok := tags.Value("onecloud:swagger-gen-singleton"); ok || tags.Has("onecloud:swagger-gen-singleton") {
+		t.Errorf("untagged onecloud:swagger-gen-singleton is found")
+	}
+	if got := tags.List("onecloud:swagger-gen-disable"); !reflect.DeepEqual(got, []string{"list", "delete", "update"}) {
+		t.Errorf("List() = %v, want [list delete update]", got)
+	}
+	if all, _ := tags.Verbs("onecloud:swagger-gen-ignore"); !all {
+		t.Errorf("Verbs() of tag without value should apply to all")
+	}
+	if all, verbs := tags.Verbs("onecloud:swagger-gen-disable"); all || !reflect.DeepEqual(verbs.List(), []string{"delete", "list", "update"}) {
+		t.Errorf("Verbs() = %v, %v, want false, [delete list update]", all, verbs.List())
+	}
+}
@@ -0,0 +1,16 @@
+// Package faketypes are fixtures building gengo types by hand, shared by generator tests
+// which don't parse a testdata package.
+package faketypes
+
+import (
+	"k8s.io/gengo/v2/types"
+)
+
+// ParamResults returns signature parameters or results of types ts
+func ParamResults(ts ...*types.Type) []*types.ParamResult {
+	ret := make([]*types.ParamResult, 0, len(ts))
+	for _, t := range ts {
+		ret = append(ret, &types.ParamResult{Type: t})
+	}
+	return ret
+}
@@ -0,0 +1,93 @@
+package common
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	"k8s.io/gengo/v2/types"
+)
+
+// TypeOverride is the swagger schema of a go type
+type TypeOverride struct {
+	// Type is the swagger type, e.g. string
+	Type string `yaml:"type"`
+	// Format is the swagger format, e.g. date-time, ipv4
+	Format string `yaml:"format"`
+	// Example is the example value
+	Example string `yaml:"example"`
+}
+
+// TypeOverrides maps go type names to swagger schema, e.g.:
+//
+//	time.Time:
+//	  type: string
+//	  format: date-time
+//	  example: "2020-01-02T03:04:05.000000Z"
+//	netutils.IPV4Addr:
+//	  type: string
+//	  format: ipv4
+//
+// type names are full like yunion.io/x/pkg/util/netutils.IPV4Addr
+// or qualified by package base name like netutils.IPV4Addr
+type TypeOverrides map[string]TypeOverride
+
+// LoadTypeOverrides reads type overrides yaml file, empty overrides if file is empty
+func LoadTypeOverrides(file string) (TypeOverrides, error) {
+	ret := make(TypeOverrides)
+	if file == "" {
+		return ret, nil
+	}
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(content, &ret); err != nil {
+		return nil, fmt.Errorf("unmarshal type overrides %s: %v", file, err)
+	}
+	for name, o := range ret {
+		if o.Type == "" && o.Format == "" && o.Example == "" {
+			return nil, fmt.Errorf("type override of %s is empty", name)
+		}
+	}
+	return ret, nil
+}
+
+// Lookup returns override of t, pointer is dereferenced
+func (o TypeOverrides) Lookup(t *types.Type) (TypeOverride, bool) {
+	if t == nil || len(o) == 0 {
+		return TypeOverride{}, false
+	}
+	if t.Kind == types.Pointer {
+		t = t.Elem
+	}
+	if ret, ok := o[t.String()]; ok {
+		return ret, true
+	}
+	if t.Name.Package == "" {
+		return TypeOverride{}, false
+	}
+	ret, ok := o[fmt.Sprintf("%s.%s", path.Base(t.Name.Package), t.Name.Name)]
+	return ret, ok
+}
+
+// CommentLines returns go-swagger field annotations of t override, without comment marker
+func (o TypeOverrides) CommentLines(t *types.Type) []string {
+	ov, ok := o.Lookup(t)
+	if !ok {
+		return nil
+	}
+	ret := make([]string, 0)
+	if ov.Type != "" {
+		ret = append(ret, fmt.Sprintf("swagger:type %s", ov.Type))
+	}
+	if ov.Format != "" {
+		ret = append(ret, fmt.Sprintf("swagger:strfmt %s", ov.Format))
+	}
+	if ov.Example != "" {
+		ret = append(ret, fmt.Sprintf("example: %s", strings.TrimSpace(ov.Example)))
+	}
+	return ret
+}
@@ -0,0 +1,58 @@
+package common
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"k8s.io/gengo/v2/types"
+)
+
+func TestTypeOverrides(t *testing.T) {
+	dir, err := ioutil.TempDir("", "type-overrides")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "overrides.yaml")
+	content := `
+time.Time:
+  type: string
+  format: date-time
+  example: "2020-01-02T03:04:05.000000Z"
+netutils.IPV4Addr:
+  type: string
+  format: ipv4
+`
+	if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	overrides, err := LoadTypeOverrides(file)
+	if err != nil {
+		t.Fatalf("LoadTypeOverrides error: %v", err)
+	}
+	timeType := &types.Type{Name: types.Name{Package: "time", Name: "Time"}, Kind: types.Struct}
+	ipType := &types.Type{Name: types.Name{Package: "yunion.io/x/pkg/util/netutils", Name: "IPV4Addr"}, Kind: types.Alias}
+	tests := []struct {
+		t    *types.Type
+		want []string
+	}{
+		{t: timeType, want: []string{"swagger:type string", "swagger:strfmt date-time", "example: 2020-01-02T03:04:05.000000Z"}},
+		{t: &types.Type{Kind: types.Pointer, Elem: ipType}, want: []string{"swagger:type string", "swagger:strfmt ipv4"}},
+		{t: &types.Type{Name: types.Name{Name: "string"}, Kind: types.Builtin}, want: nil},
+	}
+	for _, tt := range tests {
+		if got := overrides.CommentLines(tt.t); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("CommentLines(%s) = %v, want %v", tt.t, got, tt.want)
+		}
+	}
+
+	if err := ioutil.WriteFile(file, []byte("time.Time: {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadTypeOverrides(file); err == nil {
+		t.Errorf("empty override should be invalid")
+	}
+}
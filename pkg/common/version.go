@@ -0,0 +1,68 @@
+package common
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+)
+
+const (
+	// TagAPIVersion limits type, method or function to api versions, e.g.:
+	// +onecloud:swagger-gen-version=v2
+	// +onecloud:swagger-gen-version=v1,v2
+	// untagged ones are in all versions
+	TagAPIVersion = "onecloud:swagger-gen-version"
+
+	// DefaultAPIVersion is the version of unversioned endpoints
+	DefaultAPIVersion = "v1"
+)
+
+var apiVersionRegexp = regexp.MustCompile(`^v[0-9]+$`)
+
+var _ = RegisterAnnotations(Annotation{
+	Name:        TagAPIVersion,
+	Generator:   "swagger-gen",
+	Syntax:      "<version>[,<version>...]",
+	Targets:     []string{TargetType, TargetMethod, TargetFunction},
+	Description: "limit declaration to api versions like v1, v2, untagged ones are in all versions",
+})
+
+// ValidateAPIVersion checks version is like v1, v2
+func ValidateAPIVersion(version string) error {
+	if !apiVersionRegexp.MatchString(version) {
+		return fmt.Errorf("invalid api version %q, should be like v1, v2", version)
+	}
+	return nil
+}
+
+// APIVersions returns versions of TagAPIVersion in comments, nil if not tagged
+func APIVersions(comments []string) []string {
+	tags := ParseTags(comments)
+	if len(tags.Values(TagAPIVersion)) == 0 {
+		return nil
+	}
+	return tags.List(TagAPIVersion)
+}
+
+// InAPIVersion returns true if comments are not tagged by TagAPIVersion or tagged with version
+func InAPIVersion(comments []string, version string) bool {
+	versions := APIVersions(comments)
+	if len(versions) == 0 {
+		return true
+	}
+	for _, v := range versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// VersionedPackagePath returns output package path of version, e.g. pkg/apis/compute/v2,
+// path of DefaultAPIVersion is not changed
+func VersionedPackagePath(pkgPath, version string) string {
+	if version == "" || version == DefaultAPIVersion {
+		return pkgPath
+	}
+	return path.Join(pkgPath, version)
+}
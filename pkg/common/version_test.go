@@ -0,0 +1,62 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAPIVersions(t *testing.T) {
+	tests := []struct {
+		name     string
+		comments []string
+		want     []string
+		inV1     bool
+		inV2     bool
+	}{
+		{
+			name:     "untagged",
+			comments: []string{"SGuest is a virtual machine"},
+			inV1:     true,
+			inV2:     true,
+		},
+		{
+			name:     "v2 only",
+			comments: []string{"+onecloud:swagger-gen-version=v2"},
+			want:     []string{"v2"},
+			inV2:     true,
+		},
+		{
+			name:     "both versions",
+			comments: []string{"+onecloud:swagger-gen-version=v1, v2"},
+			want:     []string{"v1", "v2"},
+			inV1:     true,
+			inV2:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := APIVersions(tt.comments); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("APIVersions() = %v, want %v", got, tt.want)
+			}
+			if got := InAPIVersion(tt.comments, "v1"); got != tt.inV1 {
+				t.Errorf("InAPIVersion(v1) = %v, want %v", got, tt.inV1)
+			}
+			if got := InAPIVersion(tt.comments, "v2"); got != tt.inV2 {
+				t.Errorf("InAPIVersion(v2) = %v, want %v", got, tt.inV2)
+			}
+		})
+	}
+}
+
+func TestVersionedPackagePath(t *testing.T) {
+	pkg := "yunion.io/x/onecloud/pkg/apis/compute"
+	if got := VersionedPackagePath(pkg, DefaultAPIVersion); got != pkg {
+		t.Errorf("VersionedPackagePath(v1) = %s, want %s", got, pkg)
+	}
+	if got, want := VersionedPackagePath(pkg, "v2"), pkg+"/v2"; got != want {
+		t.Errorf("VersionedPackagePath(v2) = %s, want %s", got, want)
+	}
+	if err := ValidateAPIVersion("2"); err == nil {
+		t.Errorf("ValidateAPIVersion(2) should fail")
+	}
+}
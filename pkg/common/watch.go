@@ -0,0 +1,170 @@
+package common
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"k8s.io/klog/v2"
+)
+
+// defaultWatchInterval is the polling interval of source files if not set
+const defaultWatchInterval = time.Second
+
+// WatchArgs are the arguments to regenerate outputs when input packages change
+type WatchArgs struct {
+	// Enabled keeps running after generation and regenerates on changes of input packages
+	Enabled bool
+	// Interval is the polling interval of go files of input packages
+	Interval time.Duration
+}
+
+// AddFlags add watch flags to fs
+func (wa *WatchArgs) AddFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&wa.Enabled, "watch", wa.Enabled, "Keep running and regenerate outputs of input packages when their go files change")
+	fs.DurationVar(&wa.Interval, "watch-interval", wa.Interval, fmt.Sprintf("Polling interval of go files with --watch, %s if not set", defaultWatchInterval))
+}
+
+func (wa *WatchArgs) interval() time.Duration {
+	if wa.Interval <= 0 {
+		return defaultWatchInterval
+	}
+	return wa.Interval
+}
+
+// PackageDirs returns source directories of packages matched by patterns in modules of la, keyed by import path
+func PackageDirs(patterns []string, la *LoaderArgs) (map[string]string, error) {
+	modules, err := LoadModules(la.Modules)
+	if err != nil {
+		return nil, err
+	}
+	ret := make(map[string]string)
+	for _, group := range groupInputs(patterns, modules) {
+		pkgs, err := loadPackages(group.dir, group.patterns, la.Tags)
+		if err != nil {
+			return nil, err
+		}
+		for _, pkg := range pkgs {
+			if len(pkg.GoFiles) == 0 {
+				continue
+			}
+			ret[pkg.PkgPath] = filepath.Dir(pkg.GoFiles[0])
+		}
+	}
+	return ret, nil
+}
+
+// sourceSnapshot is modification time of go files in a directory, keyed by file name
+type sourceSnapshot map[string]time.Time
+
+// takeSnapshot returns snapshot of non test go files in dir
+func takeSnapshot(dir string) (sourceSnapshot, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	ret := make(sourceSnapshot)
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		ret[name] = info.ModTime()
+	}
+	return ret, nil
+}
+
+// equal returns true if no file of s is added, removed or modified in o
+func (s sourceSnapshot) equal(o sourceSnapshot) bool {
+	if len(s) != len(o) {
+		return false
+	}
+	for name, mtime := range s {
+		if omtime, ok := o[name]; !ok || !omtime.Equal(mtime) {
+			return false
+		}
+	}
+	return true
+}
+
+// sourceWatcher detects changes of go files of packages in dirs keyed by import path
+type sourceWatcher struct {
+	dirs      map[string]string
+	snapshots map[string]sourceSnapshot
+}
+
+func newSourceWatcher(dirs map[string]string) *sourceWatcher {
+	w := &sourceWatcher{dirs: dirs, snapshots: make(map[string]sourceSnapshot)}
+	for pkg, dir := range dirs {
+		s, err := takeSnapshot(dir)
+		if err != nil {
+			klog.Errorf("snapshot package %s: %v", pkg, err)
+		}
+		w.snapshots[pkg] = s
+	}
+	return w
+}
+
+// changed returns sorted import paths of packages changed since the last call
+func (w *sourceWatcher) changed() []string {
+	changed := make([]string, 0)
+	for pkg, dir := range w.dirs {
+		s, err := takeSnapshot(dir)
+		if err != nil {
+			klog.V(2).Infof("snapshot package %s: %v", pkg, err)
+			continue
+		}
+		if !s.equal(w.snapshots[pkg]) {
+			w.snapshots[pkg] = s
+			changed = append(changed, pkg)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// Watch polls go files of packages in dirs keyed by import path, generate is called with
+// sorted import paths of changed packages, it never returns and generation errors are logged
+func (wa *WatchArgs) Watch(dirs map[string]string, generate func(changed []string) error) {
+	w := newSourceWatcher(dirs)
+	klog.Infof("watching %d packages for changes", len(dirs))
+	for {
+		time.Sleep(wa.interval())
+		changed := w.changed()
+		if len(changed) == 0 {
+			continue
+		}
+		klog.Infof("packages %v changed, regenerating", changed)
+		if err := generate(changed); err != nil {
+			klog.Errorf("regenerate %v: %v", changed, err)
+		}
+	}
+}
+
+// PartialRun returns copy of la for a run generating part of the inputs, e.g. the changed ones with --watch.
+// Outputs of other inputs are not produced by the run, so --prune and --manifest are disabled,
+// which would remove their generated files and drop them from the manifest otherwise, like swagger-gen --only.
+func (la *LoaderArgs) PartialRun() *LoaderArgs {
+	partial := *la
+	partial.Prune = false
+	partial.Manifest = ""
+	return &partial
+}
+
+// RegenerateChanged returns the Watch callback running generate for the changed input packages only,
+// with loader arguments of PartialRun
+func RegenerateChanged(g *GeneratorArgs, la *LoaderArgs, generate func(*GeneratorArgs, *LoaderArgs) error) func(changed []string) error {
+	partial := la.PartialRun()
+	if la.Prune || la.Manifest != "" {
+		klog.Warningf("--prune and --manifest are disabled when regenerating changed packages")
+	}
+	return func(changed []string) error {
+		args := *g
+		args.InputDirs = changed
+		return generate(&args, partial)
+	}
+}
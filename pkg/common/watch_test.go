@@ -0,0 +1,131 @@
+package common
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_sourceSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	write := func(name string) {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte("package models\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("guests.go")
+	write("guests_test.go")
+	write("README.md")
+	s0, err := takeSnapshot(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s0) != 1 {
+		t.Fatalf("takeSnapshot() = %v, want only guests.go", s0)
+	}
+
+	write("guests_test.go")
+	if s, _ := takeSnapshot(dir); !s.equal(s0) {
+		t.Errorf("test file change should be ignored")
+	}
+
+	write("disks.go")
+	s1, _ := takeSnapshot(dir)
+	if s1.equal(s0) {
+		t.Errorf("added file should be a change")
+	}
+
+	mtime := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(dir, "disks.go"), mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := takeSnapshot(dir); s.equal(s1) {
+		t.Errorf("modified file should be a change")
+	}
+}
+
+func TestRegenerateChanged(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	g := NewGeneratorArgs()
+	g.OutputBase = filepath.Join(dir, "out")
+	g.OutputFileBaseName = "zz_generated.api"
+	la := &LoaderArgs{Prune: true, Manifest: filepath.Join(dir, "manifest.json")}
+	pkgs := []string{"example.com/svc/pkg/compute", "example.com/svc/pkg/image"}
+	dirs := make(map[string]string)
+	for _, pkg := range pkgs {
+		dirs[pkg] = filepath.Join(dir, "src", pkg)
+		if err := os.MkdirAll(dirs[pkg], 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dirs[pkg], "models.go"), []byte("package models\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	output := func(pkg string) string {
+		return filepath.Join(g.OutputBase, pkg, "apis", g.OutputFileBaseName+".go")
+	}
+	// generates an output of each input and finishes the run like Execute
+	generate := func(g *GeneratorArgs, la *LoaderArgs) error {
+		produced := make(producedFiles)
+		for _, pkg := range g.InputDirs {
+			file := output(pkg)
+			if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+				return err
+			}
+			content := GeneratedMarker(g.GeneratedByCommentTemplate) + "\n\npackage apis\n"
+			if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+				return err
+			}
+			produced.add(filepath.Dir(file), filepath.Base(file))
+		}
+		return finishRun(g, la, produced, produced.paths())
+	}
+	full := *g
+	full.InputDirs = pkgs
+	if err := generate(&full, la); err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := ioutil.ReadFile(la.Manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := newSourceWatcher(dirs)
+	regenerate := RegenerateChanged(&full, la, generate)
+	for i, pkg := range pkgs {
+		mtime := time.Now().Add(time.Duration(i+1) * time.Minute)
+		if err := os.Chtimes(filepath.Join(dirs[pkg], "models.go"), mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+		changed := w.changed()
+		if want := []string{pkg}; !reflect.DeepEqual(changed, want) {
+			t.Fatalf("iteration %d changed = %v, want %v", i, changed, want)
+		}
+		if err := regenerate(changed); err != nil {
+			t.Fatalf("iteration %d: %v", i, err)
+		}
+		for _, p := range pkgs {
+			if _, err := os.Stat(output(p)); err != nil {
+				t.Errorf("iteration %d: output of %s: %v", i, p, err)
+			}
+		}
+		if got, _ := ioutil.ReadFile(la.Manifest); !bytes.Equal(got, manifest) {
+			t.Errorf("iteration %d rewrote manifest:\n%s", i, got)
+		}
+	}
+	if !reflect.DeepEqual(full.InputDirs, pkgs) || !la.Prune {
+		t.Errorf("arguments of the full run are changed: inputs %v, prune %v", full.InputDirs, la.Prune)
+	}
+}
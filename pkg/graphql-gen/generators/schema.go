@@ -0,0 +1,347 @@
+package generators
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"k8s.io/gengo/v2/types"
+
+	"yunion.io/x/pkg/util/sets"
+	"yunion.io/x/pkg/utils"
+
+	"yunion.io/x/code-generator/pkg/common"
+	"yunion.io/x/code-generator/pkg/swaggergen"
+)
+
+const (
+	// scalarJSON is the free-form object scalar of maps, interfaces and unknown types
+	scalarJSON = "JSON"
+	// scalarTime is the scalar of time.Time in onecloud ISO format
+	scalarTime = "Time"
+)
+
+var invalidNameChars = regexp.MustCompile(`[^_0-9A-Za-z]`)
+
+// graphqlName converts s to a valid GraphQL name
+func graphqlName(s string) string {
+	s = invalidNameChars.ReplaceAllString(s, "_")
+	if s == "" || unicode.IsDigit(rune(s[0])) {
+		s = "_" + s
+	}
+	return s
+}
+
+// builtinScalars maps go builtin types to GraphQL scalars
+var builtinScalars = map[string]string{
+	"string":  "String",
+	"bool":    "Boolean",
+	"int":     "Int",
+	"int8":    "Int",
+	"int16":   "Int",
+	"int32":   "Int",
+	"int64":   "Int",
+	"uint":    "Int",
+	"uint8":   "Int",
+	"uint16":  "Int",
+	"uint32":  "Int",
+	"uint64":  "Int",
+	"float32": "Float",
+	"float64": "Float",
+}
+
+type schemaField struct {
+	name string
+	args []string
+	typ  string
+}
+
+func (f schemaField) String() string {
+	if len(f.args) == 0 {
+		return fmt.Sprintf("%s: %s", f.name, f.typ)
+	}
+	return fmt.Sprintf("%s(%s): %s", f.name, strings.Join(f.args, ", "), f.typ)
+}
+
+// objectDef is a declared GraphQL object or input type
+type objectDef struct {
+	name   string
+	input  bool
+	source string
+	fields []schemaField
+}
+
+// Schema collects resource types and operations of onecloud models and renders them as GraphQL SDL,
+// list and get operations are queries, perform actions are mutations
+type Schema struct {
+	universe types.Universe
+	// objects are declared types keyed by graphql name
+	objects map[string]*objectDef
+	// outputNames and inputNames map go type to graphql name of object and input type
+	outputNames map[string]string
+	inputNames  map[string]string
+	queries     map[string]schemaField
+	mutations   map[string]schemaField
+}
+
+// NewSchema returns schema resolving operation types from universe
+func NewSchema(u types.Universe) *Schema {
+	return &Schema{
+		universe:    u,
+		objects:     make(map[string]*objectDef),
+		outputNames: make(map[string]string),
+		inputNames:  make(map[string]string),
+		queries:     make(map[string]schemaField),
+		mutations:   make(map[string]schemaField),
+	}
+}
+
+// lookupType returns type of operation type name like yunion.io/x/onecloud/pkg/apis/compute.ServerDetails,
+// list is true if name is prefixed by []
+func (s *Schema) lookupType(name string) (t *types.Type, list bool) {
+	if strings.HasPrefix(name, "[]") {
+		name, list = name[2:], true
+	}
+	idx := strings.LastIndex(name, ".")
+	if idx < 0 {
+		return nil, list
+	}
+	pkg, ok := s.universe[name[:idx]]
+	if !ok {
+		return nil, list
+	}
+	return pkg.Types[name[idx+1:]], list
+}
+
+// AddOperations adds list and get operations as queries and perform actions as mutations,
+// other operations are skipped. Operations are added by path, method and operation id, so types
+// whose names collide are named the same regardless of the order of ops
+func (s *Schema) AddOperations(ops []swaggergen.Operation) {
+	ops = append([]swaggergen.Operation(nil), ops...)
+	sort.Slice(ops, func(i, j int) bool {
+		if ops[i].Path != ops[j].Path {
+			return ops[i].Path < ops[j].Path
+		}
+		if ops[i].Method != ops[j].Method {
+			return ops[i].Method < ops[j].Method
+		}
+		return ops[i].OperationId < ops[j].OperationId
+	})
+	for _, op := range ops {
+		if len(op.Tags) == 0 {
+			continue
+		}
+		keyword := op.Tags[0]
+		segs := strings.Split(strings.Trim(op.Path, "/"), "/")
+		input, _ := s.lookupType(op.Input)
+		output, isList := s.lookupType(op.Output)
+		switch {
+		case op.Method == "GET" && isList && segs[len(segs)-1] != "{id}":
+			f := schemaField{name: graphqlName(segs[len(segs)-1]), typ: fmt.Sprintf("[%s]", s.outputRef(output))}
+			if input != nil && op.InputIn == "query" {
+				f.args = append(f.args, fmt.Sprintf("query: %s", s.inputRef(input)))
+			}
+			s.queries[f.name] = f
+		case op.Method == "GET" && segs[len(segs)-1] == "{id}":
+			s.queries[graphqlName(keyword)] = schemaField{
+				name: graphqlName(keyword),
+				args: []string{"id: ID!"},
+				typ:  s.outputRef(output),
+			}
+		case op.Method == "POST" && len(segs) >= 3 && segs[len(segs)-2] == "{id}":
+			f := schemaField{
+				name: mutationName(op.OperationId),
+				args: []string{"id: ID!"},
+				typ:  s.outputRef(output),
+			}
+			if input != nil && op.InputIn == "body" {
+				f.args = append(f.args, fmt.Sprintf("input: %s", s.inputRef(input)))
+			}
+			s.mutations[f.name] = f
+		}
+	}
+}
+
+// mutationName converts operation id like server_PerformStart to serverPerformStart
+func mutationName(opId string) string {
+	parts := strings.FieldsFunc(graphqlName(opId), func(r rune) bool { return r == '_' })
+	for i, part := range parts {
+		if i == 0 {
+			parts[i] = strings.ToLower(part[:1]) + part[1:]
+		} else {
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+func (s *Schema) outputRef(t *types.Type) string {
+	return s.typeRef(t, false)
+}
+
+func (s *Schema) inputRef(t *types.Type) string {
+	return s.typeRef(t, true)
+}
+
+// typeRef returns GraphQL type of go type, structs are declared as object or input types
+func (s *Schema) typeRef(t *types.Type, input bool) string {
+	if t == nil {
+		return scalarJSON
+	}
+	switch t.Kind {
+	case types.Pointer:
+		return s.typeRef(t.Elem, input)
+	case types.Slice, types.Array:
+		if t.Elem.Kind == types.Builtin && t.Elem.Name.Name == "byte" {
+			return "String"
+		}
+		return fmt.Sprintf("[%s]", s.typeRef(t.Elem, input))
+	case types.Builtin:
+		if scalar, ok := builtinScalars[t.Name.Name]; ok {
+			return scalar
+		}
+	case types.Alias:
+		return s.typeRef(t.Underlying, input)
+	case types.Struct:
+		if t.Name.Package == "time" && t.Name.Name == "Time" {
+			return scalarTime
+		}
+		if len(structFields(t)) != 0 {
+			return s.declare(t, input)
+		}
+	}
+	return scalarJSON
+}
+
+// typeName returns graphql name of struct, qualified by package if names collide
+func (s *Schema) typeName(t *types.Type, input bool) string {
+	name := t.Name.Name
+	if in, ok := common.ParseInstantiatedType(t); ok {
+		name = in.GoName()
+	}
+	name = graphqlName(name)
+	if input {
+		if _, ok := s.outputNames[t.String()]; ok {
+			name += "Input"
+		}
+	}
+	if exist, ok := s.objects[name]; ok && (exist.source != t.String() || exist.input != input) {
+		name = graphqlName(strings.Title(path.Base(t.Name.Package))) + name
+	}
+	return name
+}
+
+// declare declares struct t as object or input type, the graphql name is returned
+func (s *Schema) declare(t *types.Type, input bool) string {
+	names := s.outputNames
+	if input {
+		names = s.inputNames
+	}
+	if name, ok := names[t.String()]; ok {
+		return name
+	}
+	def := &objectDef{name: s.typeName(t, input), input: input, source: t.String()}
+	names[t.String()] = def.name
+	s.objects[def.name] = def
+	for _, m := range structFields(t) {
+		def.fields = append(def.fields, schemaField{name: graphqlName(jsonName(m)), typ: s.typeRef(m.Type, input)})
+	}
+	return def.name
+}
+
+func jsonName(m types.Member) string {
+	name := strings.Split(reflect.StructTag(m.Tags).Get("json"), ",")[0]
+	if name == "" {
+		name = utils.CamelSplit(m.Name, "_")
+	}
+	return name
+}
+
+// structFields returns serialized members of t, embedded structs are flattened and shallower fields win
+func structFields(t *types.Type) []types.Member {
+	ret := make([]types.Member, 0)
+	names := sets.NewString()
+	embeds := make([]*types.Type, 0)
+	for _, m := range t.Members {
+		if et, _, ok := common.EmbeddedStruct(m); ok {
+			embeds = append(embeds, et)
+			continue
+		}
+		if m.Name == "" || !unicode.IsUpper(rune(m.Name[0])) {
+			continue
+		}
+		name := jsonName(m)
+		if name == "-" || names.Has(name) {
+			continue
+		}
+		names.Insert(name)
+		ret = append(ret, m)
+	}
+	for _, et := range embeds {
+		for _, m := range structFields(et) {
+			if name := jsonName(m); !names.Has(name) {
+				names.Insert(name)
+				ret = append(ret, m)
+			}
+		}
+	}
+	return ret
+}
+
+func renderFields(buf *strings.Builder, kind, name string, fields []schemaField) {
+	fmt.Fprintf(buf, "%s %s {\n", kind, name)
+	for _, f := range fields {
+		fmt.Fprintf(buf, "  %s\n", f)
+	}
+	buf.WriteString("}\n\n")
+}
+
+func sortedFields(fields map[string]schemaField) []schemaField {
+	ret := make([]schemaField, 0, len(fields))
+	for _, name := range sortedKeys(fields) {
+		ret = append(ret, fields[name])
+	}
+	return ret
+}
+
+func sortedKeys(fields map[string]schemaField) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Render returns the GraphQL SDL of collected operations and types
+func (s *Schema) Render() string {
+	buf := &strings.Builder{}
+	buf.WriteString("# Code generated by graphql-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(buf, "# %s is a free-form json object\nscalar %s\n\n", scalarJSON, scalarJSON)
+	fmt.Fprintf(buf, "# %s is a date-time string like 2006-01-02T15:04:05.000000Z\nscalar %s\n\n", scalarTime, scalarTime)
+	if len(s.queries) != 0 {
+		renderFields(buf, "type", "Query", sortedFields(s.queries))
+	}
+	if len(s.mutations) != 0 {
+		renderFields(buf, "type", "Mutation", sortedFields(s.mutations))
+	}
+	names := make([]string, 0, len(s.objects))
+	for name := range s.objects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		def := s.objects[name]
+		kind := "type"
+		if def.input {
+			kind = "input"
+		}
+		fmt.Fprintf(buf, "# %s is generated from %s\n", def.name, def.source)
+		renderFields(buf, kind, def.name, def.fields)
+	}
+	return buf.String()
+}
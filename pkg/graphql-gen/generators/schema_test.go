@@ -0,0 +1,108 @@
+package generators
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/gengo/v2/types"
+
+	"yunion.io/x/code-generator/pkg/swaggergen"
+)
+
+func TestSchema(t *testing.T) {
+	const apisPkg = "yunion.io/x/onecloud/pkg/apis/compute"
+	u := types.Universe{}
+	str := types.String
+	base := u.Type(types.Name{Package: apisPkg, Name: "StandaloneResourceDetails"})
+	base.Kind = types.Struct
+	base.Members = []types.Member{
+		{Name: "Id", Type: str},
+		{Name: "Name", Type: str},
+	}
+	details := u.Type(types.Name{Package: apisPkg, Name: "ServerDetails"})
+	details.Kind = types.Struct
+	details.Members = []types.Member{
+		{Embedded: true, Type: base},
+		{Name: "Name", Type: str, Tags: `json:"name"`},
+		{Name: "CpuCount", Type: types.Int, Tags: `json:"vcpu_count"`},
+		{Name: "Secret", Type: str, Tags: `json:"-"`},
+		{Name: "Metadata", Type: &types.Type{Kind: types.Map, Key: str, Elem: str}},
+	}
+	listInput := u.Type(types.Name{Package: apisPkg, Name: "ServerListInput"})
+	listInput.Kind = types.Struct
+	listInput.Members = []types.Member{
+		{Name: "Zone", Type: str},
+		{Name: "Ids", Type: &types.Type{Kind: types.Slice, Elem: str}},
+	}
+	startInput := u.Type(types.Name{Package: apisPkg, Name: "GuestStartInput"})
+	startInput.Kind = types.Struct
+	startInput.Members = []types.Member{
+		{Name: "QemuVersion", Type: str},
+	}
+
+	s := NewSchema(u)
+	s.AddOperations([]swaggergen.Operation{
+		{Method: "GET", Path: "/servers", OperationId: "server_List", Tags: []string{"server"},
+			Input: apisPkg + ".ServerListInput", InputIn: "query", Output: "[]" + apisPkg + ".ServerDetails"},
+		{Method: "GET", Path: "/servers/{id}", OperationId: "server_GetExtraDetails", Tags: []string{"server"},
+			Output: apisPkg + ".ServerDetails"},
+		{Method: "POST", Path: "/servers/{id}/start", OperationId: "server_PerformStart", Tags: []string{"server"},
+			Input: apisPkg + ".GuestStartInput", InputIn: "body"},
+		{Method: "DELETE", Path: "/servers/{id}", OperationId: "server_Delete", Tags: []string{"server"}},
+	})
+	got := s.Render()
+	for _, want := range []string{
+		"type Query {\n  server(id: ID!): ServerDetails\n  servers(query: ServerListInput): [ServerDetails]\n}\n",
+		"type Mutation {\n  serverPerformStart(id: ID!, input: GuestStartInput): JSON\n}\n",
+		"type ServerDetails {\n  name: String\n  vcpu_count: Int\n  metadata: JSON\n  id: String\n}\n",
+		"input ServerListInput {\n  zone: String\n  ids: [String]\n}\n",
+		"input GuestStartInput {\n  qemu_version: String\n}\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Render() = %s\nshould contain %q", got, want)
+		}
+	}
+	if strings.Contains(got, "server_Delete") || strings.Contains(got, "secret") {
+		t.Errorf("Render() = %s\nshould skip delete operation and json ignored field", got)
+	}
+}
+
+func Test_schemaInputOfOutputType(t *testing.T) {
+	const apisPkg = "yunion.io/x/onecloud/pkg/apis/compute"
+	u := types.Universe{}
+	cfg := u.Type(types.Name{Package: apisPkg, Name: "ServerConfig"})
+	cfg.Kind = types.Struct
+	cfg.Members = []types.Member{{Name: "Hypervisor", Type: types.String}}
+	s := NewSchema(u)
+	if got := s.outputRef(cfg); got != "ServerConfig" {
+		t.Errorf("outputRef() = %s, want ServerConfig", got)
+	}
+	if got := s.inputRef(cfg); got != "ServerConfigInput" {
+		t.Errorf("inputRef() = %s, want ServerConfigInput", got)
+	}
+}
+
+func Test_schemaOperationsOrder(t *testing.T) {
+	u := types.Universe{}
+	newDetails := func(pkg string) *types.Type {
+		ret := u.Type(types.Name{Package: pkg, Name: "ResourceDetails"})
+		ret.Kind = types.Struct
+		ret.Members = []types.Member{{Name: "Id", Type: types.String}}
+		return ret
+	}
+	compute, image := newDetails("yunion.io/x/onecloud/pkg/apis/compute"), newDetails("yunion.io/x/onecloud/pkg/apis/image")
+	ops := []swaggergen.Operation{
+		{Method: "GET", Path: "/servers/{id}", OperationId: "server_GetExtraDetails", Tags: []string{"server"}, Output: compute.String()},
+		{Method: "GET", Path: "/images/{id}", OperationId: "image_GetExtraDetails", Tags: []string{"image"}, Output: image.String()},
+	}
+	s := NewSchema(u)
+	s.AddOperations(ops)
+	reversed := NewSchema(u)
+	reversed.AddOperations([]swaggergen.Operation{ops[1], ops[0]})
+	if got, want := reversed.Render(), s.Render(); got != want {
+		t.Errorf("Render() of reversed operations = %s, want %s", got, want)
+	}
+	if got := s.outputNames[compute.String()]; got != "ComputeResourceDetails" {
+		t.Errorf("name of %s = %s, want ComputeResourceDetails", compute, got)
+	}
+}
@@ -0,0 +1,145 @@
+package generators
+
+import (
+	"bytes"
+	"testing"
+
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/namer"
+	"k8s.io/gengo/v2/types"
+
+	"yunion.io/x/pkg/util/sets"
+
+	"yunion.io/x/code-generator/pkg/common/testdata/faketypes"
+)
+
+func Test_generatorAliasType(t *testing.T) {
+	const srcPkg = "yunion.io/x/onecloud/pkg/compute/models"
+	str := types.String
+	guest := &types.Type{Name: types.Name{Package: srcPkg, Name: "SGuest"}, Kind: types.Struct}
+	netAddr := &types.Type{Name: types.Name{Package: "yunion.io/x/pkg/util/netutils", Name: "IPV4Addr"}, Kind: types.Alias}
+	tests := []struct {
+		name       string
+		underlying *types.Type
+		want       string
+	}{
+		{
+			name:       "Metadata",
+			underlying: &types.Type{Kind: types.Map, Key: str, Elem: str},
+			want:       "type Metadata map[string]string\n",
+		},
+		{
+			name:       "GuestRef",
+			underlying: &types.Type{Kind: types.Pointer, Elem: guest},
+			want:       "type GuestRef *SGuest\n",
+		},
+		{
+			name:       "GuestMap",
+			underlying: &types.Type{Kind: types.Map, Key: str, Elem: &types.Type{Kind: types.Slice, Elem: &types.Type{Kind: types.Pointer, Elem: guest}}},
+			want:       "type GuestMap map[string][]*SGuest\n",
+		},
+		{
+			name:       "AddrFilter",
+			underlying: &types.Type{Kind: types.Func, Signature: &types.Signature{Parameters: faketypes.ParamResults(netAddr, &types.Type{Kind: types.Slice, Elem: str}), Results: faketypes.ParamResults(types.Bool), Variadic: true}},
+			want:       "type AddrFilter func(netutils.IPV4Addr, ...string) bool\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &apiGen{
+				sourcePackage:      srcPkg,
+				imports:            generator.NewImportTracker(),
+				needImportPackages: sets.NewString(),
+				apisPkg:            "yunion.io/x/onecloud/pkg/apis",
+			}
+			buf := &bytes.Buffer{}
+			c := &generator.Context{Namers: namer.NameSystems{"public": namer.NewPublicNamer(0)}}
+			sw := generator.NewSnippetWriter(buf, c, "$", "$")
+			g.generatorAliasType(&types.Type{Name: types.Name{Package: srcPkg, Name: tt.name}, Kind: types.Alias, Underlying: tt.underlying}, sw)
+			if err := sw.Error(); err != nil {
+				t.Fatalf("snippet error: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("generatorAliasType() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func Test_addDependTypesCycle(t *testing.T) {
+	const srcPkg = "yunion.io/x/onecloud/pkg/compute/models"
+	node := &types.Type{Name: types.Name{Package: srcPkg, Name: "SNode"}, Kind: types.Struct}
+	edge := &types.Type{Name: types.Name{Package: srcPkg, Name: "SEdge"}, Kind: types.Struct}
+	node.Members = []types.Member{
+		{Name: "Parent", Type: &types.Type{Kind: types.Pointer, Elem: node}},
+		{Name: "Edge", Type: edge},
+	}
+	edge.Members = []types.Member{
+		{Name: "To", Type: &types.Type{Kind: types.Pointer, Elem: node}},
+	}
+	g := &apiGen{sourcePackage: srcPkg, dependWalked: sets.NewString()}
+	out, dependOut := sets.NewString(node.String()), sets.NewString()
+	g.addDependTypes(node, out, dependOut)
+	g.addDependTypes(edge, out, dependOut)
+	if want := sets.NewString(node.String(), edge.String()); !out.Equal(want) {
+		t.Errorf("addDependTypes() out = %v, want %v", out.List(), want.List())
+	}
+}
+
+func Test_keptAliasExpr(t *testing.T) {
+	const apisPkg = "yunion.io/x/onecloud/pkg/apis"
+	status := &types.Type{Name: types.Name{Package: apisPkg + "/compute", Name: "TGuestStatus"}, Kind: types.Alias, Underlying: types.String}
+	tests := []struct {
+		name   string
+		keep   bool
+		outPkg string
+		t      *types.Type
+		want   string
+		wantOk bool
+	}{
+		{
+			name: "not kept by default",
+			t:    status,
+		},
+		{
+			name:   "apis alias",
+			keep:   true,
+			outPkg: apisPkg + "/image",
+			t:      status,
+			want:   "compute.TGuestStatus",
+			wantOk: true,
+		},
+		{
+			name:   "alias of output package",
+			keep:   true,
+			outPkg: apisPkg + "/compute",
+			t:      status,
+			want:   "TGuestStatus",
+			wantOk: true,
+		},
+		{
+			name: "alias out of apis",
+			keep: true,
+			t:    &types.Type{Name: types.Name{Package: "yunion.io/x/pkg/tristate", Name: "TriState"}, Kind: types.Alias, Underlying: types.String},
+		},
+		{
+			name: "alias of struct",
+			keep: true,
+			t: &types.Type{Name: types.Name{Package: apisPkg + "/compute", Name: "ServerRef"}, Kind: types.Alias,
+				Underlying: &types.Type{Name: types.Name{Package: apisPkg + "/compute", Name: "ServerDetails"}, Kind: types.Struct}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &apiGen{
+				imports:    generator.NewImportTracker(),
+				apisPkg:    apisPkg,
+				customArgs: &CustomArgs{KeepAPIsAliases: tt.keep, outputPackage: tt.outPkg},
+			}
+			got, ok := g.keptAliasExpr(tt.t)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("keptAliasExpr() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
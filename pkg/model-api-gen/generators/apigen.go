@@ -2,17 +2,16 @@ package generators
 
 import (
 	"fmt"
+	"go/token"
 	"io"
 	"path/filepath"
 	"reflect"
 	"strings"
 
-	"golang.org/x/tools/imports"
-	"k8s.io/gengo/args"
-	"k8s.io/gengo/generator"
-	"k8s.io/gengo/namer"
-	"k8s.io/gengo/types"
-	"k8s.io/klog"
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/namer"
+	"k8s.io/gengo/v2/types"
+	"k8s.io/klog/v2"
 
 	"yunion.io/x/pkg/util/sets"
 	"yunion.io/x/pkg/utils"
@@ -22,24 +21,57 @@ import (
 
 const (
 	tagName = "onecloud:model-api-gen"
+	// tagGetterName generate GetXxx accessor method for the tagged member
+	tagGetterName = "onecloud:model-api-gen-getter"
+	// tagRenameName names the generated type of source struct, e.g.:
+	// +onecloud:model-api-gen-name=ServerDetails
+	tagRenameName = "onecloud:model-api-gen-name"
 	//tagPkgName           = "onecloud:model-api-gen-pkg"
 	SModelBase           = "SModelBase"
 	CloudCommonDBPackage = "yunion.io/x/onecloud/pkg/cloudcommon/db"
 	CloudProviderPackage = "yunion.io/x/onecloud/pkg/cloudprovider"
 )
 
+const generatorName = "model-api-gen"
+
+var _ = common.RegisterAnnotations(
+	common.Annotation{
+		Name:        tagName,
+		Generator:   generatorName,
+		Targets:     []string{common.TargetModel, common.TargetType},
+		Description: "generate api type of the struct",
+	},
+	common.Annotation{
+		Name:        tagGetterName,
+		Generator:   generatorName,
+		Targets:     []string{common.TargetField},
+		Description: "generate GetXxx accessor method of the member",
+	},
+	common.Annotation{
+		Name:        tagRenameName,
+		Generator:   generatorName,
+		Syntax:      "<TypeName>",
+		Targets:     []string{common.TargetModel, common.TargetType},
+		Description: "name of the generated type",
+	},
+)
+
 func extractTag(comments []string) []string {
-	return types.ExtractCommentTags("+", comments)[tagName]
+	return common.ParseTags(comments).Values(tagName)
 }
 
 func checkTag(comments []string, require ...string) bool {
-	vals := types.ExtractCommentTags("+", comments)[tagName]
+	vals := common.ParseTags(comments).Values(tagName)
 	if len(require) == 0 {
 		return len(vals) == 1 && vals[0] == ""
 	}
 	return reflect.DeepEqual(vals, require)
 }
 
+func checkTagByName(comments []string, name string) bool {
+	return common.ParseTags(comments).Has(name)
+}
+
 /*func extractPkgTag(comments []string) []string {
 	return types.ExtractCommentTags("+", comments)[tagPkgName]
 }*/
@@ -73,15 +105,41 @@ func DefaultNameSystem() string {
 }
 
 // Packages makes the api-gen package definition.
-func Packages(ctx *generator.Context, arguments *args.GeneratorArgs) generator.Packages {
+func Packages(ctx *generator.Context, arguments *common.GeneratorArgs) []generator.Target {
 	boilerplate, err := arguments.LoadGoBoilerplate()
 	if err != nil {
 		klog.Fatalf("Failed loading boilerplate: %v", err)
 	}
 
 	inputs := sets.NewString(ctx.Inputs...)
-	packages := generator.Packages{}
+	packages := []generator.Target{}
+	customArgs := getCustomArgs(arguments)
+	overrides, err := common.LoadTypeOverrides(customArgs.TypeOverrides)
+	if err != nil {
+		klog.Fatalf("Invalid --type-overrides: %v", err)
+	}
+	customArgs.typeOverrides = overrides
+	timeFormats, err := parseTimeFormats(customArgs.TimeFormats)
+	if err != nil {
+		klog.Fatalf("Invalid --time-format: %v", err)
+	}
+	customArgs.timeFormats = timeFormats
+	customArgs.initialisms = common.NewInitialisms(customArgs.Initialisms)
+	if err := common.ValidateAPIVersion(customArgs.APIVersion); err != nil {
+		klog.Fatalf("Invalid --api-version: %v", err)
+	}
+	if err := validateTrimTypePrefix(customArgs.TrimTypePrefix); err != nil {
+		klog.Fatalf("Invalid --trim-type-prefix: %v", err)
+	}
+	outPkgPath := common.VersionedPackagePath(arguments.OutputPackagePath, customArgs.APIVersion)
+	customArgs.outputPackage = outPkgPath
+	customArgs.sourceTags = append([]string{arguments.GeneratedBuildTag}, customArgs.BuildTags...)
+	customArgs.sourceModules = make(map[string]string)
 	//header := append([]byte(fmt.Sprintf("// +build !%s\n\n", arguments.GeneratedBuildTag)), boilerplate...)
+	outPkgName := strings.Split(filepath.Base(arguments.OutputPackagePath), ".")[0]
+	if customArgs.WithMetadataFields {
+		packages = append(packages, NewResourceTagPackage(outPkgName, outPkgPath, boilerplate, arguments.OutputFileBaseName))
+	}
 
 	for i := range inputs {
 		pkg := ctx.Universe[i]
@@ -90,28 +148,28 @@ func Packages(ctx *generator.Context, arguments *args.GeneratorArgs) generator.P
 			continue
 		}
 		klog.Infof("Considering pkg %q", pkg.Path)
+		customArgs.sourceModules[pkg.Path] = common.ModulePath(pkg.Dir)
 		//pkgPath := pkg.Path
-		outPkgName := strings.Split(filepath.Base(arguments.OutputPackagePath), ".")[0]
 		packages = append(packages,
-			&generator.DefaultPackage{
-				PackageName: outPkgName,
-				PackagePath: arguments.OutputPackagePath,
-				HeaderText:  boilerplate,
-				GeneratorFunc: func(c *generator.Context) []generator.Generator {
+			common.NewSourcePackage(pkg.Path, &generator.SimpleTarget{
+				PkgName:       outPkgName,
+				PkgPath:       outPkgPath,
+				HeaderComment: boilerplate,
+				GeneratorsFunc: func(c *generator.Context) []generator.Generator {
 					return []generator.Generator{
 						// Always generate a "doc.go" file.
-						// generator.DefaultGen{OptionalName: "doc"},
+						// generator.GoGenerator{OutputFilename: "doc.go"},
 						// Generate api types by model.
-						NewApiGen(arguments.OutputFileBaseName, pkg.Path, "", ctx.Order),
+						NewApiGen(arguments.OutputFileBaseName, pkg.Path, "", ctx.Order, customArgs),
 					}
 				},
-			})
+			}))
 	}
 	return packages
 }
 
 type apiGen struct {
-	generator.DefaultGen
+	generator.GoGenerator
 	// sourcePackage is source package of input types
 	sourcePackage string
 	// modelTypes record all model types in source package
@@ -124,6 +182,30 @@ type apiGen struct {
 	imports            namer.ImportTracker
 	needImportPackages sets.String
 	apisPkg            string
+	// dependWalked are the types walked by addDependTypes
+	dependWalked sets.String
+	// trimmedNames are generated names of types with --trim-type-prefix trimmed, keyed by type string
+	trimmedNames map[string]string
+	// getters are the accessor methods of current generating struct
+	getters []memberGetter
+	// columnIndex is the table columns of models in source package, loaded if --column-docs is set
+	columnIndex columnIndex
+	// columns are the table columns of current generating struct
+	columns tableColumns
+	// mutabilityIndex is the create and update inputs of models in source package, loaded if --mutability-docs is set
+	mutabilityIndex mutabilityIndex
+	// mutability is the inputs of current generating struct, nil if it's not a model or the inputs are unknown
+	mutability *mutabilityInputs
+	// universe is the types loaded by the run, set by Init
+	universe   types.Universe
+	customArgs *CustomArgs
+}
+
+type memberGetter struct {
+	name  string
+	field string
+	mem   *Member
+	args  interface{}
 }
 
 func isCommonDBPackage(pkg string) bool {
@@ -139,27 +221,43 @@ func defaultAPIsPkg(srcPkg string) string {
 }
 
 func reviseImportPath() {
-	imports.LocalPrefix = "yunion.io/x/:yunion.io/x/onecloud"
+	common.LocalImportPrefix = "yunion.io/x/:yunion.io/x/onecloud"
 }
 
-func NewApiGen(sanitizedName, sourcePackage, apisPkg string, pkgTypes []*types.Type) generator.Generator {
+func NewApiGen(sanitizedName, sourcePackage, apisPkg string, pkgTypes []*types.Type, customArgs *CustomArgs) generator.Generator {
 	reviseImportPath()
 	if apisPkg == "" {
 		apisPkg = defaultAPIsPkg(sourcePackage)
 	}
 	gen := &apiGen{
-		DefaultGen: generator.DefaultGen{
-			OptionalName: sanitizedName,
+		GoGenerator: generator.GoGenerator{
+			OutputFilename: sanitizedName + ".go",
 		},
 		sourcePackage:      sourcePackage,
 		modelTypes:         sets.NewString(),
 		modelDependTypes:   sets.NewString(),
+		dependWalked:       sets.NewString(),
 		isCommonDBPackage:  isCommonDBPackage(sourcePackage),
 		imports:            generator.NewImportTracker(),
 		needImportPackages: sets.NewString(),
 		apisPkg:            apisPkg,
+		customArgs:         customArgs,
 	}
 	gen.collectTypes(pkgTypes)
+	if customArgs.ColumnDocs {
+		gen.columnIndex = newColumnIndex(pkgTypes)
+	}
+	if customArgs.MutabilityDocs {
+		gen.mutabilityIndex = newMutabilityIndex(pkgTypes)
+	}
+	gen.trimmedNames = gen.trimTypeNames(pkgTypes)
+	if errs := gen.checkUnexported(pkgTypes); len(errs) != 0 {
+		msgs := make([]string, 0, len(errs))
+		for _, err := range errs {
+			msgs = append(msgs, err.Error())
+		}
+		klog.Fatalf("Unexported types of %s can't be referred by generated package:\n%s", sourcePackage, strings.Join(msgs, "\n"))
+	}
 	klog.V(1).Infof("sets: %v\ndepsets: %v", gen.modelTypes.List(), gen.modelDependTypes.List())
 	return gen
 }
@@ -167,7 +265,7 @@ func NewApiGen(sanitizedName, sourcePackage, apisPkg string, pkgTypes []*types.T
 func (g *apiGen) Namers(c *generator.Context) namer.NameSystems {
 	// Have the raw namer for this file track what it imports.
 	return namer.NameSystems{
-		"public": namer.NewPublicNamer(0),
+		"public": common.NewInitialismNamer(g.initialisms()),
 		"raw":    namer.NewRawNamer("", g.imports),
 	}
 }
@@ -177,17 +275,13 @@ func (g *apiGen) GetInputOutputPackageMap() map[string]string {
 }
 
 func (g *apiGen) collectTypes(pkgTypes []*types.Type) {
-	for _, t := range pkgTypes {
-		if t.Kind != types.Struct {
-			continue
-		}
-		if !g.inSourcePackage(t) {
-			continue
-		}
-		if includeType(t) || g.isResourceModel(t) {
-			g.modelTypes.Insert(t.String())
-			g.addDependTypes(t, g.modelTypes, g.modelDependTypes)
-		}
+	c := common.NewTypeCollector(g.sourcePackage)
+	c.CommonDB = g.isCommonDBPackage
+	c.APIVersion = g.customArgs.APIVersion
+	c.Include = includeType
+	c.Models = g.modelTypes
+	for _, t := range c.Collect(pkgTypes) {
+		g.addDependTypes(t, g.modelTypes, g.modelDependTypes)
 	}
 }
 
@@ -212,10 +306,28 @@ func isModelBase(t *types.Type) bool {
 }
 
 func (g *apiGen) addDependTypes(t *types.Type, out, dependOut sets.String) {
+	g.walkDependTypes(t, out, dependOut, nil)
+}
+
+// walkDependTypes add types t depends on to out and dependOut, every type is walked once,
+// path is the chain of types being walked to report reference cycles
+func (g *apiGen) walkDependTypes(t *types.Type, out, dependOut sets.String, path []string) {
 	if t.Kind == types.Alias {
 		t = getPrimitiveType(underlyingType(t))
 		out.Insert(t.String())
 	}
+	name := t.String()
+	for i, p := range path {
+		if p == name {
+			klog.V(1).Infof("type reference cycle %s", strings.Join(append(path[i:], name), " -> "))
+			return
+		}
+	}
+	if g.dependWalked.Has(name) {
+		return
+	}
+	g.dependWalked.Insert(name)
+	path = append(path, name)
 	for _, m := range t.Members {
 		switch m.Type.Kind {
 		case types.Struct:
@@ -227,7 +339,7 @@ func (g *apiGen) addDependTypes(t *types.Type, out, dependOut sets.String) {
 				continue
 			}
 			out.Insert(m.Type.String())
-			g.addDependTypes(m.Type, out, dependOut)
+			g.walkDependTypes(m.Type, out, dependOut, path)
 		case types.Pointer:
 			et := m.Type.Elem
 			//if et.Kind == types.Struct {
@@ -236,7 +348,7 @@ func (g *apiGen) addDependTypes(t *types.Type, out, dependOut sets.String) {
 				continue
 			}
 			out.Insert(et.String())
-			g.addDependTypes(et, out, dependOut)
+			g.walkDependTypes(et, out, dependOut, path)
 			//}
 		case types.Alias:
 			mt := m.Type
@@ -248,7 +360,7 @@ func (g *apiGen) addDependTypes(t *types.Type, out, dependOut sets.String) {
 			}
 			out.Insert(mt.String(), umt.String())
 			// maybe bug?
-			g.addDependTypes(umt, out, dependOut)
+			g.walkDependTypes(umt, out, dependOut, path)
 		}
 	}
 }
@@ -275,6 +387,30 @@ func (g *apiGen) isResourceModel(t *types.Type) bool {
 	return common.IsResourceModel(t, g.isCommonDBPackage)
 }
 
+// isOutputType returns true if generated t is only returned by services, i.e. resource models
+// embedded by <Resource>Details and the Details structs of bases
+func (g *apiGen) isOutputType(t *types.Type) bool {
+	return g.isResourceModel(t) || strings.HasSuffix(g.typeName(t), "Details")
+}
+
+// members returns the generated members of t in order, secret fields are skipped in output types
+// because services never return them
+func (g *apiGen) members(t *types.Type) []types.Member {
+	members := orderedMembers(t)
+	if !g.isOutputType(t) {
+		return members
+	}
+	ret := make([]types.Member, 0, len(members))
+	for _, m := range members {
+		if !m.Embedded && common.IsSecret(m.CommentLines) {
+			klog.V(1).Infof("skip secret field %s of output type %s", m.Name, t.String())
+			continue
+		}
+		ret = append(ret, m)
+	}
+	return ret
+}
+
 func (g *apiGen) args(t *types.Type) interface{} {
 	a := generator.Args{
 		"type": t,
@@ -288,12 +424,21 @@ func (g *apiGen) Imports(c *generator.Context) []string {
 	return lines
 }
 
+func (g *apiGen) Init(c *generator.Context, w io.Writer) error {
+	g.universe = c.Universe
+	sw := generator.NewSnippetWriter(w, c, "$", "$")
+	for _, enum := range collectConstEnums(c.Universe[g.sourcePackage], g.customArgs.sourceTags) {
+		enum.Do(sw)
+	}
+	return sw.Error()
+}
+
 func (g *apiGen) GenerateType(c *generator.Context, t *types.Type, w io.Writer) error {
 	klog.V(2).Infof("Generating api model for type %s", t.String())
 
 	sw := generator.NewSnippetWriter(w, c, "$", "$")
 
-	sw.Do(fmt.Sprintf("// %s is an autogenerated struct via %s.\n", t.Name.Name, t.Name.String()), nil)
+	sw.Do(fmt.Sprintf("// %s is an autogenerated struct via %s.\n", g.typeName(t), t.Name.String()), nil)
 
 	// 1. generate resource base output by model to pkg/apis/<pkg>/generated.model.go
 	switch t.Kind {
@@ -309,26 +454,158 @@ func (g *apiGen) GenerateType(c *generator.Context, t *types.Type, w io.Writer)
 
 func (g *apiGen) generateStructType(t *types.Type, sw *generator.SnippetWriter) {
 	//klog.Errorf("for type %q", t.String())
-	sw.Do("type $.type|public$ struct {\n", g.args(t))
+	sw.Do(fmt.Sprintf("type %s struct {\n", g.typeName(t)), nil)
+	g.getters = nil
+	g.columns = g.columnIndex[t.String()]
+	g.mutability = g.mutabilityIndex[t.String()]
 	g.generateFor(t, sw)
+	if g.customArgs.WithMetadataFields && g.isResourceModel(t) && isStandaloneResource(t) {
+		generateMetadataFields(sw)
+	}
 	sw.Do("}\n", nil)
+	g.generateGetters(t, sw)
+	g.generateFieldNameConsts(t, sw)
+	g.generateMemberEnums(t, sw)
+	g.generateEvents(t, sw)
+}
+
+// generateFieldNameConsts emit json field names of t including the ones promoted from embedded structs,
+// named by type and json name, e.g. SGuestFieldHostName = "host_name"
+func (g *apiGen) generateFieldNameConsts(t *types.Type, sw *generator.SnippetWriter) {
+	lines := make([]string, 0)
+	consts := make(map[string]string)
+	for _, name := range g.fieldJSONNames(t, sets.NewString()) {
+		constName := g.typeName(t) + "Field" + g.fieldName(enumConstName("", name))
+		if taken, ok := consts[constName]; ok {
+			klog.Warningf("json field %q of %s is skipped, const %s is taken by %q", name, t.String(), constName, taken)
+			continue
+		}
+		consts[constName] = name
+		lines = append(lines, fmt.Sprintf("%s = %q\n", constName, name))
+	}
+	if len(lines) == 0 {
+		return
+	}
+	sw.Do("\n", nil)
+	sw.Do(fmt.Sprintf("// %s json field names.\n", g.typeName(t)), nil)
+	sw.Do("const (\n", nil)
+	for _, l := range lines {
+		sw.Do(l, nil)
+	}
+	sw.Do(")\n", nil)
+}
+
+// fieldJSONNames returns json names of serialized fields of t in declaration order, fields promoted from
+// embedded structs are included unless shadowed, i.e. named by fields of outer structs
+func (g *apiGen) fieldJSONNames(t *types.Type, shadowed sets.String) []string {
+	members := g.members(t)
+	own := sets.NewString()
+	for _, m := range members {
+		if _, _, ok := common.EmbeddedStruct(m); !ok && token.IsExported(m.Name) {
+			own.Insert(common.MemberJSONName(m))
+		}
+	}
+	inner := shadowed.Union(own)
+	ret := make([]string, 0)
+	seen := sets.NewString("-")
+	add := func(name string) {
+		if !shadowed.Has(name) && !seen.Has(name) {
+			seen.Insert(name)
+			ret = append(ret, name)
+		}
+	}
+	for _, m := range members {
+		if et, _, ok := common.EmbeddedStruct(m); ok {
+			if isModelBase(et) {
+				continue
+			}
+			for _, name := range g.fieldJSONNames(et, inner) {
+				add(name)
+			}
+			continue
+		}
+		if token.IsExported(m.Name) {
+			add(common.MemberJSONName(m))
+		}
+	}
+	return ret
+}
+
+func (g *apiGen) generateMemberEnums(t *types.Type, sw *generator.SnippetWriter) {
+	for _, m := range g.members(t) {
+		m.Name = g.fieldName(m.Name)
+		enum := newMemberChoicesEnum(g.typeName(t), m)
+		if enum == nil {
+			continue
+		}
+		sw.Do("\n", nil)
+		enum.Do(sw)
+	}
 }
 
 func (g *apiGen) generatorAliasType(t *types.Type, sw *generator.SnippetWriter) {
-	sw.Do("type $.type|public$ ", g.args(t))
-	ut := t.Underlying
-	switch ut.Kind {
+	sw.Do(fmt.Sprintf("type %s ", g.typeName(t)), nil)
+	sw.Do(g.typeExpr(t.Underlying), nil)
+	sw.Do("\n", nil)
+}
+
+// typeExpr returns go expression of type t in generated package,
+// types of source package are referred by name, others are qualified and imported
+func (g *apiGen) typeExpr(t *types.Type) string {
+	switch t.Kind {
+	case types.Pointer:
+		return "*" + g.typeExpr(t.Elem)
 	case types.Slice:
-		elem := ut.Elem
-		content := "$.type|public$"
-		if elem.Kind == types.Pointer {
-			content = g.getPointerSourcePackageName(elem)
+		return "[]" + g.typeExpr(t.Elem)
+	case types.Map:
+		return fmt.Sprintf("map[%s]%s", g.typeExpr(t.Key), g.typeExpr(t.Elem))
+	case types.Func:
+		return g.funcExpr(t.Signature)
+	case types.Interface:
+		if g.inJSONUtilsPackage(t) || t.Name.Package == "" {
+			return "interface{}"
 		}
-		sw.Do(fmt.Sprintf("[]%s", content), g.args(elem))
+	}
+	if t.Name.Package == "" {
+		// builtin
+		return t.Name.Name
+	}
+	if g.inSourcePackage(t) {
+		return g.typeName(t)
+	}
+	if outPkg, ok := g.GetInputOutputPackageMap()[t.Name.Package]; ok {
+		g.needImportPackages.Insert(outPkg)
+		return fmt.Sprintf("%s.%s", filepath.Base(outPkg), g.initialisms().Apply(t.Name.Name))
+	}
+	g.imports.AddType(t)
+	return fmt.Sprintf("%s.%s", g.imports.LocalNameOf(t.Name.Package), t.Name.Name)
+}
+
+func (g *apiGen) funcExpr(sig *types.Signature) string {
+	if sig == nil {
+		return "func()"
+	}
+	params := make([]string, 0, len(sig.Parameters))
+	for i, p := range sig.Parameters {
+		expr := g.typeExpr(p.Type)
+		if sig.Variadic && i == len(sig.Parameters)-1 && p.Type.Kind == types.Slice {
+			expr = "..." + g.typeExpr(p.Type.Elem)
+		}
+		params = append(params, expr)
+	}
+	results := make([]string, 0, len(sig.Results))
+	for _, r := range sig.Results {
+		results = append(results, g.typeExpr(r.Type))
+	}
+	ret := fmt.Sprintf("func(%s)", strings.Join(params, ", "))
+	switch len(results) {
+	case 0:
+	case 1:
+		ret += " " + results[0]
 	default:
-		sw.Do("$.type|public$ ", g.args(t.Underlying))
+		ret += fmt.Sprintf(" (%s)", strings.Join(results, ", "))
 	}
-	sw.Do("\n", nil)
+	return ret
 }
 
 func underlyingType(t *types.Type) *types.Type {
@@ -338,13 +615,51 @@ func underlyingType(t *types.Type) *types.Type {
 	return t
 }
 
+var publicNamer = namer.NewPublicNamer(0)
+
+// typeName returns the generated name of type t, renamed by tagRenameName if tagged,
+// otherwise prefix of --trim-type-prefix is trimmed if not collided
+func (g *apiGen) typeName(t *types.Type) string {
+	if name, ok := g.trimmedNames[t.String()]; ok {
+		return name
+	}
+	return g.sourceTypeName(t)
+}
+
+// sourceTypeName returns name of type t without prefix trimming
+func (g *apiGen) sourceTypeName(t *types.Type) string {
+	if vals := common.ParseTags(t.CommentLines).Values(tagRenameName); len(vals) != 0 {
+		if token.IsIdentifier(vals[0]) {
+			return vals[0]
+		}
+		klog.Errorf("invalid tag %s=%s of %s", tagRenameName, vals[0], t.String())
+	}
+	if in, ok := common.ParseInstantiatedType(t); ok {
+		return g.initialisms().Apply(in.GoName())
+	}
+	return g.initialisms().Apply(publicNamer.Name(t))
+}
+
+// initialisms returns initialisms of --initialisms upper cased in generated names
+func (g *apiGen) initialisms() common.Initialisms {
+	if g.customArgs == nil {
+		return nil
+	}
+	return g.customArgs.initialisms
+}
+
+// fieldName returns generated name of struct field, json name is still derived from the source name
+func (g *apiGen) fieldName(name string) string {
+	return g.initialisms().Apply(name)
+}
+
 func (g *apiGen) needCopy(t *types.Type) bool {
 	tStr := t.String()
 	return !g.modelTypes.Has(tStr) && g.modelDependTypes.Has(tStr)
 }
 
 func (g *apiGen) generateFor(t *types.Type, sw *generator.SnippetWriter) {
-	for _, mem := range t.Members {
+	for _, mem := range g.members(t) {
 		mt := mem.Type
 		if isModelBase(mt) {
 			continue
@@ -368,6 +683,9 @@ func (g *apiGen) generateFor(t *types.Type, sw *generator.SnippetWriter) {
 			klog.Fatalf("Hit an unsupported type %v.%s, kind is %s", t, mt.Name.Name, mt.Kind)
 			//klog.Warningf("Hit an unsupported type %v.%s, kind is %s", t, mt.Name.Name, mt.Kind)
 		}
+		if isTimeMember(mem) {
+			f = g.doTime
+		}
 		f(mem, sw)
 	}
 }
@@ -385,7 +703,7 @@ type Member struct {
 func NewMember(name string, commentLines []string) *Member {
 	clines := []string{}
 	for _, cl := range commentLines {
-		if len(cl) == 0 {
+		if len(cl) == 0 || strings.HasPrefix(cl, "+"+tagName) {
 			continue
 		}
 		clines = append(clines, fmt.Sprintf("// %s", cl))
@@ -431,6 +749,20 @@ func (m *Member) AddTag(tags ...string) *Member {
 	return m
 }
 
+// OmitEmpty appends omitempty option to json tag, the json name is kept first
+func (m *Member) OmitEmpty() *Member {
+	if len(m.jsonTags) == 0 {
+		return m
+	}
+	for _, t := range m.jsonTags[1:] {
+		if t == "omitempty" {
+			return m
+		}
+	}
+	m.jsonTags = append(m.jsonTags, "omitempty")
+	return m
+}
+
 func (m *Member) NoTag() *Member {
 	m.jsonTags = nil
 	return m
@@ -442,19 +774,19 @@ func NewModelMember(name string, commentLines []string, tags ...string) *Member
 	return m.AddTag(jName)
 }
 
-func (m *Member) Do(sw *generator.SnippetWriter, args interface{}) {
-	var (
-		typePart string
-		ret      string
-	)
-	namePart := m.name
+func (m *Member) typePart() string {
 	if m.mType != "" {
-		typePart = m.mType
+		return m.mType
 	} else if m.useInterface {
-		typePart = "interface{}"
-	} else {
-		typePart = fmt.Sprintf("$.type|%s$", m.namer)
+		return "interface{}"
 	}
+	return fmt.Sprintf("$.type|%s$", m.namer)
+}
+
+func (m *Member) Do(sw *generator.SnippetWriter, args interface{}) {
+	var ret string
+	namePart := m.name
+	typePart := m.typePart()
 	if m.embedded {
 		ret = typePart
 	} else {
@@ -469,8 +801,60 @@ func (m *Member) Do(sw *generator.SnippetWriter, args interface{}) {
 	sw.Do(fmt.Sprintf("%s\n", ret), args)
 }
 
+// emitMember write member to struct and record its getter if tagged by tagGetterName
+func (g *apiGen) emitMember(member types.Member, m *Member, sw *generator.SnippetWriter, args interface{}) {
+	for _, l := range g.customArgs.typeOverrides.CommentLines(member.Type) {
+		m.commentLines = append(m.commentLines, fmt.Sprintf("// %s", l))
+	}
+	docs := make([]string, 0)
+	if g.customArgs.ColumnDocs {
+		docs = append(docs, columnCommentLines(member, g.columns)...)
+	}
+	if common.IsSecret(member.CommentLines) {
+		docs = common.SecretLines(docs)
+		m.OmitEmpty()
+	}
+	if g.customArgs.NullablePointers && isNullableMember(member) {
+		docs = common.AppendExtension(docs, "x-nullable", "true")
+	}
+	if mutability := g.mutability.mutability(member); mutability != "" {
+		docs = common.AppendExtension(docs, extMutability, mutability)
+	}
+	for _, l := range docs {
+		m.commentLines = append(m.commentLines, fmt.Sprintf("// %s", l))
+	}
+	if !m.embedded {
+		m.Name(g.fieldName(m.name))
+	}
+	m.Do(sw, args)
+	if !checkTagByName(member.CommentLines, tagGetterName) {
+		return
+	}
+	g.getters = append(g.getters, memberGetter{
+		name:  "Get" + m.name,
+		field: m.name,
+		mem:   m,
+		args:  args,
+	})
+}
+
+// isNullableMember returns true if member is a named pointer, which is null in json if nil
+func isNullableMember(member types.Member) bool {
+	return !member.Embedded && member.Type.Kind == types.Pointer
+}
+
+func (g *apiGen) generateGetters(t *types.Type, sw *generator.SnippetWriter) {
+	for _, getter := range g.getters {
+		sw.Do("\n", nil)
+		sw.Do(fmt.Sprintf("// %s returns %s of %s.\n", getter.name, getter.field, g.typeName(t)), nil)
+		sw.Do(fmt.Sprintf("func (o *%s) %s() %s {\n", g.typeName(t), getter.name, getter.mem.typePart()), getter.args)
+		sw.Do(fmt.Sprintf("return o.%s\n", getter.field), nil)
+		sw.Do("}\n", nil)
+	}
+}
+
 func (g *apiGen) doBuiltin(m types.Member, sw *generator.SnippetWriter) {
-	NewModelMember(m.Name, m.CommentLines).Do(sw, g.args(m.Type))
+	g.emitMember(m, NewModelMember(m.Name, m.CommentLines), sw, g.args(m.Type))
 }
 
 var (
@@ -490,11 +874,36 @@ func (g *apiGen) doAlias(member types.Member, sw *generator.SnippetWriter) {
 	mt := member.Type
 	if ct, ok := TypeMap[mt.Name.Name]; ok {
 		m := NewModelMember(name, nil).AddTag(ct.JSONTags...).Type(ct.Type)
-		m.Do(sw, nil)
+		g.emitMember(member, m, sw, nil)
+		return
+	}
+	if expr, ok := g.keptAliasExpr(mt); ok {
+		g.emitMember(member, NewModelMember(name, nil).Type(expr), sw, nil)
 		return
 	}
 	ut := underlyingType(mt)
-	NewModelMember(name, nil).Do(sw, g.args(ut))
+	g.emitMember(member, NewModelMember(name, nil), sw, g.args(ut))
+}
+
+// inAPIsPackage returns true if pkg is the apis package or one of its sub packages
+func (g *apiGen) inAPIsPackage(pkg string) bool {
+	return pkg == g.apisPkg || strings.HasPrefix(pkg, g.apisPkg+"/")
+}
+
+// keptAliasExpr returns expression of alias type mt kept named by --keep-apis-aliases,
+// only aliases of builtin types declared in apis packages are kept, others have to be expanded
+func (g *apiGen) keptAliasExpr(mt *types.Type) (string, bool) {
+	if g.customArgs == nil || !g.customArgs.KeepAPIsAliases {
+		return "", false
+	}
+	if !g.inAPIsPackage(mt.Name.Package) || underlyingType(mt).Kind != types.Builtin {
+		return "", false
+	}
+	if mt.Name.Package == g.customArgs.outputPackage {
+		return mt.Name.Name, true
+	}
+	g.imports.AddType(mt)
+	return fmt.Sprintf("%s.%s", g.imports.LocalNameOf(mt.Name.Package), mt.Name.Name), true
 }
 
 func (g *apiGen) doSlice(member types.Member, sw *generator.SnippetWriter) {
@@ -506,17 +915,25 @@ func (g *apiGen) doStruct(member types.Member, sw *generator.SnippetWriter) {
 	klog.V(5).Infof("doStruct for %s", mt.Name.String())
 	//inPkg := g.inSourcePackage(member.Type)
 	m := NewModelMember(member.Name, member.CommentLines)
-	if member.Embedded {
+	if embed, key := memberFlatten(member); embed {
 		m.Embedded()
 		m.NoTag()
+	} else if key != "" {
+		m.NoTag().AddTag(key)
 	}
 	if g.inSourcePackage(mt) {
-		m.Namer("public")
+		m.Type(g.typeName(mt))
+	} else if name, ok := apisBaseStruct(g.universe, g.apisPkg, mt); ok && member.Embedded {
+		g.needImportPackages.Insert(g.apisPkg)
+		m.Type(fmt.Sprintf("%s.%s", filepath.Base(g.apisPkg), name))
 	} else if outPkg, ok := g.GetInputOutputPackageMap()[mt.Name.Package]; ok {
+		if member.Embedded && mt.Name.Package == CloudCommonDBPackage {
+			klog.Warningf("no apis base struct of embedded %s found in %s, referred as %s", mt.String(), g.apisPkg, mt.Name.Name)
+		}
 		g.needImportPackages.Insert(outPkg)
-		m.Type(fmt.Sprintf("%s.%s", filepath.Base(outPkg), mt.Name.Name))
+		m.Type(fmt.Sprintf("%s.%s", filepath.Base(outPkg), g.initialisms().Apply(mt.Name.Name)))
 	}
-	m.Do(sw, g.args(mt))
+	g.emitMember(member, m, sw, g.args(mt))
 }
 
 func (g *apiGen) doInterface(m types.Member, sw *generator.SnippetWriter) {
@@ -528,7 +945,7 @@ func (g *apiGen) doInterface(m types.Member, sw *generator.SnippetWriter) {
 	if g.inJSONUtilsPackage(m.Type) {
 		mem.UseInterface()
 	}
-	mem.Do(sw, g.args(m.Type))
+	g.emitMember(m, mem, sw, g.args(m.Type))
 }
 
 func (g *apiGen) inSourcePackage(t *types.Type) bool {
@@ -548,10 +965,16 @@ func (g *apiGen) getPointerSourcePackageName(t *types.Type) string {
 	if !g.inSourcePackage(elem) {
 		klog.Fatalf("pointer's elem %q not in package %q", elem.Name.String(), g.sourcePackage)
 	}
-	return fmt.Sprintf("*%s", elem.Name.Name)
+	return fmt.Sprintf("*%s", g.typeName(elem))
 }
 
 func (g *apiGen) doPointer(m types.Member, sw *generator.SnippetWriter) {
+	if et, _, ok := common.EmbeddedStruct(m); ok {
+		// embedded pointer is dereferenced, its fields are optional as apis fields
+		m.Type = et
+		g.doStruct(m, sw)
+		return
+	}
 	t := m.Type
 	mem := NewModelMember(m.Name, m.CommentLines)
 	elem := m.Type.Elem
@@ -560,12 +983,8 @@ func (g *apiGen) doPointer(m types.Member, sw *generator.SnippetWriter) {
 	} else if g.inJSONUtilsPackage(elem) {
 		mem.UseInterface()
 	}
-	if m.Embedded {
-		mem.Embedded()
-		mem.NoTag()
-	}
 	args := g.args(m.Type)
-	mem.Do(sw, args)
+	g.emitMember(m, mem, sw, args)
 }
 
 type ResourceModel struct {
@@ -0,0 +1,165 @@
+package generators
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/types"
+
+	"yunion.io/x/code-generator/pkg/common"
+)
+
+func Test_generateFieldNameConsts(t *testing.T) {
+	const srcPkg = "yunion.io/x/onecloud/pkg/compute/models"
+	str := &types.Type{Name: types.Name{Name: "string"}, Kind: types.Builtin}
+	modelBase := &types.Type{
+		Name:    types.Name{Package: "yunion.io/x/onecloud/pkg/cloudcommon/db", Name: SModelBase},
+		Kind:    types.Struct,
+		Members: []types.Member{{Name: "Hidden", Type: str}},
+	}
+	resourceBase := &types.Type{
+		Name: types.Name{Package: "yunion.io/x/onecloud/pkg/cloudcommon/db", Name: "SStandaloneResourceBase"},
+		Kind: types.Struct,
+		Members: []types.Member{
+			{Name: "SModelBase", Type: modelBase, Embedded: true},
+			{Name: "Id", Type: str},
+			{Name: "Name", Type: str},
+			{Name: "Description", Type: str},
+			{Name: "CreatedAt", Type: str},
+		},
+	}
+	guest := &types.Type{
+		Name: types.Name{Package: srcPkg, Name: "SGuest"},
+		Kind: types.Struct,
+		Members: []types.Member{
+			{Name: "SStandaloneResourceBase", Type: &types.Type{Kind: types.Pointer, Elem: resourceBase}, Embedded: true},
+			{Name: "VcpuCount", Type: str},
+			{Name: "OsType", Type: str, Tags: `json:"os_type,omitempty"`},
+			{Name: "Hostname", Type: str, Tags: `json:"host_name"`},
+			{Name: "Secret", Type: str, Tags: `json:"-"`},
+			{Name: "Desc", Type: str, Tags: `json:"description"`},
+			{Name: "internal", Type: str},
+		},
+	}
+	buf := &bytes.Buffer{}
+	sw := generator.NewSnippetWriter(buf, &generator.Context{}, "$", "$")
+	g := &apiGen{sourcePackage: srcPkg}
+	g.generateFieldNameConsts(guest, sw)
+	if err := sw.Error(); err != nil {
+		t.Fatalf("generateFieldNameConsts error: %v", err)
+	}
+	want := "\n// SGuest json field names.\n" +
+		"const (\n" +
+		"SGuestFieldId = \"id\"\n" +
+		"SGuestFieldName = \"name\"\n" +
+		"SGuestFieldCreatedAt = \"created_at\"\n" +
+		"SGuestFieldVcpuCount = \"vcpu_count\"\n" +
+		"SGuestFieldOsType = \"os_type\"\n" +
+		"SGuestFieldHostName = \"host_name\"\n" +
+		"SGuestFieldDescription = \"description\"\n" +
+		")\n"
+	if buf.String() != want {
+		t.Errorf("generateFieldNameConsts() = %q, want %q", buf.String(), want)
+	}
+}
+
+func Test_membersSecret(t *testing.T) {
+	const srcPkg = "yunion.io/x/onecloud/pkg/compute/models"
+	str := &types.Type{Name: types.Name{Name: "string"}, Kind: types.Builtin}
+	members := []types.Member{
+		{Name: "Name", Type: str},
+		{Name: "Password", Type: str, CommentLines: []string{"+onecloud:model-api-gen-secret"}},
+	}
+	tests := []struct {
+		name string
+		t    *types.Type
+		want []string
+	}{
+		{
+			name: "details",
+			t:    &types.Type{Name: types.Name{Package: srcPkg, Name: "ServerLoginDetails"}, Kind: types.Struct, Members: members},
+			want: []string{"Name"},
+		},
+		{
+			name: "resource model",
+			t: &types.Type{Name: types.Name{Package: srcPkg, Name: "SGuest"}, Kind: types.Struct, Members: append([]types.Member{{
+				Name:     "SStandaloneResourceBase",
+				Type:     &types.Type{Name: types.Name{Package: CloudCommonDBPackage, Name: "SStandaloneResourceBase"}, Kind: types.Struct},
+				Embedded: true,
+			}}, members...)},
+			want: []string{"SStandaloneResourceBase", "Name"},
+		},
+		{
+			name: "input",
+			t:    &types.Type{Name: types.Name{Package: srcPkg, Name: "ServerLoginInfo"}, Kind: types.Struct, Members: members},
+			want: []string{"Name", "Password"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &apiGen{sourcePackage: srcPkg}
+			got := make([]string, 0)
+			for _, m := range g.members(tt.t) {
+				got = append(got, m.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("members() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_emitMemberTypeOverride(t *testing.T) {
+	addr := &types.Type{Name: types.Name{Package: "yunion.io/x/pkg/util/netutils", Name: "IPV4Addr"}, Kind: types.Builtin}
+	member := types.Member{Name: "IpAddr", Type: addr, CommentLines: []string{"ip address of the nic"}}
+	overrides := common.TypeOverrides{"netutils.IPV4Addr": {Type: "string", Format: "ipv4", Example: "10.0.0.1"}}
+	g := &apiGen{customArgs: &CustomArgs{typeOverrides: overrides}}
+	buf := &bytes.Buffer{}
+	sw := generator.NewSnippetWriter(buf, &generator.Context{}, "$", "$")
+	g.emitMember(member, NewModelMember(member.Name, member.CommentLines).Type("string"), sw, nil)
+	want := "// ip address of the nic\n" +
+		"// swagger:type string\n" +
+		"// swagger:strfmt ipv4\n" +
+		"// example: 10.0.0.1\n" +
+		"IpAddr string `json:\"ip_addr\"`\n"
+	if buf.String() != want {
+		t.Errorf("emitMember() = %q, want %q", buf.String(), want)
+	}
+}
+
+func Test_generateGetters(t *testing.T) {
+	const srcPkg = "yunion.io/x/onecloud/pkg/compute/models"
+	str := &types.Type{Name: types.Name{Name: "string"}, Kind: types.Builtin}
+	integer := &types.Type{Name: types.Name{Name: "int"}, Kind: types.Builtin}
+	guest := &types.Type{
+		Name: types.Name{Package: srcPkg, Name: "SGuest"},
+		Kind: types.Struct,
+		Members: []types.Member{
+			{Name: "Hostname", Type: str, CommentLines: []string{"+onecloud:model-api-gen-getter"}},
+			{Name: "OsType", Type: str},
+			{Name: "VcpuCount", Type: integer, CommentLines: []string{"+onecloud:model-api-gen-getter"}},
+		},
+	}
+	buf := &bytes.Buffer{}
+	sw := generator.NewSnippetWriter(buf, &generator.Context{Namers: NameSystems()}, "$", "$")
+	g := &apiGen{sourcePackage: srcPkg, customArgs: &CustomArgs{}}
+	g.generateFor(guest, sw)
+	buf.Reset()
+	g.generateGetters(guest, sw)
+	if err := sw.Error(); err != nil {
+		t.Fatalf("generateGetters error: %v", err)
+	}
+	want := "\n// GetHostname returns Hostname of SGuest.\n" +
+		"func (o *SGuest) GetHostname() string {\n" +
+		"return o.Hostname\n" +
+		"}\n" +
+		"\n// GetVcpuCount returns VcpuCount of SGuest.\n" +
+		"func (o *SGuest) GetVcpuCount() int {\n" +
+		"return o.VcpuCount\n" +
+		"}\n"
+	if buf.String() != want {
+		t.Errorf("generateGetters() = %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,97 @@
+package generators
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/pflag"
+
+	"yunion.io/x/code-generator/pkg/common"
+	"yunion.io/x/code-generator/pkg/models/registry"
+	"yunion.io/x/code-generator/pkg/onecloudshim"
+)
+
+// CustomArgs is the model-api-gen specific command line arguments
+type CustomArgs struct {
+	// WithMetadataFields appends Metadata and Tags fields injected by server to standalone resource structs
+	WithMetadataFields bool
+	// TypeOverrides is the yaml file mapping go types to swagger type, format and example
+	TypeOverrides string
+	// TimeFormats are formats of time.Time fields, time or string, optionally per project like meter=string,
+	// the project of a package is its module path or the last element of it, read from go.mod of the package
+	TimeFormats []string
+	// APIVersion is the api version to generate, types tagged with other versions are skipped
+	APIVersion string
+	// ColumnDocs documents sqlchemy column width, default, nullable and charset of model fields as swagger constraints
+	// and whether they can filter or sort list requests, columns are read from tables of the model managers loaded from LoadServices
+	ColumnDocs bool
+	// MutabilityDocs documents fields of models settable by create input only, update input only or neither
+	MutabilityDocs bool
+	// LoadServices are the onecloud services which model managers are loaded by --column-docs, all if empty
+	LoadServices []string
+	// DuplicateManagers deduplicates managers registered by several services
+	DuplicateManagers registry.Dedup
+	// NullablePointers annotates pointer fields by x-nullable extension
+	NullablePointers bool
+	// EventTypes generates message bus event envelopes of structs tagged by events tag
+	EventTypes bool
+	// Initialisms are words upper cased in generated type and field names, golint means golint's list
+	Initialisms []string
+	// TrimTypePrefix is trimmed from generated type names, e.g. S makes SGuest as Guest
+	TrimTypePrefix string
+	// KeepAPIsAliases keeps named alias fields of builtin types declared in apis packages, e.g. compute.TGuestStatus,
+	// instead of expanding them to the underlying type
+	KeepAPIsAliases bool
+	// BuildTags are the build tags packages are loaded with, set from LoaderArgs by main
+	BuildTags []string
+
+	// timeFormats are parsed from TimeFormats by Packages, keyed by project
+	timeFormats map[string]string
+	// sourceModules are the module paths of input packages keyed by package path, set by Packages
+	sourceModules map[string]string
+	// initialisms are parsed from Initialisms by Packages
+	initialisms common.Initialisms
+	// typeOverrides are loaded from TypeOverrides by Packages
+	typeOverrides common.TypeOverrides
+	// outputPackage is the versioned output package path set by Packages
+	outputPackage string
+	// sourceTags select source files of enum const groups the same as the loader, set by Packages
+	sourceTags []string
+}
+
+// NewDefaults returns default arguments for model-api-gen
+func NewDefaults() (*common.GeneratorArgs, *CustomArgs) {
+	genericArgs := common.NewGeneratorArgs()
+	customArgs := &CustomArgs{
+		APIVersion: common.DefaultAPIVersion,
+	}
+	genericArgs.CustomArgs = customArgs
+	genericArgs.OutputFileBaseName = "zz_generated.model"
+	genericArgs.GoHeaderFilePath = filepath.Join(common.DefaultSourceTree(), "yunion.io/x/code-generator/boilerplate/boilerplate.go.txt")
+	return genericArgs, customArgs
+}
+
+// AddFlags add model-api-gen specific flags to fs
+func (ca *CustomArgs) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&ca.TypeOverrides, "type-overrides", ca.TypeOverrides, "Yaml file mapping go type names like time.Time to swagger type, format and example annotated on fields")
+	fs.StringSliceVar(&ca.TimeFormats, "time-format", ca.TimeFormats, fmt.Sprintf("Format of time.Time fields, choices: %v, string is onecloud ISO format %s; set per project like onecloud=string or yunion.io/x/onecloud=string, project of a package is read from its go.mod", timeFormats, onecloudIsoTimeFormat))
+	fs.StringVar(&ca.APIVersion, "api-version", ca.APIVersion, fmt.Sprintf("API version to generate, types tagged +%s with other versions are skipped, apis of versions other than %s are written to <output-package>/<version>", common.TagAPIVersion, common.DefaultAPIVersion))
+	fs.StringVar(&ca.TrimTypePrefix, "trim-type-prefix", ca.TrimTypePrefix, "Prefix trimmed from generated type names, e.g. S generates SGuest as Guest; types are kept if trimmed names collide")
+	fs.StringSliceVar(&ca.Initialisms, "initialisms", ca.Initialisms, fmt.Sprintf("Words upper cased in generated type and field names like ID, CDROM, %s expands to golint's initialisms; json names are kept", common.GolintInitialisms))
+	fs.BoolVar(&ca.ColumnDocs, "column-docs", ca.ColumnDocs, "Document table columns of model fields as swagger constraints, width as max length, default, not null and charset as x-nullable and x-charset extensions, columns usable by filter and order_by of list requests as x-filterable and x-sortable extensions, managers are loaded from --load-services, requires model-api-gen built with -tags columndocs")
+	fs.StringSliceVar(&ca.LoadServices, "load-services", ca.LoadServices, fmt.Sprintf("Onecloud services to load model managers from for --column-docs, choices: %v", onecloudshim.ServiceNames()))
+	ca.DuplicateManagers.AddFlags(fs)
+	fs.BoolVar(&ca.MutabilityDocs, "mutability-docs", ca.MutabilityDocs, fmt.Sprintf("Document model fields accepted by create input only, update input only or neither as %s extension of %s, %s or %s", extMutability, MutabilityCreateOnly, MutabilityUpdateOnly, MutabilityImmutable))
+	fs.BoolVar(&ca.NullablePointers, "nullable-pointers", ca.NullablePointers, "Annotate pointer fields as nullable by x-nullable extension, a nullable column tag of --column-docs wins")
+	fs.BoolVar(&ca.EventTypes, "event-types", ca.EventTypes, fmt.Sprintf("Generate event envelopes like ServerCreatedEvent{Server ServerDetails} of structs tagged +%s=created,deleted", tagEventsName))
+	fs.BoolVar(&ca.KeepAPIsAliases, "keep-apis-aliases", ca.KeepAPIsAliases, "Keep fields typed by aliases of builtin types declared in apis packages, like type TGuestStatus string, named instead of expanding them to the underlying type")
+	fs.BoolVar(&ca.WithMetadataFields, "with-metadata-fields", ca.WithMetadataFields, "Append __meta__ Metadata and Tags fields injected at serialization time to structs of standalone resources")
+}
+
+func getCustomArgs(arguments *common.GeneratorArgs) *CustomArgs {
+	if ca, ok := arguments.CustomArgs.(*CustomArgs); ok {
+		return ca
+	}
+	_, ca := NewDefaults()
+	return ca
+}
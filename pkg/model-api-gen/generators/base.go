@@ -0,0 +1,113 @@
+package generators
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/types"
+)
+
+// apisBaseStructSuffixes are the suffixes of apis structs generated for cloudcommon/db base models,
+// e.g. VirtualResourceDetails of SVirtualResourceBase and MetadataResourceInfo of SMetadataResourceBase
+var apisBaseStructSuffixes = []string{"Details", "Info"}
+
+// apisBaseStruct returns the name of apis struct already generated for cloudcommon/db base model t,
+// which is declared in apisPkg of universe u, models embedding a base model embed the apis struct
+// instead of expanding the base again. The struct is named by the base with or without its Base suffix,
+// e.g. ModelBaseDetails of SModelBase or StandaloneResourceDetails of SStandaloneResourceBase,
+// bases named like SSharableBaseResource are looked up as SharableResourceBase
+func apisBaseStruct(u types.Universe, apisPkg string, t *types.Type) (string, bool) {
+	if t.Name.Package != CloudCommonDBPackage || !strings.HasPrefix(t.Name.Name, "S") {
+		return "", false
+	}
+	pkg, ok := u[apisPkg]
+	if !ok {
+		return "", false
+	}
+	base := strings.TrimPrefix(t.Name.Name, "S")
+	if strings.HasSuffix(base, "BaseResource") {
+		base = strings.TrimSuffix(base, "BaseResource") + "ResourceBase"
+	}
+	if !strings.HasSuffix(base, "Base") {
+		return "", false
+	}
+	for _, suffix := range apisBaseStructSuffixes {
+		for _, name := range []string{base + suffix, strings.TrimSuffix(base, "Base") + suffix} {
+			if at, ok := pkg.Types[name]; ok && at.Kind == types.Struct {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// isStandaloneResource returns true if t embeds cloudcommon/db SStandaloneResourceBase directly or indirectly
+func isStandaloneResource(t *types.Type) bool {
+	if t.Name.Package == CloudCommonDBPackage && t.Name.Name == "SStandaloneResourceBase" {
+		return true
+	}
+	for _, m := range t.Members {
+		if !m.Embedded {
+			continue
+		}
+		mt := m.Type
+		if mt.Kind == types.Pointer {
+			mt = mt.Elem
+		}
+		if isStandaloneResource(mt) {
+			return true
+		}
+	}
+	return false
+}
+
+// generateMetadataFields append fields injected by server at serialization time,
+// which never appear in model structs
+func generateMetadataFields(sw *generator.SnippetWriter) {
+	sw.Do("// Metadata is the resource metadata injected at serialization time\n", nil)
+	sw.Do("Metadata map[string]string `json:\"__meta__,omitempty\"`\n", nil)
+	sw.Do("// Tags are the resource user tags injected at serialization time\n", nil)
+	sw.Do("Tags []ResourceTag `json:\"tags,omitempty\"`\n", nil)
+}
+
+// NewResourceTagPackage returns the target declaring ResourceTag of output package pkgPath in a file of its own.
+// It's generated by every run, full or partial like regeneration of changed packages by --watch,
+// so the output package declares ResourceTag once whichever input packages are generated.
+func NewResourceTagPackage(pkgName, pkgPath string, header []byte, base string) generator.Target {
+	return &generator.SimpleTarget{
+		PkgName:       pkgName,
+		PkgPath:       pkgPath,
+		HeaderComment: header,
+		GeneratorsFunc: func(c *generator.Context) []generator.Generator {
+			return []generator.Generator{&resourceTagGen{
+				GoGenerator: generator.GoGenerator{OutputFilename: fmt.Sprintf("%s_resourcetag.go", base)},
+			}}
+		},
+	}
+}
+
+// resourceTagGen declares ResourceTag, the element type of Tags fields appended by --with-metadata-fields
+type resourceTagGen struct {
+	generator.GoGenerator
+}
+
+func (g *resourceTagGen) Filter(c *generator.Context, t *types.Type) bool {
+	return false
+}
+
+func (g *resourceTagGen) Init(c *generator.Context, w io.Writer) error {
+	sw := generator.NewSnippetWriter(w, c, "$", "$")
+	generateResourceTagType(sw)
+	return sw.Error()
+}
+
+// generateResourceTagType emit the element type of generated Tags fields
+func generateResourceTagType(sw *generator.SnippetWriter) {
+	sw.Do("// ResourceTag is an autogenerated key value tag of resource.\n", nil)
+	sw.Do("type ResourceTag struct {\n", nil)
+	sw.Do("Key string `json:\"key\"`\n", nil)
+	sw.Do("Value string `json:\"value\"`\n", nil)
+	sw.Do("}\n\n", nil)
+}
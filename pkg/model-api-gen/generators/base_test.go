@@ -0,0 +1,136 @@
+package generators
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/gengo/v2/types"
+
+	"yunion.io/x/code-generator/pkg/common"
+)
+
+func Test_apisBaseStruct(t *testing.T) {
+	u := types.Universe{}
+	for _, name := range []string{"ModelBaseDetails", "VirtualResourceDetails", "StatusStandaloneResourceDetails", "SharableResourceBaseInfo", "MetadataResourceInfo"} {
+		u.Type(types.Name{Package: APIsPackage, Name: name}).Kind = types.Struct
+	}
+	u.Type(types.Name{Package: APIsPackage, Name: "UserResourceDetails"}).Kind = types.Alias
+	tests := []struct {
+		name   types.Name
+		want   string
+		wantOk bool
+	}{
+		{types.Name{Package: CloudCommonDBPackage, Name: "SModelBase"}, "ModelBaseDetails", true},
+		{types.Name{Package: CloudCommonDBPackage, Name: "SVirtualResourceBase"}, "VirtualResourceDetails", true},
+		{types.Name{Package: CloudCommonDBPackage, Name: "SStatusStandaloneResourceBase"}, "StatusStandaloneResourceDetails", true},
+		{types.Name{Package: CloudCommonDBPackage, Name: "SSharableBaseResource"}, "SharableResourceBaseInfo", true},
+		{types.Name{Package: CloudCommonDBPackage, Name: "SMetadataResourceBase"}, "MetadataResourceInfo", true},
+		{types.Name{Package: CloudCommonDBPackage, Name: "SUserResourceBase"}, "", false},
+		{types.Name{Package: CloudCommonDBPackage, Name: "SUnknownBase"}, "", false},
+		{types.Name{Package: CloudCommonDBPackage, Name: "SModelBaseManager"}, "", false},
+		{types.Name{Package: "yunion.io/x/onecloud/pkg/compute/models", Name: "SVirtualResourceBase"}, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := apisBaseStruct(u, APIsPackage, &types.Type{Name: tt.name})
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("apisBaseStruct(%s) = %s, %v, want %s, %v", tt.name, got, ok, tt.want, tt.wantOk)
+		}
+	}
+	if _, ok := apisBaseStruct(types.Universe{}, APIsPackage, &types.Type{Name: tests[0].name}); ok {
+		t.Errorf("apisBaseStruct() of universe without apis package = true, want false")
+	}
+}
+
+func Test_isStandaloneResource(t *testing.T) {
+	standalone := &types.Type{Name: types.Name{Package: CloudCommonDBPackage, Name: "SStandaloneResourceBase"}}
+	virtual := &types.Type{
+		Name:    types.Name{Package: CloudCommonDBPackage, Name: "SVirtualResourceBase"},
+		Members: []types.Member{{Embedded: true, Type: standalone}},
+	}
+	guest := &types.Type{
+		Name:    types.Name{Package: "yunion.io/x/onecloud/pkg/compute/models", Name: "SGuest"},
+		Members: []types.Member{{Embedded: true, Type: virtual}},
+	}
+	joint := &types.Type{
+		Name:    types.Name{Package: "yunion.io/x/onecloud/pkg/compute/models", Name: "SGuestdisk"},
+		Members: []types.Member{{Embedded: true, Type: &types.Type{Name: types.Name{Package: CloudCommonDBPackage, Name: "SJointResourceBase"}}}},
+	}
+	if !isStandaloneResource(guest) {
+		t.Errorf("isStandaloneResource(%s) = false, want true", guest.Name)
+	}
+	if isStandaloneResource(joint) {
+		t.Errorf("isStandaloneResource(%s) = true, want false", joint.Name)
+	}
+}
+
+func TestResourceTagPartialRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "resourcetag")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"go.mod":                   "module yunion.io/x/svc\n\ngo 1.18\n",
+		"boilerplate.go.txt":       "",
+		"pkg/compute/models/a.go":  "package models\n\ntype SGuest struct {\n\tName string\n}\n",
+		"pkg/image/models/a.go":    "package models\n\ntype SImage struct {\n\tName string\n}\n",
+		"pkg/identity/models/a.go": "package models\n\ntype SUser struct {\n\tName string\n}\n",
+	}
+	for name, content := range files {
+		file := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	g, ca := NewDefaults()
+	ca.WithMetadataFields = true
+	g.InputDirs = []string{"yunion.io/x/svc/pkg/compute/models", "yunion.io/x/svc/pkg/identity/models", "yunion.io/x/svc/pkg/image/models"}
+	g.OutputBase = filepath.Join(dir, "out")
+	g.OutputPackagePath = "yunion.io/x/svc/pkg/apis"
+	g.GoHeaderFilePath = filepath.Join(dir, "boilerplate.go.txt")
+	la := &common.LoaderArgs{Modules: []string{dir}, OutputFileTemplate: "{{.Base}}.{{.Service}}"}
+	generate := func(g *common.GeneratorArgs, la *common.LoaderArgs) error {
+		return common.Execute(g, la, NameSystems(), DefaultNameSystem(), Packages)
+	}
+	// declarations of ResourceTag in the output package
+	declared := func() map[string]int {
+		ret := make(map[string]int)
+		outDir := filepath.Join(g.OutputBase, g.OutputPackagePath)
+		infos, err := ioutil.ReadDir(outDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, info := range infos {
+			content, err := ioutil.ReadFile(filepath.Join(outDir, info.Name()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := strings.Count(string(content), "type ResourceTag struct"); n != 0 {
+				ret[info.Name()] = n
+			}
+		}
+		return ret
+	}
+	want := map[string]int{"zz_generated.model_resourcetag.go": 1}
+
+	if err := generate(g, la); err != nil {
+		t.Fatalf("full run error: %v", err)
+	}
+	if got := declared(); !reflect.DeepEqual(got, want) {
+		t.Errorf("full run declares ResourceTag in %v, want %v", got, want)
+	}
+	// regeneration of a changed package other than the first one, like --watch
+	if err := common.RegenerateChanged(g, la, generate)([]string{"yunion.io/x/svc/pkg/image/models"}); err != nil {
+		t.Fatalf("partial run error: %v", err)
+	}
+	if got := declared(); !reflect.DeepEqual(got, want) {
+		t.Errorf("partial run declares ResourceTag in %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,96 @@
+package generators
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/gengo/v2/types"
+
+	"yunion.io/x/code-generator/pkg/common"
+	"yunion.io/x/code-generator/pkg/models/registry"
+	"yunion.io/x/code-generator/pkg/onecloudshim"
+)
+
+// tableColumns are the table columns of model keyed by column name
+type tableColumns map[string]onecloudshim.Column
+
+// columnIndex is the table columns of models and their embedded bases, keyed by type string,
+// a base shares the columns of any model embedding it since sqlchemy builds them from the same fields
+type columnIndex map[string]tableColumns
+
+// newColumnIndex returns the table columns of the structs in pkgTypes whose managers are registered
+func newColumnIndex(pkgTypes []*types.Type) columnIndex {
+	idx := make(columnIndex)
+	for _, t := range pkgTypes {
+		if t.Kind != types.Struct || strings.HasSuffix(t.Name.Name, "Manager") {
+			continue
+		}
+		man := registry.Get(t.String() + "Manager")
+		if man == nil {
+			continue
+		}
+		cols := make(tableColumns)
+		for _, col := range onecloudshim.TableColumns(man) {
+			cols[col.Name] = col
+		}
+		idx.add(t, cols)
+	}
+	return idx
+}
+
+// add records cols of model t and of the structs embedded by it, the first recorded ones are kept
+func (idx columnIndex) add(t *types.Type, cols tableColumns) {
+	if _, ok := idx[t.String()]; ok {
+		return
+	}
+	idx[t.String()] = cols
+	for _, m := range t.Members {
+		if m.Embedded && m.Type.Kind == types.Struct {
+			idx.add(m.Type, cols)
+		}
+	}
+}
+
+// columnCommentLines returns go-swagger annotations of the sqlchemy column of member in table cols, e.g.
+// a column of width 36, charset ascii, not null, default ready and indexed is documented as:
+//
+//	max length: 36
+//	default: ready
+//	Extensions:
+//	  x-nullable: false
+//	  x-charset: ascii
+//	  x-filterable: true
+//	  x-sortable: true
+//
+// all columns are usable by order_by of list requests, the searchable, indexed or primary ones by filter.
+// Members without column, e.g. of structs which aren't table models, are not documented
+func columnCommentLines(member types.Member, cols tableColumns) []string {
+	if member.Embedded {
+		return nil
+	}
+	col, ok := cols[common.MemberJSONName(member)]
+	if !ok {
+		return nil
+	}
+	ret := make([]string, 0)
+	if col.Width > 0 {
+		ret = append(ret, fmt.Sprintf("max length: %d", col.Width))
+	}
+	if col.Default != "" {
+		ret = append(ret, fmt.Sprintf("default: %s", col.Default))
+	}
+	exts := make([]string, 0)
+	if col.NotNull {
+		exts = append(exts, "  x-nullable: false")
+	}
+	if col.Charset != "" {
+		exts = append(exts, fmt.Sprintf("  x-charset: %s", col.Charset))
+	}
+	if col.Filterable {
+		exts = append(exts, "  x-filterable: true")
+	}
+	exts = append(exts, "  x-sortable: true")
+	// go-swagger reads extensions till the end of comment
+	ret = append(ret, "Extensions:")
+	return append(ret, exts...)
+}
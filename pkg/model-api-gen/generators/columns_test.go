@@ -0,0 +1,149 @@
+package generators
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/types"
+
+	"yunion.io/x/code-generator/pkg/models/registry"
+	"yunion.io/x/code-generator/pkg/models/registry/testdata/fakemodels"
+)
+
+func Test_columnCommentLines(t *testing.T) {
+	str := &types.Type{Name: types.Name{Name: "string"}, Kind: types.Builtin}
+	cols := tableColumns{
+		"status":     {Name: "status", Width: 36, Default: "ready", NotNull: true, Charset: "ascii", Filterable: true},
+		"vcpu_count": {Name: "vcpu_count", Default: "1"},
+		"ext_id":     {Name: "ext_id"},
+	}
+	tests := []struct {
+		name   string
+		member types.Member
+		want   []string
+	}{
+		{
+			name:   "text column",
+			member: types.Member{Name: "Status", Type: str},
+			want:   []string{"max length: 36", "default: ready", "Extensions:", "  x-nullable: false", "  x-charset: ascii", "  x-filterable: true", "  x-sortable: true"},
+		},
+		{
+			name:   "int column",
+			member: types.Member{Name: "VcpuCount", Type: types.Int},
+			want:   []string{"default: 1", "Extensions:", "  x-sortable: true"},
+		},
+		{
+			name:   "column named by json tag",
+			member: types.Member{Name: "ExternalId", Type: str, Tags: `json:"ext_id"`},
+			want:   []string{"Extensions:", "  x-sortable: true"},
+		},
+		{
+			name:   "not column",
+			member: types.Member{Name: "Name", Type: str, Tags: `width:"36"`},
+			want:   nil,
+		},
+		{
+			name:   "embedded",
+			member: types.Member{Name: "SStandaloneResourceBase", Type: &types.Type{Kind: types.Struct}, Embedded: true},
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := columnCommentLines(tt.member, cols); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("columnCommentLines() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_newColumnIndex(t *testing.T) {
+	fakemodels.Register()
+	defer registry.Reset()
+	pkg := "yunion.io/x/code-generator/pkg/models/registry/testdata/fakemodels"
+	base := &types.Type{Name: types.Name{Package: "db", Name: "SStatusStandaloneResourceBase"}, Kind: types.Struct}
+	guest := &types.Type{
+		Name:    types.Name{Package: pkg, Name: "SGuest"},
+		Kind:    types.Struct,
+		Members: []types.Member{{Name: "SStatusStandaloneResourceBase", Type: base, Embedded: true}},
+	}
+	// the disk manager has no table spec
+	disk := &types.Type{Name: types.Name{Package: pkg, Name: "SDisk"}, Kind: types.Struct}
+	other := &types.Type{Name: types.Name{Package: pkg, Name: "SHost"}, Kind: types.Struct}
+	idx := newColumnIndex([]*types.Type{guest, disk, other})
+	want := tableColumns{
+		"id":          {Name: "id", NotNull: true},
+		"name":        {Name: "name", NotNull: true},
+		"description": {Name: "description"},
+		"status":      {Name: "status", NotNull: true},
+	}
+	for _, typ := range []*types.Type{guest, base} {
+		if got := idx[typ.String()]; !reflect.DeepEqual(got, want) {
+			t.Errorf("columns of %s = %+v, want %+v", typ, got, want)
+		}
+	}
+	if got := idx[disk.String()]; len(got) != 0 {
+		t.Errorf("columns of %s without table spec = %+v, want none", disk, got)
+	}
+	if got, ok := idx[other.String()]; ok {
+		t.Errorf("columns of %s without manager = %+v, want none", other, got)
+	}
+}
+
+func Test_emitMemberNullable(t *testing.T) {
+	str := types.String
+	ptr := &types.Type{Kind: types.Pointer, Elem: str}
+	tests := []struct {
+		name   string
+		args   CustomArgs
+		member types.Member
+		want   string
+	}{
+		{
+			name:   "pointer",
+			args:   CustomArgs{NullablePointers: true},
+			member: types.Member{Name: "Description", Type: ptr},
+			want:   "// Extensions:\n//   x-nullable: true\nDescription *string `json:\"description\"`\n",
+		},
+		{
+			name:   "not null column wins",
+			args:   CustomArgs{NullablePointers: true, ColumnDocs: true},
+			member: types.Member{Name: "Description", Type: ptr},
+			want:   "// Extensions:\n//   x-nullable: false\n//   x-charset: utf8\n//   x-sortable: true\nDescription *string `json:\"description\"`\n",
+		},
+		{
+			name:   "not pointer",
+			args:   CustomArgs{NullablePointers: true},
+			member: types.Member{Name: "Description", Type: str},
+			want:   "Description string `json:\"description\"`\n",
+		},
+		{
+			name:   "secret",
+			args:   CustomArgs{NullablePointers: true},
+			member: types.Member{Name: "Password", Type: ptr, CommentLines: []string{"+onecloud:model-api-gen-secret"}},
+			want:   "// swagger:strfmt password\n// Extensions:\n//   x-writeOnly: true\n//   x-nullable: true\nPassword *string `json:\"password,omitempty\"`\n",
+		},
+		{
+			name:   "not enabled",
+			member: types.Member{Name: "Description", Type: ptr},
+			want:   "Description *string `json:\"description\"`\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &apiGen{customArgs: &tt.args, columns: tableColumns{"description": {Name: "description", NotNull: true, Charset: "utf8"}}}
+			buf := &bytes.Buffer{}
+			sw := generator.NewSnippetWriter(buf, &generator.Context{}, "$", "$")
+			expr := "string"
+			if tt.member.Type.Kind == types.Pointer {
+				expr = "*string"
+			}
+			g.emitMember(tt.member, NewModelMember(tt.member.Name, nil).Type(expr), sw, nil)
+			if buf.String() != tt.want {
+				t.Errorf("emitMember() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
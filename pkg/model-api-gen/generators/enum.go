@@ -0,0 +1,194 @@
+package generators
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/types"
+	"k8s.io/klog/v2"
+
+	"yunion.io/x/code-generator/pkg/common"
+)
+
+const (
+	// tagEnumName mark a const group as an enum, e.g.:
+	// +onecloud:model-api-gen-enum=GuestStatus
+	tagEnumName = "onecloud:model-api-gen-enum"
+	// structTagChoices is the column struct tag listing allowed values, e.g.:
+	// Status string `choices:"ready|running"`
+	structTagChoices = "choices"
+)
+
+var _ = common.RegisterAnnotations(common.Annotation{
+	Name:        tagEnumName,
+	Generator:   generatorName,
+	Syntax:      "<TypeName>",
+	Targets:     []string{common.TargetConst},
+	Description: "generate the const group as enum type of the name",
+})
+
+type enumConst struct {
+	name  string
+	value string
+}
+
+type enumType struct {
+	name   string
+	source string
+	consts []enumConst
+}
+
+func extractEnumTag(comments []string) []string {
+	return common.ParseTags(comments).Values(tagEnumName)
+}
+
+// emptyConstSuffix names the const of empty value, which would be named as the enum type itself
+const emptyConstSuffix = "Empty"
+
+func enumConstName(prefix, value string) string {
+	parts := strings.FieldsFunc(value, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	})
+	for i, p := range parts {
+		parts[i] = strings.Title(p)
+	}
+	if len(parts) == 0 {
+		return prefix + emptyConstSuffix
+	}
+	return prefix + strings.Join(parts, "")
+}
+
+// add appends const of value named by enum name, value whose const name is taken by another value,
+// e.g. start-fail and start_fail, is skipped
+func (e *enumType) add(value string) {
+	name := enumConstName(e.name, value)
+	for _, c := range e.consts {
+		if c.name == name {
+			klog.Warningf("enum %s value %q is skipped, const %s is taken by %q", e.source, value, name, c.value)
+			return
+		}
+	}
+	e.consts = append(e.consts, enumConst{name: name, value: value})
+}
+
+// newMemberChoicesEnum return enum defined by member's choices struct tag
+func newMemberChoicesEnum(typeName string, m types.Member) *enumType {
+	choices := reflect.StructTag(m.Tags).Get(structTagChoices)
+	if choices == "" {
+		return nil
+	}
+	enum := &enumType{
+		name:   typeName + m.Name,
+		source: fmt.Sprintf("%s.%s", typeName, m.Name),
+	}
+	for _, val := range strings.Split(choices, "|") {
+		enum.add(val)
+	}
+	return enum
+}
+
+// collectConstEnums parse source files of pkg selected by build tags and find const groups marked by tagEnumName.
+// gengo doesn't record constant values, so the literals are read from ast directly.
+func collectConstEnums(pkg *types.Package, tags []string) []*enumType {
+	if pkg == nil || pkg.Dir == "" {
+		return nil
+	}
+	ctx := build.Default
+	ctx.BuildTags = tags
+	// same as gengo parser, cgo is not supported
+	ctx.CgoEnabled = false
+	bp, err := ctx.ImportDir(pkg.Dir, 0)
+	if err != nil {
+		klog.Warningf("find source files of package %s for enums: %v", pkg.Path, err)
+		return nil
+	}
+	fset := token.NewFileSet()
+	enums := make([]*enumType, 0)
+	for _, name := range bp.GoFiles {
+		f, err := parser.ParseFile(fset, filepath.Join(bp.Dir, name), nil, parser.ParseComments)
+		if err != nil {
+			klog.Warningf("parse %s of package %s for enums: %v", name, pkg.Path, err)
+			continue
+		}
+		for _, decl := range f.Decls {
+			if enum := newConstDeclEnum(pkg.Path, decl); enum != nil {
+				enums = append(enums, enum)
+			}
+		}
+	}
+	return enums
+}
+
+// newConstDeclEnum return enum defined by const group marked by tagEnumName, consts are named by
+// enum name and value like choices enums, e.g. GuestStatusReady of VM_READY, source names like VM_READY
+// are usually declared in apis package already
+func newConstDeclEnum(pkgPath string, decl ast.Decl) *enumType {
+	gd, ok := decl.(*ast.GenDecl)
+	if !ok || gd.Tok != token.CONST || gd.Doc == nil {
+		return nil
+	}
+	comments := make([]string, 0)
+	for _, c := range gd.Doc.List {
+		comments = append(comments, strings.TrimSpace(strings.TrimPrefix(c.Text, "//")))
+	}
+	vals := extractEnumTag(comments)
+	if len(vals) == 0 || vals[0] == "" {
+		return nil
+	}
+	enum := &enumType{
+		name:   vals[0],
+		source: fmt.Sprintf("%s.%s", pkgPath, vals[0]),
+	}
+	for _, spec := range gd.Specs {
+		vs := spec.(*ast.ValueSpec)
+		for i, n := range vs.Names {
+			if i >= len(vs.Values) || !n.IsExported() {
+				continue
+			}
+			lit, ok := vs.Values[i].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				continue
+			}
+			val, err := strconv.Unquote(lit.Value)
+			if err != nil {
+				continue
+			}
+			enum.add(val)
+		}
+	}
+	if len(enum.consts) == 0 {
+		klog.Warningf("enum %s has no exported string constants", enum.source)
+		return nil
+	}
+	return enum
+}
+
+func (e *enumType) Do(sw *generator.SnippetWriter) {
+	sw.Do(fmt.Sprintf("// %s is an autogenerated enum via %s.\n", e.name, e.source), nil)
+	sw.Do(fmt.Sprintf("type %s string\n\n", e.name), nil)
+	sw.Do("const (\n", nil)
+	for _, c := range e.consts {
+		sw.Do(fmt.Sprintf("%s %s = %q\n", c.name, e.name, c.value), nil)
+	}
+	sw.Do(")\n\n", nil)
+	names := make([]string, 0, len(e.consts))
+	for _, c := range e.consts {
+		names = append(names, c.name)
+	}
+	sw.Do(fmt.Sprintf("// IsValid reports whether v is one of the %s constants.\n", e.name), nil)
+	sw.Do(fmt.Sprintf("func (v %s) IsValid() bool {\n", e.name), nil)
+	sw.Do("switch v {\n", nil)
+	sw.Do(fmt.Sprintf("case %s:\n", strings.Join(names, ", ")), nil)
+	sw.Do("return true\n", nil)
+	sw.Do("}\n", nil)
+	sw.Do("return false\n", nil)
+	sw.Do("}\n\n", nil)
+}
@@ -0,0 +1,155 @@
+package generators
+
+import (
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"k8s.io/gengo/v2/types"
+)
+
+func Test_newMemberChoicesEnum(t *testing.T) {
+	tests := []struct {
+		name   string
+		member types.Member
+		want   *enumType
+	}{
+		{
+			name: "choices tag",
+			member: types.Member{
+				Name: "Status",
+				Tags: `json:"status" choices:"ready|running|start_fail"`,
+			},
+			want: &enumType{
+				name:   "SGuestStatus",
+				source: "SGuest.Status",
+				consts: []enumConst{
+					{"SGuestStatusReady", "ready"},
+					{"SGuestStatusRunning", "running"},
+					{"SGuestStatusStartFail", "start_fail"},
+				},
+			},
+		},
+		{
+			name: "empty choice",
+			member: types.Member{
+				Name: "Hypervisor",
+				Tags: `json:"hypervisor" choices:"|kvm"`,
+			},
+			want: &enumType{
+				name:   "SGuestHypervisor",
+				source: "SGuest.Hypervisor",
+				consts: []enumConst{
+					{"SGuestHypervisorEmpty", ""},
+					{"SGuestHypervisorKvm", "kvm"},
+				},
+			},
+		},
+		{
+			name: "no choices tag",
+			member: types.Member{
+				Name: "Name",
+				Tags: `json:"name"`,
+			},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newMemberChoicesEnum("SGuest", tt.member); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newMemberChoicesEnum() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_newConstDeclEnum(t *testing.T) {
+	src := `package models
+
+// +onecloud:model-api-gen-enum=GuestStatus
+const (
+	VM_READY        = "ready"
+	VM_START_FAIL   = "start_fail"
+	VM_START_FAILED = "start-fail"
+	vmHidden        = "hidden"
+)
+`
+	f, err := parser.ParseFile(token.NewFileSet(), "guests.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("parse source: %v", err)
+	}
+	got := newConstDeclEnum("yunion.io/x/onecloud/pkg/compute/models", f.Decls[0])
+	want := &enumType{
+		name:   "GuestStatus",
+		source: "yunion.io/x/onecloud/pkg/compute/models.GuestStatus",
+		consts: []enumConst{
+			{"GuestStatusReady", "ready"},
+			{"GuestStatusStartFail", "start_fail"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("newConstDeclEnum() = %v, want %v", got, want)
+	}
+}
+
+func Test_collectConstEnums(t *testing.T) {
+	dir, err := ioutil.TempDir("", "enums")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"guests.go": `package models
+
+// +onecloud:model-api-gen-enum=GuestStatus
+const (
+	VM_READY = "ready"
+)
+`,
+		"guests_feature.go": `//go:build feature_a
+
+package models
+
+// +onecloud:model-api-gen-enum=GuestFeature
+const (
+	FEATURE_A = "a"
+)
+`,
+		"guests_test.go": `package models
+
+// +onecloud:model-api-gen-enum=GuestTest
+const (
+	TEST_VALUE = "test"
+)
+`,
+	}
+	for name, src := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pkg := &types.Package{Path: "example.com/models", Dir: dir, Name: "models"}
+	tests := []struct {
+		name string
+		tags []string
+		want []string
+	}{
+		{name: "default", want: []string{"GuestStatus"}},
+		{name: "build tag", tags: []string{"feature_a"}, want: []string{"GuestStatus", "GuestFeature"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, enum := range collectConstEnums(pkg, tt.tags) {
+				got = append(got, enum.name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("collectConstEnums() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package generators
+
+import (
+	"fmt"
+	"go/token"
+	"regexp"
+	"strings"
+
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/types"
+	"k8s.io/klog/v2"
+
+	"yunion.io/x/pkg/utils"
+
+	"yunion.io/x/code-generator/pkg/common"
+)
+
+const (
+	// tagEventsName lists message bus events of resource, envelope types are generated with --event-types, e.g.:
+	// +onecloud:model-api-gen-events=created,updated,deleted
+	tagEventsName = "onecloud:model-api-gen-events"
+	// tagEventResourceName names the resource of events, payload is <Resource>Details declared in output package, e.g.:
+	// +onecloud:model-api-gen-event-resource=Server
+	tagEventResourceName = "onecloud:model-api-gen-event-resource"
+)
+
+var _ = common.RegisterAnnotations(
+	common.Annotation{
+		Name:        tagEventsName,
+		Generator:   generatorName,
+		Syntax:      "<event>[,<event>...]",
+		Targets:     []string{common.TargetModel},
+		Description: "message bus events of the resource, envelope types are generated with --event-types",
+	},
+	common.Annotation{
+		Name:        tagEventResourceName,
+		Generator:   generatorName,
+		Syntax:      "<Resource>",
+		Targets:     []string{common.TargetModel},
+		Description: "resource name of events, payload is <Resource>Details",
+	},
+)
+
+var eventNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// eventEnvelope is the payload of resource event, e.g. ServerCreatedEvent{Server ServerDetails}
+type eventEnvelope struct {
+	name     string
+	source   string
+	resource string
+	event    string
+}
+
+func (e eventEnvelope) payload() string {
+	return e.resource + "Details"
+}
+
+// payloadType returns the payload type of e declared in output package outPkg of universe u, nil if not found
+func (e eventEnvelope) payloadType(u types.Universe, outPkg string) *types.Type {
+	pkg, ok := u[outPkg]
+	if !ok {
+		return nil
+	}
+	return pkg.Types[e.payload()]
+}
+
+func (e eventEnvelope) key() string {
+	return utils.CamelSplit(e.resource, "_")
+}
+
+// eventResource returns resource name of events, the tagged one or type name without S prefix
+func eventResource(t *types.Type, typeName string) string {
+	if vals := common.ParseTags(t.CommentLines).Values(tagEventResourceName); len(vals) != 0 {
+		if token.IsIdentifier(vals[0]) && token.IsExported(vals[0]) {
+			return vals[0]
+		}
+		klog.Errorf("invalid tag %s=%s of %s", tagEventResourceName, vals[0], t.String())
+	}
+	name, _ := trimTypePrefix(typeName, "S")
+	return name
+}
+
+// modelEvents returns event envelopes of t tagged by tagEventsName, typeName is the generated name of t
+func modelEvents(t *types.Type, typeName string) []eventEnvelope {
+	vals := common.ParseTags(t.CommentLines).Values(tagEventsName)
+	if len(vals) == 0 {
+		return nil
+	}
+	resource := eventResource(t, typeName)
+	ret := make([]eventEnvelope, 0)
+	for _, val := range vals {
+		for _, event := range strings.Split(val, ",") {
+			event = strings.TrimSpace(event)
+			if !eventNameRegexp.MatchString(event) {
+				klog.Errorf("invalid tag %s=%s of %s", tagEventsName, val, t.String())
+				continue
+			}
+			ret = append(ret, eventEnvelope{
+				name:     enumConstName(resource, event) + "Event",
+				source:   t.Name.String(),
+				resource: resource,
+				event:    event,
+			})
+		}
+	}
+	return ret
+}
+
+func (e eventEnvelope) Do(sw *generator.SnippetWriter) {
+	sw.Do(fmt.Sprintf("// %s is an autogenerated message bus payload of %s event via %s.\n", e.name, e.event, e.source), nil)
+	sw.Do(fmt.Sprintf("type %s struct {\n", e.name), nil)
+	sw.Do(fmt.Sprintf("%s %s `json:\"%s\"`\n", e.resource, e.payload(), e.key()), nil)
+	sw.Do("}\n\n", nil)
+	sw.Do(fmt.Sprintf("// EventName returns the name of %s.\n", e.name), nil)
+	sw.Do(fmt.Sprintf("func (e %s) EventName() string {\n", e.name), nil)
+	sw.Do(fmt.Sprintf("return %q\n", e.key()+"."+e.event), nil)
+	sw.Do("}\n\n", nil)
+}
+
+// generateEvents emits event envelopes of t if --event-types,
+// events whose payload isn't declared in output package are skipped
+func (g *apiGen) generateEvents(t *types.Type, sw *generator.SnippetWriter) {
+	if !g.customArgs.EventTypes {
+		return
+	}
+	for _, e := range modelEvents(t, g.typeName(t)) {
+		if e.payloadType(g.universe, g.customArgs.outputPackage) == nil {
+			klog.Warningf("skip event %s of %s, payload type %s is not found in %s", e.name, t.String(), e.payload(), g.customArgs.outputPackage)
+			continue
+		}
+		e.Do(sw)
+	}
+}
@@ -0,0 +1,89 @@
+package generators
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/types"
+)
+
+func Test_modelEvents(t *testing.T) {
+	const srcPkg = "yunion.io/x/onecloud/pkg/compute/models"
+	tests := []struct {
+		name     string
+		comments []string
+		want     []string
+	}{
+		{
+			name: "untagged",
+		},
+		{
+			name:     "default resource",
+			comments: []string{"+onecloud:model-api-gen-events=created,status_changed"},
+			want:     []string{"GuestCreatedEvent", "GuestStatusChangedEvent"},
+		},
+		{
+			name: "tagged resource",
+			comments: []string{
+				"+onecloud:model-api-gen-events=deleted, Bad",
+				"+onecloud:model-api-gen-event-resource=Server",
+			},
+			want: []string{"ServerDeletedEvent"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typ := &types.Type{Name: types.Name{Package: srcPkg, Name: "SGuest"}, Kind: types.Struct, CommentLines: tt.comments}
+			var got []string
+			for _, e := range modelEvents(typ, "SGuest") {
+				got = append(got, e.name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("modelEvents() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_eventEnvelopeDo(t *testing.T) {
+	e := eventEnvelope{name: "ServerCreatedEvent", source: "models.SGuest", resource: "Server", event: "created"}
+	buf := &bytes.Buffer{}
+	sw := generator.NewSnippetWriter(buf, &generator.Context{}, "$", "$")
+	e.Do(sw)
+	for _, want := range []string{
+		"type ServerCreatedEvent struct {\nServer ServerDetails `json:\"server\"`\n}\n",
+		"func (e ServerCreatedEvent) EventName() string {\nreturn \"server.created\"\n}\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("eventEnvelope.Do() = %q, should contain %q", buf.String(), want)
+		}
+	}
+}
+
+func Test_generateEventsPayload(t *testing.T) {
+	const outPkg = "yunion.io/x/onecloud/pkg/apis/compute"
+	u := types.Universe{}
+	u.Type(types.Name{Package: outPkg, Name: "ServerDetails"}).Kind = types.Struct
+	g := &apiGen{customArgs: &CustomArgs{EventTypes: true, outputPackage: outPkg}, universe: u}
+	buf := &bytes.Buffer{}
+	sw := generator.NewSnippetWriter(buf, &generator.Context{}, "$", "$")
+	for _, resource := range []string{"Server", "Disk"} {
+		g.generateEvents(&types.Type{
+			Name:         types.Name{Package: "yunion.io/x/onecloud/pkg/compute/models", Name: "SGuest"},
+			Kind:         types.Struct,
+			CommentLines: []string{"+onecloud:model-api-gen-events=created", "+onecloud:model-api-gen-event-resource=" + resource},
+		}, sw)
+	}
+	if err := sw.Error(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "type ServerCreatedEvent struct {") {
+		t.Errorf("generateEvents() = %q, should contain ServerCreatedEvent", buf.String())
+	}
+	if strings.Contains(buf.String(), "DiskCreatedEvent") {
+		t.Errorf("generateEvents() = %q, should skip DiskCreatedEvent without DiskDetails", buf.String())
+	}
+}
@@ -0,0 +1,64 @@
+package generators
+
+import (
+	"strings"
+
+	"k8s.io/gengo/v2/types"
+	"k8s.io/klog/v2"
+
+	"yunion.io/x/pkg/utils"
+
+	"yunion.io/x/code-generator/pkg/common"
+)
+
+const (
+	// tagFlattenName controls whether struct member is embedded or nested under json key, e.g.:
+	// +onecloud:model-api-gen-flatten=false nests embedded member under its snake case name
+	// +onecloud:model-api-gen-flatten=key=config nests member under json key config
+	// +onecloud:model-api-gen-flatten=true embeds named member
+	tagFlattenName = "onecloud:model-api-gen-flatten"
+)
+
+var _ = common.RegisterAnnotations(common.Annotation{
+	Name:        tagFlattenName,
+	Generator:   generatorName,
+	Syntax:      "true|false|key=<json key>",
+	Targets:     []string{common.TargetField},
+	Description: "embed the member, or nest it under its snake case name or the json key",
+})
+
+// memberFlatten returns whether struct member is embedded, key is the json key of nested member,
+// empty key means the default one
+func memberFlatten(m types.Member) (embed bool, key string) {
+	vals := common.ParseTags(m.CommentLines).Values(tagFlattenName)
+	if len(vals) == 0 {
+		return m.Embedded, ""
+	}
+	val := vals[0]
+	switch {
+	case val == "true":
+		return true, ""
+	case val == "false":
+		return false, utils.CamelSplit(m.Name, "_")
+	case strings.HasPrefix(val, "key=") && len(val) > len("key="):
+		return false, strings.TrimPrefix(val, "key=")
+	default:
+		klog.Errorf("invalid tag %s=%s of member %s", tagFlattenName, val, m.Name)
+		return m.Embedded, ""
+	}
+}
+
+// orderedMembers returns members of t in source declaration order with the embedded ones first,
+// so generated struct diffs follow the source struct
+func orderedMembers(t *types.Type) []types.Member {
+	embedded := make([]types.Member, 0)
+	named := make([]types.Member, 0, len(t.Members))
+	for _, m := range t.Members {
+		if embed, _ := memberFlatten(m); embed {
+			embedded = append(embedded, m)
+		} else {
+			named = append(named, m)
+		}
+	}
+	return append(embedded, named...)
+}
@@ -0,0 +1,80 @@
+package generators
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/gengo/v2/types"
+)
+
+func Test_memberFlatten(t *testing.T) {
+	base := &types.Type{Name: types.Name{Name: "SCloudregionResourceBase"}, Kind: types.Struct}
+	tests := []struct {
+		name      string
+		member    types.Member
+		wantEmbed bool
+		wantKey   string
+	}{
+		{
+			name:      "embedded without tag",
+			member:    types.Member{Name: "SCloudregionResourceBase", Embedded: true, Type: base},
+			wantEmbed: true,
+		},
+		{
+			name: "embedded nested by default key",
+			member: types.Member{Name: "CloudregionResource", Embedded: true, Type: base,
+				CommentLines: []string{"+onecloud:model-api-gen-flatten=false"}},
+			wantKey: "cloudregion_resource",
+		},
+		{
+			name: "nested by key",
+			member: types.Member{Name: "Region", Type: base,
+				CommentLines: []string{"+onecloud:model-api-gen-flatten=key=region_info"}},
+			wantKey: "region_info",
+		},
+		{
+			name: "named member embedded",
+			member: types.Member{Name: "Region", Type: base,
+				CommentLines: []string{"+onecloud:model-api-gen-flatten=true"}},
+			wantEmbed: true,
+		},
+		{
+			name: "invalid tag",
+			member: types.Member{Name: "Region", Type: base,
+				CommentLines: []string{"+onecloud:model-api-gen-flatten=key="}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			embed, key := memberFlatten(tt.member)
+			if embed != tt.wantEmbed || key != tt.wantKey {
+				t.Errorf("memberFlatten() = %v, %q, want %v, %q", embed, key, tt.wantEmbed, tt.wantKey)
+			}
+		})
+	}
+}
+
+func Test_orderedMembers(t *testing.T) {
+	base := &types.Type{Name: types.Name{Name: "SCloudregionResourceBase"}, Kind: types.Struct}
+	guest := &types.Type{
+		Name: types.Name{Name: "SGuest"},
+		Kind: types.Struct,
+		Members: []types.Member{
+			{Name: "VcpuCount", Type: types.Int},
+			{Name: "SVirtualResourceBase", Embedded: true, Type: base},
+			{Name: "VmemSize", Type: types.Int},
+			{Name: "Region", Type: base, CommentLines: []string{"+onecloud:model-api-gen-flatten=true"}},
+			{Name: "SBillingResourceBase", Embedded: true, Type: &types.Type{Kind: types.Pointer, Elem: base}},
+			{Name: "Zone", Embedded: true, Type: base, CommentLines: []string{"+onecloud:model-api-gen-flatten=false"}},
+			{Name: "Hypervisor", Type: types.String},
+		},
+	}
+	got := make([]string, 0)
+	for _, m := range orderedMembers(guest) {
+		got = append(got, m.Name)
+	}
+	want := []string{"SVirtualResourceBase", "Region", "SBillingResourceBase", "VcpuCount", "VmemSize", "Zone", "Hypervisor"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("orderedMembers() = %v, want %v", got, want)
+	}
+}
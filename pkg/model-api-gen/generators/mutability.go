@@ -0,0 +1,116 @@
+package generators
+
+import (
+	"path"
+
+	"k8s.io/gengo/v2/types"
+
+	"yunion.io/x/pkg/util/sets"
+
+	"yunion.io/x/code-generator/pkg/common"
+)
+
+const (
+	// extMutability documents whether a model field is settable by create or update input
+	extMutability = "x-mutability"
+
+	// MutabilityCreateOnly is field settable by create input only, e.g. backend of disk
+	MutabilityCreateOnly = "create-only"
+	// MutabilityUpdateOnly is field settable by update input only
+	MutabilityUpdateOnly = "update-only"
+	// MutabilityImmutable is field settable by neither create nor update input
+	MutabilityImmutable = "immutable"
+
+	validateCreateData = "ValidateCreateData"
+	validateUpdateData = "ValidateUpdateData"
+)
+
+// mutabilityInputs are the json fields of create and update inputs of a model,
+// create or update is nil if the model is not created or updated
+type mutabilityInputs struct {
+	create sets.String
+	update sets.String
+}
+
+// mutabilityIndex is the inputs of models keyed by type string
+type mutabilityIndex map[string]*mutabilityInputs
+
+// newMutabilityIndex returns inputs of the models in pkgTypes by ValidateCreateData of their managers
+// and their ValidateUpdateData, models having any of them without typed input, e.g. *jsonutils.JSONDict,
+// whose fields are unknown, are not indexed
+func newMutabilityIndex(pkgTypes []*types.Type) mutabilityIndex {
+	byName := make(map[string]*types.Type)
+	for _, t := range pkgTypes {
+		byName[t.Name.Name] = t
+	}
+	idx := make(mutabilityIndex)
+	for _, t := range pkgTypes {
+		if t.Kind != types.Struct {
+			continue
+		}
+		inputs := &mutabilityInputs{}
+		if man, ok := byName[t.Name.Name+"Manager"]; ok {
+			if m, ok := man.Methods[validateCreateData]; ok {
+				if inputs.create = inputFields(m); inputs.create == nil {
+					continue
+				}
+			}
+		}
+		if m, ok := t.Methods[validateUpdateData]; ok {
+			if inputs.update = inputFields(m); inputs.update == nil {
+				continue
+			}
+		}
+		if inputs.create == nil && inputs.update == nil {
+			continue
+		}
+		idx[t.String()] = inputs
+	}
+	return idx
+}
+
+// inputFields returns json fields of the typed input of ValidateCreateData or ValidateUpdateData method m,
+// which is its last parameter, or the validated result of variants taking *jsonutils.JSONDict, nil if none is typed
+func inputFields(m *types.Type) sets.String {
+	if m.Signature == nil {
+		return nil
+	}
+	candidates := make([]*types.Type, 0, 2)
+	if params := m.Signature.Parameters; len(params) != 0 && !m.Signature.Variadic {
+		candidates = append(candidates, params[len(params)-1].Type)
+	}
+	if results := m.Signature.Results; len(results) != 0 {
+		candidates = append(candidates, results[0].Type)
+	}
+	for _, t := range candidates {
+		if t.Kind == types.Pointer {
+			t = t.Elem
+		}
+		if t.Kind == types.Struct && path.Base(t.Name.Package) != "jsonutils" {
+			return common.JSONFields(t)
+		}
+	}
+	return nil
+}
+
+// mutability returns mutability of member by inputs, empty if member is settable by both inputs
+// or inputs are unknown, embedded members are documented by their own fields
+func (inputs *mutabilityInputs) mutability(member types.Member) string {
+	if inputs == nil || member.Embedded {
+		return ""
+	}
+	name := common.MemberJSONName(member)
+	if name == "-" {
+		return ""
+	}
+	create, update := inputs.create.Has(name), inputs.update.Has(name)
+	switch {
+	case create && !update:
+		return MutabilityCreateOnly
+	case update && !create:
+		return MutabilityUpdateOnly
+	case !create && !update:
+		return MutabilityImmutable
+	}
+	return ""
+}
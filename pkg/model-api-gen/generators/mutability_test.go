@@ -0,0 +1,76 @@
+package generators
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/types"
+
+	"yunion.io/x/pkg/util/sets"
+
+	"yunion.io/x/code-generator/pkg/common/testdata/faketypes"
+)
+
+func Test_newMutabilityIndex(t *testing.T) {
+	newStruct := func(pkg, name string, fields ...string) *types.Type {
+		ret := &types.Type{Name: types.Name{Package: pkg, Name: name}, Kind: types.Struct}
+		for _, f := range fields {
+			ret.Members = append(ret.Members, types.Member{Name: f, Type: types.String})
+		}
+		return ret
+	}
+	method := func(params ...*types.Type) *types.Type {
+		return &types.Type{Kind: types.Func, Signature: &types.Signature{Parameters: faketypes.ParamResults(params...), Results: faketypes.ParamResults(params[len(params)-1])}}
+	}
+	apis, models := "yunion.io/x/onecloud/pkg/apis/compute", "yunion.io/x/onecloud/pkg/compute/models"
+	create := newStruct(apis, "DiskCreateInput", "Name", "Description", "Backend")
+	update := newStruct(apis, "DiskUpdateInput", "Name", "Description", "AutoDelete")
+	dict := &types.Type{Name: types.Name{Package: "yunion.io/x/jsonutils", Name: "JSONDict"}, Kind: types.Struct}
+
+	disk := newStruct(models, "SDisk", "Id", "Name", "Description", "Backend", "AutoDelete", "Status")
+	disk.Methods = map[string]*types.Type{validateUpdateData: method(types.String, update)}
+	diskMan := newStruct(models, "SDiskManager")
+	diskMan.Methods = map[string]*types.Type{validateCreateData: method(types.String, &types.Type{Kind: types.Pointer, Elem: create})}
+	host := newStruct(models, "SHost", "Id", "Name")
+	host.Methods = map[string]*types.Type{validateUpdateData: method(types.String, &types.Type{Kind: types.Pointer, Elem: dict})}
+	zone := newStruct(models, "SZone", "Id", "Name")
+
+	idx := newMutabilityIndex([]*types.Type{disk, diskMan, host, zone})
+	if len(idx) != 1 || idx[disk.String()] == nil {
+		t.Fatalf("newMutabilityIndex() = %v, want inputs of %s only", idx, disk)
+	}
+	inputs := idx[disk.String()]
+	for field, want := range map[string]string{
+		"Id":          MutabilityImmutable,
+		"Name":        "",
+		"Backend":     MutabilityCreateOnly,
+		"AutoDelete":  MutabilityUpdateOnly,
+		"Status":      MutabilityImmutable,
+		"Description": "",
+	} {
+		if got := inputs.mutability(types.Member{Name: field, Type: types.String}); got != want {
+			t.Errorf("mutability(%s) = %q, want %q", field, got, want)
+		}
+	}
+	var unknown *mutabilityInputs
+	if got := unknown.mutability(types.Member{Name: "Id", Type: types.String}); got != "" {
+		t.Errorf("mutability() of unknown inputs = %q, want empty", got)
+	}
+}
+
+func Test_emitMemberMutability(t *testing.T) {
+	g := &apiGen{
+		customArgs: &CustomArgs{NullablePointers: true, MutabilityDocs: true},
+		mutability: &mutabilityInputs{create: sets.NewString("backend")},
+	}
+	member := types.Member{Name: "Backend", Type: &types.Type{Kind: types.Pointer, Elem: types.String}}
+	buf := &bytes.Buffer{}
+	sw := generator.NewSnippetWriter(buf, &generator.Context{}, "$", "$")
+	g.emitMember(member, NewModelMember(member.Name, nil).Type("*string"), sw, nil)
+	want := fmt.Sprintf("// Extensions:\n//   x-nullable: true\n//   %s: %s\nBackend *string `json:\"backend\"`\n", extMutability, MutabilityCreateOnly)
+	if buf.String() != want {
+		t.Errorf("emitMember() = %q, want %q", buf.String(), want)
+	}
+}
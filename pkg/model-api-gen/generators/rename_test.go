@@ -0,0 +1,111 @@
+package generators
+
+import (
+	"bytes"
+	"testing"
+
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/types"
+
+	"yunion.io/x/code-generator/pkg/common"
+)
+
+func Test_typeName(t *testing.T) {
+	const srcPkg = "yunion.io/x/onecloud/pkg/compute/models"
+	tests := []struct {
+		name string
+		typ  *types.Type
+		want string
+	}{
+		{
+			name: "untagged",
+			typ:  &types.Type{Name: types.Name{Package: srcPkg, Name: "SGuest"}, Kind: types.Struct},
+			want: "SGuest",
+		},
+		{
+			name: "renamed",
+			typ: &types.Type{Name: types.Name{Package: srcPkg, Name: "SGuestDetails"}, Kind: types.Struct,
+				CommentLines: []string{"+onecloud:model-api-gen-name=ServerDetails"}},
+			want: "ServerDetails",
+		},
+		{
+			name: "invalid name ignored",
+			typ: &types.Type{Name: types.Name{Package: srcPkg, Name: "SGuestDetails"}, Kind: types.Struct,
+				CommentLines: []string{"+onecloud:model-api-gen-name=server-details"}},
+			want: "SGuestDetails",
+		},
+		{
+			name: "instantiated",
+			typ:  &types.Type{Name: types.Name{Package: srcPkg, Name: "SPagedList[" + srcPkg + ".SGuest]"}, Kind: types.Struct},
+			want: "SPagedListOfSGuest",
+		},
+	}
+	g := &apiGen{sourcePackage: srcPkg}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := g.typeName(tt.typ); got != tt.want {
+				t.Errorf("typeName() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_trimTypeNames(t *testing.T) {
+	const srcPkg = "yunion.io/x/onecloud/pkg/compute/models"
+	newType := func(name string, comments ...string) *types.Type {
+		return &types.Type{Name: types.Name{Package: srcPkg, Name: name}, Kind: types.Struct, CommentLines: comments}
+	}
+	guest := newType("SGuest")
+	disk := newType("SDisk")
+	sDisk := newType("SSDisk")
+	snapshot := newType("Snapshot")
+	network := newType("SNetwork")
+	networkAlias := newType("Network")
+	sNetwork := newType("SSNetwork")
+	host := newType("SHost")
+	hostRenamed := newType("SHostDetails", "+onecloud:model-api-gen-name=Host")
+	g := &apiGen{
+		sourcePackage: srcPkg,
+		customArgs:    &CustomArgs{TrimTypePrefix: "S"},
+	}
+	g.trimmedNames = g.trimTypeNames([]*types.Type{guest, disk, sDisk, snapshot, network, networkAlias, sNetwork, host, hostRenamed})
+	for _, tt := range []struct {
+		typ  *types.Type
+		want string
+	}{
+		{guest, "Guest"},
+		{disk, "Disk"},
+		{sDisk, "SDisk"},
+		{snapshot, "Snapshot"},
+		{network, "SNetwork"},
+		{networkAlias, "Network"},
+		{sNetwork, "SSNetwork"},
+		{host, "SHost"},
+		{hostRenamed, "Host"},
+	} {
+		if got := g.typeName(tt.typ); got != tt.want {
+			t.Errorf("typeName(%s) = %s, want %s", tt.typ.Name.Name, got, tt.want)
+		}
+	}
+}
+
+func Test_initialismNames(t *testing.T) {
+	const srcPkg = "yunion.io/x/onecloud/pkg/compute/models"
+	g := &apiGen{
+		sourcePackage: srcPkg,
+		customArgs:    &CustomArgs{initialisms: common.NewInitialisms([]string{"id", "cdrom"})},
+	}
+	if got := g.typeName(&types.Type{Name: types.Name{Package: srcPkg, Name: "SGuestCdrom"}, Kind: types.Struct}); got != "SGuestCDROM" {
+		t.Errorf("typeName() = %s, want SGuestCDROM", got)
+	}
+	if got := g.fieldName("CdromId"); got != "CDROMID" {
+		t.Errorf("fieldName() = %s, want CDROMID", got)
+	}
+	buf := &bytes.Buffer{}
+	sw := generator.NewSnippetWriter(buf, &generator.Context{}, "$", "$")
+	member := types.Member{Name: "GuestId", Type: types.String}
+	g.emitMember(member, NewModelMember(member.Name, nil).Type("string"), sw, nil)
+	if want := "GuestID string `json:\"guest_id\"`\n"; buf.String() != want {
+		t.Errorf("emitMember() = %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,98 @@
+package generators
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/types"
+)
+
+const (
+	// TimeFormatTime emits time.Time fields as time.Time
+	TimeFormatTime = "time"
+	// TimeFormatString emits time.Time fields as string in onecloud ISO format
+	TimeFormatString = "string"
+
+	// onecloudIsoTimeFormat is the time format of onecloud apis, same as timeutils.IsoTimeFormat
+	onecloudIsoTimeFormat = "2006-01-02T15:04:05.000000Z"
+)
+
+var timeFormats = []string{TimeFormatTime, TimeFormatString}
+
+func isTimeType(t *types.Type) bool {
+	return t != nil && t.Name.Package == "time" && t.Name.Name == "Time"
+}
+
+// isTimeMember returns true if member is not embedded time.Time or *time.Time
+func isTimeMember(m types.Member) bool {
+	if m.Embedded {
+		return false
+	}
+	return isTimeType(m.Type) || (m.Type.Kind == types.Pointer && isTimeType(m.Type.Elem))
+}
+
+// parseTimeFormats parses --time-format values like string, meter=string or yunion.io/x/meter=string,
+// the format without project is stored by empty key
+func parseTimeFormats(vals []string) (map[string]string, error) {
+	ret := make(map[string]string)
+	for _, val := range vals {
+		project, format := "", val
+		if idx := strings.Index(val, "="); idx >= 0 {
+			project, format = val[:idx], val[idx+1:]
+		}
+		if format != TimeFormatTime && format != TimeFormatString {
+			return nil, fmt.Errorf("invalid time format %q, choices: %v", val, timeFormats)
+		}
+		ret[project] = format
+	}
+	return ret, nil
+}
+
+var majorVersionSuffix = regexp.MustCompile(`/v[0-9]+$`)
+
+// projectOf returns project name of module path, which is its last element without major version,
+// e.g. onecloud of yunion.io/x/onecloud or meter of example.com/ops/meter/v2
+func projectOf(module string) string {
+	if module == "" {
+		return ""
+	}
+	return path.Base(majorVersionSuffix.ReplaceAllString(module, ""))
+}
+
+// timeFormat returns time format of source package by path or project of its module
+func (g *apiGen) timeFormat() string {
+	formats := g.customArgs.timeFormats
+	module := g.customArgs.sourceModules[g.sourcePackage]
+	for _, key := range []string{module, projectOf(module)} {
+		if key == "" {
+			continue
+		}
+		if format, ok := formats[key]; ok {
+			return format
+		}
+	}
+	if format, ok := formats[""]; ok {
+		return format
+	}
+	return TimeFormatTime
+}
+
+// doTime emits time.Time or *time.Time member as date-time
+func (g *apiGen) doTime(member types.Member, sw *generator.SnippetWriter) {
+	m := NewModelMember(member.Name, member.CommentLines)
+	if _, ok := g.customArgs.typeOverrides.Lookup(member.Type); !ok {
+		m.commentLines = append(m.commentLines, "// swagger:strfmt date-time")
+	}
+	if g.timeFormat() == TimeFormatString {
+		m.commentLines = append(m.commentLines, fmt.Sprintf("// time in format %s", onecloudIsoTimeFormat))
+		if member.Type.Kind == types.Pointer {
+			m.Type("*string")
+		} else {
+			m.Type("string")
+		}
+	}
+	g.emitMember(member, m, sw, g.args(member.Type))
+}
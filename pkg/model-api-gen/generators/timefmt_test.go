@@ -0,0 +1,70 @@
+package generators
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseTimeFormats(t *testing.T) {
+	tests := []struct {
+		vals    []string
+		want    map[string]string
+		wantErr bool
+	}{
+		{vals: nil, want: map[string]string{}},
+		{vals: []string{"string"}, want: map[string]string{"": TimeFormatString}},
+		{vals: []string{"time", "meter=string"}, want: map[string]string{"": TimeFormatTime, "meter": TimeFormatString}},
+		{vals: []string{"meter=unix"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseTimeFormats(tt.vals)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseTimeFormats(%v) error = %v, wantErr %v", tt.vals, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseTimeFormats(%v) = %v, want %v", tt.vals, got, tt.want)
+		}
+	}
+}
+
+func Test_projectOf(t *testing.T) {
+	for module, want := range map[string]string{
+		"yunion.io/x/onecloud":     "onecloud",
+		"example.com/ops/meter":    "meter",
+		"example.com/ops/meter/v2": "meter",
+		"":                         "",
+	} {
+		if got := projectOf(module); got != want {
+			t.Errorf("projectOf(%q) = %q, want %q", module, got, want)
+		}
+	}
+}
+
+func Test_apiGenTimeFormat(t *testing.T) {
+	formats := map[string]string{"": TimeFormatString, "meter": TimeFormatTime, "example.com/ops/billing": TimeFormatTime}
+	modules := map[string]string{
+		"yunion.io/x/onecloud/pkg/compute/models": "yunion.io/x/onecloud",
+		"example.com/ops/meter/pkg/models":        "example.com/ops/meter",
+		"example.com/ops/billing/pkg/models":      "example.com/ops/billing",
+	}
+	tests := []struct {
+		pkg  string
+		want string
+	}{
+		{pkg: "yunion.io/x/onecloud/pkg/compute/models", want: TimeFormatString},
+		{pkg: "example.com/ops/meter/pkg/models", want: TimeFormatTime},
+		{pkg: "example.com/ops/billing/pkg/models", want: TimeFormatTime},
+		{pkg: "gopath/meter/pkg/models", want: TimeFormatString},
+	}
+	for _, tt := range tests {
+		g := &apiGen{sourcePackage: tt.pkg, customArgs: &CustomArgs{timeFormats: formats, sourceModules: modules}}
+		if got := g.timeFormat(); got != tt.want {
+			t.Errorf("timeFormat() of %s = %s, want %s", tt.pkg, got, tt.want)
+		}
+	}
+	g := &apiGen{sourcePackage: "yunion.io/x/onecloud/pkg/compute/models", customArgs: &CustomArgs{}}
+	if got := g.timeFormat(); got != TimeFormatTime {
+		t.Errorf("default timeFormat() = %s, want %s", got, TimeFormatTime)
+	}
+}
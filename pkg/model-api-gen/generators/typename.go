@@ -0,0 +1,99 @@
+package generators
+
+import (
+	"fmt"
+	"go/token"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"k8s.io/gengo/v2/types"
+	"k8s.io/klog/v2"
+
+	"yunion.io/x/code-generator/pkg/common"
+)
+
+// validateTrimTypePrefix checks prefix of --trim-type-prefix is an exported identifier
+func validateTrimTypePrefix(prefix string) error {
+	if prefix == "" {
+		return nil
+	}
+	if !token.IsIdentifier(prefix) || !token.IsExported(prefix) {
+		return fmt.Errorf("prefix %q should be an exported identifier", prefix)
+	}
+	return nil
+}
+
+// trimTypePrefix trims prefix from name if the rest is still exported, e.g. SGuest as Guest,
+// but Snapshot is kept
+func trimTypePrefix(name, prefix string) (string, bool) {
+	if prefix == "" || !strings.HasPrefix(name, prefix) {
+		return name, false
+	}
+	rest := name[len(prefix):]
+	r, _ := utf8.DecodeRuneInString(rest)
+	if !unicode.IsUpper(r) {
+		return name, false
+	}
+	return rest, true
+}
+
+// trimTypeNames returns trimmed names of source package types keyed by type string,
+// types whose trimmed names collide in the final names, e.g. SNetwork with Network, are kept as is,
+// which is repeated since a kept name may collide with another trimmed one, e.g. SSNetwork as SNetwork
+func (g *apiGen) trimTypeNames(pkgTypes []*types.Type) map[string]string {
+	prefix := g.customArgs.TrimTypePrefix
+	if prefix == "" {
+		return nil
+	}
+	names := make(map[string]string)
+	trimmed := make([]string, 0)
+	origins := make(map[string]string)
+	for _, t := range pkgTypes {
+		if !g.inSourcePackage(t) {
+			continue
+		}
+		name := g.sourceTypeName(t)
+		names[t.String()] = name
+		if common.ParseTags(t.CommentLines).Has(tagRenameName) {
+			continue
+		}
+		if newName, ok := trimTypePrefix(name, prefix); ok {
+			names[t.String()] = newName
+			origins[t.String()] = name
+			trimmed = append(trimmed, t.String())
+		}
+	}
+	sort.Strings(trimmed)
+	for changed := true; changed; {
+		changed = false
+		owners := make(map[string][]string)
+		for tStr, name := range names {
+			owners[name] = append(owners[name], tStr)
+		}
+		for _, tStr := range trimmed {
+			name := names[tStr]
+			if name == origins[tStr] || len(owners[name]) == 1 {
+				continue
+			}
+			others := make([]string, 0, len(owners[name])-1)
+			for _, o := range owners[name] {
+				if o != tStr {
+					others = append(others, o)
+				}
+			}
+			sort.Strings(others)
+			klog.Warningf("keep type name of %s, trimmed name %s collides with %v", tStr, name, others)
+			names[tStr] = origins[tStr]
+			changed = true
+		}
+	}
+	ret := make(map[string]string)
+	for _, tStr := range trimmed {
+		if names[tStr] != origins[tStr] {
+			ret[tStr] = names[tStr]
+		}
+	}
+	return ret
+}
@@ -0,0 +1,77 @@
+package generators
+
+import (
+	"fmt"
+	"go/token"
+	"sort"
+	"strings"
+
+	"k8s.io/gengo/v2/types"
+)
+
+// namedTypes returns the named types t refers, elements of pointer, slice, map and chan are unwrapped,
+// aliases are expanded as they are generated by underlying types
+func namedTypes(t *types.Type) []*types.Type {
+	switch t.Kind {
+	case types.Pointer, types.Slice, types.Array, types.Chan:
+		return namedTypes(t.Elem)
+	case types.Map:
+		return append(namedTypes(t.Key), namedTypes(t.Elem)...)
+	case types.Alias:
+		return namedTypes(underlyingType(t))
+	case types.Builtin:
+		return nil
+	}
+	if t.Name.Package == "" || t.Name.Name == "" {
+		return nil
+	}
+	return []*types.Type{t}
+}
+
+// isUnexportedType returns true if go name of t is unexported, type arguments of instantiation are ignored
+func isUnexportedType(t *types.Type) bool {
+	name := t.Name.Name
+	if idx := strings.Index(name, "["); idx >= 0 {
+		name = name[:idx]
+	}
+	return !token.IsExported(name)
+}
+
+// checkUnexported returns errors of model types referring unexported types, unexported types of
+// source package are copied by exported names, others can't be referred by the generated package
+func (g *apiGen) checkUnexported(pkgTypes []*types.Type) []error {
+	errs := make([]error, 0)
+	copied := make(map[string][]string)
+	for _, t := range pkgTypes {
+		if !g.modelTypes.Has(t.String()) {
+			continue
+		}
+		if g.inSourcePackage(t) {
+			name := g.typeName(t)
+			copied[name] = append(copied[name], t.String())
+		}
+		for _, m := range t.Members {
+			if isModelBase(m.Type) {
+				continue
+			}
+			for _, mt := range namedTypes(m.Type) {
+				if g.inSourcePackage(mt) || !isUnexportedType(mt) {
+					continue
+				}
+				errs = append(errs, fmt.Errorf("field %s.%s refers unexported type %s of another package, export the type or copy it to %s", t.String(), m.Name, mt.String(), g.sourcePackage))
+			}
+		}
+	}
+	names := make([]string, 0, len(copied))
+	for name := range copied {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if owners := copied[name]; len(owners) > 1 {
+			sort.Strings(owners)
+			errs = append(errs, fmt.Errorf("types %s are generated as the same exported name %s, rename one by +%s", strings.Join(owners, ", "), name, tagRenameName))
+		}
+	}
+	return errs
+}
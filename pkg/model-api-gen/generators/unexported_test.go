@@ -0,0 +1,37 @@
+package generators
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/gengo/v2/types"
+
+	"yunion.io/x/pkg/util/sets"
+)
+
+func Test_checkUnexported(t *testing.T) {
+	const srcPkg = "yunion.io/x/onecloud/pkg/compute/models"
+	str := &types.Type{Name: types.Name{Name: "string"}, Kind: types.Builtin}
+	newType := func(pkg, name string, members ...types.Member) *types.Type {
+		return &types.Type{Name: types.Name{Package: pkg, Name: name}, Kind: types.Struct, Members: members}
+	}
+	netConf := newType(srcPkg, "sNetConf", types.Member{Name: "Bridge", Type: str})
+	hostConf := newType("yunion.io/x/onecloud/pkg/hostman/options", "hostConf", types.Member{Name: "Ip", Type: str})
+	guest := newType(srcPkg, "SGuest",
+		types.Member{Name: "NetConf", Type: netConf},
+		types.Member{Name: "HostConfs", Type: &types.Type{Kind: types.Slice, Elem: &types.Type{Kind: types.Pointer, Elem: hostConf}}},
+	)
+	exported := newType(srcPkg, "SNetConf")
+
+	g := &apiGen{sourcePackage: srcPkg, modelTypes: sets.NewString(guest.String(), netConf.String())}
+	errs := g.checkUnexported([]*types.Type{guest, netConf, exported})
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "field "+srcPkg+".SGuest.HostConfs refers unexported type") {
+		t.Errorf("checkUnexported() = %v, want error of HostConfs", errs)
+	}
+
+	g.modelTypes.Insert(exported.String())
+	errs = g.checkUnexported([]*types.Type{guest, netConf, exported})
+	if len(errs) != 2 || !strings.Contains(errs[1].Error(), "same exported name SNetConf") {
+		t.Errorf("checkUnexported() = %v, want collision of SNetConf", errs)
+	}
+}
@@ -6,11 +6,10 @@ import (
 	"path/filepath"
 	"strings"
 
-	"k8s.io/gengo/args"
-	"k8s.io/gengo/generator"
-	"k8s.io/gengo/namer"
-	"k8s.io/gengo/types"
-	"k8s.io/klog"
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/namer"
+	"k8s.io/gengo/v2/types"
+	"k8s.io/klog/v2"
 
 	"yunion.io/x/pkg/util/sets"
 
@@ -29,12 +28,12 @@ func DefaultNameSystem() string {
 	return "public"
 }
 
-func Packages(ctx *generator.Context, arguments *args.GeneratorArgs) generator.Packages {
+func Packages(ctx *generator.Context, arguments *common.GeneratorArgs) []generator.Target {
 	boilerplate, err := arguments.LoadGoBoilerplate()
 	if err != nil {
 		klog.Fatalf("Failed loading boilerplate: %v", err)
 	}
-	pkgs := generator.Packages{}
+	pkgs := []generator.Target{}
 	inputs := sets.NewString(ctx.Inputs...)
 	header := append([]byte(fmt.Sprintf("// +build !%s\n\n", arguments.GeneratedBuildTag)), boilerplate...)
 
@@ -46,25 +45,25 @@ func Packages(ctx *generator.Context, arguments *args.GeneratorArgs) generator.P
 		klog.Infof("Considering pkg %q", pkg.Path)
 		outPkgName := strings.Split(filepath.Base(arguments.OutputPackagePath), ".")[0]
 		pkgs = append(pkgs,
-			&generator.DefaultPackage{
-				PackageName: outPkgName,
-				PackagePath: arguments.OutputPackagePath,
-				HeaderText:  header,
-				GeneratorFunc: func(c *generator.Context) []generator.Generator {
+			common.NewSourcePackage(pkg.Path, &generator.SimpleTarget{
+				PkgName:       outPkgName,
+				PkgPath:       arguments.OutputPackagePath,
+				HeaderComment: header,
+				GeneratorsFunc: func(c *generator.Context) []generator.Generator {
 					return []generator.Generator{
 						// Always generate a "doc.go" file.
-						generator.DefaultGen{OptionalName: "doc"},
+						generator.GoGenerator{OutputFilename: "doc.go"},
 						// Generate swagger code by model.
 						NewModelPkgGen(arguments.OutputFileBaseName, pkg.Path, ctx.Order),
 					}
 				},
-			})
+			}))
 	}
 	return pkgs
 }
 
 type modelPkgGen struct {
-	generator.DefaultGen
+	generator.GoGenerator
 	ident         string
 	sourcePackage string
 	modelManagers map[string]*types.Type
@@ -73,19 +72,20 @@ type modelPkgGen struct {
 func NewModelPkgGen(sanitizedName, sourcePackage string, pkgTypes []*types.Type) generator.Generator {
 	ident := filepath.Base(strings.TrimRight(sourcePackage, "models"))
 	gen := &modelPkgGen{
-		DefaultGen: generator.DefaultGen{
-			OptionalName: fmt.Sprintf("%s_%s", sanitizedName, ident),
+		GoGenerator: generator.GoGenerator{
+			OutputFilename: fmt.Sprintf("%s_%s.go", sanitizedName, ident),
 		},
 		ident:         ident,
 		sourcePackage: sourcePackage,
-		modelManagers: make(map[string]*types.Type),
 	}
 	gen.collectTypes(pkgTypes)
 	return gen
 }
 
 func (g *modelPkgGen) collectTypes(pkgTypes []*types.Type) {
-	common.CollectModelManager(g.sourcePackage, pkgTypes, sets.NewString(), g.modelManagers)
+	c := common.NewTypeCollector(g.sourcePackage)
+	c.Collect(pkgTypes)
+	g.modelManagers = c.Managers
 	for _, man := range g.modelManagers {
 		g.modelManagers[man.String()] = man
 	}
@@ -0,0 +1,87 @@
+package generators
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ServiceRegistration is an out-of-tree onecloud style service registered by generated registration file
+type ServiceRegistration struct {
+	// Name is the service name selected by --load-services, e.g. mysvc
+	Name string
+	// Package is the import path of the package declaring InitHandlers
+	Package string
+	// Func is the InitHandlers function name
+	Func string
+}
+
+// ParseServiceRegistration parses value like mysvc=example.com/mysvc/pkg/mysvc/service.InitHandlers
+func ParseServiceRegistration(val string) (ServiceRegistration, error) {
+	idx := strings.Index(val, "=")
+	if idx <= 0 {
+		return ServiceRegistration{}, fmt.Errorf("invalid service %q, should be <name>=<package>.<InitHandlers>", val)
+	}
+	name, fn := val[:idx], val[idx+1:]
+	dot := strings.LastIndex(fn, ".")
+	if dot <= 0 || strings.LastIndex(fn, "/") > dot || !token.IsIdentifier(fn[dot+1:]) {
+		return ServiceRegistration{}, fmt.Errorf("invalid init handlers %q of service %s, should be <package>.<InitHandlers>", fn, name)
+	}
+	return ServiceRegistration{Name: name, Package: fn[:dot], Func: fn[dot+1:]}, nil
+}
+
+// modelsPackage is the package of RegisterService called by the generated registration file
+const modelsPackage = "yunion.io/x/code-generator/pkg/models"
+
+// RenderServiceRegistration renders file of package pkgName registering services by models.RegisterService,
+// pkgName is a package owned by the caller, whose swagger-gen main imports it and runs swagger-gen command.Main
+func RenderServiceRegistration(pkgName string, services []ServiceRegistration) ([]byte, error) {
+	imports := []string{fmt.Sprintf("%q", modelsPackage)}
+	registers := &bytes.Buffer{}
+	aliases := make(map[string]string)
+	for _, svc := range services {
+		alias, ok := aliases[svc.Package]
+		if !ok {
+			alias = fmt.Sprintf("%s%d", strings.Replace(path.Base(svc.Package), "-", "_", -1), len(aliases))
+			aliases[svc.Package] = alias
+			imports = append(imports, fmt.Sprintf("%s %q", alias, svc.Package))
+		}
+		fmt.Fprintf(registers, "models.RegisterService(%q, %s.%s)\n", svc.Name, alias, svc.Func)
+	}
+	buf := &bytes.Buffer{}
+	buf.WriteString("// Code generated by models-pkg-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(buf, "package %s\n\n", pkgName)
+	fmt.Fprintf(buf, "import (\n%s\n)\n\n", strings.Join(imports, "\n"))
+	fmt.Fprintf(buf, "func init() {\n%s}\n", registers.String())
+	return format.Source(buf.Bytes())
+}
+
+// WriteServiceRegistration write file named by base to output package dir, registering services parsed from vals,
+// dir is a package of the caller, it must not be the models package of code-generator
+func WriteServiceRegistration(dir, base string, vals []string) error {
+	services := make([]ServiceRegistration, 0, len(vals))
+	for _, val := range vals {
+		svc, err := ParseServiceRegistration(val)
+		if err != nil {
+			return err
+		}
+		services = append(services, svc)
+	}
+	if len(services) == 0 {
+		return fmt.Errorf("no service is registered")
+	}
+	content, err := RenderServiceRegistration(strings.Split(filepath.Base(dir), ".")[0], services)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, base+".go"), content, 0644)
+}
@@ -0,0 +1,53 @@
+package generators
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseServiceRegistration(t *testing.T) {
+	tests := []struct {
+		val     string
+		want    ServiceRegistration
+		wantErr bool
+	}{
+		{
+			val:  "mysvc=example.com/mysvc/pkg/mysvc/service.InitHandlers",
+			want: ServiceRegistration{Name: "mysvc", Package: "example.com/mysvc/pkg/mysvc/service", Func: "InitHandlers"},
+		},
+		{val: "example.com/mysvc/pkg/mysvc/service.InitHandlers", wantErr: true},
+		{val: "mysvc=example.com/mysvc.v2/service", wantErr: true},
+		{val: "mysvc=InitHandlers", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseServiceRegistration(tt.val)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseServiceRegistration(%q) error = %v, wantErr %v", tt.val, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseServiceRegistration(%q) = %+v, want %+v", tt.val, got, tt.want)
+		}
+	}
+}
+
+func TestRenderServiceRegistration(t *testing.T) {
+	content, err := RenderServiceRegistration("swaggerservices", []ServiceRegistration{
+		{Name: "mysvc", Package: "example.com/mysvc/pkg/mysvc/service", Func: "InitHandlers"},
+		{Name: "myjob", Package: "example.com/mysvc/pkg/mysvc/service", Func: "InitJobHandlers"},
+	})
+	if err != nil {
+		t.Fatalf("RenderServiceRegistration error: %v", err)
+	}
+	for _, want := range []string{
+		"package swaggerservices\n",
+		`"yunion.io/x/code-generator/pkg/models"`,
+		`service0 "example.com/mysvc/pkg/mysvc/service"`,
+		`models.RegisterService("mysvc", service0.InitHandlers)`,
+		`models.RegisterService("myjob", service0.InitJobHandlers)`,
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("service registration missing %q:\n%s", want, content)
+		}
+	}
+}
@@ -1,71 +1,109 @@
 package models
 
 import (
-	"fmt"
-	"reflect"
-
-	"k8s.io/gengo/types"
+	"k8s.io/gengo/v2/types"
 
 	"yunion.io/x/onecloud/pkg/appsrv"
 	"yunion.io/x/onecloud/pkg/cloudcommon/db"
 	computesvc "yunion.io/x/onecloud/pkg/compute/service"
 	imagesvc "yunion.io/x/onecloud/pkg/image/service"
 	identitysvc "yunion.io/x/onecloud/pkg/keystone/service"
+
+	"yunion.io/x/code-generator/pkg/models/registry"
+	"yunion.io/x/code-generator/pkg/onecloudshim"
 )
 
+// serviceInitHandlers are the onecloud services which model managers can be loaded
+var serviceInitHandlers = map[string]func(*appsrv.Application){
+	"compute":  computesvc.InitHandlers,
+	"image":    imagesvc.InitHandlers,
+	"identity": identitysvc.InitHandlers,
+}
+
 func init() {
-	cleanF := func(man map[string]db.IModelManager) {
-		for key, _ := range man {
-			delete(man, key)
-		}
+	for name, initHandlers := range serviceInitHandlers {
+		RegisterService(name, initHandlers)
 	}
-	registerF := func(man map[string]db.IModelManager) {
-		for _, man := range db.GlobalModelManagerTables() {
-			RegisterModelManager(man)
+}
+
+// RegisterService adds onecloud style service, e.g. an out-of-tree one built on cloudcommon/db,
+// its model managers are loaded by Initialize like the builtin services
+func RegisterService(name string, initHandlers func(*appsrv.Application)) {
+	onecloudshim.RegisterService(name, serviceInit(initHandlers))
+}
+
+// serviceInit adapts onecloud InitHandlers to onecloudshim.ServiceInit,
+// managers are collected from db global tables registered by InitHandlers
+func serviceInit(initHandlers func(*appsrv.Application)) onecloudshim.ServiceInit {
+	return func() ([]onecloudshim.ModelManager, error) {
+		app := appsrv.NewApplication("", 1, false)
+		initHandlers(app)
+		tables := db.GlobalModelManagerTables()
+		ret := make([]onecloudshim.ModelManager, 0, len(tables))
+		for key, man := range tables {
+			ret = append(ret, man)
+			// onecloud refuses duplicated keywords, tables are cleaned for the next service,
+			// duplicates among services are resolved by registry.Dedup
+			delete(tables, key)
 		}
+		return ret, nil
 	}
-	for _, f := range []func(*appsrv.Application){
-		computesvc.InitHandlers,
-		imagesvc.InitHandlers,
-		identitysvc.InitHandlers,
-	} {
-		app := appsrv.NewApplication("", 1, false)
-		f(app)
-		registerF(db.GlobalModelManagerTables())
-		// hack: clean all model manager to avoid duplicate registered
-		cleanF(db.GlobalModelManagerTables())
+}
+
+var initialized bool
+
+// ServiceNames returns all the services supported by Initialize
+func ServiceNames() []string {
+	return onecloudshim.ServiceNames()
+}
+
+// Initialize registers model managers of services by calling their InitHandlers,
+// all supported services are loaded if services is empty.
+// Managers registered by several services are deduplicated by dedup.
+func Initialize(services []string, dedup registry.Dedup) error {
+	if initialized {
+		return nil
+	}
+	if err := registry.Load(services, dedup); err != nil {
+		return err
 	}
+	initialized = true
+	return nil
 }
 
-var globalManagers map[string]db.IModelManager
+// Reinitialize drops the registered model managers and registers them of services again,
+// e.g. before regeneration of --watch, so managers dropped or replaced by the previous run don't leak
+func Reinitialize(services []string, dedup registry.Dedup) error {
+	registry.Reset()
+	initialized = false
+	return Initialize(services, dedup)
+}
 
+// GlobalManagers returns registered onecloud model managers
 func GlobalManagers() map[string]db.IModelManager {
-	return globalManagers
+	ret := make(map[string]db.IModelManager)
+	for key, man := range registry.Managers() {
+		if dbMan, ok := man.(db.IModelManager); ok {
+			ret[key] = dbMan
+		}
+	}
+	return ret
 }
 
 func GetModelManagerKey(man db.IModelManager) string {
-	manType := reflect.TypeOf(man)
-	if manType.Kind() == reflect.Ptr {
-		manType = manType.Elem()
-	}
-	return fmt.Sprintf("%s.%s", manType.PkgPath(), manType.Name())
+	return registry.ManagerKey(man)
 }
 
 func RegisterModelManager(man db.IModelManager) {
-	if globalManagers == nil {
-		globalManagers = make(map[string]db.IModelManager)
-	}
-	globalManagers[GetModelManagerKey(man)] = man
+	registry.Register(man)
 }
 
 func GetModelManager(typeName string) db.IModelManager {
-	return globalManagers[typeName]
+	man, _ := registry.Get(typeName).(db.IModelManager)
+	return man
 }
 
 func GetModelManagerByType(t *types.Type) db.IModelManager {
-	if t == nil {
-		return nil
-	}
-	// t.String() is pkgPath.typeName, e.g:yunion.io/x/onecloud/pkg/keystone/models.SAssignmentManager
-	return GetModelManager(t.String())
+	man, _ := registry.GetByType(t).(db.IModelManager)
+	return man
 }
@@ -4,8 +4,11 @@ import (
 	"reflect"
 	"testing"
 
+	"yunion.io/x/onecloud/pkg/appsrv"
 	"yunion.io/x/onecloud/pkg/cloudcommon/db"
 	"yunion.io/x/onecloud/pkg/compute/models"
+
+	"yunion.io/x/code-generator/pkg/models/registry"
 )
 
 func init() {
@@ -35,3 +38,50 @@ func TestGetModelManager(t *testing.T) {
 		})
 	}
 }
+
+func TestInitialize(t *testing.T) {
+	defer func() {
+		initialized = false
+		registry.Reset()
+		RegisterModelManager(models.GuestManager)
+	}()
+	calls := 0
+	RegisterService("lazy", func(app *appsrv.Application) {
+		calls++
+		db.GlobalModelManagerTables()["server"] = models.GuestManager
+	})
+	if calls != 0 {
+		t.Fatalf("service is initialized on registration")
+	}
+	if err := Reinitialize([]string{"lazy", "unknown"}, registry.Dedup{}); err == nil {
+		t.Errorf("Initialize() of unsupported service should fail")
+	}
+	if err := Initialize([]string{"lazy"}, registry.Dedup{Policy: "random"}); err == nil {
+		t.Errorf("Initialize() with invalid dedup policy should fail")
+	}
+	if calls != 0 || initialized {
+		t.Fatalf("failed Initialize() called %d init handlers, initialized %v", calls, initialized)
+	}
+
+	if err := Initialize([]string{"lazy"}, registry.Dedup{}); err != nil {
+		t.Fatalf("Initialize() error: %v", err)
+	}
+	if got := GetModelManager(GetModelManagerKey(models.GuestManager)); got != models.GuestManager {
+		t.Errorf("GetModelManager() = %v, want %v", got, models.GuestManager)
+	}
+	if _, ok := db.GlobalModelManagerTables()["server"]; ok {
+		t.Errorf("db tables are not cleaned for the next service")
+	}
+	if err := Initialize([]string{"lazy"}, registry.Dedup{}); err != nil {
+		t.Fatalf("Initialize() again error: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("init handlers called %d times by Initialize() twice, want 1", calls)
+	}
+	if err := Reinitialize([]string{"lazy"}, registry.Dedup{}); err != nil {
+		t.Fatalf("Reinitialize() error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("init handlers called %d times after Reinitialize(), want 2", calls)
+	}
+}
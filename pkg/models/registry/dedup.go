@@ -0,0 +1,92 @@
+package registry
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"k8s.io/klog/v2"
+
+	"yunion.io/x/pkg/util/sets"
+)
+
+const (
+	// DuplicateKeep registers managers of different types even if they have the same keyword,
+	// e.g. keystone user manager and compute user cache manager, it's the default
+	DuplicateKeep = "keep"
+	// DuplicateFirst keeps the manager registered first, the later ones of the same keyword are dropped with warning
+	DuplicateFirst = "first"
+	// DuplicateLast replaces the registered manager by the later one of the same keyword with warning
+	DuplicateLast = "last"
+	// DuplicateError fails registration of the later manager of the same keyword
+	DuplicateError = "error"
+)
+
+var duplicatePolicies = []string{DuplicateKeep, DuplicateFirst, DuplicateLast, DuplicateError}
+
+// Dedup decides which managers are registered when several services register them.
+// Managers are registered by their types like Register, the same type registered by several services,
+// e.g. task and subtask managers of cloudcommon/db, is registered once.
+// Managers of different types with the same keyword are all registered unless a keyword policy is opted in.
+type Dedup struct {
+	// Policy is DuplicateKeep, DuplicateFirst, DuplicateLast or DuplicateError, DuplicateKeep if empty
+	Policy string
+	// Shared are keywords expected to be registered by several services, the first one is kept silently
+	Shared []string
+}
+
+// AddFlags add manager dedup flags to fs
+func (d *Dedup) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&d.Policy, "duplicate-managers", d.Policy, fmt.Sprintf("Policy of managers of different types with the same keyword registered by several services, choices: %v, %s if empty", duplicatePolicies, DuplicateKeep))
+	fs.StringSliceVar(&d.Shared, "shared-managers", d.Shared, "Keywords of managers of different types registered by several services, e.g. task,subtask, the first registered is kept without warning")
+}
+
+// Validate checks policy of d
+func (d Dedup) Validate() error {
+	if d.Policy == "" || sets.NewString(duplicatePolicies...).Has(d.Policy) {
+		return nil
+	}
+	return fmt.Errorf("invalid duplicate managers policy %q, choices: %v", d.Policy, duplicatePolicies)
+}
+
+// registeredByKeyword returns registry key of manager registered with keyword
+func registeredByKeyword(keyword string) (string, bool) {
+	for key, man := range managers {
+		if man.Keyword() == keyword {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// Add registers man by its type, a type registered by several services is registered once.
+// Manager of a keyword registered by another type is resolved by shared keywords and policy.
+func (d Dedup) Add(man ModelManager) error {
+	key := ManagerKey(man)
+	if Get(key) != nil {
+		return nil
+	}
+	keyword := man.Keyword()
+	shared := sets.NewString(d.Shared...).Has(keyword)
+	if !shared && (d.Policy == "" || d.Policy == DuplicateKeep) {
+		Register(man)
+		return nil
+	}
+	exist, ok := registeredByKeyword(keyword)
+	if !ok {
+		Register(man)
+		return nil
+	}
+	switch {
+	case shared:
+		klog.V(2).Infof("shared manager %s of %s is registered by %s", keyword, key, exist)
+	case d.Policy == DuplicateError:
+		return fmt.Errorf("manager %s of %s is already registered by %s", keyword, key, exist)
+	case d.Policy == DuplicateLast:
+		klog.Warningf("manager %s of %s replaces %s", keyword, key, exist)
+		delete(managers, exist)
+		Register(man)
+	default:
+		klog.Warningf("manager %s of %s is dropped, it's registered by %s", keyword, key, exist)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package registry
+
+import (
+	"reflect"
+	"testing"
+
+	"yunion.io/x/pkg/util/sets"
+)
+
+type computeTaskManager struct{}
+
+func (m *computeTaskManager) Keyword() string       { return "task" }
+func (m *computeTaskManager) KeywordPlural() string { return "tasks" }
+
+type imageTaskManager struct{}
+
+func (m *imageTaskManager) Keyword() string       { return "task" }
+func (m *imageTaskManager) KeywordPlural() string { return "tasks" }
+
+func TestDedupAdd(t *testing.T) {
+	computeKey := ManagerKey(&computeTaskManager{})
+	imageKey := ManagerKey(&imageTaskManager{})
+	tests := []struct {
+		name    string
+		dedup   Dedup
+		want    []string
+		wantErr bool
+	}{
+		{name: "keep by default", dedup: Dedup{}, want: []string{computeKey, imageKey}},
+		{name: "keep", dedup: Dedup{Policy: DuplicateKeep}, want: []string{computeKey, imageKey}},
+		{name: "first", dedup: Dedup{Policy: DuplicateFirst}, want: []string{computeKey}},
+		{name: "last", dedup: Dedup{Policy: DuplicateLast}, want: []string{imageKey}},
+		{name: "error", dedup: Dedup{Policy: DuplicateError}, wantErr: true},
+		{name: "shared", dedup: Dedup{Policy: DuplicateError, Shared: []string{"task"}}, want: []string{computeKey}},
+		{name: "shared with keep", dedup: Dedup{Shared: []string{"task"}}, want: []string{computeKey}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Reset()
+			defer Reset()
+			if err := tt.dedup.Add(&computeTaskManager{}); err != nil {
+				t.Fatalf("Add() of first manager: %v", err)
+			}
+			err := tt.dedup.Add(&imageTaskManager{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Add() of duplicated manager error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := sets.StringKeySet(Managers()).List(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("registered managers = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	Reset()
+	defer Reset()
+	// the same type registered by several services
+	for i := 0; i < 2; i++ {
+		if err := (Dedup{Policy: DuplicateError}).Add(&computeTaskManager{}); err != nil {
+			t.Fatalf("Add() of manager registered by several services: %v", err)
+		}
+	}
+	if len(Managers()) != 1 || Get(computeKey) == nil {
+		t.Errorf("registered managers = %v, want only %s", Managers(), computeKey)
+	}
+	if err := (Dedup{Policy: "random"}).Validate(); err == nil {
+		t.Errorf("Validate() of policy random should fail")
+	}
+}
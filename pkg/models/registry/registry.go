@@ -0,0 +1,75 @@
+// Package registry keeps the model managers loaded by generators.
+// It does not import onecloud, so generators depending on it can be
+// tested with fake managers instead of the full onecloud dependency tree.
+package registry
+
+import (
+	"fmt"
+	"reflect"
+
+	"k8s.io/gengo/v2/types"
+
+	"yunion.io/x/code-generator/pkg/onecloudshim"
+)
+
+// ModelManager is the part of onecloud db.IModelManager used by generators,
+// onecloud managers satisfy it without conversion
+type ModelManager = onecloudshim.ModelManager
+
+var managers = make(map[string]ModelManager)
+
+// ManagerKey returns registry key of man, e.g. yunion.io/x/onecloud/pkg/compute/models.SGuestManager
+func ManagerKey(man ModelManager) string {
+	manType := reflect.TypeOf(man)
+	if manType.Kind() == reflect.Ptr {
+		manType = manType.Elem()
+	}
+	return fmt.Sprintf("%s.%s", manType.PkgPath(), manType.Name())
+}
+
+// Register adds man to registry, manager with the same key is replaced
+func Register(man ModelManager) {
+	managers[ManagerKey(man)] = man
+}
+
+// Managers returns all registered managers by their keys
+func Managers() map[string]ModelManager {
+	return managers
+}
+
+// Get returns manager registered by typeName, nil if not found
+func Get(typeName string) ModelManager {
+	return managers[typeName]
+}
+
+// GetByType returns manager registered by go type of t, nil if not found
+func GetByType(t *types.Type) ModelManager {
+	if t == nil {
+		return nil
+	}
+	// t.String() is pkgPath.typeName, e.g:yunion.io/x/onecloud/pkg/keystone/models.SAssignmentManager
+	return Get(t.String())
+}
+
+// Load registers model managers of services registered to onecloudshim, e.g. by importing pkg/models,
+// all registered services are loaded if services is empty, managers are deduplicated by dedup
+func Load(services []string, dedup Dedup) error {
+	if err := dedup.Validate(); err != nil {
+		return err
+	}
+	mans, err := onecloudshim.LoadServices(services)
+	if err != nil {
+		return err
+	}
+	for _, man := range mans {
+		if err := dedup.Add(man); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reset removes all registered managers, it's used by tests
+func Reset() {
+	managers = make(map[string]ModelManager)
+}
@@ -0,0 +1,39 @@
+package registry_test
+
+import (
+	"testing"
+
+	"yunion.io/x/code-generator/pkg/models/registry"
+	"yunion.io/x/code-generator/pkg/models/registry/testdata/fakemodels"
+	"yunion.io/x/code-generator/pkg/onecloudshim"
+)
+
+func TestLoad(t *testing.T) {
+	registry.Reset()
+	defer registry.Reset()
+	onecloudshim.RegisterService("registry-test", func() ([]registry.ModelManager, error) {
+		return []registry.ModelManager{fakemodels.GuestManager, fakemodels.DiskManager, fakemodels.GuestdiskManager}, nil
+	})
+	// the guest manager is registered by several services, like cloudcommon ones
+	onecloudshim.RegisterService("registry-test-shared", func() ([]registry.ModelManager, error) {
+		return []registry.ModelManager{fakemodels.GuestManager}, nil
+	})
+	services := []string{"registry-test", "registry-test-shared"}
+	if err := registry.Load(services, registry.Dedup{Policy: "random"}); err == nil {
+		t.Errorf("Load() with invalid dedup policy should fail")
+	}
+	if err := registry.Load(services, registry.Dedup{Policy: registry.DuplicateError}); err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if len(registry.Managers()) != 3 {
+		t.Errorf("registered managers = %v, want the 3 fake managers", registry.Managers())
+	}
+	for _, man := range []registry.ModelManager{fakemodels.GuestManager, fakemodels.DiskManager, fakemodels.GuestdiskManager} {
+		if got := registry.Get(registry.ManagerKey(man)); got != man {
+			t.Errorf("Get(%s) = %v, want %v", registry.ManagerKey(man), got, man)
+		}
+	}
+	if err := registry.Load([]string{"unknown"}, registry.Dedup{}); err == nil {
+		t.Errorf("Load() of unsupported service should fail")
+	}
+}
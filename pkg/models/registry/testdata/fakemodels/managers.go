@@ -0,0 +1,108 @@
+// Package fakemodels are fixture model managers mirroring onecloud base classes,
+// used by generator tests instead of importing onecloud service packages.
+package fakemodels
+
+import (
+	"yunion.io/x/code-generator/pkg/models/registry"
+)
+
+// SColumn mirrors a sqlchemy column, onecloudshim.TableColumns reads it by reflection
+type SColumn struct {
+	name     string
+	nullable bool
+}
+
+func NewColumn(name string, nullable bool) *SColumn {
+	return &SColumn{name: name, nullable: nullable}
+}
+
+func (c *SColumn) Name() string     { return c.name }
+func (c *SColumn) IsNullable() bool { return c.nullable }
+
+// STableSpec mirrors sqlchemy.STableSpec
+type STableSpec struct {
+	columns []interface{}
+}
+
+func (s *STableSpec) Columns() []interface{} { return s.columns }
+
+// SModelBaseManager mirrors onecloud db.SModelBaseManager
+type SModelBaseManager struct {
+	keyword       string
+	keywordPlural string
+	tableSpec     *STableSpec
+}
+
+// NewModelBaseManager returns base manager of keywords, with table spec of columns if any
+func NewModelBaseManager(keyword, keywordPlural string, columns ...*SColumn) SModelBaseManager {
+	m := SModelBaseManager{keyword: keyword, keywordPlural: keywordPlural}
+	if len(columns) != 0 {
+		m.tableSpec = &STableSpec{}
+		for _, col := range columns {
+			m.tableSpec.columns = append(m.tableSpec.columns, col)
+		}
+	}
+	return m
+}
+
+func (m *SModelBaseManager) Keyword() string       { return m.keyword }
+func (m *SModelBaseManager) KeywordPlural() string { return m.keywordPlural }
+func (m *SModelBaseManager) Alias() string         { return "" }
+func (m *SModelBaseManager) AliasPlural() string   { return "" }
+
+// TableSpec returns nil if the manager has no columns
+func (m *SModelBaseManager) TableSpec() *STableSpec { return m.tableSpec }
+
+// SStandaloneResourceBaseManager mirrors onecloud db.SStandaloneResourceBaseManager
+type SStandaloneResourceBaseManager struct {
+	SModelBaseManager
+}
+
+// SJointResourceBaseManager mirrors onecloud db.SJointResourceBaseManager,
+// master and slave managers are returned by interface like onecloud does
+type SJointResourceBaseManager struct {
+	SModelBaseManager
+
+	master registry.ModelManager
+	slave  registry.ModelManager
+}
+
+func (m *SJointResourceBaseManager) GetMasterManager() registry.ModelManager { return m.master }
+func (m *SJointResourceBaseManager) GetSlaveManager() registry.ModelManager  { return m.slave }
+
+type SGuestManager struct {
+	SStandaloneResourceBaseManager
+}
+
+type SDiskManager struct {
+	SStandaloneResourceBaseManager
+}
+
+type SGuestdiskManager struct {
+	SJointResourceBaseManager
+}
+
+var (
+	GuestManager = &SGuestManager{
+		SStandaloneResourceBaseManager{NewModelBaseManager("server", "servers",
+			NewColumn("id", false), NewColumn("name", false), NewColumn("description", true), NewColumn("status", false),
+		)},
+	}
+	DiskManager = &SDiskManager{
+		SStandaloneResourceBaseManager{NewModelBaseManager("disk", "disks")},
+	}
+	GuestdiskManager = &SGuestdiskManager{
+		SJointResourceBaseManager{
+			SModelBaseManager: NewModelBaseManager("guestdisk", "guestdisks"),
+			master:            GuestManager,
+			slave:             DiskManager,
+		},
+	}
+)
+
+// Register registers all the fake managers, registry.Reset removes them
+func Register() {
+	for _, man := range []registry.ModelManager{GuestManager, DiskManager, GuestdiskManager} {
+		registry.Register(man)
+	}
+}
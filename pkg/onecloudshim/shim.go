@@ -0,0 +1,54 @@
+// Package onecloudshim is the boundary between generators and onecloud.
+// It declares the few onecloud interfaces used by generators, services are
+// registered by pkg/models which imports onecloud, so generators build against
+// different onecloud versions, or without onecloud when fake services are registered.
+package onecloudshim
+
+import (
+	"fmt"
+
+	"yunion.io/x/pkg/util/sets"
+)
+
+// ModelManager is the part of onecloud db.IModelManager used by generators
+type ModelManager interface {
+	Keyword() string
+	KeywordPlural() string
+}
+
+// ServiceInit initializes handlers of a onecloud service and returns its model managers
+type ServiceInit func() ([]ModelManager, error)
+
+var services = make(map[string]ServiceInit)
+
+// RegisterService adds service init of name, service with the same name is replaced
+func RegisterService(name string, init ServiceInit) {
+	services[name] = init
+}
+
+// ServiceNames returns names of all registered services
+func ServiceNames() []string {
+	return sets.StringKeySet(services).List()
+}
+
+// LoadServices initializes services by names and returns their model managers,
+// all registered services are loaded if names is empty
+func LoadServices(names []string) ([]ModelManager, error) {
+	if len(names) == 0 {
+		names = ServiceNames()
+	}
+	for _, name := range names {
+		if _, ok := services[name]; !ok {
+			return nil, fmt.Errorf("unsupported service %q, choose from %v", name, ServiceNames())
+		}
+	}
+	ret := make([]ModelManager, 0)
+	for _, name := range names {
+		mans, err := services[name]()
+		if err != nil {
+			return nil, fmt.Errorf("init service %s: %v", name, err)
+		}
+		ret = append(ret, mans...)
+	}
+	return ret, nil
+}
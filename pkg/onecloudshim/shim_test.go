@@ -0,0 +1,130 @@
+package onecloudshim
+
+import (
+	"reflect"
+	"testing"
+)
+
+type fakeManager struct {
+	keyword string
+}
+
+func (m fakeManager) Keyword() string       { return m.keyword }
+func (m fakeManager) KeywordPlural() string { return m.keyword + "s" }
+
+func TestLoadServices(t *testing.T) {
+	defer func(old map[string]ServiceInit) { services = old }(services)
+	services = make(map[string]ServiceInit)
+	RegisterService("compute", func() ([]ModelManager, error) {
+		return []ModelManager{fakeManager{"server"}, fakeManager{"disk"}}, nil
+	})
+	RegisterService("image", func() ([]ModelManager, error) {
+		return []ModelManager{fakeManager{"image"}}, nil
+	})
+
+	if got, want := ServiceNames(), []string{"compute", "image"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ServiceNames() = %v, want %v", got, want)
+	}
+	tests := []struct {
+		name    string
+		names   []string
+		want    []ModelManager
+		wantErr bool
+	}{
+		{
+			name:  "all services",
+			names: nil,
+			want:  []ModelManager{fakeManager{"server"}, fakeManager{"disk"}, fakeManager{"image"}},
+		},
+		{
+			name:  "one service",
+			names: []string{"image"},
+			want:  []ModelManager{fakeManager{"image"}},
+		},
+		{
+			name:    "unsupported service",
+			names:   []string{"image", "network"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadServices(tt.names)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadServices() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadServices() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type fakeColumn struct {
+	name    string
+	primary bool
+	index   bool
+}
+
+func (c *fakeColumn) Name() string       { return c.name }
+func (c *fakeColumn) IsPrimary() bool    { return c.primary }
+func (c *fakeColumn) IsIndex() bool      { return c.index }
+func (c *fakeColumn) IsSearchable() bool { return false }
+
+// fakeWidthColumn is like sqlchemy.SBaseWidthColumn, width is unexported
+type fakeWidthColumn struct {
+	fakeColumn
+	width int
+}
+
+// fakeTextColumn is like sqlchemy.STextColumn
+type fakeTextColumn struct {
+	fakeWidthColumn
+	Charset  string
+	def      string
+	nullable bool
+}
+
+func (c *fakeTextColumn) IsText() bool     { return true }
+func (c *fakeTextColumn) IsNullable() bool { return c.nullable }
+func (c *fakeTextColumn) Default() string  { return c.def }
+
+type fakeTableSpec struct {
+	columns []interface{}
+}
+
+func (s *fakeTableSpec) Columns() []interface{} { return s.columns }
+
+type fakeTableManager struct {
+	fakeManager
+	spec *fakeTableSpec
+}
+
+func (m fakeTableManager) TableSpec() *fakeTableSpec { return m.spec }
+
+func TestTableColumns(t *testing.T) {
+	man := fakeTableManager{
+		fakeManager: fakeManager{"server"},
+		spec: &fakeTableSpec{columns: []interface{}{
+			&fakeColumn{name: "id", primary: true},
+			&fakeColumn{name: "name", index: true},
+			&fakeColumn{name: "description"},
+			&fakeTextColumn{fakeWidthColumn: fakeWidthColumn{fakeColumn{name: "status"}, 36}, Charset: "ascii", def: "ready"},
+		}},
+	}
+	want := []Column{
+		{Name: "id", Filterable: true},
+		{Name: "name", Filterable: true},
+		{Name: "description"},
+		{Name: "status", Width: 36, Default: "ready", NotNull: true, Charset: "ascii"},
+	}
+	if got := TableColumns(man); !reflect.DeepEqual(got, want) {
+		t.Errorf("TableColumns() = %+v, want %+v", got, want)
+	}
+	if got := TableColumns(fakeTableManager{fakeManager: fakeManager{"server"}}); got != nil {
+		t.Errorf("TableColumns() without table spec = %+v, want nil", got)
+	}
+	if got := TableColumns(fakeManager{"server"}); got != nil {
+		t.Errorf("TableColumns() of manager without TableSpec = %+v, want nil", got)
+	}
+}
@@ -0,0 +1,112 @@
+package onecloudshim
+
+import (
+	"reflect"
+)
+
+// Column is a column of model table, every column can be used in order_by of list
+type Column struct {
+	Name string
+	// Filterable is true if column is searchable, indexed or primary, so it's usable by filter of list
+	Filterable bool
+	// Width is the max length of text column, 0 if not limited or not text
+	Width int
+	// Default is the default value of column, empty if none
+	Default string
+	// NotNull is true if column doesn't accept null
+	NotNull bool
+	// Charset is the charset of text column, e.g. ascii or utf8
+	Charset string
+}
+
+// callResult calls method name without arguments of v, returns the single result if it's not nil
+func callResult(v reflect.Value, name string) (reflect.Value, bool) {
+	m := v.MethodByName(name)
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return reflect.Value{}, false
+	}
+	out := m.Call(nil)[0]
+	switch out.Kind() {
+	case reflect.Interface, reflect.Ptr, reflect.Slice:
+		if out.IsNil() {
+			return reflect.Value{}, false
+		}
+	}
+	return out, true
+}
+
+// columnFlag returns result of bool method name of column, false if not implemented
+func columnFlag(col interface{}, name string) bool {
+	out, ok := callResult(reflect.ValueOf(col), name)
+	return ok && out.Kind() == reflect.Bool && out.Bool()
+}
+
+// columnString returns result of string method name of column, empty if not implemented
+func columnString(col interface{}, name string) string {
+	out, ok := callResult(reflect.ValueOf(col), name)
+	if !ok || out.Kind() != reflect.String {
+		return ""
+	}
+	return out.String()
+}
+
+// columnField returns field name of column struct, which may be unexported or promoted from embedded base column
+func columnField(col interface{}, name string) reflect.Value {
+	v := reflect.Indirect(reflect.ValueOf(col))
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+	return v.FieldByName(name)
+}
+
+// textColumn sets width and charset of column col if it's text, e.g. sqlchemy.STextColumn
+func textColumn(col interface{}, ret *Column) {
+	if !columnFlag(col, "IsText") {
+		return
+	}
+	if width := columnField(col, "width"); width.IsValid() && width.Kind() == reflect.Int && width.Int() > 0 {
+		ret.Width = int(width.Int())
+	}
+	if charset := columnField(col, "Charset"); charset.IsValid() && charset.Kind() == reflect.String {
+		ret.Charset = charset.String()
+	} else if columnFlag(col, "IsAscii") {
+		ret.Charset = "ascii"
+	}
+}
+
+// TableColumns returns columns of man table spec, nil if man has no table spec, e.g. fake managers.
+// TableSpec, Columns and column properties are got by reflection because their types,
+// e.g. db.ITableSpec and sqlchemy.IColumnSpec, differ among onecloud versions.
+func TableColumns(man ModelManager) []Column {
+	if man == nil {
+		return nil
+	}
+	spec, ok := callResult(reflect.ValueOf(man), "TableSpec")
+	if !ok {
+		return nil
+	}
+	cols, ok := callResult(spec, "Columns")
+	if !ok || cols.Kind() != reflect.Slice {
+		return nil
+	}
+	ret := make([]Column, 0, cols.Len())
+	for i := 0; i < cols.Len(); i++ {
+		col := cols.Index(i).Interface()
+		named, ok := col.(interface{ Name() string })
+		if !ok {
+			continue
+		}
+		column := Column{
+			Name:       named.Name(),
+			Filterable: columnFlag(col, "IsSearchable") || columnFlag(col, "IsIndex") || columnFlag(col, "IsPrimary"),
+			Default:    columnString(col, "Default"),
+		}
+		// columns not implementing IsNullable, e.g. fake ones, are nullable like sqlchemy defaults
+		if out, ok := callResult(reflect.ValueOf(col), "IsNullable"); ok && out.Kind() == reflect.Bool {
+			column.NotNull = !out.Bool()
+		}
+		textColumn(col, &column)
+		ret = append(ret, column)
+	}
+	return ret
+}
@@ -0,0 +1,161 @@
+// Package command is the swagger-gen command. Builds of out-of-tree services run Main
+// from their own main package, which imports the package registering the services
+// generated by models-pkg-gen --service.
+package command
+
+import (
+	goflag "flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/pflag"
+	"k8s.io/klog/v2"
+
+	"yunion.io/x/code-generator/pkg/common"
+	"yunion.io/x/code-generator/pkg/models"
+	"yunion.io/x/code-generator/pkg/swagger-gen/generators"
+)
+
+// Main runs swagger-gen with command line arguments
+func Main() {
+	klog.InitFlags(nil)
+	arguments, customArgs := generators.NewDefaults()
+	loaderArgs := &common.LoaderArgs{}
+	arguments.AddFlags(pflag.CommandLine)
+	customArgs.AddFlags(pflag.CommandLine)
+	loaderArgs.AddFlags(pflag.CommandLine)
+	pflag.CommandLine.AddGoFlagSet(goflag.CommandLine)
+	pflag.Parse()
+	common.SetupLogging(&loaderArgs.Log)
+	// outputs of other resources are missing rather than stale with --only
+	if len(customArgs.Only) != 0 && loaderArgs.Prune {
+		klog.Warningf("--prune is disabled with --only")
+		loaderArgs.Prune = false
+	}
+
+	if err := models.Initialize(customArgs.LoadServices, customArgs.DuplicateManagers); err != nil {
+		klog.Errorf("Initialize models: %v", err)
+		os.Exit(1)
+	}
+
+	if err := Generate(arguments, customArgs, loaderArgs); err != nil {
+		klog.Errorf("Error: %v", err)
+		os.Exit(1)
+	}
+
+	if !loaderArgs.Watch.Enabled {
+		return
+	}
+	inputs := arguments.InputDirs
+	dirs, err := common.PackageDirs(inputs, loaderArgs)
+	if err != nil {
+		klog.Errorf("Watch: %v", err)
+		os.Exit(1)
+	}
+	// input packages are generated into one output package, all of them are regenerated
+	loaderArgs.Watch.Watch(dirs, func([]string) error {
+		arguments.InputDirs = inputs
+		if err := models.Reinitialize(customArgs.LoadServices, customArgs.DuplicateManagers); err != nil {
+			return fmt.Errorf("initialize models: %v", err)
+		}
+		return Generate(arguments, customArgs, loaderArgs)
+	})
+}
+
+// Generate executes a run of swagger-gen and writes the reports collected by it,
+// model managers of the inputs must be registered by models.Initialize first
+func Generate(arguments *common.GeneratorArgs, customArgs *generators.CustomArgs, loaderArgs *common.LoaderArgs) error {
+	run, err := generators.NewGeneration(customArgs, arguments.OutputPackagePath, loaderArgs.Tags)
+	if err != nil {
+		return err
+	}
+	if err := common.ExecuteWithReports(
+		arguments,
+		loaderArgs,
+		generators.NameSystems(),
+		generators.DefaultNameSystem(),
+		run.Packages,
+		func() ([]string, error) {
+			return writeReports(run, customArgs, loaderArgs)
+		},
+	); err != nil {
+		return err
+	}
+	// checked at last, reports are still written for the registered ones
+	if customArgs.RequireRegisteredManagers {
+		return run.CheckRegisteredManagers()
+	}
+	return nil
+}
+
+// writeReports writes the reports of run selected by customArgs, returns the files written
+func writeReports(run *generators.Generation, customArgs *generators.CustomArgs, loaderArgs *common.LoaderArgs) ([]string, error) {
+	if unmatched := run.UnmatchedOnly(); len(unmatched) != 0 {
+		klog.Warningf("--only %v match no resource", unmatched)
+	}
+	var statsTable io.Writer = os.Stderr
+	if loaderArgs.Log.Quiet {
+		statsTable = nil
+	}
+	if err := run.WriteStats(statsTable, customArgs.StatsFile); err != nil {
+		return nil, fmt.Errorf("write stats: %v", err)
+	}
+	if err := run.WriteCoverageReport(customArgs.CoverageReport); err != nil {
+		return nil, fmt.Errorf("write coverage report: %v", err)
+	}
+	if err := run.WriteSourceMap(customArgs.SourceMap); err != nil {
+		return nil, fmt.Errorf("write source map: %v", err)
+	}
+	if err := run.WriteRouteTable(customArgs.RouteTable); err != nil {
+		return nil, fmt.Errorf("write route table: %v", err)
+	}
+	if err := run.WriteMetricsLabels(customArgs.MetricsLabels); err != nil {
+		return nil, fmt.Errorf("write metrics labels: %v", err)
+	}
+	if err := run.WriteGatewayConfig(customArgs.GatewayConfig, customArgs.GatewayFormat, customArgs.GatewayUpstream); err != nil {
+		return nil, fmt.Errorf("write gateway config: %v", err)
+	}
+	if err := run.WritePolicySkeleton(customArgs.PolicySkeleton); err != nil {
+		return nil, fmt.Errorf("write policy skeleton: %v", err)
+	}
+	if err := run.WriteMockServer(customArgs.MockServer); err != nil {
+		return nil, fmt.Errorf("write mock server: %v", err)
+	}
+	if err := run.WriteContractTest(customArgs.ContractTest); err != nil {
+		return nil, fmt.Errorf("write contract test: %v", err)
+	}
+	if err := run.WriteAPIInterfaces(customArgs.APIInterfaces); err != nil {
+		return nil, fmt.Errorf("write api interfaces: %v", err)
+	}
+	if err := run.WriteAPIMocks(customArgs.APIMocks); err != nil {
+		return nil, fmt.Errorf("write api mocks: %v", err)
+	}
+	if err := run.WriteInputConstructors(customArgs.InputConstructors); err != nil {
+		return nil, fmt.Errorf("write input constructors: %v", err)
+	}
+	if err := run.WriteExternalDefinitions(customArgs.ExternalDefinitionsFile); err != nil {
+		return nil, fmt.Errorf("write external definitions: %v", err)
+	}
+	files := make([]string, 0)
+	for _, file := range []string{
+		customArgs.StatsFile,
+		customArgs.CoverageReport,
+		customArgs.SourceMap,
+		customArgs.RouteTable,
+		customArgs.MetricsLabels,
+		customArgs.GatewayConfig,
+		customArgs.PolicySkeleton,
+		customArgs.MockServer,
+		customArgs.ContractTest,
+		customArgs.APIInterfaces,
+		customArgs.APIMocks,
+		customArgs.InputConstructors,
+		customArgs.ExternalDefinitionsFile,
+	} {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
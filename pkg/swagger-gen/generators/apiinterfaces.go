@@ -0,0 +1,142 @@
+package generators
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"strings"
+
+	"yunion.io/x/pkg/util/sets"
+)
+
+// apiIdent converts words joined by _ or - like guest_disk to GuestDisk
+func apiIdent(s string) string {
+	parts := strings.FieldsFunc(invalidIdentChars.ReplaceAllString(s, "_"), func(r rune) bool { return r == '_' })
+	for i, part := range parts {
+		parts[i] = strings.ToUpper(part[:1]) + part[1:]
+	}
+	return strings.Join(parts, "")
+}
+
+// apiParamName converts path param like disk_id to diskId
+func apiParamName(param string) string {
+	name := apiIdent(param)
+	if name == "" {
+		return "param"
+	}
+	return constructorParamName(name)
+}
+
+// apiParam is a parameter of API method
+type apiParam struct {
+	name string
+	typ  string
+}
+
+// apiMethod is a method of resource API interface
+type apiMethod struct {
+	name string
+	// params are the parameters, e.g. ctx context.Context, id string, input *compute.ServerStartInput
+	params []apiParam
+	// output is the type of returned value besides error, e.g. *compute.ServerDetails, empty if only error is returned
+	output string
+}
+
+func (m *apiMethod) signature() string {
+	params := make([]string, 0, len(m.params))
+	for _, p := range m.params {
+		params = append(params, fmt.Sprintf("%s %s", p.name, p.typ))
+	}
+	result := "error"
+	if m.output != "" {
+		result = fmt.Sprintf("(%s, error)", m.output)
+	}
+	return fmt.Sprintf("%s(%s) %s", m.name, strings.Join(params, ", "), result)
+}
+
+// newAPIMethod returns method of route, operation id like server_PerformStart is split into resource and method name
+func newAPIMethod(imports *mockImports, r RouteInfo) (string, *apiMethod, bool) {
+	idx := strings.Index(r.OperationId, "_")
+	if idx <= 0 || idx == len(r.OperationId)-1 {
+		return "", nil, false
+	}
+	resource, name := apiIdent(r.OperationId[:idx]), apiIdent(r.OperationId[idx+1:])
+	if resource == "" || name == "" {
+		return "", nil, false
+	}
+	m := &apiMethod{name: name, params: []apiParam{{"ctx", "context.Context"}}}
+	for _, match := range pathParamRegexp.FindAllString(r.Path, -1) {
+		m.params = append(m.params, apiParam{apiParamName(strings.Trim(match, "{}")), "string"})
+	}
+	if r.Input != "" {
+		ref := imports.typeRef(r.Input)
+		if ref == "" {
+			ref = "interface{}"
+		} else {
+			ref = "*" + ref
+		}
+		m.params = append(m.params, apiParam{"input", ref})
+	}
+	if output := strings.TrimPrefix(r.Output, "[]"); output != "" {
+		ref := imports.typeRef(output)
+		switch {
+		case ref == "":
+			ref = "interface{}"
+		case output != r.Output:
+			ref = "[]" + ref
+		default:
+			ref = "*" + ref
+		}
+		m.output = ref
+	}
+	return resource, m, true
+}
+
+// apiResources returns API methods of routes by resource,
+// methods are named by operation ids, routes of duplicated operation id are skipped
+func apiResources(imports *mockImports, routes []RouteInfo) map[string][]*apiMethod {
+	resources := make(map[string][]*apiMethod)
+	declared := sets.NewString()
+	for _, r := range routes {
+		resource, m, ok := newAPIMethod(imports, r)
+		if !ok || declared.Has(resource+"."+m.name) {
+			continue
+		}
+		declared.Insert(resource + "." + m.name)
+		resources[resource] = append(resources[resource], m)
+	}
+	return resources
+}
+
+// renderAPIInterfaces renders go source declaring <Resource>API interface of each resource
+func renderAPIInterfaces(pkgName string, routes []RouteInfo) ([]byte, error) {
+	imports := &mockImports{aliases: make(map[string]string), used: sets.NewString("context")}
+	resources := apiResources(imports, routes)
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "// Code generated by swagger-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(buf, "package %s\n\n", pkgName)
+	fmt.Fprintf(buf, "import (\n\"context\"\n\n%s\n)\n\n", strings.Join(imports.imports(), "\n"))
+	for _, resource := range sets.StringKeySet(resources).List() {
+		fmt.Fprintf(buf, "// %sAPI is the API surface of %s, implemented by clients and mocks\n", resource, resource)
+		fmt.Fprintf(buf, "type %sAPI interface {\n", resource)
+		for _, m := range resources[resource] {
+			fmt.Fprintf(buf, "%s\n", m.signature())
+		}
+		buf.WriteString("}\n\n")
+	}
+	return format.Source(buf.Bytes())
+}
+
+// WriteAPIInterfaces write go interfaces of resources describing collected routes to file
+func (run *Generation) WriteAPIInterfaces(file string) error {
+	if file == "" {
+		return nil
+	}
+	content, err := renderAPIInterfaces(run.routePackage, run.sortedRoutes())
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, content, 0644)
+}
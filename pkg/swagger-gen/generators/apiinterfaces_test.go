@@ -0,0 +1,37 @@
+package generators
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_renderAPIInterfaces(t *testing.T) {
+	routes := []RouteInfo{
+		{Method: "GET", Path: "/servers", OperationId: "server_List", Input: "yunion.io/x/onecloud/pkg/apis/compute.ServerListInput", Output: "[]yunion.io/x/onecloud/pkg/apis/compute.ServerDetails"},
+		{Method: "GET", Path: "/servers/{id}", OperationId: "server_Get", Output: "yunion.io/x/onecloud/pkg/apis/compute.ServerDetails"},
+		{Method: "POST", Path: "/servers/{id}/start", OperationId: "server_PerformStart", Input: "yunion.io/x/onecloud/pkg/apis/compute.ServerStartInput", Output: "yunion.io/x/onecloud/pkg/apis/compute.ServerDetails"},
+		{Method: "DELETE", Path: "/servers/{id}/disks/{disk_id}", OperationId: "guestdisk_Detach"},
+		{Method: "GET", Path: "/v2/servers", OperationId: "server_List"},
+		{Method: "GET", Path: "/version", OperationId: "version"},
+	}
+	content, err := renderAPIInterfaces("compute", routes)
+	if err != nil {
+		t.Fatalf("renderAPIInterfaces error: %v", err)
+	}
+	for _, want := range []string{
+		"package compute\n",
+		`compute "yunion.io/x/onecloud/pkg/apis/compute"`,
+		"type ServerAPI interface {\n",
+		"\tList(ctx context.Context, input *compute.ServerListInput) ([]compute.ServerDetails, error)\n",
+		"\tGet(ctx context.Context, id string) (*compute.ServerDetails, error)\n",
+		"\tPerformStart(ctx context.Context, id string, input *compute.ServerStartInput) (*compute.ServerDetails, error)\n",
+		"type GuestdiskAPI interface {\n\tDetach(ctx context.Context, id string, diskId string) error\n}",
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("api interfaces missing %q:\n%s", want, content)
+		}
+	}
+	if strings.Count(string(content), "List(") != 1 || strings.Contains(string(content), "Version") {
+		t.Errorf("api interfaces should skip duplicated and unscoped operations:\n%s", content)
+	}
+}
@@ -0,0 +1,29 @@
+package generators
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_renderAPIMocks(t *testing.T) {
+	routes := []RouteInfo{
+		{Method: "GET", Path: "/servers/{id}", OperationId: "server_Get", Output: "yunion.io/x/onecloud/pkg/apis/compute.ServerDetails"},
+		{Method: "DELETE", Path: "/servers/{id}", OperationId: "server_Delete"},
+	}
+	content, err := renderAPIMocks("compute", routes)
+	if err != nil {
+		t.Fatalf("renderAPIMocks error: %v", err)
+	}
+	for _, want := range []string{
+		`"github.com/stretchr/testify/mock"`,
+		"type MockServerAPI struct {\n\tmock.Mock\n}",
+		"var _ ServerAPI = (*MockServerAPI)(nil)",
+		"func (m *MockServerAPI) Get(ctx context.Context, id string) (*compute.ServerDetails, error) {\n\tret := m.Called(ctx, id)\n",
+		"\t\tout = v.(*compute.ServerDetails)\n",
+		"func (m *MockServerAPI) Delete(ctx context.Context, id string) error {\n\tret := m.Called(ctx, id)\n\treturn ret.Error(0)\n}",
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("api mocks missing %q:\n%s", want, content)
+		}
+	}
+}
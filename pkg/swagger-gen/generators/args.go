@@ -0,0 +1,157 @@
+package generators
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/pflag"
+
+	"yunion.io/x/code-generator/pkg/common"
+	"yunion.io/x/code-generator/pkg/models/registry"
+	"yunion.io/x/code-generator/pkg/onecloudshim"
+)
+
+// CustomArgs is the swagger-gen specific command line arguments
+type CustomArgs struct {
+	// CoverageReport is the file to write models API coverage report, not written if empty
+	CoverageReport string
+	// LoadServices are the onecloud services which model managers are loaded, all if empty
+	LoadServices []string
+	// DuplicateManagers deduplicates managers registered by several services
+	DuplicateManagers registry.Dedup
+	// RequireRegisteredManagers fails generation if models are skipped for unregistered managers
+	RequireRegisteredManagers bool
+	// StatsFile is the file to write generation stats of packages as json, not written if empty
+	StatsFile string
+	// SourceMap is the file to write operation id to source method mapping, not written if empty
+	SourceMap string
+	// ListPagination is the default pagination mode of model list routes, offset or marker
+	ListPagination string
+	// Lang is the language of route summary and description, en or zh, comments are used if empty
+	Lang string
+	// AllDocComments exports all doc comment lines of routes, otherwise only the ones below +onecloud:doc or the godoc paragraph
+	AllDocComments bool
+	// ExactSignatures matches model methods by exact number of parameters and takes query and body by position
+	ExactSignatures bool
+	// DefinitionNaming is the naming strategy of body definitions, plain, package or service
+	DefinitionNaming string
+	// RouteTable is the go file to write GeneratedRoutes table, not written if empty
+	RouteTable string
+	// MetricsLabels is the go file to write operation id constants and labels of routes, not written if empty
+	MetricsLabels string
+	// GatewayConfig is the file to write api gateway config of routes, not written if empty
+	GatewayConfig string
+	// GatewayFormat is the format of GatewayConfig, kong or nginx
+	GatewayFormat string
+	// GatewayUpstream is the service url routes are proxied to, e.g. http://region:8889
+	GatewayUpstream string
+	// PolicySkeleton is the file to write rbac policy skeleton of routes, not written if empty
+	PolicySkeleton string
+	// ListCommonParams injects standard list query params into all list routes
+	ListCommonParams bool
+	// FilterDocs appends filter query operators and filterable fields to list route descriptions
+	FilterDocs bool
+	// CrossReferences links input fields like zone_id to the registered resources they refer
+	CrossReferences bool
+	// HeadRoutes emits HEAD route checking existence of resource along with GET route
+	HeadRoutes bool
+	// ShareParameters emits one parameters struct for operations with identical parameters
+	ShareParameters bool
+	// NamedBodyWrappers declares request body wrappers by resource key once as named types
+	NamedBodyWrappers bool
+	// MockServer is the file net/http mock server of generated routes written to
+	MockServer string
+	// ContractTest is the _test.go file json contract tests of route types written to
+	ContractTest string
+	// APIInterfaces is the go file <Resource>API interfaces of generated routes written to
+	APIInterfaces string
+	// APIMocks is the go file testify mocks of APIInterfaces written to
+	APIMocks string
+	// InputConstructors is the go file NewXxx constructors of create and perform input types written to
+	InputConstructors string
+	// ExternalDefinitions are package prefixes of body types referred as shared definitions
+	ExternalDefinitions []string
+	// ExternalDefinitionsFile is the go file declaring models of referred external types
+	ExternalDefinitionsFile string
+	// TypeOverrides is the yaml file mapping go types to swagger type, format and example
+	TypeOverrides string
+	// NullablePointers annotates pointer query fields by x-nullable extension
+	NullablePointers bool
+	// APIVersionHeader adds X-Yunion-Api-Version header parameter to all routes
+	APIVersionHeader bool
+	// ListResponseHeaders are standard headers emitted on list responses, e.g. X-Total-Count
+	ListResponseHeaders []string
+	// ProfilesFile is the yaml file of deployment profiles, host, base path and schemes of spec by name
+	ProfilesFile string
+	// Profile is the deployment profile in ProfilesFile emitted in doc package, the local one if empty
+	Profile string
+	// Only are the resources generated, by keyword, plural keyword or type name, all if empty
+	Only []string
+	// APIVersion is the api version to generate, types and methods tagged with other versions are skipped
+	APIVersion string
+}
+
+// NewDefaults returns default arguments for swagger-gen
+func NewDefaults() (*common.GeneratorArgs, *CustomArgs) {
+	genericArgs := common.NewGeneratorArgs()
+	customArgs := &CustomArgs{
+		ListPagination:   PaginationOffset,
+		DefinitionNaming: DefinitionNamingPlain,
+		GatewayFormat:    GatewayFormatKong,
+		APIVersion:       common.DefaultAPIVersion,
+	}
+	genericArgs.CustomArgs = customArgs
+	genericArgs.OutputFileBaseName = "zz_generated.swagger_spec"
+	genericArgs.GoHeaderFilePath = filepath.Join(common.DefaultSourceTree(), "yunion.io/x/onecloud/scripts/copyright.txt")
+	return genericArgs, customArgs
+}
+
+// AddFlags add swagger-gen specific flags to fs
+func (ca *CustomArgs) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&ca.CoverageReport, "coverage-report", ca.CoverageReport, "Write API coverage report of models to this json file")
+	fs.StringSliceVar(&ca.LoadServices, "load-services", ca.LoadServices, fmt.Sprintf("Onecloud services to load model managers from, choices: %v", onecloudshim.ServiceNames()))
+	ca.DuplicateManagers.AddFlags(fs)
+	fs.BoolVar(&ca.RequireRegisteredManagers, "require-registered-managers", ca.RequireRegisteredManagers, "List models skipped because their managers are not registered by loaded services and exit non-zero")
+	fs.StringVar(&ca.StatsFile, "stats-file", ca.StatsFile, "Write generation stats of input packages, models found, routes by verb, functions and skipped items with reasons, to this json file, the stats table is printed at the end of run unless --quiet")
+	fs.StringVar(&ca.SourceMap, "source-map", ca.SourceMap, "Write operation id to source method mapping to this json file, used by swagger-serve validate")
+	fs.StringVar(&ca.ListPagination, "list-pagination", ca.ListPagination, fmt.Sprintf("Default pagination of list routes, choices: %s, %s", PaginationOffset, PaginationMarker))
+	fs.StringVar(&ca.Lang, "lang", ca.Lang, fmt.Sprintf("Language of route summary and description, choices: %v, other languages are emitted as x-summary-<lang> and x-description-<lang> extensions", supportedLangs))
+	fs.BoolVar(&ca.AllDocComments, "all-doc-comments", ca.AllDocComments, "Export all doc comment lines of routes as before, by default only the lines below +onecloud:doc or the godoc paragraph starting with method name are exported")
+	fs.BoolVar(&ca.ExactSignatures, "exact-signatures", ca.ExactSignatures, "Match model methods like ValidateCreateData by exact number of parameters and take query and body by position as before, by default they are found by type so methods with extra parameters like ownerId are not skipped")
+	fs.StringVar(&ca.RouteTable, "route-table", ca.RouteTable, "Write generated routes as GeneratedRoutes go table to this file, package is the same as --output-package")
+	fs.StringVar(&ca.MetricsLabels, "metrics-labels", ca.MetricsLabels, "Write operation id constants and OperationLabels mapping them to method and path template to this go file, package is the same as --output-package")
+	fs.StringVar(&ca.GatewayConfig, "gateway-config", ca.GatewayConfig, "Write api gateway config of generated routes to this file")
+	fs.StringVar(&ca.GatewayFormat, "gateway-format", ca.GatewayFormat, fmt.Sprintf("Format of --gateway-config, choices: %v", gatewayFormats))
+	fs.StringVar(&ca.GatewayUpstream, "gateway-upstream", ca.GatewayUpstream, "Upstream service url of --gateway-config, e.g. http://region:8889, path is only supported by kong format")
+	fs.StringVar(&ca.PolicySkeleton, "policy-skeleton", ca.PolicySkeleton, "Write rbac policy yaml skeleton of generated routes to this file")
+	fs.BoolVar(&ca.ListCommonParams, "list-common-params", ca.ListCommonParams, "Inject standard list query params like limit, offset, order_by, filter into all list routes")
+	fs.BoolVar(&ca.FilterDocs, "filter-docs", ca.FilterDocs, "Append filter query syntax like filter=name.contains(web), its operators and fields of list item to description of list routes")
+	fs.BoolVar(&ca.CrossReferences, "cross-references", ca.CrossReferences, "Link input fields like zone_id or network_ids to resources of registered managers by x-onecloud-refs extension and description")
+	fs.BoolVar(&ca.HeadRoutes, "head-routes", ca.HeadRoutes, "Emit HEAD /<plural>/{id} routes checking existence of resources, mirroring GET routes without response body")
+	fs.BoolVar(&ca.NamedBodyWrappers, "named-body-wrappers", ca.NamedBodyWrappers, "Declare request body wrappers by resource key, e.g. ServerCreateBody of {\"server\": ServerCreateInput}, once as named types shared by operations instead of anonymous structs")
+	fs.BoolVar(&ca.ShareParameters, "share-parameters", ca.ShareParameters, "Share one swagger:parameters definition among operations with identical parameters to reduce spec size")
+	fs.StringVar(&ca.MockServer, "mock-server", ca.MockServer, "Write net/http mock server go source serving generated routes with example outputs to file")
+	fs.StringVar(&ca.ContractTest, "contract-test", ca.ContractTest, "Write go test checking json round trip, field uniqueness and required fields of route input and output types to _test.go file")
+	fs.StringVar(&ca.APIInterfaces, "api-interfaces", ca.APIInterfaces, "Write <Resource>API go interfaces with methods of generated routes like List, Get, PerformStart to this file, package is the same as --output-package")
+	fs.StringVar(&ca.APIMocks, "api-mocks", ca.APIMocks, "Write testify mocks Mock<Resource>API of --api-interfaces to this file, package is the same as --output-package")
+	fs.StringVar(&ca.InputConstructors, "input-constructors", ca.InputConstructors, "Write NewXxx constructors of create and perform input types, taking required fields as parameters, to this go file, package is the same as --output-package")
+	fs.StringSliceVar(&ca.ExternalDefinitions, "external-definitions", ca.ExternalDefinitions, "Package prefixes, e.g. yunion.io/x/onecloud/pkg/apis, of body types referred as #/definitions/<pkg>.<Type> placeholders, which are filled by swagger-serve link")
+	fs.StringVar(&ca.ExternalDefinitionsFile, "external-definitions-file", ca.ExternalDefinitionsFile, "Write go source declaring models of referred external types to file, scan it once to generate the shared definitions spec")
+	fs.StringVar(&ca.TypeOverrides, "type-overrides", ca.TypeOverrides, "Yaml file mapping go type names like time.Time to swagger type, format and example of fields")
+	fs.BoolVar(&ca.NullablePointers, "nullable-pointers", ca.NullablePointers, "Annotate pointer fields of flattened query parameters as nullable by x-nullable extension, pointer fields of body types are annotated by model-api-gen --nullable-pointers")
+	fs.BoolVar(&ca.APIVersionHeader, "api-version-header", ca.APIVersionHeader, fmt.Sprintf("Add %s header parameter the gateway negotiates api version by to all routes, by one shared parameters definition per generated file", APIVersionHeader))
+	fs.StringSliceVar(&ca.ListResponseHeaders, "list-response-headers", ca.ListResponseHeaders, "Standard headers of list responses SDKs may read totals from, choices: X-Total-Count, Content-Range")
+	fs.StringVar(&ca.ProfilesFile, "profiles-file", ca.ProfilesFile, "Yaml file of deployment profiles like dev and prod, each with host, basePath and schemes of the spec")
+	fs.StringVar(&ca.Profile, "profile", ca.Profile, "Deployment profile of --profiles-file which host, basePath and schemes are emitted in doc package, 127.0.0.1:8889 with https and http if empty")
+	fs.StringSliceVar(&ca.Only, "only", ca.Only, "Generate only these resources, by keyword like server, plural keyword like servers, model or manager type name like SGuest, or function name or route tag of function routes, for fast iteration on some models, generated files miss routes of other resources")
+	fs.StringVar(&ca.APIVersion, "api-version", ca.APIVersion, fmt.Sprintf("API version to generate, types and methods tagged +%s with other versions are skipped, routes and spec of versions other than %s are prefixed by /<version> and written to <output-package>/<version>", common.TagAPIVersion, common.DefaultAPIVersion))
+	fs.StringVar(&ca.DefinitionNaming, "definition-naming", ca.DefinitionNaming, fmt.Sprintf("Naming strategy of body definitions to avoid collisions when specs are merged, choices: %v", definitionNamings))
+}
+
+func getCustomArgs(arguments *common.GeneratorArgs) *CustomArgs {
+	if ca, ok := arguments.CustomArgs.(*CustomArgs); ok {
+		return ca
+	}
+	_, ca := NewDefaults()
+	return ca
+}
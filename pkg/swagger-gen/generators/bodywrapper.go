@@ -0,0 +1,86 @@
+package generators
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/types"
+)
+
+// bodyWrapperNamer names the structs wrapping request bodies by resource key, e.g.:
+//
+//	// ServerCreateBody wraps compute.ServerCreateInput by key server
+//	type ServerCreateBody struct {
+//		Input compute.ServerCreateInput `json:"server"`
+//	}
+//
+// operations with the same key and body type refer to one wrapper instead of anonymous structs
+type bodyWrapperNamer struct {
+	enabled bool
+	// names maps wrapper content to declared wrapper name
+	names map[string]string
+	// keys maps declared wrapper name to its content
+	keys map[string]string
+}
+
+func newBodyWrapperNamer(enabled bool) *bodyWrapperNamer {
+	return &bodyWrapperNamer{
+		enabled: enabled,
+		names:   make(map[string]string),
+		keys:    make(map[string]string),
+	}
+}
+
+// wrapperKey returns content identity of wrapper of body referred by ref and args under json key
+func wrapperKey(key, ref string, args interface{}, body *types.Type) string {
+	if args != nil {
+		ref = body.String()
+	}
+	return key + "|" + ref
+}
+
+// wrapperName returns go type name of wrapper of body under resource key, e.g. ServerCreateBody
+// of compute.ServerCreateInput by server, body types not named after resource are prefixed by it
+func wrapperName(key string, body *types.Type) string {
+	res := paramFieldName(key)
+	name := strings.TrimPrefix(body.Name.Name, res)
+	name = strings.TrimSuffix(name, "Input")
+	return invalidIdentChars.ReplaceAllString(res+name, "_") + "Body"
+}
+
+// name returns wrapper type name of body, the second value is false if wrapper is not declared yet
+func (n *bodyWrapperNamer) name(key, ref string, args interface{}, body *types.Type) (string, bool) {
+	content := wrapperKey(key, ref, args, body)
+	if name, ok := n.names[content]; ok {
+		return name, true
+	}
+	base := wrapperName(key, body)
+	name := base
+	for i := 2; ; i++ {
+		if _, ok := n.keys[name]; !ok {
+			break
+		}
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	n.names[content] = name
+	n.keys[name] = content
+	return name, false
+}
+
+// declare emits wrapper of body of parameter r once, returns the wrapper type name
+func (n *bodyWrapperNamer) declare(r parameter, sw *generator.SnippetWriter) string {
+	body := r.getBody()
+	ref, args := r.bodyRef()
+	name, declared := n.name(r.singular, ref, args, body)
+	if declared {
+		return name
+	}
+	h := newSW(sw)
+	sw.Do(fmt.Sprintf("// %s wraps %s by key %s\n", name, body.String(), r.singular), nil)
+	sw.Do(fmt.Sprintf("type %s struct {\n", name), nil)
+	doTypeOverride(r.run.typeOverrides, body, h)
+	sw.Do(fmt.Sprintf("Input %s `json:\"%s\"`\n", ref, r.singular), args)
+	sw.Do("}\n\n", nil)
+	return name
+}
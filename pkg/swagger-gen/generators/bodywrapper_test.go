@@ -0,0 +1,51 @@
+package generators
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/namer"
+	"k8s.io/gengo/v2/types"
+)
+
+func Test_bodyWrapperNamer(t *testing.T) {
+	createInput := &types.Type{Name: types.Name{Package: "yunion.io/x/onecloud/pkg/apis/compute", Name: "ServerCreateInput"}, Kind: types.Struct}
+	statusInput := &types.Type{Name: types.Name{Package: "yunion.io/x/onecloud/pkg/apis", Name: "PerformStatusInput"}, Kind: types.Struct}
+	otherCreateInput := &types.Type{Name: types.Name{Package: "example.com/apis/compute", Name: "ServerCreateInput"}, Kind: types.Struct}
+	run := newTestGeneration(t, func(ca *CustomArgs) { ca.NamedBodyWrappers = true })
+
+	buf := &bytes.Buffer{}
+	sw := generator.NewSnippetWriter(buf, &generator.Context{Namers: namer.NameSystems{"raw": namer.NewRawNamer("", nil)}}, "$", "$")
+	params := []struct {
+		id   string
+		body *types.Type
+		want string
+	}{
+		{id: "server_ValidateCreateData", body: createInput, want: "ServerCreateBody"},
+		{id: "server_PerformRebuild", body: createInput, want: "ServerCreateBody"},
+		{id: "server_PerformStatus", body: statusInput, want: "ServerPerformStatusBody"},
+		{id: "server_PerformImport", body: otherCreateInput, want: "ServerCreateBody2"},
+	}
+	for _, p := range params {
+		r := newParameter("server", "servers", p.id)
+		r.body, r.run, r.out = p.body, run, newOutputPackage()
+		r.Do(sw)
+		if err := sw.Error(); err != nil {
+			t.Fatal(err)
+		}
+		if want := fmt.Sprintf("Body %s `json:\"body\"`", p.want); !strings.Contains(buf.String(), want) {
+			t.Errorf("parameter %s = %q, should contain %q", p.id, buf.String(), want)
+		}
+	}
+	for _, name := range []string{"ServerCreateBody", "ServerPerformStatusBody", "ServerCreateBody2"} {
+		if got := strings.Count(buf.String(), fmt.Sprintf("type %s struct {\n", name)); got != 1 {
+			t.Errorf("wrapper %s declared %d times, want 1", name, got)
+		}
+	}
+	if !strings.Contains(buf.String(), "Input apis.PerformStatusInput `json:\"server\"`\n") {
+		t.Errorf("wrapper of PerformStatusInput = %q, should wrap it by key server", buf.String())
+	}
+}
@@ -0,0 +1,112 @@
+package generators
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io/ioutil"
+	"strings"
+	"unicode"
+
+	"k8s.io/gengo/v2/types"
+	"k8s.io/klog/v2"
+
+	"yunion.io/x/pkg/util/sets"
+)
+
+func (run *Generation) recordConstructorType(t *types.Type) {
+	if t == nil || t.Kind != types.Struct {
+		return
+	}
+	run.constructorTypes[t.String()] = t
+}
+
+// constructorParamName converts member name to lower camel parameter name, e.g. VpcId to vpcId, OSType to osType
+func constructorParamName(name string) string {
+	runes := []rune(name)
+	i := 0
+	for i < len(runes) && unicode.IsUpper(runes[i]) {
+		i++
+	}
+	// keep the upper rune starting next word, e.g. T of OSType
+	if i > 1 && i < len(runes) {
+		i--
+	}
+	ret := strings.ToLower(string(runes[:i])) + string(runes[i:])
+	if token.IsKeyword(ret) || ret == "ret" {
+		ret += "_"
+	}
+	return ret
+}
+
+// constructorTypeExpr returns go expression of t in constructor file, packages are imported by imports
+func constructorTypeExpr(imports *mockImports, t *types.Type) string {
+	switch t.Kind {
+	case types.Pointer:
+		return "*" + constructorTypeExpr(imports, t.Elem)
+	case types.Slice:
+		return "[]" + constructorTypeExpr(imports, t.Elem)
+	case types.Map:
+		return fmt.Sprintf("map[%s]%s", constructorTypeExpr(imports, t.Key), constructorTypeExpr(imports, t.Elem))
+	case types.Interface:
+		if t.Name.Name == "" {
+			return "interface{}"
+		}
+	}
+	if ref := imports.typeRef(t.String()); ref != "" {
+		return ref
+	}
+	return t.Name.Name
+}
+
+// renderInputConstructors renders go source declaring NewXxx constructors of input types,
+// required members are the constructor parameters
+func renderInputConstructors(pkgName string, inputs map[string]*types.Type) ([]byte, error) {
+	imports := &mockImports{aliases: make(map[string]string), used: sets.NewString()}
+	funcs := &bytes.Buffer{}
+	declared := sets.NewString()
+	for _, name := range sets.StringKeySet(inputs).List() {
+		t := inputs[name]
+		ref := imports.typeRef(name)
+		if ref == "" {
+			continue
+		}
+		funcName := "New" + t.Name.Name
+		if declared.Has(funcName) {
+			klog.Warningf("constructor %s of %s is already declared, skipped", funcName, name)
+			continue
+		}
+		declared.Insert(funcName)
+		params, assigns := make([]string, 0), &bytes.Buffer{}
+		for _, m := range requiredMembers(t) {
+			param := constructorParamName(m.Name)
+			params = append(params, fmt.Sprintf("%s %s", param, constructorTypeExpr(imports, m.Type)))
+			fmt.Fprintf(assigns, "ret.%s = %s\n", m.Name, param)
+		}
+		fmt.Fprintf(funcs, "// %s returns %s with required fields set\n", funcName, ref)
+		fmt.Fprintf(funcs, "func %s(%s) *%s {\n", funcName, strings.Join(params, ", "), ref)
+		fmt.Fprintf(funcs, "ret := new(%s)\n%sreturn ret\n}\n\n", ref, assigns.String())
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "// Code generated by swagger-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(buf, "package %s\n\n", pkgName)
+	if imps := imports.imports(); len(imps) != 0 {
+		fmt.Fprintf(buf, "import (\n%s\n)\n\n", strings.Join(imps, "\n"))
+	}
+	buf.Write(funcs.Bytes())
+	return format.Source(buf.Bytes())
+}
+
+// WriteInputConstructors write NewXxx constructors of create and perform input types to go file
+func (run *Generation) WriteInputConstructors(file string) error {
+	if file == "" {
+		return nil
+	}
+	content, err := renderInputConstructors(run.routePackage, run.constructorTypes)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, content, 0644)
+}
@@ -0,0 +1,59 @@
+package generators
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/gengo/v2/types"
+)
+
+func Test_renderInputConstructors(t *testing.T) {
+	str := &types.Type{Name: types.Name{Name: "string"}, Kind: types.Builtin}
+	strs := &types.Type{Name: types.Name{Name: "[]string"}, Kind: types.Slice, Elem: str}
+	required := []string{"required: true"}
+	base := &types.Type{
+		Name: types.Name{Package: "yunion.io/x/onecloud/pkg/apis", Name: "VirtualResourceCreateInput"},
+		Kind: types.Struct,
+		Members: []types.Member{
+			{Name: "Name", Type: str, CommentLines: required},
+			{Name: "Description", Type: str},
+		},
+	}
+	config := &types.Type{Name: types.Name{Package: "yunion.io/x/onecloud/pkg/apis/compute", Name: "ServerConfigs"}, Kind: types.Struct}
+	create := &types.Type{
+		Name: types.Name{Package: "yunion.io/x/onecloud/pkg/apis/compute", Name: "ServerCreateInput"},
+		Kind: types.Struct,
+		Members: []types.Member{
+			{Name: "VirtualResourceCreateInput", Type: base, Embedded: true},
+			{Name: "ServerConfigs", Type: &types.Type{Kind: types.Pointer, Elem: config}, CommentLines: required},
+			{Name: "OSType", Type: str, CommentLines: required},
+			{Name: "Type", Type: str, CommentLines: required},
+		},
+	}
+	start := &types.Type{Name: types.Name{Package: "yunion.io/x/onecloud/pkg/apis/compute", Name: "ServerStartInput"}, Kind: types.Struct}
+	migrate := &types.Type{
+		Name:    types.Name{Package: "yunion.io/x/onecloud/pkg/apis/compute", Name: "ServerMigrateInput"},
+		Kind:    types.Struct,
+		Members: []types.Member{{Name: "PreferHosts", Type: strs, CommentLines: required}},
+	}
+	content, err := renderInputConstructors("compute", map[string]*types.Type{
+		create.String():  create,
+		start.String():   start,
+		migrate.String(): migrate,
+	})
+	if err != nil {
+		t.Fatalf("renderInputConstructors error: %v", err)
+	}
+	for _, want := range []string{
+		"package compute\n",
+		`compute "yunion.io/x/onecloud/pkg/apis/compute"`,
+		"func NewServerCreateInput(name string, serverConfigs *compute.ServerConfigs, osType string, type_ string) *compute.ServerCreateInput {",
+		"\tret.Name = name\n\tret.ServerConfigs = serverConfigs\n\tret.OSType = osType\n\tret.Type = type_\n",
+		"func NewServerStartInput() *compute.ServerStartInput {",
+		"func NewServerMigrateInput(preferHosts []string) *compute.ServerMigrateInput {",
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("input constructors missing %q:\n%s", want, content)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package generators
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"strings"
+
+	"k8s.io/gengo/v2/types"
+
+	"yunion.io/x/pkg/util/sets"
+
+	"yunion.io/x/code-generator/pkg/common"
+)
+
+// isRequiredLine returns true if comment line documents required like go-swagger, e.g.:
+// // required: true
+func isRequiredLine(l string) bool {
+	return strings.Replace(strings.TrimSpace(l), " ", "", -1) == "required:true"
+}
+
+func isRequiredMember(m types.Member) bool {
+	for _, l := range m.CommentLines {
+		if isRequiredLine(l) {
+			return true
+		}
+	}
+	return false
+}
+
+// requiredMembers returns required members including embedded ones,
+// members of embedded pointers are optional
+func requiredMembers(t *types.Type) []types.Member {
+	ret := make([]types.Member, 0)
+	for _, m := range t.Members {
+		if et, isPtr, ok := common.EmbeddedStruct(m); ok {
+			if !isPtr {
+				ret = append(ret, requiredMembers(et)...)
+			}
+			continue
+		}
+		if isRequiredMember(m) {
+			ret = append(ret, m)
+		}
+	}
+	return ret
+}
+
+// requiredJSONFields returns json names of required members
+func requiredJSONFields(t *types.Type) []string {
+	ret := make([]string, 0)
+	for _, m := range requiredMembers(t) {
+		ret = append(ret, common.MemberJSONName(m))
+	}
+	return ret
+}
+
+func (run *Generation) recordContractType(t *types.Type) {
+	if t == nil || t.Kind != types.Struct {
+		return
+	}
+	if _, ok := run.contractTypes[t.String()]; ok {
+		return
+	}
+	run.contractTypes[t.String()] = requiredJSONFields(t)
+}
+
+// contractTestRuntime checks contractTypes, it's emitted as is to contract test file
+const contractTestRuntime = `
+func TestContractRoundTrip(t *testing.T) {
+	for _, ct := range contractTypes {
+		t.Run(ct.name, func(t *testing.T) {
+			obj := ct.newObj()
+			fillContractSample(reflect.ValueOf(obj).Elem(), 0)
+			got := ct.newObj()
+			if err := jsonutils.Marshal(obj).Unmarshal(got); err != nil {
+				t.Fatalf("unmarshal %s: %v", ct.name, err)
+			}
+			if !reflect.DeepEqual(obj, got) {
+				t.Errorf("round trip of %s changed:\n%s\n%s", ct.name, jsonutils.Marshal(obj), jsonutils.Marshal(got))
+			}
+		})
+	}
+}
+
+func TestContractJSONFields(t *testing.T) {
+	for _, ct := range contractTypes {
+		t.Run(ct.name, func(t *testing.T) {
+			fields := make(map[string]contractField)
+			for _, err := range collectContractFields(reflect.TypeOf(ct.newObj()).Elem(), 0, "", fields) {
+				t.Error(err)
+			}
+			for _, name := range ct.required {
+				if _, ok := fields[name]; !ok {
+					t.Errorf("required field %s of %s not found", name, ct.name)
+				}
+			}
+		})
+	}
+}
+
+type contractField struct {
+	depth int
+	path  string
+}
+
+// collectContractFields collects json names of struct fields like jsonutils,
+// names duplicated at the same embedding depth are returned as errors
+func collectContractFields(t reflect.Type, depth int, prefix string, fields map[string]contractField) []error {
+	errs := make([]error, 0)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := strings.Split(f.Tag.Get("json"), ",")[0]
+		if tag == "-" {
+			continue
+		}
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && tag == "" && ft.Kind() == reflect.Struct {
+			errs = append(errs, collectContractFields(ft, depth+1, prefix+f.Name+".", fields)...)
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		name := tag
+		if name == "" {
+			name = utils.CamelSplit(f.Name, "_")
+		}
+		if exist, ok := fields[name]; ok {
+			if exist.depth == depth {
+				errs = append(errs, fmt.Errorf("json field %s is duplicated by %s and %s", name, exist.path, prefix+f.Name))
+			}
+			if exist.depth <= depth {
+				continue
+			}
+		}
+		fields[name] = contractField{depth: depth, path: prefix + f.Name}
+	}
+	return errs
+}
+
+// fillContractSample sets non-zero sample values to v
+func fillContractSample(v reflect.Value, depth int) {
+	if depth > 5 || !v.CanSet() {
+		return
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString("sample")
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(1)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(1.5)
+	case reflect.Ptr:
+		v.Set(reflect.New(v.Type().Elem()))
+		fillContractSample(v.Elem(), depth+1)
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		fillContractSample(v.Index(0), depth+1)
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return
+		}
+		m := reflect.MakeMap(v.Type())
+		key := reflect.New(v.Type().Key()).Elem()
+		key.SetString("sample")
+		val := reflect.New(v.Type().Elem()).Elem()
+		fillContractSample(val, depth+1)
+		m.SetMapIndex(key, val)
+		v.Set(m)
+	case reflect.Struct:
+		if v.Type() == reflect.TypeOf(time.Time{}) {
+			v.Set(reflect.ValueOf(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)))
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			fillContractSample(v.Field(i), depth+1)
+		}
+	}
+}
+`
+
+// renderContractTest renders go test source checking json contract of types
+func renderContractTest(pkgName string, contractTypes map[string][]string) ([]byte, error) {
+	imports := newMockImports()
+	imports.used.Insert("reflect", "strings", "testing", "time", "utils")
+	entries := &bytes.Buffer{}
+	for _, name := range sets.StringKeySet(contractTypes).List() {
+		ref := imports.typeRef(name)
+		if ref == "" {
+			continue
+		}
+		required := make([]string, 0)
+		for _, f := range contractTypes[name] {
+			required = append(required, fmt.Sprintf("%q", f))
+		}
+		fmt.Fprintf(entries, "{name: %q, newObj: %s, required: []string{%s}},\n", ref, mockNewFunc(ref), strings.Join(required, ", "))
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "// Code generated by swagger-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(buf, "package %s\n\n", pkgName)
+	buf.WriteString("import (\n\"fmt\"\n\"reflect\"\n\"strings\"\n\"testing\"\n\"time\"\n\n\"yunion.io/x/jsonutils\"\n\"yunion.io/x/pkg/utils\"\n\n")
+	buf.WriteString(strings.Join(imports.imports(), "\n"))
+	buf.WriteString("\n)\n\n")
+	buf.WriteString("// contractTypes are input and output types of generated routes\n")
+	buf.WriteString("var contractTypes = []struct {\nname string\nnewObj func() interface{}\nrequired []string\n}{\n")
+	buf.Write(entries.Bytes())
+	buf.WriteString("}\n")
+	buf.WriteString(contractTestRuntime)
+	return format.Source(buf.Bytes())
+}
+
+// WriteContractTest write go test checking json contract of route input and output types to file
+func (run *Generation) WriteContractTest(file string) error {
+	if file == "" {
+		return nil
+	}
+	if !strings.HasSuffix(file, "_test.go") {
+		return fmt.Errorf("contract test file %q should end with _test.go", file)
+	}
+	content, err := renderContractTest(run.routePackage, run.contractTypes)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, content, 0644)
+}
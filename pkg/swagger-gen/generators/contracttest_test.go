@@ -0,0 +1,58 @@
+package generators
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/gengo/v2/types"
+)
+
+func Test_requiredJSONFields(t *testing.T) {
+	str := &types.Type{Name: types.Name{Name: "string"}, Kind: types.Builtin}
+	base := &types.Type{
+		Name: types.Name{Name: "ResourceBaseCreateInput"},
+		Kind: types.Struct,
+		Members: []types.Member{
+			{Name: "Name", Type: str, CommentLines: []string{"resource name", "required: true"}},
+		},
+	}
+	input := &types.Type{
+		Name: types.Name{Package: "compute", Name: "ServerCreateInput"},
+		Kind: types.Struct,
+		Members: []types.Member{
+			{Embedded: true, Type: base},
+			{Name: "VcpuCount", Type: str, Tags: `json:"vcpu_count"`, CommentLines: []string{"required:true"}},
+			{Name: "Description", Type: str},
+			{Embedded: true, Type: &types.Type{Kind: types.Pointer, Elem: &types.Type{
+				Name:    types.Name{Name: "ProjectizedResourceInput"},
+				Kind:    types.Struct,
+				Members: []types.Member{{Name: "Project", Type: str, CommentLines: []string{"required: true"}}},
+			}}},
+		},
+	}
+	if got, want := requiredJSONFields(input), []string{"name", "vcpu_count"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("requiredJSONFields() = %v, want %v", got, want)
+	}
+}
+
+func Test_renderContractTest(t *testing.T) {
+	content, err := renderContractTest("compute", map[string][]string{
+		"yunion.io/x/onecloud/pkg/apis/compute.ServerCreateInput": {"name"},
+		"yunion.io/x/onecloud/pkg/apis/compute.ServerDetails":     {},
+	})
+	if err != nil {
+		t.Fatalf("renderContractTest error: %v", err)
+	}
+	for _, want := range []string{
+		"package compute\n",
+		`compute "yunion.io/x/onecloud/pkg/apis/compute"`,
+		`{name: "compute.ServerCreateInput", newObj: func() interface{} { return new(compute.ServerCreateInput) }, required: []string{"name"}},`,
+		`{name: "compute.ServerDetails", newObj: func() interface{} { return new(compute.ServerDetails) }, required: []string{}},`,
+		"func TestContractRoundTrip(t *testing.T) {",
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("contract test missing %q:\n%s", want, content)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"k8s.io/gengo/v2/types"
+
+	"yunion.io/x/pkg/util/sets"
+)
+
+// ModelCoverage records which routes of a model are generated
+type ModelCoverage struct {
+	Package   string   `json:"package"`
+	Model     string   `json:"model"`
+	Keyword   string   `json:"keyword"`
+	Generated []string `json:"generated"`
+	Missing   []string `json:"missing"`
+	Ignored   []string `json:"ignored,omitempty"`
+}
+
+func newModelCoverage(pkg string, model *types.Type, keyword string) *ModelCoverage {
+	return &ModelCoverage{
+		Package:   pkg,
+		Model:     model.Name.Name,
+		Keyword:   keyword,
+		Generated: make([]string, 0),
+		Missing:   make([]string, 0),
+	}
+}
+
+// check record verb as generated when method and all its depends methods exist
+func (c *ModelCoverage) check(verb string, funcKeyword string, receiver *types.Type, method *Method, depends ...*Method) {
+	if method == nil {
+		c.Missing = append(c.Missing, fmt.Sprintf("%s: %s", verb, missingReason(funcKeyword, receiver)))
+		return
+	}
+	for _, dep := range depends {
+		if dep == nil {
+			c.Missing = append(c.Missing, fmt.Sprintf("%s: depends on %s", verb, Get))
+			return
+		}
+	}
+	c.Generated = append(c.Generated, verb)
+}
+
+func (c *ModelCoverage) ignore(verb string) {
+	c.Ignored = append(c.Ignored, verb)
+}
+
+// checkActions record generated actions, the never allowed ones and the ones dropped by signature mismatch
+func (c *ModelCoverage) checkActions(run *Generation, verb string, funcKeyword string, receiver *types.Type, methods []*Method) {
+	generated := sets.NewString()
+	for _, m := range methods {
+		generated.Insert(m.Name())
+		c.Generated = append(c.Generated, fmt.Sprintf("%s %s", verb, strings.TrimPrefix(m.Name(), funcKeyword)))
+	}
+	for _, name := range methodNamesWithPrefix(receiver, funcKeyword) {
+		if generated.Has(name) {
+			continue
+		}
+		if isNeverAllowed(NewMethod(run, receiver, name, receiver.Methods[name], "", "")) {
+			c.Ignored = append(c.Ignored, fmt.Sprintf("%s %s: never allowed by Allow%s", verb, strings.TrimPrefix(name, funcKeyword), name))
+			continue
+		}
+		c.Missing = append(c.Missing, fmt.Sprintf("%s %s: signature mismatch", verb, strings.TrimPrefix(name, funcKeyword)))
+	}
+}
+
+func missingReason(funcKeyword string, receiver *types.Type) string {
+	if len(methodNamesWithPrefix(receiver, funcKeyword)) != 0 {
+		return fmt.Sprintf("%s signature mismatch", funcKeyword)
+	}
+	return fmt.Sprintf("%s not defined", funcKeyword)
+}
+
+func methodNamesWithPrefix(t *types.Type, prefix string) []string {
+	names := make([]string, 0)
+	for name, m := range t.Methods {
+		if strings.HasPrefix(name, prefix) && !includeIgnoreTag(m) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (run *Generation) addCoverage(c *ModelCoverage) {
+	sort.Strings(c.Generated)
+	run.coverage = append(run.coverage, c)
+}
+
+// WriteCoverageReport write collected models API coverage to file as json
+func (run *Generation) WriteCoverageReport(file string) error {
+	if file == "" {
+		return nil
+	}
+	sort.Slice(run.coverage, func(i, j int) bool {
+		ci, cj := run.coverage[i], run.coverage[j]
+		if ci.Package != cj.Package {
+			return ci.Package < cj.Package
+		}
+		return ci.Model < cj.Model
+	})
+	content, err := json.MarshalIndent(run.coverage, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, content, 0644)
+}
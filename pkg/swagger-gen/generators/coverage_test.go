@@ -0,0 +1,147 @@
+package generators
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"k8s.io/gengo/v2/types"
+)
+
+func TestModelCoverage_check(t *testing.T) {
+	const modelsPkg = "yunion.io/x/onecloud/pkg/compute/models"
+	run := newTestGeneration(t, nil)
+	fn := &types.Type{Kind: types.Func}
+	guest := &types.Type{
+		Name: types.Name{Package: modelsPkg, Name: "SGuest"},
+		Kind: types.Struct,
+		Methods: map[string]*types.Type{
+			Get + "Vnc": fn,
+		},
+	}
+	get := NewMethod(run, guest, Get, fn, "server", "servers")
+	for _, tt := range []struct {
+		name          string
+		method        *Method
+		depends       []*Method
+		wantGenerated []string
+		wantMissing   []string
+	}{
+		{
+			name:          "generated",
+			method:        get,
+			wantGenerated: []string{"get"},
+			wantMissing:   []string{},
+		},
+		{
+			name:          "signature mismatch",
+			wantGenerated: []string{},
+			wantMissing:   []string{"get: " + Get + " signature mismatch"},
+		},
+		{
+			name:          "depends on get",
+			method:        get,
+			depends:       []*Method{nil},
+			wantGenerated: []string{},
+			wantMissing:   []string{"get: depends on " + Get},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newModelCoverage(modelsPkg, guest, "server")
+			c.check("get", Get, guest, tt.method, tt.depends...)
+			if !reflect.DeepEqual(c.Generated, tt.wantGenerated) {
+				t.Errorf("Generated = %v, want %v", c.Generated, tt.wantGenerated)
+			}
+			if !reflect.DeepEqual(c.Missing, tt.wantMissing) {
+				t.Errorf("Missing = %v, want %v", c.Missing, tt.wantMissing)
+			}
+		})
+	}
+
+	disk := &types.Type{Name: types.Name{Package: modelsPkg, Name: "SDisk"}, Kind: types.Struct}
+	c := newModelCoverage(modelsPkg, disk, "disk")
+	c.check("get", Get, disk, nil)
+	if want := []string{"get: " + Get + " not defined"}; !reflect.DeepEqual(c.Missing, want) {
+		t.Errorf("Missing = %v, want %v", c.Missing, want)
+	}
+}
+
+func TestModelCoverage_checkActions(t *testing.T) {
+	const modelsPkg = "yunion.io/x/onecloud/pkg/compute/models"
+	run := newTestGeneration(t, nil)
+	fn := &types.Type{Kind: types.Func}
+	guest := &types.Type{
+		Name: types.Name{Package: modelsPkg, Name: "SGuest"},
+		Kind: types.Struct,
+		Methods: map[string]*types.Type{
+			Perform + "Start":          fn,
+			Perform + "Sync":           fn,
+			Perform + "Migrate":        fn,
+			Perform + "Purge":          {Kind: types.Func, CommentLines: []string{"+onecloud:swagger-gen-ignore"}},
+			"Allow" + Perform + "Sync": fn,
+		},
+	}
+	// guards are analyzed from source, the package is not parsed when they are known
+	run.guards.pkgs[modelsPkg] = map[string]*guardInfo{
+		"SGuest.AllowPerformSync": {name: "AllowPerformSync", never: true},
+	}
+	c := newModelCoverage(modelsPkg, guest, "server")
+	c.checkActions(run, "perform", Perform, guest, []*Method{NewMethod(run, guest, Perform+"Start", fn, "server", "servers")})
+	if want := []string{"perform Start"}; !reflect.DeepEqual(c.Generated, want) {
+		t.Errorf("Generated = %v, want %v", c.Generated, want)
+	}
+	if want := []string{"perform Migrate: signature mismatch"}; !reflect.DeepEqual(c.Missing, want) {
+		t.Errorf("Missing = %v, want %v", c.Missing, want)
+	}
+	if want := []string{"perform Sync: never allowed by AllowPerformSync"}; !reflect.DeepEqual(c.Ignored, want) {
+		t.Errorf("Ignored = %v, want %v", c.Ignored, want)
+	}
+}
+
+func TestGeneration_WriteCoverageReport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "coverage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	run := newTestGeneration(t, nil)
+	if err := run.WriteCoverageReport(""); err != nil {
+		t.Errorf("WriteCoverageReport() without file error: %v", err)
+	}
+	model := func(pkg, name string) *types.Type {
+		return &types.Type{Name: types.Name{Package: pkg, Name: name}, Kind: types.Struct}
+	}
+	for _, c := range []*ModelCoverage{
+		newModelCoverage("yunion.io/x/onecloud/pkg/image/models", model("yunion.io/x/onecloud/pkg/image/models", "SImage"), "image"),
+		newModelCoverage("yunion.io/x/onecloud/pkg/compute/models", model("yunion.io/x/onecloud/pkg/compute/models", "SGuest"), "server"),
+		newModelCoverage("yunion.io/x/onecloud/pkg/compute/models", model("yunion.io/x/onecloud/pkg/compute/models", "SDisk"), "disk"),
+	} {
+		c.Generated = append(c.Generated, "list", "get")
+		run.addCoverage(c)
+	}
+	file := filepath.Join(dir, "coverage.json")
+	if err := run.WriteCoverageReport(file); err != nil {
+		t.Fatalf("WriteCoverageReport() error: %v", err)
+	}
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]ModelCoverage, 0)
+	if err := json.Unmarshal(content, &got); err != nil {
+		t.Fatalf("unmarshal report: %v", err)
+	}
+	models := make([]string, 0, len(got))
+	for _, c := range got {
+		models = append(models, c.Model)
+		if want := []string{"get", "list"}; !reflect.DeepEqual(c.Generated, want) {
+			t.Errorf("%s Generated = %v, want sorted %v", c.Model, c.Generated, want)
+		}
+	}
+	if want := []string{"SDisk", "SGuest", "SImage"}; !reflect.DeepEqual(models, want) {
+		t.Errorf("report models = %v, want %v", models, want)
+	}
+}
@@ -0,0 +1,177 @@
+package generators
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/types"
+
+	"yunion.io/x/pkg/util/sets"
+)
+
+const (
+	// DefinitionNamingPlain names definition by go type name, e.g. ListInput
+	DefinitionNamingPlain = "plain"
+	// DefinitionNamingPackage qualifies definition by go package name, e.g. compute.ListInput
+	DefinitionNamingPackage = "package"
+	// DefinitionNamingService prefixes definition by service name, e.g. ComputeListInput
+	DefinitionNamingService = "service"
+)
+
+var definitionNamings = []string{DefinitionNamingPlain, DefinitionNamingPackage, DefinitionNamingService}
+
+func validateDefinitionNaming(strategy string) error {
+	if sets.NewString(definitionNamings...).Has(strategy) {
+		return nil
+	}
+	return fmt.Errorf("invalid definition naming %q, choices: %v", strategy, definitionNamings)
+}
+
+// definitionNamer names the swagger definitions of parameter and response bodies,
+// non-plain names are applied by declaring local types annotated with swagger:model
+type definitionNamer struct {
+	strategy string
+	service  string
+	// external are package prefixes of types defined by a shared definitions spec,
+	// they are referred by empty placeholders named <pkg>.<Type>
+	external []string
+	// externals are the referred external types by local name
+	externals map[string]*types.Type
+}
+
+func newDefinitionNamer(strategy, service string) *definitionNamer {
+	return &definitionNamer{
+		strategy:  strategy,
+		service:   service,
+		externals: make(map[string]*types.Type),
+	}
+}
+
+// isExternal returns true if t is defined by shared definitions spec
+func (n *definitionNamer) isExternal(t *types.Type) bool {
+	if t == nil {
+		return false
+	}
+	for _, prefix := range n.external {
+		if strings.HasPrefix(t.Name.Package, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// externalName returns definition name of external type, e.g. compute.ServerCreateInput
+func externalName(t *types.Type) string {
+	return fmt.Sprintf("%s.%s", path.Base(t.Name.Package), t.Name.Name)
+}
+
+// externalLocalName returns go type name of external type placeholder, e.g. Compute_ServerCreateInput
+func externalLocalName(t *types.Type) string {
+	return fmt.Sprintf("%s_%s", strings.Title(path.Base(t.Name.Package)), t.Name.Name)
+}
+
+func (n *definitionNamer) isPlain(t *types.Type) bool {
+	return t == nil || n.strategy == DefinitionNamingPlain || n.strategy == ""
+}
+
+// definitionName returns the swagger definition name of t
+func (n *definitionNamer) definitionName(t *types.Type) string {
+	switch n.strategy {
+	case DefinitionNamingPackage:
+		return fmt.Sprintf("%s.%s", path.Base(t.Name.Package), t.Name.Name)
+	case DefinitionNamingService:
+		return strings.Title(n.service) + t.Name.Name
+	default:
+		return t.Name.Name
+	}
+}
+
+// localName returns the go type name declared for t in generated package
+func (n *definitionNamer) localName(t *types.Type) string {
+	parts := strings.Split(n.definitionName(t), ".")
+	for i, p := range parts {
+		parts[i] = strings.Title(p)
+	}
+	return strings.Join(parts, "_")
+}
+
+// declare emits local type of t named by the strategy once in output package of declared types
+func (n *definitionNamer) declare(t *types.Type, declared sets.String, sw *generator.SnippetWriter) {
+	if n.isExternal(t) {
+		name := externalLocalName(t)
+		if declared.Has(name) {
+			return
+		}
+		declared.Insert(name)
+		n.externals[name] = t
+		sw.Do(fmt.Sprintf("// %s is placeholder of shared definition %s\n", name, externalName(t)), nil)
+		sw.Do(fmt.Sprintf("// swagger:model %s\n", externalName(t)), nil)
+		sw.Do(fmt.Sprintf("type %s struct{}\n\n", name), nil)
+		return
+	}
+	if n.isPlain(t) {
+		return
+	}
+	name := n.localName(t)
+	if declared.Has(name) {
+		return
+	}
+	declared.Insert(name)
+	sw.Do(fmt.Sprintf("// swagger:model %s\n", n.definitionName(t)), nil)
+	sw.Do(fmt.Sprintf("type %s $.type|raw$\n\n", name), getArgs(t))
+}
+
+// ref returns the snippet and args referring type t
+func (n *definitionNamer) ref(t *types.Type) (string, interface{}) {
+	if n.isExternal(t) {
+		return externalLocalName(t), nil
+	}
+	if n.isPlain(t) {
+		return "$.type|raw$", getArgs(t)
+	}
+	return n.localName(t), nil
+}
+
+// renderExternalDefinitions renders go source declaring models of referred external types,
+// scanning it once generates the shared definitions spec
+func renderExternalDefinitions(pkgName string, externals map[string]*types.Type) ([]byte, error) {
+	imports := newMockImports()
+	decls := &bytes.Buffer{}
+	for _, name := range sets.StringKeySet(externals).List() {
+		t := externals[name]
+		fmt.Fprintf(decls, "// swagger:model %s\n", externalName(t))
+		fmt.Fprintf(decls, "type %s %s\n\n", name, imports.typeRef(t.String()))
+	}
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "// Code generated by swagger-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(buf, "package %s\n\n", pkgName)
+	if len(externals) != 0 {
+		fmt.Fprintf(buf, "import (\n%s\n)\n\n", strings.Join(imports.imports(), "\n"))
+	}
+	buf.Write(decls.Bytes())
+	return format.Source(buf.Bytes())
+}
+
+// WriteExternalDefinitions write go source declaring models of referred external types to file,
+// package name is the base name of file directory
+func (run *Generation) WriteExternalDefinitions(file string) error {
+	if file == "" {
+		return nil
+	}
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return err
+	}
+	pkgName := invalidIdentChars.ReplaceAllString(filepath.Base(filepath.Dir(abs)), "_")
+	content, err := renderExternalDefinitions(pkgName, run.definitions.externals)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, content, 0644)
+}
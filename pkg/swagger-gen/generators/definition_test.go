@@ -0,0 +1,76 @@
+package generators
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/types"
+
+	"yunion.io/x/pkg/util/sets"
+)
+
+func Test_definitionNamer(t *testing.T) {
+	input := &types.Type{Name: types.Name{Package: "yunion.io/x/onecloud/pkg/apis/compute", Name: "ListInput"}, Kind: types.Struct}
+	tests := []struct {
+		strategy  string
+		wantName  string
+		wantLocal string
+	}{
+		{strategy: DefinitionNamingPlain, wantName: "ListInput", wantLocal: "ListInput"},
+		{strategy: DefinitionNamingPackage, wantName: "compute.ListInput", wantLocal: "Compute_ListInput"},
+		{strategy: DefinitionNamingService, wantName: "ComputeListInput", wantLocal: "ComputeListInput"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			n := newDefinitionNamer(tt.strategy, "compute")
+			if got := n.definitionName(input); got != tt.wantName {
+				t.Errorf("definitionName() = %s, want %s", got, tt.wantName)
+			}
+			if got := n.localName(input); got != tt.wantLocal {
+				t.Errorf("localName() = %s, want %s", got, tt.wantLocal)
+			}
+		})
+	}
+}
+
+func Test_definitionNamerExternal(t *testing.T) {
+	input := &types.Type{Name: types.Name{Package: "yunion.io/x/onecloud/pkg/apis/compute", Name: "ServerCreateInput"}, Kind: types.Struct}
+	local := &types.Type{Name: types.Name{Package: "yunion.io/x/onecloud/pkg/compute/models", Name: "SGuest"}, Kind: types.Struct}
+	n := newDefinitionNamer(DefinitionNamingPlain, "compute")
+	n.external = []string{"yunion.io/x/onecloud/pkg/apis"}
+	if !n.isExternal(input) || n.isExternal(local) {
+		t.Fatalf("isExternal() of %s should be true and %s false", input, local)
+	}
+	buf := &bytes.Buffer{}
+	sw := generator.NewSnippetWriter(buf, &generator.Context{}, "$", "$")
+	declared := sets.NewString()
+	n.declare(input, declared, sw)
+	n.declare(input, declared, sw)
+	if want := "// Compute_ServerCreateInput is placeholder of shared definition compute.ServerCreateInput\n// swagger:model compute.ServerCreateInput\ntype Compute_ServerCreateInput struct{}\n\n"; buf.String() != want {
+		t.Errorf("declare() = %q, want %q", buf.String(), want)
+	}
+	// another output package declares its own placeholder
+	buf.Reset()
+	n.declare(input, sets.NewString(), sw)
+	if !strings.Contains(buf.String(), "type Compute_ServerCreateInput struct{}") {
+		t.Errorf("declare() in another output package = %q, want placeholder declared", buf.String())
+	}
+	if ref, _ := n.ref(input); ref != "Compute_ServerCreateInput" {
+		t.Errorf("ref() = %s, want Compute_ServerCreateInput", ref)
+	}
+	content, err := renderExternalDefinitions("definitions", n.externals)
+	if err != nil {
+		t.Fatalf("renderExternalDefinitions error: %v", err)
+	}
+	for _, want := range []string{
+		"package definitions\n",
+		`compute "yunion.io/x/onecloud/pkg/apis/compute"`,
+		"// swagger:model compute.ServerCreateInput\ntype Compute_ServerCreateInput compute.ServerCreateInput\n",
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("external definitions missing %q:\n%s", want, content)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package generators
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/types"
+
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/util/sets"
+)
+
+// bodyVariant is the body type used when discriminator field is value
+type bodyVariant struct {
+	value string
+	t     *types.Type
+}
+
+// bodyDiscriminator models polymorphic body varied by a json field, e.g. hypervisor of server create input
+type bodyDiscriminator struct {
+	field    string
+	variants []bodyVariant
+}
+
+// extractDiscriminator parse tags of method with polymorphic body like:
+// +onecloud:swagger-gen-param-discriminator=hypervisor
+// +onecloud:swagger-gen-param-variant=aliyun:AliyunServerCreateInput
+// +onecloud:swagger-gen-param-variant=aws:yunion.io/x/onecloud/pkg/apis/compute.AwsServerCreateInput
+// variant type without package is in the package of body, variants missing in universe u are skipped.
+func extractDiscriminator(u types.Universe, comments []string, body *types.Type) *bodyDiscriminator {
+	fields := extractTagByName(comments, tagParamDiscriminator)
+	vals := extractTagByName(comments, tagParamVariant)
+	if len(fields) == 0 {
+		if len(vals) != 0 {
+			log.Errorf("tag %s requires %s", tagParamVariant, tagParamDiscriminator)
+		}
+		return nil
+	}
+	if body == nil || fields[0] == "" {
+		log.Errorf("invalid tag %s=%s, body is required", tagParamDiscriminator, fields[0])
+		return nil
+	}
+	d := &bodyDiscriminator{field: fields[0]}
+	for _, val := range vals {
+		parts := strings.SplitN(val, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			log.Errorf("invalid tag %s=%s, should be <value>:<type>", tagParamVariant, val)
+			continue
+		}
+		name := types.Name{Package: body.Name.Package, Name: parts[1]}
+		if idx := strings.LastIndex(parts[1], "."); idx >= 0 {
+			name = types.Name{Package: parts[1][:idx], Name: parts[1][idx+1:]}
+		}
+		t := lookupType(u, name)
+		if t == nil {
+			log.Errorf("invalid tag %s=%s, type %s not found", tagParamVariant, val, name)
+			continue
+		}
+		d.variants = append(d.variants, bodyVariant{value: parts[0], t: t})
+	}
+	if len(d.variants) == 0 {
+		log.Errorf("invalid tag %s=%s, no %s", tagParamDiscriminator, d.field, tagParamVariant)
+		return nil
+	}
+	return d
+}
+
+// lookupType returns the loaded type of name in universe u, nil if its package or the type isn't loaded
+func lookupType(u types.Universe, name types.Name) *types.Type {
+	pkg, ok := u[name.Package]
+	if !ok {
+		return nil
+	}
+	return pkg.Types[name.Name]
+}
+
+// baseName returns go type name of polymorphic body declared for operation
+func (d *bodyDiscriminator) baseName(operationId string) string {
+	return operationId + "_Input"
+}
+
+// definitionName returns swagger definition name of polymorphic body named by n, e.g. ServerCreateInputByHypervisor
+func (d *bodyDiscriminator) definitionName(n *definitionNamer, body *types.Type) string {
+	return fmt.Sprintf("%sBy%s", n.definitionName(body), paramFieldName(d.field))
+}
+
+// declare emits polymorphic body and its variants as go-swagger allOf models, e.g.:
+//
+//	// swagger:model ServerCreateInputByHypervisor
+//	type server_ValidateCreateData_Input struct {
+//		compute.ServerCreateInput
+//		// discriminator: true
+//		Hypervisor string `json:"hypervisor"`
+//	}
+//
+//	// swagger:model ServerCreateInputByHypervisorAliyun
+//	type server_ValidateCreateData_Input_aliyun struct {
+//		// swagger:allOf aliyun
+//		server_ValidateCreateData_Input
+//		compute.AliyunServerCreateInput
+//	}
+func (d *bodyDiscriminator) declare(n *definitionNamer, operationId string, body *types.Type, declared sets.String, sw *generator.SnippetWriter) {
+	base := d.baseName(operationId)
+	defName := d.definitionName(n, body)
+	ref, args := n.ref(body)
+	sw.Do(fmt.Sprintf("// %s is %s varied by %s\n", base, body.Name.Name, d.field), nil)
+	sw.Do(fmt.Sprintf("// swagger:model %s\n", defName), nil)
+	sw.Do(fmt.Sprintf("type %s struct {\n", base), nil)
+	sw.Do(ref+"\n", args)
+	sw.Do("// discriminator: true\n", nil)
+	sw.Do(fmt.Sprintf("%s string `json:\"%s\"`\n", paramFieldName(d.field), d.field), nil)
+	sw.Do("}\n\n", nil)
+	for _, v := range d.variants {
+		n.declare(v.t, declared, sw)
+		vref, vargs := n.ref(v.t)
+		sw.Do(fmt.Sprintf("// swagger:model %s%s\n", defName, paramFieldName(v.value)), nil)
+		sw.Do(fmt.Sprintf("type %s_%s struct {\n", base, invalidIdentChars.ReplaceAllString(v.value, "_")), nil)
+		sw.Do(fmt.Sprintf("// swagger:allOf %s\n", v.value), nil)
+		sw.Do(base+"\n", nil)
+		sw.Do(vref+"\n", vargs)
+		sw.Do("}\n\n", nil)
+	}
+}
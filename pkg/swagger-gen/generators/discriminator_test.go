@@ -0,0 +1,86 @@
+package generators
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/namer"
+	"k8s.io/gengo/v2/types"
+)
+
+func Test_extractDiscriminator(t *testing.T) {
+	u := types.Universe{}
+	body := u.Type(types.Name{Package: "yunion.io/x/onecloud/pkg/apis/compute", Name: "ServerCreateInput"})
+	u.Type(types.Name{Package: "yunion.io/x/onecloud/pkg/apis/compute", Name: "AliyunServerCreateInput"})
+	u.Type(types.Name{Package: "yunion.io/x/onecloud/pkg/apis/compute/aws", Name: "ServerCreateInput"})
+	comments := []string{
+		"+onecloud:swagger-gen-param-discriminator=hypervisor",
+		"+onecloud:swagger-gen-param-variant=aliyun:AliyunServerCreateInput",
+		"+onecloud:swagger-gen-param-variant=aws:yunion.io/x/onecloud/pkg/apis/compute/aws.ServerCreateInput",
+		"+onecloud:swagger-gen-param-variant=azure:AzureServerCreateInput",
+		"+onecloud:swagger-gen-param-variant=invalid",
+	}
+	d := extractDiscriminator(u, comments, body)
+	if d == nil {
+		t.Fatalf("extractDiscriminator() = nil")
+	}
+	got := make([]string, 0)
+	for _, v := range d.variants {
+		got = append(got, v.value+"="+v.t.String())
+	}
+	want := []string{
+		"aliyun=yunion.io/x/onecloud/pkg/apis/compute.AliyunServerCreateInput",
+		"aws=yunion.io/x/onecloud/pkg/apis/compute/aws.ServerCreateInput",
+	}
+	if d.field != "hypervisor" || !reflect.DeepEqual(got, want) {
+		t.Errorf("extractDiscriminator() = %s %v, want hypervisor %v", d.field, got, want)
+	}
+	if d := extractDiscriminator(u, comments[1:], body); d != nil {
+		t.Errorf("extractDiscriminator() without discriminator tag = %v, want nil", d)
+	}
+	if d := extractDiscriminator(u, []string{comments[0], comments[3]}, body); d != nil {
+		t.Errorf("extractDiscriminator() of missing variant types = %v, want nil", d)
+	}
+}
+
+func Test_parameterDiscriminator(t *testing.T) {
+	u := types.Universe{}
+	body := u.Type(types.Name{Package: "compute", Name: "ServerCreateInput"})
+	u.Type(types.Name{Package: "compute", Name: "AliyunServerCreateInput"}).Kind = types.Struct
+	body.Kind = types.Struct
+	buf := &bytes.Buffer{}
+	ctx := &generator.Context{Namers: namer.NameSystems{"raw": namer.NewRawNamer("", nil)}}
+	sw := generator.NewSnippetWriter(buf, ctx, "$", "$")
+	p := newParameter("", "servers", "server_ValidateCreateData")
+	p.body, p.run, p.out = body, newTestGeneration(t, nil), newOutputPackage()
+	p.discriminator = extractDiscriminator(u, []string{
+		"+onecloud:swagger-gen-param-discriminator=hypervisor",
+		"+onecloud:swagger-gen-param-variant=aliyun:AliyunServerCreateInput",
+	}, body)
+	p.Do(sw)
+	if err := sw.Error(); err != nil {
+		t.Fatalf("parameter Do error: %v", err)
+	}
+	want := "// server_ValidateCreateData_Input is ServerCreateInput varied by hypervisor\n" +
+		"// swagger:model ServerCreateInputByHypervisor\n" +
+		"type server_ValidateCreateData_Input struct {\n" +
+		"compute.ServerCreateInput\n" +
+		"// discriminator: true\n" +
+		"Hypervisor string `json:\"hypervisor\"`\n" +
+		"}\n\n" +
+		"// swagger:model ServerCreateInputByHypervisorAliyun\n" +
+		"type server_ValidateCreateData_Input_aliyun struct {\n" +
+		"// swagger:allOf aliyun\n" +
+		"server_ValidateCreateData_Input\n" +
+		"compute.AliyunServerCreateInput\n" +
+		"}\n\n" +
+		"// swagger:parameters server_ValidateCreateData\n" +
+		"type server_ValidateCreateData struct {\n" +
+		"// in:body\n" +
+		"Body server_ValidateCreateData_Input `json:\"body\"`}\n"
+	if buf.String() != want {
+		t.Errorf("parameter Do = %q, want %q", buf.String(), want)
+	}
+}
@@ -6,16 +6,16 @@ import (
 	"strings"
 	"text/template"
 
-	"k8s.io/gengo/generator"
+	"k8s.io/gengo/v2/generator"
 )
 
 const swaggerMeta = `
 // {{.Service}} API
 //
-//     Schemes: https, http
-//     BasePath: /
-//     Version: 1.0
-//     Host: "127.0.0.1:8889"
+//     Schemes: {{.Schemes}}
+//     BasePath: {{.BasePath}}
+//     Version: {{.Version}}
+//     Host: "{{.Host}}"
 //     Contact: Zexi Li<lizexi@yunion.cn>
 //     License: Apache 2.0 http://www.apache.org/licenses/LICENSE-2.0.html
 //
@@ -35,32 +35,39 @@ const swaggerMeta = `
 `
 
 type swaggerDocGen struct {
-	generator.DefaultGen
+	generator.GoGenerator
 }
 
 func NewSwaggerDocGen() generator.Generator {
 	return &swaggerDocGen{
-		DefaultGen: generator.DefaultGen{
-			OptionalName: "doc",
+		GoGenerator: generator.GoGenerator{
+			OutputFilename: "doc.go",
 		},
 	}
 }
 
 type DocPackage struct {
-	*generator.DefaultPackage
+	*generator.SimpleTarget
 }
 
-func NewDocPackage(pkgName string, pkgPath string, header []byte, service string) generator.Package {
+func NewDocPackage(pkgName string, pkgPath string, header []byte, service string, version string, profile DeployProfile) generator.Target {
 	out := new(bytes.Buffer)
 	t := template.Must(template.New("compiled_template").Parse(swaggerMeta))
-	if err := t.Execute(out, map[string]string{"Service": strings.Title(service)}); err != nil {
+	meta := map[string]string{
+		"Service":  strings.Title(service),
+		"Version":  apiVersionNumber(version),
+		"Host":     profile.Host,
+		"BasePath": profile.BasePath,
+		"Schemes":  strings.Join(profile.Schemes, ", "),
+	}
+	if err := t.Execute(out, meta); err != nil {
 		panic(err)
 	}
-	defaultPkg := &generator.DefaultPackage{
-		PackageName: pkgName,
-		PackagePath: pkgPath,
-		HeaderText:  []byte(fmt.Sprintf("%s %s", header, out.String())),
-		GeneratorFunc: func(c *generator.Context) []generator.Generator {
+	defaultPkg := &generator.SimpleTarget{
+		PkgName:       pkgName,
+		PkgPath:       pkgPath,
+		HeaderComment: []byte(fmt.Sprintf("%s %s", header, out.String())),
+		GeneratorsFunc: func(c *generator.Context) []generator.Generator {
 			return []generator.Generator{
 				// Always generate a "doc.go" file.
 				NewSwaggerDocGen(),
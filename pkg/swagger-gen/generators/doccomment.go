@@ -0,0 +1,136 @@
+package generators
+
+import (
+	"strings"
+
+	"yunion.io/x/code-generator/pkg/common"
+)
+
+const (
+	// structured doc comment markers of routes, e.g.:
+	// Summary: Start the server
+	// Description: Power on the server,
+	// the request is rejected if the server is running.
+	// Example:
+	// climc server-start vm1
+	// comment lines before the first marker are implementation notes kept out of the spec
+	docMarkerSummary     = "Summary:"
+	docMarkerDescription = "Description:"
+	docMarkerExample     = "Example:"
+
+	// tagDoc marks the start of doc comment lines exported to spec, lines above it are implementation notes:
+	// +onecloud:doc
+	tagDoc = "onecloud:doc"
+)
+
+var _ = common.RegisterAnnotations(common.Annotation{
+	Name:        tagDoc,
+	Generator:   generatorName,
+	Targets:     []string{common.TargetMethod, common.TargetFunction},
+	Description: "export only the doc comment lines below it to spec",
+})
+
+var docMarkers = []string{docMarkerSummary, docMarkerDescription, docMarkerExample}
+
+// routeDoc is the summary and description of route parsed from doc comments
+type routeDoc struct {
+	summary     string
+	description []string
+}
+
+// docMarker returns marker starting line l and the text following it on the line
+func docMarker(l string) (string, string, bool) {
+	l = strings.TrimSpace(l)
+	for _, marker := range docMarkers {
+		if strings.HasPrefix(l, marker) {
+			return marker, strings.TrimSpace(strings.TrimPrefix(l, marker)), true
+		}
+	}
+	return "", "", false
+}
+
+// hasDocMarker returns true if any of lines starts with a structured doc comment marker
+func hasDocMarker(lines []string) bool {
+	for _, l := range lines {
+		if _, _, ok := docMarker(l); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// exportedDocLines returns doc comment lines of declaration name exported to spec, the lines below
+// +onecloud:doc, or else the godoc paragraph starting with name, all lines if all is true, e.g. of --all-doc-comments
+func exportedDocLines(name string, comments []string, all bool) []string {
+	if all {
+		return docCommentLines(comments)
+	}
+	for i, l := range comments {
+		if strings.TrimSpace(l) == "+"+tagDoc {
+			return docCommentLines(comments[i+1:])
+		}
+	}
+	lines := docCommentLines(comments)
+	for i, l := range lines {
+		if name == "" || !strings.HasPrefix(strings.TrimSpace(l), name+" ") {
+			continue
+		}
+		end := i + 1
+		for end < len(lines) && strings.TrimSpace(lines[end]) != "" {
+			end++
+		}
+		return lines[i:end]
+	}
+	return nil
+}
+
+// parseRouteDoc parses doc comments of declaration name, the first exported line is summary and
+// the rest is description, unless structured markers are used, then only the marked sections are
+// exported and examples are appended to description as code blocks, all is passed to exportedDocLines
+func parseRouteDoc(name string, comments []string, all bool) routeDoc {
+	lines := docCommentLines(comments)
+	if !hasDocMarker(lines) {
+		lines = exportedDocLines(name, comments, all)
+		doc := routeDoc{description: make([]string, 0)}
+		if len(lines) > 0 {
+			doc.summary = lines[0]
+			doc.description = append(doc.description, lines[1:]...)
+		}
+		return doc
+	}
+	sections := make(map[string][]string)
+	examples := make([][]string, 0)
+	current := ""
+	for _, l := range lines {
+		if marker, text, ok := docMarker(l); ok {
+			current = marker
+			if marker == docMarkerExample {
+				examples = append(examples, nil)
+			}
+			l = text
+			if l == "" {
+				continue
+			}
+		}
+		switch current {
+		case "":
+		case docMarkerExample:
+			examples[len(examples)-1] = append(examples[len(examples)-1], l)
+		default:
+			sections[current] = append(sections[current], strings.TrimSpace(l))
+		}
+	}
+	doc := routeDoc{
+		summary:     strings.Join(sections[docMarkerSummary], " "),
+		description: append(make([]string, 0), sections[docMarkerDescription]...),
+	}
+	for _, example := range examples {
+		if len(example) == 0 {
+			continue
+		}
+		doc.description = append(doc.description, "", "Example:", "```")
+		doc.description = append(doc.description, example...)
+		doc.description = append(doc.description, "```")
+	}
+	return doc
+}
@@ -0,0 +1,67 @@
+package generators
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseRouteDoc(t *testing.T) {
+	tests := []struct {
+		name     string
+		comments []string
+		all      bool
+		want     routeDoc
+	}{
+		{
+			name:     "all comments",
+			comments: []string{"Start the server", "+onecloud:swagger-gen-route-tag=server", "rejected if running"},
+			all:      true,
+			want:     routeDoc{summary: "Start the server", description: []string{"rejected if running"}},
+		},
+		{
+			name:     "implementation comments",
+			comments: []string{"Start the server", "TODO: check host status"},
+			want:     routeDoc{description: []string{}},
+		},
+		{
+			name:     "godoc paragraph",
+			comments: []string{"TODO: check host status", "", "PerformStart starts the server,", "rejected if running", "", "implementation note"},
+			want:     routeDoc{summary: "PerformStart starts the server,", description: []string{"rejected if running"}},
+		},
+		{
+			name:     "doc marker",
+			comments: []string{"检查宿主机状态", "+onecloud:doc", "Start the server", "rejected if running"},
+			want:     routeDoc{summary: "Start the server", description: []string{"rejected if running"}},
+		},
+		{
+			name: "structured",
+			comments: []string{
+				"TODO: check the host status",
+				"Summary: Start the server",
+				"Description: Power on the server,",
+				"  rejected if running.",
+				"Example:",
+				"climc server-start vm1",
+			},
+			want: routeDoc{
+				summary: "Start the server",
+				description: []string{
+					"Power on the server,", "rejected if running.",
+					"", "Example:", "```", "climc server-start vm1", "```",
+				},
+			},
+		},
+		{
+			name:     "summary only",
+			comments: []string{"implementation note", "Summary: Start the server"},
+			want:     routeDoc{summary: "Start the server", description: []string{}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRouteDoc("PerformStart", tt.comments, tt.all); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseRouteDoc() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
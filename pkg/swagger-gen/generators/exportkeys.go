@@ -0,0 +1,31 @@
+package generators
+
+import (
+	"strings"
+
+	"k8s.io/gengo/v2/types"
+
+	"yunion.io/x/pkg/util/sets"
+
+	"yunion.io/x/code-generator/pkg/common"
+)
+
+const (
+	// extExportKeys lists keys accepted by export_keys list query, e.g. id,name,status
+	extExportKeys = "x-export-keys"
+)
+
+// exportKeys returns json fields of list item type including embedded ones,
+// fields skipped by json or unexported are not exportable
+func exportKeys(t *types.Type) sets.String {
+	return common.JSONFields(t)
+}
+
+// addExportKeys documents export_keys of list route by keys of item details struct
+func (r *route) addExportKeys(item *types.Type) {
+	keys := exportKeys(item)
+	if keys.Len() == 0 {
+		return
+	}
+	r.addExtension(extExportKeys, strings.Join(keys.List(), ","))
+}
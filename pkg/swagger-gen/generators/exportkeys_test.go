@@ -0,0 +1,39 @@
+package generators
+
+import (
+	"testing"
+
+	"k8s.io/gengo/v2/types"
+)
+
+func Test_routeAddExportKeys(t *testing.T) {
+	str := &types.Type{Name: types.Name{Name: "string"}, Kind: types.Builtin}
+	base := &types.Type{
+		Name: types.Name{Name: "StandaloneResourceDetails"},
+		Kind: types.Struct,
+		Members: []types.Member{
+			{Name: "Id", Type: str},
+			{Name: "Name", Type: str},
+		},
+	}
+	details := &types.Type{
+		Name: types.Name{Package: "compute", Name: "ServerDetails"},
+		Kind: types.Struct,
+		Members: []types.Member{
+			{Embedded: true, Type: base},
+			{Name: "ZoneId", Type: str, Tags: `json:"zone_id"`},
+			{Name: "Secret", Type: str, Tags: `json:"-"`},
+			{Name: "internal", Type: str},
+		},
+	}
+	r := &route{}
+	r.addExportKeys(details)
+	if got, want := r.extensions[extExportKeys], "id,name,zone_id"; got != want {
+		t.Errorf("addExportKeys() extension = %q, want %q", got, want)
+	}
+	r = &route{}
+	r.addExportKeys(nil)
+	if _, ok := r.extensions[extExportKeys]; ok {
+		t.Errorf("addExportKeys(nil) should not add extension")
+	}
+}
@@ -0,0 +1,58 @@
+package generators
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/gengo/v2/types"
+)
+
+// filterOperator is a condition operator of onecloud filter query, e.g. name.contains(web)
+type filterOperator struct {
+	name string
+	desc string
+}
+
+// filterOperators are the operators of filter conditions of list requests parsed by yunion.io/x/pkg/util/filterclause,
+// conditions of equals, like, contains, startswith and endswith match any of several values
+var filterOperators = []filterOperator{
+	{"equals(v)", "field equals v"},
+	{"notequals(v)", "field doesn't equal v"},
+	{"in(v1,v2)", "field is one of the values"},
+	{"notin(v1,v2)", "field is none of the values"},
+	{"like(v)", "field matches sql like pattern v, e.g. web%"},
+	{"contains(v)", "field contains v"},
+	{"startswith(v)", "field starts with v"},
+	{"endswith(v)", "field ends with v"},
+	{"between(v1,v2)", "field is between v1 and v2"},
+	{"ge(v)", "field is greater than or equal to v"},
+	{"gt(v)", "field is greater than v"},
+	{"le(v)", "field is less than or equal to v"},
+	{"lt(v)", "field is less than v"},
+	{"isnull()", "field is null"},
+	{"isnotnull()", "field is not null"},
+	{"isempty()", "field is empty string"},
+	{"isnotempty()", "field is neither null nor empty string"},
+	{"isnullorempty()", "field is null or empty string"},
+}
+
+// filterDocLines returns description of filter query of list route, fields are json keys of item
+func filterDocLines(item *types.Type) []string {
+	fields := exportKeys(item)
+	if fields.Len() == 0 {
+		return nil
+	}
+	ret := []string{
+		"Items are filtered by filter query conditions <field>.<operator>, e.g. filter=name.contains(web),",
+		"several conditions are all matched, or any of them with filter_any=true. Operators:",
+	}
+	for _, op := range filterOperators {
+		ret = append(ret, fmt.Sprintf("- %s: %s", op.name, op.desc))
+	}
+	return append(ret, fmt.Sprintf("Fields: %s", strings.Join(fields.List(), ", ")))
+}
+
+// addFilterDocs appends filter query description to list route
+func (r *route) addFilterDocs(item *types.Type) {
+	r.description = append(r.description, filterDocLines(item)...)
+}
@@ -0,0 +1,38 @@
+package generators
+
+import (
+	"testing"
+
+	"k8s.io/gengo/v2/types"
+)
+
+func Test_routeAddFilterDocs(t *testing.T) {
+	str := &types.Type{Name: types.Name{Name: "string"}, Kind: types.Builtin}
+	details := &types.Type{
+		Name: types.Name{Package: "compute", Name: "ServerDetails"},
+		Kind: types.Struct,
+		Members: []types.Member{
+			{Name: "Name", Type: str},
+			{Name: "ZoneId", Type: str, Tags: `json:"zone_id"`},
+		},
+	}
+	r := &route{description: []string{"List servers"}}
+	r.addFilterDocs(details)
+	if got := r.description[0]; got != "List servers" {
+		t.Errorf("addFilterDocs() first line = %q, want the route description kept", got)
+	}
+	if got, want := len(r.description), 1+2+len(filterOperators)+1; got != want {
+		t.Fatalf("addFilterDocs() description lines = %d, want %d", got, want)
+	}
+	if got, want := r.description[3], "- equals(v): field equals v"; got != want {
+		t.Errorf("addFilterDocs() operator line = %q, want %q", got, want)
+	}
+	if got, want := r.description[len(r.description)-1], "Fields: name, zone_id"; got != want {
+		t.Errorf("addFilterDocs() fields line = %q, want %q", got, want)
+	}
+	r = &route{}
+	r.addFilterDocs(nil)
+	if len(r.description) != 0 {
+		t.Errorf("addFilterDocs(nil) should not add description, got %q", r.description)
+	}
+}
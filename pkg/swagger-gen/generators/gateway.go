@@ -0,0 +1,111 @@
+package generators
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"yunion.io/x/pkg/util/sets"
+)
+
+const (
+	GatewayFormatKong  = "kong"
+	GatewayFormatNginx = "nginx"
+)
+
+var (
+	gatewayFormats = []string{GatewayFormatKong, GatewayFormatNginx}
+
+	pathParamRegexp = regexp.MustCompile(`\{[^/}]+\}`)
+)
+
+// gatewayPathRegex converts route path like /servers/{id} to anchored regex ^/servers/[^/]+$
+func gatewayPathRegex(path string) string {
+	return fmt.Sprintf("^%s$", pathParamRegexp.ReplaceAllString(path, "[^/]+"))
+}
+
+// gatewayPaths groups routes by path, keeping the order of routes
+func gatewayPaths(routes []RouteInfo) ([]string, map[string][]string) {
+	paths := make([]string, 0)
+	methods := make(map[string][]string)
+	for _, r := range routes {
+		if _, ok := methods[r.Path]; !ok {
+			paths = append(paths, r.Path)
+		}
+		if !sets.NewString(methods[r.Path]...).Has(r.Method) {
+			methods[r.Path] = append(methods[r.Path], r.Method)
+		}
+	}
+	return paths, methods
+}
+
+func renderKongConfig(service string, upstream *url.URL, routes []RouteInfo) []byte {
+	buf := &bytes.Buffer{}
+	buf.WriteString("# Code generated by swagger-gen. DO NOT EDIT.\n")
+	buf.WriteString("_format_version: \"1.1\"\n")
+	buf.WriteString("services:\n")
+	fmt.Fprintf(buf, "- name: %q\n", service)
+	fmt.Fprintf(buf, "  url: %q\n", upstream.String())
+	buf.WriteString("  routes:\n")
+	for _, r := range routes {
+		fmt.Fprintf(buf, "  - name: %q\n", r.OperationId)
+		fmt.Fprintf(buf, "    methods: [%q]\n", r.Method)
+		fmt.Fprintf(buf, "    paths: [%q]\n", gatewayPathRegex(r.Path))
+		buf.WriteString("    strip_path: false\n")
+	}
+	return buf.Bytes()
+}
+
+func renderNginxConfig(service string, upstream *url.URL, routes []RouteInfo) []byte {
+	buf := &bytes.Buffer{}
+	buf.WriteString("# Code generated by swagger-gen. DO NOT EDIT.\n")
+	fmt.Fprintf(buf, "upstream %s {\n", service)
+	fmt.Fprintf(buf, "    server %s;\n", upstream.Host)
+	buf.WriteString("}\n")
+	paths, methods := gatewayPaths(routes)
+	for _, p := range paths {
+		buf.WriteString("\n")
+		fmt.Fprintf(buf, "location ~ %s {\n", gatewayPathRegex(p))
+		fmt.Fprintf(buf, "    limit_except %s {\n", strings.Join(methods[p], " "))
+		buf.WriteString("        deny all;\n")
+		buf.WriteString("    }\n")
+		fmt.Fprintf(buf, "    proxy_pass %s://%s;\n", upstream.Scheme, service)
+		buf.WriteString("}\n")
+	}
+	return buf.Bytes()
+}
+
+// WriteGatewayConfig write collected routes to file as api gateway config of format,
+// all routes are proxied to upstream service url
+func (run *Generation) WriteGatewayConfig(file string, format string, upstream string) error {
+	if file == "" {
+		return nil
+	}
+	if upstream == "" {
+		return fmt.Errorf("gateway upstream is required")
+	}
+	upURL, err := url.Parse(upstream)
+	if err != nil {
+		return fmt.Errorf("invalid gateway upstream %q: %v", upstream, err)
+	}
+	if upURL.Scheme == "" || upURL.Host == "" {
+		return fmt.Errorf("invalid gateway upstream %q, should be like http://host:port", upstream)
+	}
+	var content []byte
+	switch format {
+	case GatewayFormatKong:
+		content = renderKongConfig(run.routePackage, upURL, run.sortedRoutes())
+	case GatewayFormatNginx:
+		// proxy_pass can't carry uri in regex location, so the path of upstream would be dropped
+		if strings.Trim(upURL.Path, "/") != "" {
+			return fmt.Errorf("invalid gateway upstream %q, path is not supported by %s format", upstream, format)
+		}
+		content = renderNginxConfig(run.routePackage, upURL, run.sortedRoutes())
+	default:
+		return fmt.Errorf("invalid gateway format %q, choices: %v", format, gatewayFormats)
+	}
+	return ioutil.WriteFile(file, content, 0644)
+}
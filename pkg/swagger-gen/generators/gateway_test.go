@@ -0,0 +1,76 @@
+package generators
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_renderNginxConfig(t *testing.T) {
+	routes := []RouteInfo{
+		{Method: "GET", Path: "/servers/{id}", OperationId: "serverGet"},
+		{Method: "PUT", Path: "/servers/{id}", OperationId: "serverUpdate"},
+		{Method: "POST", Path: "/servers/{id}/start", OperationId: "serverPerformStart"},
+	}
+	upstream, _ := url.Parse("http://region:8889")
+	want := `# Code generated by swagger-gen. DO NOT EDIT.
+upstream compute {
+    server region:8889;
+}
+
+location ~ ^/servers/[^/]+$ {
+    limit_except GET PUT {
+        deny all;
+    }
+    proxy_pass http://compute;
+}
+
+location ~ ^/servers/[^/]+/start$ {
+    limit_except POST {
+        deny all;
+    }
+    proxy_pass http://compute;
+}
+`
+	if got := string(renderNginxConfig("compute", upstream, routes)); got != want {
+		t.Errorf("renderNginxConfig() = %s, want %s", got, want)
+	}
+}
+
+func TestWriteGatewayConfigUpstreamPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gateway")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tests := []struct {
+		format   string
+		upstream string
+		wantErr  bool
+	}{
+		{format: GatewayFormatNginx, upstream: "http://region:8889/api", wantErr: true},
+		{format: GatewayFormatNginx, upstream: "http://region:8889/"},
+		{format: GatewayFormatKong, upstream: "http://region:8889/api"},
+	}
+	for _, tt := range tests {
+		run := &Generation{routePackage: "compute"}
+		file := filepath.Join(dir, tt.format+".conf")
+		err := run.WriteGatewayConfig(file, tt.format, tt.upstream)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("WriteGatewayConfig(%s, %s) error = %v, wantErr %v", tt.format, tt.upstream, err, tt.wantErr)
+		}
+		if err != nil || tt.format != GatewayFormatKong {
+			continue
+		}
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := `url: "http://region:8889/api"`; !strings.Contains(string(content), want) {
+			t.Errorf("kong config %s doesn't contain %s", content, want)
+		}
+	}
+}
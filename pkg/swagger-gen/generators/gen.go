@@ -4,21 +4,20 @@ import (
 	"fmt"
 	"io"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 
-	"k8s.io/gengo/args"
-	"k8s.io/gengo/generator"
-	"k8s.io/gengo/namer"
-	"k8s.io/gengo/types"
-	"k8s.io/klog"
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/namer"
+	"k8s.io/gengo/v2/types"
+	"k8s.io/klog/v2"
 
 	"yunion.io/x/log"
-	"yunion.io/x/onecloud/pkg/cloudcommon/db"
 	"yunion.io/x/pkg/util/sets"
 
 	"yunion.io/x/code-generator/pkg/common"
-	"yunion.io/x/code-generator/pkg/models"
+	"yunion.io/x/code-generator/pkg/models/registry"
 )
 
 const (
@@ -28,12 +27,194 @@ const (
 	tagRouteTag      = "onecloud:swagger-gen-route-tag"
 	tagParamQueryIdx = "onecloud:swagger-gen-param-query-index"
 	tagParamBodyIdx  = "onecloud:swagger-gen-param-body-index"
+	tagParamPath     = "onecloud:swagger-gen-param-path"
+	tagParamHeader   = "onecloud:swagger-gen-param-header"
 	tagRespIdx       = "onecloud:swagger-gen-resp-index"
 	tagRespBodyKey   = "onecloud:swagger-gen-resp-body-key"
+	tagRespBodyList  = "onecloud:swagger-gen-resp-body-list"
+	tagRespListField = "onecloud:swagger-gen-resp-list-field"
+	tagRespHeader    = "onecloud:swagger-gen-resp-header"
+
+	tagParamDiscriminator = "onecloud:swagger-gen-param-discriminator"
+	tagParamVariant       = "onecloud:swagger-gen-param-variant"
+
+	tagRouteWebsocket = "onecloud:swagger-gen-route-websocket"
+	tagExtension      = "onecloud:swagger-gen-extension"
+	tagDisable        = "onecloud:swagger-gen-disable"
+	tagListPagination = "onecloud:swagger-gen-list-pagination"
+	tagSingleton      = "onecloud:swagger-gen-singleton"
+)
+
+const generatorName = "swagger-gen"
+
+var _ = common.RegisterAnnotations(
+	common.Annotation{
+		Name:        tagIgnoreName,
+		Generator:   generatorName,
+		Syntax:      "[<verb>[,<verb>...]]",
+		Choices:     []string{VerbCreate, VerbList, VerbGet, VerbUpdate, VerbDelete, VerbGetDetails, VerbPerform},
+		Targets:     []string{common.TargetType, common.TargetModel, common.TargetManager},
+		Description: "ignore the type, or only routes of the verbs",
+	},
+	common.Annotation{
+		Name:        tagRouteMethod,
+		Generator:   generatorName,
+		Syntax:      "<http method>",
+		Choices:     []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		Targets:     []string{common.TargetMethod, common.TargetFunction},
+		Description: "http method of the route, declared with route path and route tag",
+		Indexed:     true,
+	},
+	common.Annotation{
+		Name:        tagRoutePath,
+		Generator:   generatorName,
+		Syntax:      "/<path>",
+		Targets:     []string{common.TargetMethod, common.TargetFunction},
+		Description: "path of the route, path params like {id} are declared by param-path",
+		Indexed:     true,
+	},
+	common.Annotation{
+		Name:        tagRouteTag,
+		Generator:   generatorName,
+		Syntax:      "<tag>",
+		Targets:     []string{common.TargetMethod, common.TargetFunction},
+		Description: "swagger tag of the route, indexed routes fall back to the plain tags",
+		Repeatable:  true,
+		Indexed:     true,
+	},
+	common.Annotation{
+		Name:        tagParamQueryIdx,
+		Generator:   generatorName,
+		Syntax:      "<argument index>",
+		Targets:     []string{common.TargetMethod, common.TargetFunction},
+		Description: "argument of the route documented as query params",
+	},
+	common.Annotation{
+		Name:        tagParamBodyIdx,
+		Generator:   generatorName,
+		Syntax:      "<argument index>",
+		Targets:     []string{common.TargetMethod, common.TargetFunction},
+		Description: "argument of the route documented as request body",
+	},
+	common.Annotation{
+		Name:        tagParamPath,
+		Generator:   generatorName,
+		Syntax:      "<name>[:<type>]:<description>",
+		Targets:     []string{common.TargetMethod, common.TargetFunction},
+		Description: "path param of the route, type is one of string, integer, int and uuid",
+		Repeatable:  true,
+	},
+	common.Annotation{
+		Name:        tagParamHeader,
+		Generator:   generatorName,
+		Syntax:      "<name>[:<type>]:<description>",
+		Targets:     []string{common.TargetFunction},
+		Description: "required header param of the route, e.g. X-Auth-Token, type is one of string, integer, int and uuid",
+		Repeatable:  true,
+	},
+	common.Annotation{
+		Name:        tagRespIdx,
+		Generator:   generatorName,
+		Syntax:      "<result index>",
+		Targets:     []string{common.TargetMethod, common.TargetFunction},
+		Description: "result of the route documented as response body",
+	},
+	common.Annotation{
+		Name:        tagRespBodyKey,
+		Generator:   generatorName,
+		Syntax:      "<json key>",
+		Targets:     []string{common.TargetMethod, common.TargetFunction},
+		Description: "json key wrapping the response body, overrides resource keyword of model methods, empty responds unwrapped",
+	},
+	common.Annotation{
+		Name:        tagRespBodyList,
+		Generator:   generatorName,
+		Targets:     []string{common.TargetMethod, common.TargetFunction},
+		Description: "document the response body as list envelope",
+	},
+	common.Annotation{
+		Name:        tagRespListField,
+		Generator:   generatorName,
+		Syntax:      "<role>:<json key>",
+		Choices:     listEnvelopeRoles,
+		Targets:     []string{common.TargetMethod, common.TargetFunction},
+		Description: "rename field of list envelope, empty json key drops it",
+		Repeatable:  true,
+	},
+	common.Annotation{
+		Name:        tagRespHeader,
+		Generator:   generatorName,
+		Syntax:      "<name>[:<type>]:<description>",
+		Targets:     []string{common.TargetFunction},
+		Description: "header of the route response, e.g. X-Subject-Token of issued token",
+		Repeatable:  true,
+	},
+	common.Annotation{
+		Name:        tagParamDiscriminator,
+		Generator:   generatorName,
+		Syntax:      "<json key>",
+		Targets:     []string{common.TargetMethod, common.TargetFunction},
+		Description: "field of polymorphic request body selecting its variant",
+	},
+	common.Annotation{
+		Name:        tagParamVariant,
+		Generator:   generatorName,
+		Syntax:      "<value>:<Type or package.Type>",
+		Targets:     []string{common.TargetMethod, common.TargetFunction},
+		Description: "request body type of discriminator value",
+		Repeatable:  true,
+	},
+	common.Annotation{
+		Name:        tagRouteWebsocket,
+		Generator:   generatorName,
+		Targets:     []string{common.TargetMethod, common.TargetFunction},
+		Description: "document the route as websocket upgrade",
+	},
+	common.Annotation{
+		Name:        tagExtension,
+		Generator:   generatorName,
+		Syntax:      "x-<name>:<value>",
+		Targets:     []string{common.TargetMethod, common.TargetFunction},
+		Description: "vendor extension of the route",
+		Repeatable:  true,
+	},
+	common.Annotation{
+		Name:        tagDisable,
+		Generator:   generatorName,
+		Syntax:      "<verb>[,<verb>...]",
+		Choices:     []string{VerbCreate, VerbList, VerbGet, VerbUpdate, VerbDelete, VerbGetDetails, VerbPerform},
+		Targets:     []string{common.TargetModel, common.TargetManager},
+		Description: "disable routes of the verbs",
+	},
+	common.Annotation{
+		Name:        tagListPagination,
+		Generator:   generatorName,
+		Syntax:      "<mode>",
+		Choices:     []string{PaginationOffset, PaginationMarker},
+		Targets:     []string{common.TargetManager},
+		Description: "pagination of list route, overrides --list-pagination",
+	},
+	common.Annotation{
+		Name:        tagSingleton,
+		Generator:   generatorName,
+		Targets:     []string{common.TargetManager},
+		Description: "serve the singleton resource at /<singular> without list and create routes",
+	},
+)
+
+const (
+	// extBatchResponse refers the response of create route when count > 1
+	extBatchResponse = "x-onecloud-batch-response"
+)
+
+const (
+	// list pagination modes
+	PaginationOffset = "offset"
+	PaginationMarker = "marker"
 )
 
 func extractTagByName(comments []string, tagName string) []string {
-	return types.ExtractCommentTags("+", comments)[tagName]
+	return common.ParseTags(comments).Values(tagName)
 }
 
 func extractIgnoreTag(comments []string) []string {
@@ -60,9 +241,86 @@ func extractSwaggerRoute(comments []string) *SwaggerConfigRoute {
 		return nil
 	}
 	route.Tags = vals
+	// 4. get websocket upgrade flag
+	route.Websocket = isWebsocketRoute(comments)
+	// 5. get vendor extensions
+	route.Extensions = extractExtensions(comments)
 	return route
 }
 
+// extractExtensions parse repeatable tag like +onecloud:swagger-gen-extension=x-foo:value
+func extractExtensions(comments []string) map[string]string {
+	vals := extractTagByName(comments, tagExtension)
+	if len(vals) == 0 {
+		return nil
+	}
+	ret := make(map[string]string)
+	for _, val := range vals {
+		parts := strings.SplitN(val, ":", 2)
+		if len(parts) != 2 || !strings.HasPrefix(parts[0], "x-") {
+			log.Errorf("invalid tag %s=%s, should be x-<name>:<value>", tagExtension, val)
+			continue
+		}
+		ret[parts[0]] = strings.TrimSpace(parts[1])
+	}
+	return ret
+}
+
+func indexedTagName(tagName string, idx int) string {
+	return fmt.Sprintf("%s[%d]", tagName, idx)
+}
+
+// extractSwaggerRoutes return the plain route and indexed routes declared like:
+// +onecloud:swagger-gen-route-method[0]=GET
+// +onecloud:swagger-gen-route-path[0]=/v3/auth/tokens
+// indexed routes use +onecloud:swagger-gen-route-tag[<idx>] or the plain route tags.
+func extractSwaggerRoutes(comments []string) []*SwaggerConfigRoute {
+	routes := make([]*SwaggerConfigRoute, 0)
+	if route := extractSwaggerRoute(comments); route != nil {
+		routes = append(routes, route)
+	}
+	tags := common.ParseTags(comments)
+	for idx := 0; ; idx++ {
+		methods := tags.Values(indexedTagName(tagRouteMethod, idx))
+		paths := tags.Values(indexedTagName(tagRoutePath, idx))
+		if len(methods) == 0 || len(paths) == 0 {
+			break
+		}
+		routeTags := tags.Values(indexedTagName(tagRouteTag, idx))
+		if len(routeTags) == 0 {
+			routeTags = tags.Values(tagRouteTag)
+		}
+		if len(routeTags) == 0 {
+			log.Errorf("route %s %s has no %s", methods[0], paths[0], tagRouteTag)
+			continue
+		}
+		routes = append(routes, &SwaggerConfigRoute{
+			Method:     methods[0],
+			Path:       paths[0],
+			Tags:       routeTags,
+			Websocket:  isWebsocketRoute(comments),
+			Extensions: extractExtensions(comments),
+		})
+	}
+	return routes
+}
+
+func isWebsocketRoute(comments []string) bool {
+	return len(extractTagByName(comments, tagRouteWebsocket)) != 0
+}
+
+// docCommentLines drop the +tag lines from comments
+func docCommentLines(comments []string) []string {
+	ret := make([]string, 0)
+	for _, l := range comments {
+		if strings.HasPrefix(strings.TrimSpace(l), "+") {
+			continue
+		}
+		ret = append(ret, l)
+	}
+	return ret
+}
+
 func fetchTagIdx(ut *types.Type, comments []string, tagName string) *types.Type {
 	vals := extractTagByName(comments, tagName)
 	if len(vals) == 0 {
@@ -78,27 +336,103 @@ func fetchTagIdx(ut *types.Type, comments []string, tagName string) *types.Type
 		log.Errorf("invalid tag %s=%s, only %d arguments", tagName, vals[0], len(params))
 		return nil
 	}
-	return params[idx]
+	return params[idx].Type
 }
 
-func extractSwaggerParam(ut *types.Type, comments []string) *SwaggerConfigParam {
+// extractPathParams parse repeatable tag like +onecloud:swagger-gen-param-path=<name>[:<type>]:<description>,
+// the param must appear in all route paths as {<name>}.
+func extractPathParams(routePaths []string, comments []string) []SwaggerConfigPathParam {
+	vals := extractTagByName(comments, tagParamPath)
+	ret := make([]SwaggerConfigPathParam, 0)
+	for _, val := range vals {
+		name, typ, desc, ok := parseTypedParam(tagParamPath, val)
+		if !ok {
+			continue
+		}
+		param := SwaggerConfigPathParam{Name: name, Type: typ, Description: desc}
+		inPath := true
+		for _, routePath := range routePaths {
+			if !strings.Contains(routePath, fmt.Sprintf("{%s}", param.Name)) {
+				log.Errorf("invalid tag %s=%s, param %q not in route path %s", tagParamPath, val, param.Name, routePath)
+				inPath = false
+			}
+		}
+		if inPath {
+			ret = append(ret, param)
+		}
+	}
+	if len(ret) == 0 {
+		return nil
+	}
+	return ret
+}
+
+// parseTypedParam parse tag value like <name>[:<type>]:<description>, type is string if omitted
+func parseTypedParam(tagName, val string) (string, string, string, bool) {
+	parts := strings.SplitN(val, ":", 3)
+	name, typ, desc := parts[0], "string", ""
+	switch len(parts) {
+	case 2:
+		desc = parts[1]
+	case 3:
+		typ = parts[1]
+		desc = parts[2]
+	}
+	if _, ok := pathParamTypes[typ]; !ok {
+		log.Errorf("invalid tag %s=%s, unsupported type %q", tagName, val, typ)
+		return "", "", "", false
+	}
+	return name, typ, desc, true
+}
+
+// extractHeaders parse repeatable header tag like +onecloud:swagger-gen-param-header=<name>[:<type>]:<description>
+// of request or response, e.g. X-Auth-Token of keystone auth routes
+func extractHeaders(comments []string, tagName string) []SwaggerConfigHeader {
+	ret := make([]SwaggerConfigHeader, 0)
+	for _, val := range extractTagByName(comments, tagName) {
+		name, typ, desc, ok := parseTypedParam(tagName, val)
+		if !ok {
+			continue
+		}
+		if name == "" {
+			log.Errorf("invalid tag %s=%s, header name is empty", tagName, val)
+			continue
+		}
+		ret = append(ret, SwaggerConfigHeader{Name: name, Type: typ, Description: desc})
+	}
+	if len(ret) == 0 {
+		return nil
+	}
+	return ret
+}
+
+func extractSwaggerParam(ut *types.Type, routePaths []string, comments []string) *SwaggerConfigParam {
 	query := fetchTagIdx(ut, comments, tagParamQueryIdx)
 	body := fetchTagIdx(ut, comments, tagParamBodyIdx)
-	if query == nil && body == nil {
+	path := extractPathParams(routePaths, comments)
+	header := extractHeaders(comments, tagParamHeader)
+	if query == nil && body == nil && path == nil && header == nil {
 		return nil
 	}
 	param := new(SwaggerConfigParam)
 	param.Query = query
 	param.Body = body
+	param.Path = path
+	param.Header = header
 	return param
 }
 
 func extractSwaggerResponse(ut *types.Type, comments []string) *SwaggerConfigResponse {
+	headers := extractHeaders(comments, tagRespHeader)
 	vals := extractTagByName(comments, tagRespIdx)
 	if len(vals) == 0 {
-		return nil
+		if headers == nil {
+			return nil
+		}
+		// response of headers only, e.g. token issued by X-Subject-Token header
+		return &SwaggerConfigResponse{Headers: headers}
 	}
-	resp := new(SwaggerConfigResponse)
+	resp := &SwaggerConfigResponse{Headers: headers}
 	idx, err := strconv.Atoi(vals[0])
 	if err != nil {
 		log.Errorf("invalid tag %s=%s", tagRespIdx, vals[0])
@@ -113,24 +447,92 @@ func extractSwaggerResponse(ut *types.Type, comments []string) *SwaggerConfigRes
 	if len(vals) > 0 {
 		resp.BodyKey = vals[0]
 	}
-	resp.Output = results[idx]
+	resp.Output = results[idx].Type
+	if len(extractTagByName(comments, tagRespBodyList)) != 0 {
+		resp.IsList = true
+		if resp.Output.Kind == types.Slice {
+			resp.Output = resp.Output.Elem
+		}
+		resp.ListFields = extractListFields(comments)
+	}
 	return resp
 }
 
+// extractListFields parse repeatable tag like +onecloud:swagger-gen-resp-list-field=<role>:<json key>,
+// role is one of limit, total, offset and marker, empty json key drops the field.
+func extractListFields(comments []string) []listEnvelopeField {
+	vals := extractTagByName(comments, tagRespListField)
+	if len(vals) == 0 {
+		return nil
+	}
+	keys := map[string]string{
+		"limit":  listEnvelopeFields["limit"].Key,
+		"total":  listEnvelopeFields["total"].Key,
+		"offset": listEnvelopeFields["offset"].Key,
+	}
+	for _, val := range vals {
+		parts := strings.SplitN(val, ":", 2)
+		if _, ok := listEnvelopeFields[parts[0]]; !ok || len(parts) != 2 {
+			log.Errorf("invalid tag %s=%s, should be <%s>:<json key>", tagRespListField, val, strings.Join(listEnvelopeRoles, "|"))
+			continue
+		}
+		keys[parts[0]] = parts[1]
+	}
+	ret := make([]listEnvelopeField, 0)
+	for _, role := range listEnvelopeRoles {
+		if key := keys[role]; key != "" {
+			f := listEnvelopeFields[role]
+			f.Key = key
+			ret = append(ret, f)
+		}
+	}
+	return ret
+}
+
 func extractSwaggerConfig(ut *types.Type, comments []string) *SwaggerConfig {
-	route := extractSwaggerRoute(comments)
-	if route == nil {
+	routes := extractSwaggerRoutes(comments)
+	if len(routes) == 0 {
 		return nil
 	}
-	param := extractSwaggerParam(ut, comments)
+	routePaths := make([]string, 0, len(routes))
+	for _, r := range routes {
+		routePaths = append(routePaths, r.Path)
+	}
+	param := extractSwaggerParam(ut, routePaths, comments)
 	resp := extractSwaggerResponse(ut, comments)
 	return &SwaggerConfig{
-		Route:    route,
+		Routes:   routes,
 		Param:    param,
 		Response: resp,
 	}
 }
 
+// getIgnoreVerbs parse ignore tag like +onecloud:swagger-gen-ignore=delete,update,
+// the whole type is ignored if no verb specified.
+func getIgnoreVerbs(t *types.Type) (bool, sets.String) {
+	if t == nil {
+		return false, sets.NewString()
+	}
+	return common.ParseTags(t.CommentLines).Verbs(tagIgnoreName)
+}
+
+// isSingletonManager returns true if manager is tagged by +onecloud:swagger-gen-singleton,
+// the singleton resource is served at /<singular> without list and create routes
+func isSingletonManager(man *types.Type) bool {
+	if man == nil {
+		return false
+	}
+	return common.ParseTags(man.CommentLines).Has(tagSingleton)
+}
+
+// getDisabledVerbs parse disable tag like +onecloud:swagger-gen-disable=list,delete
+func getDisabledVerbs(t *types.Type) sets.String {
+	if t == nil {
+		return sets.NewString()
+	}
+	return sets.NewString(common.ParseTags(t.CommentLines).List(tagDisable)...)
+}
+
 func includeIgnoreTag(t *types.Type) bool {
 	vals := extractIgnoreTag(t.CommentLines)
 	if len(vals) != 0 {
@@ -158,19 +560,30 @@ func DefaultNameSystem() string {
 	return "public"
 }
 
-func Packages(ctx *generator.Context, arguments *args.GeneratorArgs) generator.Packages {
+// Packages returns the doc package and the packages of swagger routes generated from input packages by the run
+func (run *Generation) Packages(ctx *generator.Context, arguments *common.GeneratorArgs) []generator.Target {
 	boilerplate, err := arguments.LoadGoBoilerplate()
 	if err != nil {
 		klog.Fatalf("Failed loading boilerplate: %v", err)
 	}
-	pkgs := generator.Packages{}
+	pkgs := []generator.Target{}
+	run.universe = ctx.Universe
 	inputs := sets.NewString(ctx.Inputs...)
 	header := append([]byte(fmt.Sprintf("// +build !%s\n\n", arguments.GeneratedBuildTag)), boilerplate...)
+	customArgs := run.args
+	if run.filter != nil {
+		klog.Warningf("generating only resources %v, generated files miss routes of other resources", customArgs.Only)
+	}
 
-	outPkgName := strings.Split(filepath.Base(arguments.OutputPackagePath), ".")[0]
-	pkgPath := arguments.OutputPackagePath
+	outPkgName := run.routePackage
+	pkgPath := common.VersionedPackagePath(arguments.OutputPackagePath, customArgs.APIVersion)
 	svcName := outPkgName
-	pkgs = append(pkgs, NewDocPackage(outPkgName, pkgPath, header, svcName))
+	profile, err := LoadDeployProfile(customArgs.ProfilesFile, customArgs.Profile)
+	if err != nil {
+		klog.Fatalf("Invalid --profile: %v", err)
+	}
+	pkgs = append(pkgs, NewDocPackage(outPkgName, pkgPath, header, svcName, customArgs.APIVersion, profile))
+	// input packages are generated into one output package, which is shared by the contexts of streamed inputs
 	for i := range inputs {
 		pkg := ctx.Universe[i]
 		if pkg == nil {
@@ -178,84 +591,156 @@ func Packages(ctx *generator.Context, arguments *args.GeneratorArgs) generator.P
 		}
 		klog.Infof("Considering pkg %q", pkg.Path)
 		pkgs = append(pkgs,
-			&generator.DefaultPackage{
-				PackageName: outPkgName,
-				PackagePath: pkgPath,
-				HeaderText:  header,
-				GeneratorFunc: func(c *generator.Context) []generator.Generator {
+			common.NewSourcePackage(pkg.Path, &generator.SimpleTarget{
+				PkgName:       outPkgName,
+				PkgPath:       pkgPath,
+				HeaderComment: header,
+				GeneratorsFunc: func(c *generator.Context) []generator.Generator {
 					return []generator.Generator{
 						// Generate swagger code by model.
-						NewSwaggerGen(arguments.OutputFileBaseName, pkg.Path, ctx.Order),
+						NewSwaggerGen(run, arguments.OutputFileBaseName, pkg.Path, ctx.Order, run.out),
 					}
 				},
 				FilterFunc: func(c *generator.Context, t *types.Type) bool {
 					return t.Name.Package == pkg.Path
 				},
-			},
+			}),
 		)
 	}
 	return pkgs
 }
 
 type swaggerGen struct {
-	generator.DefaultGen
+	generator.GoGenerator
 	sourcePackage string
 	modelTypes    sets.String
 	modelManagers map[string]*types.Type
+	args          *CustomArgs
+	// run is the run generating the package
+	run *Generation
+	// params is the sharer of parameter structs, nil if --share-parameters is off
+	params *parameterSharer
+	// headers collects operations accepting shared header parameters, nil if --api-version-header is off
+	headers *headerParameters
+	// out is the output package shared with generators of other source packages
+	out *outputPackage
+}
+
+// outputPackage is the state of a go package generated from several source packages,
+// e.g. definition types are declared once in it
+type outputPackage struct {
+	// declaredTypes are the names of declared definition types
+	declaredTypes sets.String
+	// commonListQueryDeclared is true once CommonListQuery is emitted
+	commonListQueryDeclared bool
+}
+
+func newOutputPackage() *outputPackage {
+	return &outputPackage{
+		declaredTypes: sets.NewString(),
+	}
 }
 
-func NewSwaggerGen(sanitizedName, sourcePackage string, pkgTypes []*types.Type) generator.Generator {
+func NewSwaggerGen(run *Generation, sanitizedName, sourcePackage string, pkgTypes []*types.Type, out *outputPackage) generator.Generator {
 	ident := filepath.Base(strings.TrimRight(sourcePackage, "models"))
 	gen := &swaggerGen{
-		DefaultGen: generator.DefaultGen{
-			OptionalName: fmt.Sprintf("%s_%s", sanitizedName, ident),
+		GoGenerator: generator.GoGenerator{
+			OutputFilename: fmt.Sprintf("%s_%s.go", sanitizedName, ident),
 		},
 		sourcePackage: sourcePackage,
-		modelTypes:    sets.NewString(),
-		modelManagers: make(map[string]*types.Type),
+		args:          run.args,
+		run:           run,
+		out:           out,
+	}
+	if run.args.ShareParameters {
+		gen.params = newParameterSharer()
+	}
+	if run.args.APIVersionHeader {
+		gen.headers = newHeaderParameters(privateName(ident, "APIVersionHeader"), run.args.APIVersion)
 	}
 	gen.collectTypes(pkgTypes)
+	packageStats(run.stats, sourcePackage).Models = gen.modelTypes.Len()
 	//klog.V(5).Infof("modelTypes: %v, modelManagers: %v", gen.modelTypes.List(), gen.modelManagers)
 	log.Infof("modelTypes: %v, modelManagers: %v", gen.modelTypes.List(), gen.modelManagers)
 	return gen
 }
 
 func (g *swaggerGen) collectTypes(pkgTypes []*types.Type) {
-	common.CollectModelManager(g.sourcePackage, pkgTypes, g.modelTypes, g.modelManagers)
+	c := common.NewTypeCollector(g.sourcePackage)
+	c.Collect(pkgTypes)
+	g.modelTypes, g.modelManagers = c.Models, c.Managers
+}
+
+// getListPagination return pagination mode of manager's list route,
+// +onecloud:swagger-gen-list-pagination=marker on manager overrides --list-pagination
+func (g *swaggerGen) getListPagination(manType *types.Type) string {
+	vals := extractTagByName(manType.CommentLines, tagListPagination)
+	if len(vals) != 0 {
+		return vals[0]
+	}
+	return g.args.ListPagination
 }
 
 func (g *swaggerGen) getModelManager(t *types.Type) *types.Type {
 	return g.modelManagers[t.String()]
 }
 
-func (g *swaggerGen) getModelManagerInstance(t *types.Type) db.IModelManager {
+func (g *swaggerGen) getModelManagerInstance(t *types.Type) registry.ModelManager {
 	mt := g.getModelManager(t)
-	return models.GetModelManagerByType(mt)
+	return registry.GetByType(mt)
 }
 
 func isModelManagerRegistered(mt *types.Type) bool {
 	if mt == nil {
 		return false
 	}
-	man := models.GetModelManagerByType(mt)
+	man := registry.GetByType(mt)
 	if man == nil {
 		return false
 	}
 	return true
 }
 
+// CheckRegisteredManagers returns error listing model types skipped by the run for unregistered managers
+func (run *Generation) CheckRegisteredManagers() error {
+	if run.unregisteredModels.Len() == 0 {
+		return nil
+	}
+	return fmt.Errorf("managers of models %s are not registered, load their services in pkg/models/register.go", strings.Join(run.unregisteredModels.List(), ", "))
+}
+
 func (g *swaggerGen) Filter(c *generator.Context, t *types.Type) bool {
-	if includeIgnoreTag(t) {
+	if !inAPIVersion(g.args.APIVersion, t) {
+		return false
+	}
+	// verbs of ignore tag on models are dropped by generateCode, only the verb-less tag skips the whole model
+	if ignoreAll, _ := getIgnoreVerbs(t); ignoreAll {
 		return false
 	}
 	if t.Kind == types.DeclarationOf {
+		if includeIgnoreTag(t) {
+			return false
+		}
 		swaggerCfg := getFunctionHasSwaggerConfig(t)
 		if swaggerCfg != nil {
-			return true
+			return g.run.filter.matchFunction(t, swaggerCfg)
 		}
 	}
-	if g.modelTypes.Has(t.String()) && isModelManagerRegistered(g.getModelManager(t)) {
-		return true
+	if g.modelTypes.Has(t.String()) && !isModelManagerRegistered(g.getModelManager(t)) {
+		if g.getModelManager(t) != nil {
+			klog.V(2).Infof("skip model %s, manager is not registered", t)
+			g.run.unregisteredModels.Insert(t.String())
+		}
+		return false
+	}
+	if g.modelTypes.Has(t.String()) {
+		if !inAPIVersion(g.args.APIVersion, g.getModelManager(t)) {
+			return false
+		}
+		if ignoreAll, _ := getIgnoreVerbs(g.getModelManager(t)); ignoreAll {
+			return false
+		}
+		return g.run.filter.matchModel(t, g.getModelManager(t), g.getModelManagerInstance(t))
 	}
 	return false
 }
@@ -263,6 +748,9 @@ func (g *swaggerGen) Filter(c *generator.Context, t *types.Type) bool {
 func (g *swaggerGen) GenerateType(c *generator.Context, t *types.Type, w io.Writer) error {
 	klog.V(2).Infof("Generating api model for type %s", t)
 	sw := generator.NewSnippetWriter(w, c, "$", "$")
+	if g.params != nil {
+		g.params.ctx = c
+	}
 	if t.Kind == types.DeclarationOf {
 		g.generateDeclarationCode(t, sw)
 	} else {
@@ -271,33 +759,115 @@ func (g *swaggerGen) GenerateType(c *generator.Context, t *types.Type, w io.Writ
 	return sw.Error()
 }
 
+// Finalize emits the parameter structs shared by operations
+func (g *swaggerGen) Finalize(c *generator.Context, w io.Writer) error {
+	if g.headers != nil {
+		if err := g.headers.write(w); err != nil {
+			return err
+		}
+	}
+	if g.params == nil {
+		return nil
+	}
+	log.Infof("%s: %d shared parameter definitions", g.sourcePackage, len(g.params.order))
+	return g.params.write(w)
+}
+
 func (g *swaggerGen) generateDeclarationCode(t *types.Type, sw *generator.SnippetWriter) {
 	config := getFunctionHasSwaggerConfig(t)
-	config.generate(t, sw)
+	config.generate(g, t, sw)
+	packageStats(g.run.stats, g.sourcePackage).Functions++
 }
 
 func (g *swaggerGen) generateCode(manType *types.Type, modelType *types.Type, sw *generator.SnippetWriter) {
 	manIns := g.getModelManagerInstance(modelType)
-	parser := newTypeParser(manIns, manType, modelType)
+	parser := newTypeParser(g.run, manIns, manType, modelType)
+	_, ignoreVerbs := getIgnoreVerbs(manType)
+	_, modelIgnoreVerbs := getIgnoreVerbs(modelType)
+	ignoreVerbs = ignoreVerbs.Union(modelIgnoreVerbs).Union(getDisabledVerbs(manType)).Union(getDisabledVerbs(modelType))
+
+	cov := newModelCoverage(g.sourcePackage, modelType, parser.singular)
+	defer g.run.addCoverage(cov)
 
 	getM := parser.getM()
-	generateGet(getM, sw)
-	generateCreate(parser.createM(), getM, sw)
-	lm := parser.listM()
-	generateList(lm, getM, sw)
-	generateUpdate(parser.updateM(), getM, sw)
-	generateDelete(parser.deleteM(), getM, sw)
+	crud := []struct {
+		verb     string
+		keyword  string
+		receiver *types.Type
+		method   *Method
+		depends  []*Method
+		generate func(*Method, *Method, *generator.SnippetWriter)
+	}{
+		{VerbGet, Get, modelType, getM, nil, func(m, _ *Method, sw *generator.SnippetWriter) {
+			g.generateGet(m, parser.customizedGetDetailsBodyM(), sw)
+		}},
+		{VerbCreate, Create, manType, parser.createM(), []*Method{getM}, g.generateCreate},
+		{VerbList, List, manType, parser.listM(), []*Method{getM}, func(m, getM *Method, sw *generator.SnippetWriter) {
+			g.generateList(m, getM, g.getListPagination(manType), g.args.ListCommonParams, g.args.FilterDocs, sw)
+		}},
+		{VerbUpdate, Update, modelType, parser.updateM(), []*Method{getM}, g.generateUpdate},
+		{VerbDelete, Delete, modelType, parser.deleteM(), []*Method{getM}, g.generateDelete},
+	}
+	for _, c := range crud {
+		if ignoreVerbs.Has(c.verb) {
+			cov.ignore(c.verb)
+			continue
+		}
+		if parser.singleton && (c.verb == VerbList || c.verb == VerbCreate) {
+			cov.ignore(fmt.Sprintf("%s: singleton %s", c.verb, parser.singular))
+			continue
+		}
+		if isNeverAllowed(c.method) {
+			cov.ignore(fmt.Sprintf("%s: never allowed by %s", c.verb, allowMethodName(c.method.Name())))
+			continue
+		}
+		c.generate(c.method, getM, sw)
+		cov.check(c.verb, c.keyword, c.receiver, c.method, c.depends...)
+	}
+
+	if g.args.HeadRoutes && !ignoreVerbs.Has(VerbGet) && !isNeverAllowed(getM) {
+		g.generateHead(getM, sw)
+	}
+
+	if ignoreVerbs.Has(VerbGetDetails) {
+		cov.ignore(VerbGetDetails)
+	} else {
+		cov.checkActions(g.run, VerbGetDetails, GetSpec, modelType, applyGenerateFunc(g.generateGetSpec, allowedMethods(parser.getSpecM), sw))
+	}
+	if ignoreVerbs.Has(VerbPerform) {
+		cov.ignore(VerbPerform)
+	} else {
+		cov.checkActions(g.run, VerbPerform, Perform, modelType, applyGenerateFunc(g.generatePerformAction, allowedMethods(parser.performActionM), sw))
+	}
 
-	applyGenerateFunc(generateGetSpec, parser.getSpecM, sw)
-	applyGenerateFunc(generatePerformAction, parser.performActionM, sw)
+	if isJointModel(modelType) {
+		if master, slave, ok := jointManagers(manIns); ok {
+			g.generateJoint(parser, master, slave, cov, sw)
+		} else {
+			log.Warningf("joint model %s manager has no master or slave manager", modelType.String())
+		}
+	}
 }
 
-func applyGenerateFunc(genFunc func(*Method, *generator.SnippetWriter), getMethods func() []*Method, sw *generator.SnippetWriter) {
-	for _, m := range getMethods() {
+func applyGenerateFunc(genFunc func(*Method, *generator.SnippetWriter), getMethods func() []*Method, sw *generator.SnippetWriter) []*Method {
+	ms := getMethods()
+	for _, m := range ms {
 		genFunc(m, sw)
 	}
+	return ms
 }
 
+const (
+	// verbs of model routes
+	VerbCreate     = "create"
+	VerbList       = "list"
+	VerbGet        = "get"
+	VerbUpdate     = "update"
+	VerbDelete     = "delete"
+	VerbGetDetails = "get-details"
+	VerbPerform    = "perform"
+)
+
 const (
 	// model or model manager func keyword
 	Create                      = "ValidateCreateData"
@@ -312,15 +882,22 @@ const (
 )
 
 type Method struct {
+	// run is the run generating routes of method
+	run         *Generation
 	resSingular string
 	resPlural   string
 	receiver    *types.Type
 	name        string
 	method      *types.Type
+	// model is the model serving method promoted from its embedded receiver, nil if receiver is the model
+	model *types.Type
+	// singleton is true if method is of singleton resource served at /<singular>
+	singleton bool
 }
 
-func NewMethod(receiver *types.Type, name string, method *types.Type, singular, plural string) *Method {
+func NewMethod(run *Generation, receiver *types.Type, name string, method *types.Type, singular, plural string) *Method {
 	return &Method{
+		run:         run,
 		receiver:    receiver,
 		name:        name,
 		method:      method,
@@ -333,6 +910,14 @@ func (m *Method) Receiver() *types.Type {
 	return m.receiver
 }
 
+// Model returns the model serving the routes of m, which differs from the receiver of promoted methods
+func (m *Method) Model() *types.Type {
+	if m.model != nil {
+		return m.model
+	}
+	return m.receiver
+}
+
 func (m *Method) Name() string {
 	return m.name
 }
@@ -342,11 +927,11 @@ func (m *Method) Signature() *types.Signature {
 }
 
 func (m *Method) Params(idx int) *types.Type {
-	return m.Signature().Parameters[idx]
+	return m.Signature().Parameters[idx].Type
 }
 
 func (m *Method) Resutls(idx int) *types.Type {
-	return m.Signature().Results[idx]
+	return m.Signature().Results[idx].Type
 }
 
 func (m *Method) Method() *types.Type {
@@ -358,6 +943,7 @@ func (m *Method) String() string {
 }
 
 func getTypeMethods(
+	run *Generation,
 	funcPrefixKeyword string,
 	keyword, keywordPlural string,
 	t *types.Type,
@@ -368,9 +954,9 @@ func getTypeMethods(
 	}
 	methods := make([]*Method, 0)
 	for name, m := range t.Methods {
-		if strings.HasPrefix(name, funcPrefixKeyword) && !includeIgnoreTag(m) {
+		if isRouteMethod(run, funcPrefixKeyword, name, m) {
 			useIt := true
-			mWrap := NewMethod(t, name, m, keyword, keywordPlural)
+			mWrap := NewMethod(run, t, name, m, keyword, keywordPlural)
 			if predicateF != nil {
 				useIt = predicateF(mWrap)
 			}
@@ -383,26 +969,35 @@ func getTypeMethods(
 	return methods
 }
 
+// isRouteMethod returns true if method name has prefix and isn't ignored or out of --api-version
+func isRouteMethod(run *Generation, prefix string, name string, m *types.Type) bool {
+	return strings.HasPrefix(name, prefix) && !includeIgnoreTag(m) && inAPIVersion(run.args.APIVersion, m)
+}
+
 type typeParser struct {
-	managerInstance db.IModelManager
+	run             *Generation
+	managerInstance registry.ModelManager
 	manager         *types.Type
 	model           *types.Type
 	singular        string
 	plural          string
+	singleton       bool
 }
 
-func newTypeParser(manIns db.IModelManager, man *types.Type, model *types.Type) *typeParser {
+func newTypeParser(run *Generation, manIns registry.ModelManager, man *types.Type, model *types.Type) *typeParser {
 	keyword, keywordPlural := getManagerKeywords(manIns)
 	return &typeParser{
+		run:             run,
 		managerInstance: manIns,
 		manager:         man,
 		model:           model,
 		singular:        keyword,
 		plural:          keywordPlural,
+		singleton:       isSingletonManager(man),
 	}
 }
 
-func getManagerKeywords(man db.IModelManager) (string, string) {
+func getManagerKeywords(man registry.ModelManager) (string, string) {
 	return man.Keyword(), man.KeywordPlural()
 }
 
@@ -435,14 +1030,7 @@ func isValidType(t *types.Type) error {
 func (p *typeParser) createM() *Method {
 	return p.getMethod(Create, p.manager,
 		func(m *Method) bool {
-			sig := m.Signature()
-			paramsLen := len(sig.Parameters)
-			retLen := len(sig.Results)
-			// ValidateCreateData(context.Context, mcclient.TokenCredential, mcclient.IIdentityProvider, query jsonutils.JSONObject, data *jsonutils.JSONDict) (Object, error)
-			if paramsLen != 5 || retLen != 2 {
-				return false
-			}
-			return true
+			return m.matchSignature(signatureCreate)
 		},
 	)
 }
@@ -450,68 +1038,45 @@ func (p *typeParser) createM() *Method {
 func (p *typeParser) listM() *Method {
 	return p.getMethod(List, p.manager,
 		func(m *Method) bool {
-			sig := m.Signature()
-			paramsLen := len(sig.Parameters)
-			retLen := len(sig.Results)
-			// ListItemFilter(context.Context, *sqlchemy.SQuery, mcclient.TokenCredential, query jsonutils.JSONObject) (*sqlchemy.SQuery, error)
-			if paramsLen != 4 || retLen != 2 {
-				return false
-			}
-			return true
+			return m.matchSignature(signatureList)
 		})
 }
 
 func (p *typeParser) getM() *Method {
 	return p.getMethod(Get, p.model,
 		func(m *Method) bool {
-			sig := m.Signature()
-			paramsLen := len(sig.Parameters)
-			retLen := len(sig.Results)
-			// GetExtraDetails(context.Context, userCred mcclient.TokenCredential, query Object) (Object, error)
-			if paramsLen != 3 || retLen != 2 {
-				return false
-			}
-			return true
+			return m.matchSignature(signatureGet)
+		})
+}
+
+func (p *typeParser) customizedGetDetailsBodyM() *Method {
+	return p.getMethod(GetCustomizedGetDetailsBody, p.model,
+		func(m *Method) bool {
+			return m.matchSignature(signatureGet)
 		})
 }
 
 func (p *typeParser) updateM() *Method {
 	return p.getMethod(Update, p.model, func(m *Method) bool {
-		sig := m.Signature()
-		paramsLen := len(sig.Parameters)
-		retLen := len(sig.Results)
-		// ValidateUpdateData(context.Context, mcclient.TokenCredential, query Object, data Object) (Object, error)
-		if paramsLen != 4 || retLen != 2 {
-			return false
-		}
-		return true
+		return m.matchSignature(signatureUpdate)
 	})
 }
 
 func (p *typeParser) deleteM() *Method {
 	return p.getMethod(Delete, p.model, func(m *Method) bool {
-		sig := m.Signature()
-		paramsLen := len(sig.Parameters)
-		retLen := len(sig.Results)
-		// CustomizeDelete(context.Context, mcclient.TokenCredential, query Object, body Object) error
-		if paramsLen != 4 || retLen != 1 {
-			return false
-		}
-		return true
+		return m.matchSignature(signatureDelete)
 	})
 }
 
 func (p *typeParser) performActionM() []*Method {
-	return p.getMethods(Perform, p.model,
+	return p.getPromotedMethods(Perform, p.model,
 		func(m *Method) bool {
-			sig := m.Signature()
-			paramsLen := len(sig.Parameters)
-			retLen := len(sig.Results)
-			if paramsLen != 4 || retLen != 2 {
+			// PerformXxx(ctx, userCred, query, body) (Object, error)
+			if !m.matchSignature(signatureUpdate) {
 				return false
 			}
-			body := sig.Parameters[3]
-			output := sig.Results[0]
+			_, body := m.requestParams(signatureUpdate)
+			output := m.Signature().Results[0].Type
 			// input body and output must struct pointer
 			if err := validInputOutput(body, output); err != nil {
 				log.Warningf("validInputOutput for method %s: %v", m.String(), err)
@@ -523,16 +1088,18 @@ func (p *typeParser) performActionM() []*Method {
 }
 
 func (p *typeParser) getSpecM() []*Method {
-	return p.getMethods(GetSpec, p.model,
+	return p.getPromotedMethods(GetSpec, p.model,
 		func(m *Method) bool {
-			paramsLen := len(m.Signature().Parameters)
-			retLen := len(m.Signature().Results)
-			if paramsLen != 3 || retLen != 2 {
+			if !m.matchSignature(signatureGet) {
 				return false
 			}
-			sig := m.Signature()
-			//input := sig.Parameters[2]
-			output := sig.Results[0]
+			output := m.Signature().Results[0].Type
+			if _, ok := primitiveGoType(output); ok {
+				return true
+			}
+			if _, ok := sliceElem(output); ok {
+				return true
+			}
 			if err := isValidType(output); err != nil {
 				log.Warningf("method %s: output type is invalid: %v", m.String(), err)
 				//return false
@@ -543,7 +1110,80 @@ func (p *typeParser) getSpecM() []*Method {
 }
 
 func (p *typeParser) getMethods(funcPreKeyword string, model *types.Type, preF func(*Method) bool) []*Method {
-	return getTypeMethods(funcPreKeyword, p.singular, p.plural, model, preF)
+	ms := getTypeMethods(p.run, funcPreKeyword, p.singular, p.plural, model, preF)
+	for _, m := range ms {
+		m.singleton = p.singleton
+	}
+	return ms
+}
+
+// getPromotedMethods is like getMethods, but methods promoted from embedded base models are included,
+// e.g. PerformPublic of SSharableVirtualResourceBase is served for all sharable resources.
+// Promoted methods keep their declaring receiver and are served by model.
+func (p *typeParser) getPromotedMethods(funcPreKeyword string, model *types.Type, preF func(*Method) bool) []*Method {
+	ms := make([]*Method, 0)
+	for name, pm := range promotedMethods(model) {
+		if !isRouteMethod(p.run, funcPreKeyword, name, pm.method) {
+			continue
+		}
+		m := NewMethod(p.run, pm.receiver, name, pm.method, p.singular, p.plural)
+		m.model, m.singleton = model, p.singleton
+		if preF != nil && !preF(m) {
+			continue
+		}
+		ms = append(ms, m)
+	}
+	sort.Slice(ms, func(i, j int) bool { return ms[i].Name() < ms[j].Name() })
+	return ms
+}
+
+// promotedMethod is a method of a model and the type declaring it
+type promotedMethod struct {
+	receiver *types.Type
+	method   *types.Type
+}
+
+// promotedMethods returns methods of t including the ones promoted from embedded types by go selector rules:
+// methods of the shallowest depth take precedence, and names declared by several types of that depth
+// are ambiguous and not promoted at all
+func promotedMethods(t *types.Type) map[string]promotedMethod {
+	ret := make(map[string]promotedMethod)
+	ambiguous := sets.NewString()
+	seen := make(map[*types.Type]bool)
+	for level := []*types.Type{t}; len(level) != 0; {
+		found := make(map[string][]promotedMethod)
+		next := make([]*types.Type, 0)
+		for _, lt := range level {
+			seen[lt] = true
+			for name, method := range lt.Methods {
+				found[name] = append(found[name], promotedMethod{receiver: lt, method: method})
+			}
+			for _, m := range lt.Members {
+				if !m.Embedded {
+					continue
+				}
+				mt := m.Type
+				if mt.Kind == types.Pointer {
+					mt = mt.Elem
+				}
+				if !seen[mt] {
+					next = append(next, mt)
+				}
+			}
+		}
+		for name, pms := range found {
+			if _, ok := ret[name]; ok || ambiguous.Has(name) {
+				continue
+			}
+			if len(pms) > 1 {
+				ambiguous.Insert(name)
+				continue
+			}
+			ret[name] = pms[0]
+		}
+		level = next
+	}
+	return ret
 }
 
 func (p *typeParser) getMethod(funcPreKeyword string, model *types.Type, preF func(*Method) bool) *Method {
@@ -564,6 +1204,20 @@ type commenter struct {
 	response  *response
 }
 
+// newCommenter returns commenter of route generated by g.run, parameters of file being generated are shared by g.params,
+// operations accepting shared headers are collected by g.headers and types are declared once in g.out
+func (g *swaggerGen) newCommenter(route *route, param *parameter, resp *response) *commenter {
+	route.run, route.headers = g.run, g.headers
+	route.addReferences(g.run.references)
+	param.run, param.sharer, param.out = g.run, g.params, g.out
+	resp.run, resp.out = g.run, g.out
+	return &commenter{
+		route:     route,
+		parameter: param,
+		response:  resp,
+	}
+}
+
 func (c commenter) Do(sw *generator.SnippetWriter) {
 	for _, f := range []func(*generator.SnippetWriter){
 		c.route.Do,
@@ -596,107 +1250,121 @@ func (w snippetWriter) line(l string) {
 	w.lines([]string{l})
 }
 
-func generateCreate(createMethod, getMethod *Method, sw *generator.SnippetWriter) {
+// batchCreateMethod is the manager method validating input of creating resources in batch by count query
+const batchCreateMethod = "BatchCreateValidateCreateData"
+
+// supportsBatchCreate returns true if manager implements batchCreateMethod, the ones of cloudcommon/db
+// base managers reject batch create and don't count
+func supportsBatchCreate(manager *types.Type) bool {
+	pm, ok := promotedMethods(manager)[batchCreateMethod]
+	return ok && !strings.HasSuffix(pm.receiver.Name.Package, "/pkg/cloudcommon/db")
+}
+
+func (g *swaggerGen) generateCreate(createMethod, getMethod *Method, sw *generator.SnippetWriter) {
 	if createMethod == nil || getMethod == nil {
 		return
 	}
+	batch := supportsBatchCreate(createMethod.Receiver())
 	param := newParameterFactory(createMethod).Create()
+	param.batchCount = batch
+	g.run.recordConstructorType(param.getBody())
 	resp := newResponseFactory(createMethod).ResultByGetMethod(getMethod)
+	if batch {
+		resp.batch = newResponseFactory(createMethod).BatchCreateResult(getMethod)
+	}
 	route := newRouteFactory(createMethod).Create(param, resp)
-	c := &commenter{
-		route:     route,
-		parameter: param,
-		response:  resp,
+	if batch {
+		// swagger 2.0 allows one schema per status code, refer the batch response by extension
+		route.addExtension(extBatchResponse, resp.batch.id)
 	}
-	c.Do(sw)
+	g.newCommenter(route, param, resp).Do(sw)
 }
 
-func generateList(listMethod, getMethod *Method, sw *generator.SnippetWriter) {
+func (g *swaggerGen) generateList(listMethod, getMethod *Method, pagination string, commonParams, filterDocs bool, sw *generator.SnippetWriter) {
 	if listMethod == nil || getMethod == nil {
 		return
 	}
 	param := newParameterFactory(listMethod).List()
+	param.commonListParams = commonParams
 	resp := newResponseFactory(listMethod).ListResult(getMethod)
+	if pagination == PaginationMarker {
+		param.markerPaging = true
+		resp.listFields = []listEnvelopeField{listEnvelopeFields["marker"]}
+	}
 	route := newRouteFactory(listMethod).List(param, resp)
-	c := &commenter{
-		route:     route,
-		parameter: param,
-		response:  resp,
+	route.addExportKeys(resp.getOutput())
+	if filterDocs {
+		route.addFilterDocs(resp.getOutput())
 	}
-	c.Do(sw)
+	g.newCommenter(route, param, resp).Do(sw)
 }
 
-func generateGet(method *Method, sw *generator.SnippetWriter) {
+func (g *swaggerGen) generateGet(method, customizedBodyMethod *Method, sw *generator.SnippetWriter) {
 	if method == nil {
 		return
 	}
 	param := newParameterFactory(method).Get()
-	resp := newResponseFactory(method).FirstSingularResult()
+	var resp *response
+	if customizedBodyMethod != nil {
+		// server responds the customized body as is, without the details wrapper
+		resp = newResponseFactory(method).RawResultByMethod(customizedBodyMethod)
+	} else {
+		resp = newResponseFactory(method).FirstSingularResult()
+	}
 	route := newRouteFactory(method).Get(param, resp)
-	c := &commenter{
-		route:     route,
-		parameter: param,
-		response:  resp,
+	g.newCommenter(route, param, resp).Do(sw)
+}
+
+// generateHead emits HEAD route checking existence of resource, it mirrors GET route without response body
+func (g *swaggerGen) generateHead(getMethod *Method, sw *generator.SnippetWriter) {
+	if getMethod == nil {
+		return
 	}
-	c.Do(sw)
+	param := newParameterFactory(getMethod).Get()
+	param.operationId = privateName(getMethod.resSingular, "Head")
+	resp := newResponseFactory(getMethod).newResponse()
+	resp.id = param.operationId + "Output"
+	route := newRouteFactory(getMethod).Head(param, resp)
+	g.newCommenter(route, param, resp).Do(sw)
 }
 
-func generateUpdate(method, getMethod *Method, sw *generator.SnippetWriter) {
+func (g *swaggerGen) generateUpdate(method, getMethod *Method, sw *generator.SnippetWriter) {
 	if method == nil || getMethod == nil {
 		return
 	}
 	param := newParameterFactory(method).Update()
 	resp := newResponseFactory(method).ResultByGetMethod(getMethod)
 	route := newRouteFactory(method).Update(param, resp)
-	c := &commenter{
-		route:     route,
-		parameter: param,
-		response:  resp,
-	}
-	c.Do(sw)
+	g.newCommenter(route, param, resp).Do(sw)
 }
 
-func generateDelete(method, getMethod *Method, sw *generator.SnippetWriter) {
+func (g *swaggerGen) generateDelete(method, getMethod *Method, sw *generator.SnippetWriter) {
 	if method == nil || getMethod == nil {
 		return
 	}
 	param := newParameterFactory(method).Delete()
 	resp := newResponseFactory(method).ResultByGetMethod(getMethod)
 	route := newRouteFactory(method).Delete(param, resp)
-	c := &commenter{
-		route:     route,
-		parameter: param,
-		response:  resp,
-	}
-	c.Do(sw)
+	g.newCommenter(route, param, resp).Do(sw)
 }
 
-func generateGetSpec(method *Method, sw *generator.SnippetWriter) {
+func (g *swaggerGen) generateGetSpec(method *Method, sw *generator.SnippetWriter) {
 	if method == nil {
 		return
 	}
 	param := newParameterFactory(method).GetSpec()
-	resp := newResponseFactory(method).FirstSingularResult()
+	resp := newResponseFactory(method).withPrimitives().FirstSingularResult()
 	route := newRouteFactory(method).GetSpec(param, resp)
-	c := &commenter{
-		route:     route,
-		parameter: param,
-		response:  resp,
-	}
-	c.Do(sw)
+	g.newCommenter(route, param, resp).Do(sw)
 }
 
-func generatePerformAction(method *Method, sw *generator.SnippetWriter) {
+func (g *swaggerGen) generatePerformAction(method *Method, sw *generator.SnippetWriter) {
 	if method == nil {
 		return
 	}
 	param := newParameterFactory(method).PerformAction()
+	g.run.recordConstructorType(param.getBody())
 	resp := newResponseFactory(method).FirstSingularResultNoError()
 	route := newRouteFactory(method).PerformAction(param, resp)
-	c := &commenter{
-		route:     route,
-		parameter: param,
-		response:  resp,
-	}
-	c.Do(sw)
+	g.newCommenter(route, param, resp).Do(sw)
 }
@@ -1,8 +1,20 @@
 package generators
 
 import (
+	"bytes"
 	"reflect"
+	"strings"
 	"testing"
+
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/namer"
+	"k8s.io/gengo/v2/types"
+
+	"yunion.io/x/pkg/util/sets"
+
+	"yunion.io/x/code-generator/pkg/common/testdata/faketypes"
+	"yunion.io/x/code-generator/pkg/models/registry"
+	"yunion.io/x/code-generator/pkg/models/registry/testdata/fakemodels"
 )
 
 func Test_extractSwaggerRoute(t *testing.T) {
@@ -25,6 +37,41 @@ func Test_extractSwaggerRoute(t *testing.T) {
 				Tags:   []string{"tag1", "tag2"},
 			},
 		},
+		{
+			name: "websocket input",
+			comments: []string{
+				"+onecloud:swagger-gen-route-method=GET",
+				"+onecloud:swagger-gen-route-path=/websockify",
+				"+onecloud:swagger-gen-route-tag=webconsole",
+				"+onecloud:swagger-gen-route-websocket",
+			},
+			want: &SwaggerConfigRoute{
+				Method:    "GET",
+				Path:      "/websockify",
+				Tags:      []string{"webconsole"},
+				Websocket: true,
+			},
+		},
+		{
+			name: "extensions input",
+			comments: []string{
+				"+onecloud:swagger-gen-route-method=POST",
+				"+onecloud:swagger-gen-route-path=/v3/auth/tokens",
+				"+onecloud:swagger-gen-route-tag=authentication",
+				"+onecloud:swagger-gen-extension=x-gateway-route:identity",
+				"+onecloud:swagger-gen-extension=x-internal: true",
+				"+onecloud:swagger-gen-extension=invalid",
+			},
+			want: &SwaggerConfigRoute{
+				Method: "POST",
+				Path:   "/v3/auth/tokens",
+				Tags:   []string{"authentication"},
+				Extensions: map[string]string{
+					"x-gateway-route": "identity",
+					"x-internal":      "true",
+				},
+			},
+		},
 		{
 			name: "no method input",
 			comments: []string{
@@ -59,3 +106,455 @@ func Test_extractSwaggerRoute(t *testing.T) {
 		})
 	}
 }
+
+func Test_getIgnoreVerbs(t *testing.T) {
+	tests := []struct {
+		name      string
+		comments  []string
+		wantAll   bool
+		wantVerbs []string
+	}{
+		{
+			name:      "ignore all",
+			comments:  []string{"+onecloud:swagger-gen-ignore"},
+			wantAll:   true,
+			wantVerbs: []string{},
+		},
+		{
+			name:      "ignore verbs",
+			comments:  []string{"+onecloud:swagger-gen-ignore=delete, update"},
+			wantAll:   false,
+			wantVerbs: []string{"delete", "update"},
+		},
+		{
+			name:      "no tag",
+			comments:  []string{"SGuestManager is the manager of guests"},
+			wantAll:   false,
+			wantVerbs: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			all, verbs := getIgnoreVerbs(&types.Type{CommentLines: tt.comments})
+			if all != tt.wantAll || !reflect.DeepEqual(verbs.List(), tt.wantVerbs) {
+				t.Errorf("getIgnoreVerbs() = %v, %v, want %v, %v", all, verbs.List(), tt.wantAll, tt.wantVerbs)
+			}
+		})
+	}
+}
+
+func Test_extractPathParams(t *testing.T) {
+	tests := []struct {
+		name       string
+		routePaths []string
+		comments   []string
+		want       []SwaggerConfigPathParam
+	}{
+		{
+			name:       "pair and triple",
+			routePaths: []string{"/v3/projects/{project_id}/users/{uid}"},
+			comments: []string{
+				"+onecloud:swagger-gen-param-path=project_id:The id of project",
+				"+onecloud:swagger-gen-param-path=uid:uuid:The id of user",
+			},
+			want: []SwaggerConfigPathParam{
+				{Name: "project_id", Type: "string", Description: "The id of project"},
+				{Name: "uid", Type: "uuid", Description: "The id of user"},
+			},
+		},
+		{
+			name:       "not in path or unknown type",
+			routePaths: []string{"/v3/projects/{project_id}", "/v3/users/{uid}"},
+			comments: []string{
+				"+onecloud:swagger-gen-param-path=uid:uuid:The id of user",
+				"+onecloud:swagger-gen-param-path=project_id:float:The id of project",
+			},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractPathParams(tt.routePaths, tt.comments); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractPathParams() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_extractSwaggerRoutes(t *testing.T) {
+	comments := []string{
+		"+onecloud:swagger-gen-route-method=POST",
+		"+onecloud:swagger-gen-route-path=/v3/auth/tokens",
+		"+onecloud:swagger-gen-route-tag=authentication",
+		"+onecloud:swagger-gen-route-method[0]=GET",
+		"+onecloud:swagger-gen-route-path[0]=/v3/auth/tokens",
+		"+onecloud:swagger-gen-route-method[1]=POST",
+		"+onecloud:swagger-gen-route-path[1]=/v2.0/tokens",
+		"+onecloud:swagger-gen-route-tag[1]=authentication-v2",
+	}
+	want := []*SwaggerConfigRoute{
+		{Method: "POST", Path: "/v3/auth/tokens", Tags: []string{"authentication"}},
+		{Method: "GET", Path: "/v3/auth/tokens", Tags: []string{"authentication"}},
+		{Method: "POST", Path: "/v2.0/tokens", Tags: []string{"authentication-v2"}},
+	}
+	if got := extractSwaggerRoutes(comments); !reflect.DeepEqual(got, want) {
+		t.Errorf("extractSwaggerRoutes() = %v, want %v", got, want)
+	}
+}
+
+func Test_extractListFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		comments []string
+		want     []listEnvelopeField
+	}{
+		{
+			name:     "default",
+			comments: []string{},
+			want:     nil,
+		},
+		{
+			name: "rename total and drop offset with marker",
+			comments: []string{
+				"+onecloud:swagger-gen-resp-list-field=total:total_count",
+				"+onecloud:swagger-gen-resp-list-field=offset:",
+				"+onecloud:swagger-gen-resp-list-field=marker:next_marker",
+			},
+			want: []listEnvelopeField{
+				{"Limit", "int", "limit"},
+				{"Total", "int", "total_count"},
+				{"NextMarker", "string", "next_marker"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractListFields(tt.comments); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractListFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_registeredFakeManagers(t *testing.T) {
+	fakemodels.Register()
+	defer registry.Reset()
+
+	fakePkg := "yunion.io/x/code-generator/pkg/models/registry/testdata/fakemodels"
+	man := &types.Type{Name: types.Name{Package: fakePkg, Name: "SGuestManager"}}
+	if !isModelManagerRegistered(man) {
+		t.Fatalf("fake manager %s is not registered", man)
+	}
+	if singular, plural := getManagerKeywords(registry.GetByType(man)); singular != "server" || plural != "servers" {
+		t.Errorf("getManagerKeywords() = %s, %s, want server, servers", singular, plural)
+	}
+	want := sets.NewString("server", "disk", "guestdisk")
+	got := managerKeywords(registry.Managers())
+	if !got.singular.Equal(want) {
+		t.Errorf("managerKeywords() = %v, want %v", got.singular.List(), want.List())
+	}
+	if got.plural["servers"] != "server" {
+		t.Errorf("managerKeywords() plural of servers = %q, want server", got.plural["servers"])
+	}
+	model := &types.Type{Name: types.Name{Package: fakePkg, Name: "SGuest"}, Kind: types.Struct}
+	if _, err := ParseModel(man, model, nil); err != nil {
+		t.Errorf("ParseModel() of fake manager: %v", err)
+	}
+}
+
+func Test_ignoreAndDisableVerbs(t *testing.T) {
+	fakemodels.Register()
+	defer registry.Reset()
+
+	fakePkg := "yunion.io/x/code-generator/pkg/models/registry/testdata/fakemodels"
+	for _, tt := range []struct {
+		name        string
+		comments    []string
+		manComments []string
+		wantFilter  bool
+		wantIgnored []string
+	}{
+		{name: "no tag", wantFilter: true},
+		{name: "ignore verbs", comments: []string{"+onecloud:swagger-gen-ignore=delete,update"}, wantFilter: true, wantIgnored: []string{VerbUpdate, VerbDelete}},
+		{name: "ignore all", comments: []string{"+onecloud:swagger-gen-ignore"}},
+		{name: "disable on model", comments: []string{"+onecloud:swagger-gen-disable=list"}, wantFilter: true, wantIgnored: []string{VerbList}},
+		{name: "disable on manager", manComments: []string{"+onecloud:swagger-gen-disable=create,delete"}, wantFilter: true, wantIgnored: []string{VerbCreate, VerbDelete}},
+		{
+			name:        "disable on both",
+			comments:    []string{"+onecloud:swagger-gen-disable=update"},
+			manComments: []string{"+onecloud:swagger-gen-disable=list"},
+			wantFilter:  true,
+			wantIgnored: []string{VerbList, VerbUpdate},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			man := &types.Type{Name: types.Name{Package: fakePkg, Name: "SGuestManager"}, Kind: types.Struct, CommentLines: tt.manComments}
+			model := &types.Type{Name: types.Name{Package: fakePkg, Name: "SGuest"}, Kind: types.Struct, CommentLines: tt.comments}
+			run := newTestGeneration(t, nil)
+			g := newTestSwaggerGen(run)
+			g.sourcePackage = fakePkg
+			g.modelTypes = sets.NewString(model.String())
+			g.modelManagers = map[string]*types.Type{model.String(): man}
+			if got := g.Filter(nil, model); got != tt.wantFilter {
+				t.Fatalf("Filter() = %v, want %v", got, tt.wantFilter)
+			}
+			if !tt.wantFilter {
+				return
+			}
+			sw := generator.NewSnippetWriter(&bytes.Buffer{}, &generator.Context{Namers: NameSystems()}, "$", "$")
+			g.generateCode(man, model, sw)
+			if len(run.coverage) != 1 {
+				t.Fatalf("coverage = %v, want the model's", run.coverage)
+			}
+			ignored := sets.NewString(run.coverage[0].Ignored...)
+			for _, verb := range []string{VerbGet, VerbCreate, VerbList, VerbUpdate, VerbDelete} {
+				if want := sets.NewString(tt.wantIgnored...).Has(verb); ignored.Has(verb) != want {
+					t.Errorf("verb %s ignored = %v, want %v", verb, ignored.Has(verb), want)
+				}
+			}
+		})
+	}
+}
+
+func Test_promotedMethods(t *testing.T) {
+	method := func(comment string) *types.Type {
+		return &types.Type{Kind: types.Func, CommentLines: []string{comment}}
+	}
+	standalone := &types.Type{
+		Name: types.Name{Name: "SStandaloneResourceBase"},
+		Methods: map[string]*types.Type{
+			"GetDetailsMetadata": method("standalone"),
+			"PerformMetadata":    method("standalone"),
+		},
+	}
+	sharable := &types.Type{
+		Name:    types.Name{Name: "SSharableVirtualResourceBase"},
+		Members: []types.Member{{Embedded: true, Type: standalone}},
+		Methods: map[string]*types.Type{
+			"PerformPublic":   method("sharable"),
+			"PerformMetadata": method("sharable"),
+		},
+	}
+	external := &types.Type{
+		Name: types.Name{Name: "SExternalizedResourceBase"},
+		Methods: map[string]*types.Type{
+			"PerformSync":     method("external"),
+			"PerformMetadata": method("external"),
+		},
+	}
+	syncable := &types.Type{
+		Name: types.Name{Name: "SSyncableBaseResource"},
+		Methods: map[string]*types.Type{
+			"PerformSync": method("syncable"),
+		},
+	}
+	model := &types.Type{
+		Name: types.Name{Name: "SNetwork"},
+		Members: []types.Member{
+			{Embedded: true, Type: &types.Type{Kind: types.Pointer, Elem: sharable}},
+			{Embedded: true, Type: external},
+			{Embedded: true, Type: syncable},
+		},
+		Methods: map[string]*types.Type{
+			"PerformPublic": method("network"),
+		},
+	}
+	got := promotedMethods(model)
+	// PerformSync is ambiguous between external and syncable, PerformMetadata between sharable and external
+	want := map[string]*types.Type{
+		"GetDetailsMetadata": standalone,
+		"PerformPublic":      model,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("promotedMethods() = %v, want %v", got, want)
+	}
+	for name, recv := range want {
+		m, ok := got[name]
+		if !ok || m.receiver != recv || m.method != recv.Methods[name] {
+			t.Errorf("promotedMethods()[%s] = %v, want method of %s", name, m, recv.Name)
+		}
+	}
+}
+
+func Test_getPromotedMethods(t *testing.T) {
+	perform := &types.Type{Kind: types.Func}
+	base := &types.Type{
+		Name:    types.Name{Package: "yunion.io/x/onecloud/pkg/cloudcommon/db", Name: "SSharableVirtualResourceBase"},
+		Kind:    types.Struct,
+		Methods: map[string]*types.Type{"PerformPublic": perform},
+	}
+	model := &types.Type{
+		Name:    types.Name{Package: "yunion.io/x/onecloud/pkg/compute/models", Name: "SNetwork"},
+		Kind:    types.Struct,
+		Members: []types.Member{{Embedded: true, Type: base}},
+	}
+	p := &typeParser{run: newTestGeneration(t, nil), model: model, singular: "network", plural: "networks"}
+	ms := p.getPromotedMethods(Perform, model, nil)
+	if len(ms) != 1 {
+		t.Fatalf("getPromotedMethods() = %v, want PerformPublic", ms)
+	}
+	if ms[0].Receiver() != base || ms[0].Model() != model {
+		t.Errorf("getPromotedMethods() receiver %s model %s, want %s of %s", ms[0].Receiver(), ms[0].Model(), base, model)
+	}
+}
+
+func Test_singletonRoutes(t *testing.T) {
+	man := &types.Type{Name: types.Name{Name: "SCapabilityManager"}, CommentLines: []string{"+onecloud:swagger-gen-singleton"}}
+	if !isSingletonManager(man) {
+		t.Fatalf("isSingletonManager() = false, want true")
+	}
+	model := &types.Type{Name: types.Name{Name: "SCapability"}}
+	run := newTestGeneration(t, nil)
+	m := NewMethod(run, model, "PerformSync", &types.Type{Kind: types.Func}, "capability", "capabilities")
+	m.singleton = true
+	r := newRouteFactory(m).PerformAction(newParameterFactory(m).newParameter(), &response{})
+	if r.path != "/capability/sync" {
+		t.Errorf("singleton route path = %s, want /capability/sync", r.path)
+	}
+	r.applyPolicy()
+	want, _ := routePolicyAction(run.args.APIVersion, RouteInfo{Method: "POST", Path: "/capabilities/{id}/sync"})
+	if got := r.extensions[extPolicyAction]; !strings.HasSuffix(got, want.String()) {
+		t.Errorf("singleton route policy action = %s, want %s", got, want.String())
+	}
+}
+
+func Test_generateHead(t *testing.T) {
+	str := &types.Type{Name: types.Name{Name: "string"}, Kind: types.Builtin}
+	query := &types.Type{Name: types.Name{Package: "compute", Name: "ServerGetInput"}, Kind: types.Struct}
+	output := &types.Type{Name: types.Name{Package: "compute", Name: "ServerDetails"}, Kind: types.Struct}
+	run := newTestGeneration(t, nil)
+	get := NewMethod(run, &types.Type{Name: types.Name{Name: "SGuest"}}, Get, &types.Type{
+		Kind:         types.Func,
+		CommentLines: []string{"Get server details"},
+		Signature: &types.Signature{
+			Parameters: faketypes.ParamResults(str, str, &types.Type{Kind: types.Pointer, Elem: query}),
+			Results:    faketypes.ParamResults(&types.Type{Kind: types.Pointer, Elem: output}, str),
+		},
+	}, "server", "servers")
+	buf := &bytes.Buffer{}
+	ctx := &generator.Context{Namers: namer.NameSystems{"raw": namer.NewRawNamer("", nil)}}
+	sw := generator.NewSnippetWriter(buf, ctx, "$", "$")
+	newTestSwaggerGen(run).generateHead(get, sw)
+	if err := sw.Error(); err != nil {
+		t.Fatalf("generateHead error: %v", err)
+	}
+	for _, want := range []string{
+		"// swagger:route HEAD /servers/{id} server server_Head\n",
+		"// Check existence of server, responds 404 if not found\n",
+		"// 200: server_HeadOutput\n",
+		"// swagger:parameters server_Head\n",
+		"compute.ServerGetInput\n",
+		"// swagger:response server_HeadOutput\ntype server_HeadOutput struct {\n}\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("generateHead() = %q, should contain %q", buf.String(), want)
+		}
+	}
+	if strings.Contains(buf.String(), "Get server details") {
+		t.Errorf("generateHead() = %q, should not contain GET docs", buf.String())
+	}
+}
+
+func Test_generateGetValueQuery(t *testing.T) {
+	str := &types.Type{Name: types.Name{Name: "string"}, Kind: types.Builtin}
+	boolPtr := &types.Type{Kind: types.Pointer, Elem: &types.Type{Name: types.Name{Name: "bool"}, Kind: types.Builtin}}
+	meta := &types.Type{Name: types.Name{Package: "apis", Name: "Meta"}, Kind: types.Struct}
+	query := &types.Type{
+		Name: types.Name{Package: "compute", Name: "ServerDetailsQuery"},
+		Kind: types.Struct,
+		Members: []types.Member{
+			{Name: "Meta", Embedded: true, Type: meta},
+			{Name: "Details", Type: boolPtr, Tags: `json:"details"`, CommentLines: []string{"return details of server"}},
+		},
+	}
+	output := &types.Type{Name: types.Name{Package: "compute", Name: "ServerDetails"}, Kind: types.Struct}
+	run := newTestGeneration(t, nil)
+	get := NewMethod(run, &types.Type{Name: types.Name{Name: "SGuest"}}, Get, &types.Type{
+		Kind: types.Func,
+		Signature: &types.Signature{
+			Parameters: faketypes.ParamResults(str, str, query),
+			Results:    faketypes.ParamResults(&types.Type{Kind: types.Pointer, Elem: output}, str),
+		},
+	}, "server", "servers")
+	buf := &bytes.Buffer{}
+	ctx := &generator.Context{Namers: namer.NameSystems{"raw": namer.NewRawNamer("", nil)}}
+	sw := generator.NewSnippetWriter(buf, ctx, "$", "$")
+	newTestSwaggerGen(run).generateGet(get, nil, sw)
+	if err := sw.Error(); err != nil {
+		t.Fatalf("generateGet error: %v", err)
+	}
+	want := "// return details of server\nDetails *bool `json:\"details\"`\n"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("generateGet() = %q, should contain %q", buf.String(), want)
+	}
+	if strings.Contains(buf.String(), "apis.Meta") || strings.Contains(buf.String(), "compute.ServerDetailsQuery\n") {
+		t.Errorf("generateGet() = %q, should flatten query with marker", buf.String())
+	}
+}
+
+func Test_generateGetSpecOutputs(t *testing.T) {
+	str := &types.Type{Name: types.Name{Name: "string"}, Kind: types.Builtin}
+	errType := &types.Type{Name: types.Name{Name: "error"}, Kind: types.Builtin}
+	query := &types.Type{Name: types.Name{Package: "yunion.io/x/jsonutils", Name: "JSONObject"}, Kind: types.Interface}
+	tests := []struct {
+		name   string
+		output *types.Type
+		want   string
+	}{
+		{name: "string", output: str, want: "Output string `json:\"server\"`\n"},
+		{name: "jsonutils", output: query, want: "Output map[string]interface{} `json:\"server\"`\n"},
+		{
+			name:   "slice of structs",
+			output: &types.Type{Kind: types.Slice, Elem: &types.Type{Kind: types.Pointer, Elem: &types.Type{Name: types.Name{Package: "compute", Name: "GuestDiskDetails"}, Kind: types.Struct}}},
+			want:   "Output []compute.GuestDiskDetails `json:\"server\"`\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := newTestGeneration(t, nil)
+			m := NewMethod(run, &types.Type{Name: types.Name{Name: "SGuest"}}, "GetDetailsVncPassword", &types.Type{
+				Kind: types.Func,
+				Signature: &types.Signature{
+					Parameters: faketypes.ParamResults(str, str, query),
+					Results:    faketypes.ParamResults(tt.output, errType),
+				},
+			}, "server", "servers")
+			buf := &bytes.Buffer{}
+			ctx := &generator.Context{Namers: namer.NameSystems{"raw": namer.NewRawNamer("", nil)}}
+			sw := generator.NewSnippetWriter(buf, ctx, "$", "$")
+			newTestSwaggerGen(run).generateGetSpec(m, sw)
+			if err := sw.Error(); err != nil {
+				t.Fatalf("generateGetSpec error: %v", err)
+			}
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("generateGetSpec() = %q, should contain %q", buf.String(), tt.want)
+			}
+			if strings.Contains(buf.String(), "input or output error exists") {
+				t.Errorf("generateGetSpec() = %q, should not report output error", buf.String())
+			}
+		})
+	}
+}
+
+func Test_supportsBatchCreate(t *testing.T) {
+	method := &types.Type{Kind: types.Func}
+	dbBase := &types.Type{
+		Name:    types.Name{Package: "yunion.io/x/onecloud/pkg/cloudcommon/db", Name: "SModelBaseManager"},
+		Methods: map[string]*types.Type{batchCreateMethod: method},
+	}
+	guests := &types.Type{
+		Name:    types.Name{Package: "yunion.io/x/onecloud/pkg/compute/models", Name: "SGuestManager"},
+		Members: []types.Member{{Embedded: true, Type: dbBase}},
+		Methods: map[string]*types.Type{batchCreateMethod: method},
+	}
+	zones := &types.Type{
+		Name:    types.Name{Package: "yunion.io/x/onecloud/pkg/compute/models", Name: "SZoneManager"},
+		Members: []types.Member{{Embedded: true, Type: dbBase}},
+	}
+	if !supportsBatchCreate(guests) {
+		t.Errorf("supportsBatchCreate(%s) = false, want true", guests)
+	}
+	if supportsBatchCreate(zones) {
+		t.Errorf("supportsBatchCreate(%s) of db base method = true, want false", zones)
+	}
+}
@@ -0,0 +1,106 @@
+package generators
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/gengo/v2/types"
+
+	"yunion.io/x/pkg/util/sets"
+
+	"yunion.io/x/code-generator/pkg/common"
+	"yunion.io/x/code-generator/pkg/models/registry"
+)
+
+// Generation is a run of swagger-gen built from CustomArgs, reports of the run, e.g. routes,
+// coverage and stats, are collected by it and written by its Write* methods.
+// Runs don't share state, a new Generation is used for each run, e.g. regeneration of --watch.
+type Generation struct {
+	args *CustomArgs
+	// routePackage is the go package name of output package, e.g. compute
+	routePackage string
+	// filter restricts generation to the resources of --only, nil if all resources are generated
+	filter *resourceFilter
+	// references are keywords of resources referable by input fields, nil if --cross-references is off
+	references *resourceKeywords
+	// definitions is the definition namer of body types
+	definitions *definitionNamer
+	// typeOverrides are the swagger schema of go types of --type-overrides
+	typeOverrides common.TypeOverrides
+	// bodyWrappers is the namer of body wrappers of --named-body-wrappers
+	bodyWrappers *bodyWrapperNamer
+	// guards are the analyzed Allow* guards of model packages
+	guards *guardAnalyzer
+	// universe is the loaded types of the run, set when packages are generated
+	universe types.Universe
+	// out is the output package all input packages are generated into, it outlives the contexts of streamed inputs
+	out *outputPackage
+
+	routes []RouteInfo
+	// coverage records which routes of models are generated
+	coverage []*ModelCoverage
+	// sourceMap records operation id to the source method or function generating it
+	sourceMap map[string]string
+	// contractTypes are input and output types of routes -> required json fields
+	contractTypes map[string][]string
+	// constructorTypes are body types of create and perform routes, NewXxx constructors are rendered for them
+	constructorTypes map[string]*types.Type
+	// unregisteredModels are model types skipped because their managers are not registered
+	unregisteredModels sets.String
+	// stats are the models and functions of input packages found, keyed by package path
+	stats map[string]*PackageStats
+}
+
+// outputPackageName returns go package name of output package path, e.g. compute of .../swagger/compute
+func outputPackageName(pkgPath string) string {
+	if pkgPath == "" {
+		return ""
+	}
+	return strings.Split(filepath.Base(pkgPath), ".")[0]
+}
+
+// NewGeneration validates customArgs and returns the run generating into output package outputPackagePath,
+// source files of model packages are selected by build tags the same as loading them
+func NewGeneration(customArgs *CustomArgs, outputPackagePath string, tags []string) (*Generation, error) {
+	if err := validateLang(customArgs.Lang); err != nil {
+		return nil, fmt.Errorf("invalid --lang: %v", err)
+	}
+	if err := validateDefinitionNaming(customArgs.DefinitionNaming); err != nil {
+		return nil, fmt.Errorf("invalid --definition-naming: %v", err)
+	}
+	if err := common.ValidateAPIVersion(customArgs.APIVersion); err != nil {
+		return nil, fmt.Errorf("invalid --api-version: %v", err)
+	}
+	if err := validateListResponseHeaders(customArgs.ListResponseHeaders); err != nil {
+		return nil, fmt.Errorf("invalid --list-response-headers: %v", err)
+	}
+	overrides, err := common.LoadTypeOverrides(customArgs.TypeOverrides)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --type-overrides: %v", err)
+	}
+	pkgName := outputPackageName(outputPackagePath)
+	run := &Generation{
+		args:          customArgs,
+		routePackage:  pkgName,
+		filter:        newResourceFilter(customArgs.Only),
+		definitions:   newDefinitionNamer(customArgs.DefinitionNaming, pkgName),
+		typeOverrides: overrides,
+		bodyWrappers:  newBodyWrapperNamer(customArgs.NamedBodyWrappers),
+		guards:        newGuardAnalyzer(tags),
+		out:           newOutputPackage(),
+
+		routes:             make([]RouteInfo, 0),
+		coverage:           make([]*ModelCoverage, 0),
+		sourceMap:          make(map[string]string),
+		contractTypes:      make(map[string][]string),
+		constructorTypes:   make(map[string]*types.Type),
+		unregisteredModels: sets.NewString(),
+		stats:              make(map[string]*PackageStats),
+	}
+	run.definitions.external = customArgs.ExternalDefinitions
+	if customArgs.CrossReferences {
+		run.references = managerKeywords(registry.Managers())
+	}
+	return run, nil
+}
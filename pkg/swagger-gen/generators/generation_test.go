@@ -0,0 +1,65 @@
+package generators
+
+import (
+	"testing"
+)
+
+// newTestGeneration returns the run of default arguments modified by set
+func newTestGeneration(t *testing.T, set func(ca *CustomArgs)) *Generation {
+	_, ca := NewDefaults()
+	if set != nil {
+		set(ca)
+	}
+	run, err := NewGeneration(ca, "yunion.io/x/onecloud/pkg/generated/swagger/compute", nil)
+	if err != nil {
+		t.Fatalf("NewGeneration() error: %v", err)
+	}
+	return run
+}
+
+// newTestSwaggerGen returns generator of run writing into a new output package
+func newTestSwaggerGen(run *Generation) *swaggerGen {
+	return &swaggerGen{args: run.args, run: run, out: newOutputPackage()}
+}
+
+func TestNewGeneration(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		set     func(ca *CustomArgs)
+		wantErr bool
+	}{
+		{name: "defaults"},
+		{name: "lang", set: func(ca *CustomArgs) { ca.Lang = "fr" }, wantErr: true},
+		{name: "definition naming", set: func(ca *CustomArgs) { ca.DefinitionNaming = "long" }, wantErr: true},
+		{name: "api version", set: func(ca *CustomArgs) { ca.APIVersion = "2" }, wantErr: true},
+		{name: "list response headers", set: func(ca *CustomArgs) { ca.ListResponseHeaders = []string{"X-Next-Page"} }, wantErr: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ca := NewDefaults()
+			if tt.set != nil {
+				tt.set(ca)
+			}
+			run, err := NewGeneration(ca, "yunion.io/x/onecloud/pkg/generated/swagger/compute", nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewGeneration() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && run.routePackage != "compute" {
+				t.Errorf("NewGeneration() route package = %s, want compute", run.routePackage)
+			}
+		})
+	}
+}
+
+func TestGenerationIsolated(t *testing.T) {
+	// reports of a run are not seen by other runs, e.g. regeneration of --watch
+	first := newTestGeneration(t, nil)
+	first.recordRoute(route{action: "GET", path: "/servers", parameter: newParameter("server", "servers", "server_List")})
+	first.unregisteredModels.Insert("compute.SGuest")
+	second := newTestGeneration(t, nil)
+	if len(second.sortedRoutes()) != 0 || second.CheckRegisteredManagers() != nil {
+		t.Errorf("new run sees routes %v or unregistered models of other run", second.sortedRoutes())
+	}
+	if len(first.sortedRoutes()) != 1 || first.CheckRegisteredManagers() == nil {
+		t.Errorf("run lost its routes %v or unregistered models", first.sortedRoutes())
+	}
+}
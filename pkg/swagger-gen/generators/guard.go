@@ -0,0 +1,196 @@
+package generators
+
+import (
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+
+	"yunion.io/x/log"
+)
+
+const (
+	// extGuard is the Allow* guard method of route, e.g. AllowPerformStart
+	extGuard = "x-guard"
+	// extGuardScope is the least scope allowed by the guard, e.g. project
+	extGuardScope = "x-guard-scope"
+)
+
+// guardInfo is the authorization behavior of an Allow* guard method
+type guardInfo struct {
+	// name is the guard method name, e.g. AllowPerformStart
+	name string
+	// never is true if the guard always returns false, the route is never allowed
+	never bool
+	// scope is the least scope allowed by the guard, empty if unknown
+	scope string
+}
+
+// guardAnalyzer parse go source of model packages to analyze Allow* method bodies,
+// gengo doesn't keep function bodies.
+type guardAnalyzer struct {
+	// tags are the build tags selecting source files, same as the ones loading input packages
+	tags []string
+	// package path -> "Receiver.Method" -> guard
+	pkgs map[string]map[string]*guardInfo
+}
+
+func newGuardAnalyzer(tags []string) *guardAnalyzer {
+	return &guardAnalyzer{
+		tags: tags,
+		pkgs: make(map[string]map[string]*guardInfo),
+	}
+}
+
+// guardOf returns the analyzed Allow* guard of route method m, nil if not defined
+func (a *guardAnalyzer) guardOf(m *Method) *guardInfo {
+	allow := m.allowMethod()
+	if allow == nil {
+		return nil
+	}
+	recv := allow.receiver
+	guards := a.loadPackage(recv.Name.Package)
+	if guard, ok := guards[recv.Name.Name+"."+allowMethodName(m.Name())]; ok {
+		return guard
+	}
+	return &guardInfo{name: allowMethodName(m.Name())}
+}
+
+func (a *guardAnalyzer) loadPackage(pkgPath string) map[string]*guardInfo {
+	if guards, ok := a.pkgs[pkgPath]; ok {
+		return guards
+	}
+	guards := make(map[string]*guardInfo)
+	a.pkgs[pkgPath] = guards
+	ctx := build.Default
+	ctx.BuildTags = a.tags
+	// same as gengo parser, cgo is not supported
+	ctx.CgoEnabled = false
+	bp, err := ctx.Import(pkgPath, ".", 0)
+	if err != nil {
+		log.Warningf("find package %s for Allow* guards: %v", pkgPath, err)
+		return guards
+	}
+	fset := token.NewFileSet()
+	for _, name := range bp.GoFiles {
+		f, err := parser.ParseFile(fset, filepath.Join(bp.Dir, name), nil, 0)
+		if err != nil {
+			log.Warningf("parse %s for Allow* guards: %v", name, err)
+			continue
+		}
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || len(fn.Recv.List) == 0 || !strings.HasPrefix(fn.Name.Name, "Allow") {
+				continue
+			}
+			recv := receiverName(fn.Recv.List[0].Type)
+			if recv == "" {
+				continue
+			}
+			guards[recv+"."+fn.Name.Name] = classifyGuard(fn)
+		}
+	}
+	return guards
+}
+
+func receiverName(expr ast.Expr) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+func callName(call *ast.CallExpr) string {
+	switch fn := call.Fun.(type) {
+	case *ast.Ident:
+		return fn.Name
+	case *ast.SelectorExpr:
+		return fn.Sel.Name
+	}
+	return ""
+}
+
+// classifyGuard analyze Allow* method body:
+// always returning false means never allowed,
+// onecloud db.IsAdminAllow*, IsDomainAllow*, IsProjectAllow* and IsOwner calls decide the least scope.
+// Function literals are skipped, their returns and calls aren't the ones of the guard.
+func classifyGuard(fn *ast.FuncDecl) *guardInfo {
+	guard := &guardInfo{name: fn.Name.Name}
+	if fn.Body == nil {
+		return guard
+	}
+	hasReturn, onlyFalse := false, true
+	admin, domain, project := false, false, false
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			hasReturn = true
+			if len(x.Results) != 1 {
+				onlyFalse = false
+			} else if ident, ok := x.Results[0].(*ast.Ident); !ok || ident.Name != "false" {
+				onlyFalse = false
+			}
+		case *ast.CallExpr:
+			name := callName(x)
+			switch {
+			case strings.HasPrefix(name, "IsAdminAllow"):
+				admin = true
+			case strings.HasPrefix(name, "IsDomainAllow"):
+				domain = true
+			case strings.HasPrefix(name, "IsProjectAllow"), name == "IsOwner":
+				project = true
+			}
+		}
+		return true
+	})
+	switch {
+	case hasReturn && onlyFalse:
+		guard.never = true
+	case project:
+		guard.scope = PolicyScopeProject
+	case domain:
+		guard.scope = PolicyScopeDomain
+	case admin:
+		guard.scope = PolicyScopeSystem
+	}
+	return guard
+}
+
+// addGuard documents the Allow* guard of route by extGuard and its least scope by extGuardScope if known
+func (r *route) addGuard(g *guardInfo) {
+	r.addExtension(extGuard, g.name)
+	if g.scope != "" {
+		r.addExtension(extGuardScope, g.scope)
+	}
+}
+
+// isNeverAllowed returns true if m is guarded by Allow* method always returning false
+func isNeverAllowed(m *Method) bool {
+	if m == nil {
+		return false
+	}
+	guard := m.run.guards.guardOf(m)
+	return guard != nil && guard.never
+}
+
+// allowedMethods filter out never allowed methods returned by getMethods
+func allowedMethods(getMethods func() []*Method) func() []*Method {
+	return func() []*Method {
+		ret := make([]*Method, 0)
+		for _, m := range getMethods() {
+			if isNeverAllowed(m) {
+				log.Infof("skip never allowed route method %s", m.String())
+				continue
+			}
+			ret = append(ret, m)
+		}
+		return ret
+	}
+}
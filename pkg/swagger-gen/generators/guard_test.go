@@ -0,0 +1,109 @@
+package generators
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_classifyGuard(t *testing.T) {
+	src := `package models
+
+func (self *SGuest) AllowPerformPurge(userCred mcclient.TokenCredential) bool {
+	return db.IsAdminAllowPerform(userCred, self, "purge")
+}
+
+func (self *SGuest) AllowPerformStart(userCred mcclient.TokenCredential) bool {
+	return self.IsOwner(userCred) || db.IsAdminAllowPerform(userCred, self, "start")
+}
+
+func (self *SGuest) AllowPerformMigrate(userCred mcclient.TokenCredential) bool {
+	return db.IsDomainAllowPerform(userCred, self, "migrate") || db.IsAdminAllowPerform(userCred, self, "migrate")
+}
+
+func (self *SGuest) AllowPerformSync(userCred mcclient.TokenCredential) bool {
+	return false
+}
+
+func (self *SGuest) AllowPerformSave(userCred mcclient.TokenCredential) bool {
+	return userCred.HasSystemAdminPrivilege()
+}
+
+func (self *SGuest) AllowPerformRenew(userCred mcclient.TokenCredential) bool {
+	isAdmin := func() bool {
+		return db.IsAdminAllowPerform(userCred, self, "renew")
+	}
+	return isAdmin() || userCred.HasSystemAdminPrivilege()
+}
+
+func (self *SGuest) AllowPerformReset(userCred mcclient.TokenCredential) bool {
+	allow := func() bool { return true }
+	_ = allow
+	return false
+}
+`
+	f, err := goparser.ParseFile(token.NewFileSet(), "models.go", src, 0)
+	if err != nil {
+		t.Fatalf("parse source: %v", err)
+	}
+	want := map[string]guardInfo{
+		"AllowPerformPurge":   {name: "AllowPerformPurge", scope: PolicyScopeSystem},
+		"AllowPerformStart":   {name: "AllowPerformStart", scope: PolicyScopeProject},
+		"AllowPerformMigrate": {name: "AllowPerformMigrate", scope: PolicyScopeDomain},
+		"AllowPerformSync":    {name: "AllowPerformSync", never: true},
+		"AllowPerformSave":    {name: "AllowPerformSave"},
+		"AllowPerformRenew":   {name: "AllowPerformRenew"},
+		"AllowPerformReset":   {name: "AllowPerformReset", never: true},
+	}
+	for _, decl := range f.Decls {
+		fn := decl.(*ast.FuncDecl)
+		if got := classifyGuard(fn); *got != want[fn.Name.Name] {
+			t.Errorf("classifyGuard(%s) = %+v, want %+v", fn.Name.Name, *got, want[fn.Name.Name])
+		}
+	}
+}
+
+func Test_guardAnalyzerBuildTags(t *testing.T) {
+	dir, err := ioutil.TempDir("", "guards")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"guest.go":    "package models\n\nfunc (self *SGuest) AllowPerformStart() bool {\n\treturn self.IsOwner()\n}\n",
+		"guest_ee.go": "// +build ee\n\npackage models\n\nfunc (self *SGuest) AllowPerformSync() bool {\n\treturn false\n}\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkgPath, err := filepath.Rel(cwd, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkgPath = filepath.ToSlash(pkgPath); !strings.HasPrefix(pkgPath, "../") {
+		pkgPath = "./" + pkgPath
+	}
+	for _, tt := range []struct {
+		tags []string
+		want int
+	}{
+		{tags: nil, want: 1},
+		{tags: []string{"ee"}, want: 2},
+	} {
+		guards := newGuardAnalyzer(tt.tags).loadPackage(pkgPath)
+		if len(guards) != tt.want {
+			t.Errorf("loadPackage() with tags %v = %d guards, want %d", tt.tags, len(guards), tt.want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package generators
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// APIVersionHeader is the request header the gateway negotiates api version by
+const APIVersionHeader = "X-Yunion-Api-Version"
+
+// headerParameters collects operations of a generated file which accept the shared header parameters
+type headerParameters struct {
+	name string
+	// version is the api version of example
+	version string
+	ids     []string
+}
+
+func newHeaderParameters(name, version string) *headerParameters {
+	return &headerParameters{
+		name:    name,
+		version: version,
+		ids:     make([]string, 0),
+	}
+}
+
+// add records operation id accepting the header parameters
+func (h *headerParameters) add(id string) {
+	h.ids = append(h.ids, id)
+}
+
+// write emits the swagger:parameters struct of header parameters shared by collected operations
+func (h *headerParameters) write(w io.Writer) error {
+	if len(h.ids) == 0 {
+		return nil
+	}
+	_, err := fmt.Fprintf(w, `// swagger:parameters %s
+type %s struct {
+	// Api version of request, the gateway routes request to the backend serving the version,
+	// the version of route path is used if absent
+	// in:header
+	// example: %s
+	APIVersion string %s
+}
+
+`, strings.Join(h.ids, " "), h.name, h.version, fmt.Sprintf("`json:\"%s\"`", APIVersionHeader))
+	return err
+}
+
+// responseHeader is a standard header of responses
+type responseHeader struct {
+	// field is the go field name in response struct
+	field       string
+	typ         string
+	description string
+}
+
+// listResponseHeaders are the standard headers list responses may carry, selected by --list-response-headers
+var listResponseHeaders = map[string]responseHeader{
+	"X-Total-Count": {
+		field:       "TotalCount",
+		typ:         "int64",
+		description: "Total number of resources matching the query, same as total of body",
+	},
+	"Content-Range": {
+		field:       "ContentRange",
+		typ:         "string",
+		description: "Range of returned resources in total, e.g. items 0-19/100",
+	},
+}
+
+// validateListResponseHeaders checks headers are in listResponseHeaders
+func validateListResponseHeaders(headers []string) error {
+	for _, name := range headers {
+		if _, ok := listResponseHeaders[name]; !ok {
+			choices := make([]string, 0, len(listResponseHeaders))
+			for n := range listResponseHeaders {
+				choices = append(choices, n)
+			}
+			sort.Strings(choices)
+			return fmt.Errorf("unknown list response header %q, choices: %v", name, choices)
+		}
+	}
+	return nil
+}
+
+// doListResponseHeaders emits fields of headers named by names in list response struct, e.g. of --list-response-headers
+func doListResponseHeaders(names []string, h *snippetWriter) {
+	for _, name := range names {
+		header := listResponseHeaders[name]
+		h.line(header.description)
+		h.line("in:header")
+		h.sw.Do(fmt.Sprintf("%s %s `json:\"%s\"`\n", header.field, header.typ, name), nil)
+	}
+}
+
+// doHeaderField emits the field of header declared by function route tags, e.g. XAuthToken of X-Auth-Token
+func doHeaderField(header SwaggerConfigHeader, h *snippetWriter) {
+	pt := pathParamTypes[header.Type]
+	if pt.format != "" {
+		h.line(fmt.Sprintf("swagger:strfmt %s", pt.format))
+	}
+	h.sw.Do(fmt.Sprintf("%s %s `json:\"%s\"`\n", paramFieldName(header.Name), pt.goType, header.Name), nil)
+}
@@ -0,0 +1,118 @@
+package generators
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/namer"
+	"k8s.io/gengo/v2/types"
+
+	"yunion.io/x/code-generator/pkg/common/testdata/faketypes"
+)
+
+func Test_headerParameters(t *testing.T) {
+	run := newTestGeneration(t, nil)
+	h := newHeaderParameters("compute_APIVersionHeader", "v1")
+	buf := &bytes.Buffer{}
+	if err := h.write(buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("headerParameters.write() without operations = %q, want empty", buf.String())
+	}
+	sw := generator.NewSnippetWriter(&bytes.Buffer{}, &generator.Context{}, "$", "$")
+	for _, id := range []string{"server_List", "server_Get"} {
+		route{action: "GET", path: "/servers", operationId: id, response: map[int]*response{}, headers: h, run: run}.Do(sw)
+	}
+	if err := h.write(buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"// swagger:parameters server_List server_Get\ntype compute_APIVersionHeader struct {\n",
+		"// in:header\n",
+		"APIVersion string `json:\"X-Yunion-Api-Version\"`\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("headerParameters.write() = %q, should contain %q", buf.String(), want)
+		}
+	}
+}
+
+func Test_listResponseHeaders(t *testing.T) {
+	if err := validateListResponseHeaders([]string{"X-Total-Count", "X-Next-Page"}); err == nil {
+		t.Errorf("validateListResponseHeaders(X-Next-Page) should fail")
+	}
+	run := newTestGeneration(t, func(ca *CustomArgs) { ca.ListResponseHeaders = []string{"X-Total-Count", "Content-Range"} })
+	output := &types.Type{Name: types.Name{Package: "compute", Name: "ServerDetails"}, Kind: types.Struct}
+	for _, tt := range []struct {
+		name string
+		resp response
+		want bool
+	}{
+		{name: "list", resp: response{output: output, id: "server_ListOutput", bodyKey: "servers", isList: true}, want: true},
+		{name: "get", resp: response{output: output, id: "server_GetOutput", bodyKey: "server"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			sw := generator.NewSnippetWriter(buf, &generator.Context{Namers: namer.NameSystems{"raw": namer.NewRawNamer("", nil)}}, "$", "$")
+			tt.resp.run, tt.resp.out = run, newOutputPackage()
+			tt.resp.Do(sw)
+			if err := sw.Error(); err != nil {
+				t.Fatal(err)
+			}
+			for _, header := range []string{"// in:header\nTotalCount int64 `json:\"X-Total-Count\"`\n", "ContentRange string `json:\"Content-Range\"`\n"} {
+				if got := strings.Contains(buf.String(), header); got != tt.want {
+					t.Errorf("response.Do() = %q, contains %q: %v, want %v", buf.String(), header, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func Test_SwaggerConfigHeaders(t *testing.T) {
+	str := &types.Type{Name: types.Name{Name: "string"}, Kind: types.Builtin}
+	errType := &types.Type{Name: types.Name{Name: "error"}, Kind: types.Builtin}
+	input := &types.Type{Name: types.Name{Package: "identity", Name: "AuthInput"}, Kind: types.Struct}
+	fn := &types.Type{
+		Name: types.Name{Package: "yunion.io/x/onecloud/pkg/keystone/tokens", Name: "AuthenticateV3"},
+		Kind: types.DeclarationOf,
+		Underlying: &types.Type{
+			Kind: types.Func,
+			Signature: &types.Signature{
+				Parameters: faketypes.ParamResults(str, &types.Type{Kind: types.Pointer, Elem: input}),
+				Results:    faketypes.ParamResults(errType),
+			},
+		},
+		SecondClosestCommentLines: []string{
+			"+onecloud:swagger-gen-route-method=POST",
+			"+onecloud:swagger-gen-route-path=/auth/tokens",
+			"+onecloud:swagger-gen-route-tag=auth",
+			"+onecloud:swagger-gen-param-body-index=1",
+			"+onecloud:swagger-gen-param-header=X-Auth-Token:token to authenticate by",
+			"+onecloud:swagger-gen-resp-header=X-Subject-Token:issued token",
+			"+onecloud:swagger-gen-resp-header=X-Token-Expires:int:seconds the token expires in",
+		},
+	}
+	config := getFunctionHasSwaggerConfig(fn)
+	if config == nil {
+		t.Fatal("getFunctionHasSwaggerConfig() = nil")
+	}
+	buf := &bytes.Buffer{}
+	ctx := &generator.Context{Namers: namer.NameSystems{"raw": namer.NewRawNamer("", nil)}}
+	sw := generator.NewSnippetWriter(buf, ctx, "$", "$")
+	config.generate(newTestSwaggerGen(newTestGeneration(t, nil)), fn, sw)
+	if err := sw.Error(); err != nil {
+		t.Fatalf("generate error: %v", err)
+	}
+	for _, want := range []string{
+		"// token to authenticate by\n// in:header\n// required:true\nXAuthToken string `json:\"X-Auth-Token\"`\n",
+		"type tokens_AuthenticateV3Output struct {\n// issued token\n// in:header\nXSubjectToken string `json:\"X-Subject-Token\"`\n" +
+			"// seconds the token expires in\n// in:header\nXTokenExpires int64 `json:\"X-Token-Expires\"`\n}\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("generate() = %q, should contain %q", buf.String(), want)
+		}
+	}
+}
@@ -2,14 +2,18 @@ package generators
 
 import (
 	"fmt"
+	"go/ast"
 	"path/filepath"
 	"strings"
 
-	"k8s.io/gengo/generator"
-	"k8s.io/gengo/types"
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/types"
 
 	"yunion.io/x/log"
+	"yunion.io/x/pkg/util/sets"
 	"yunion.io/x/pkg/utils"
+
+	"yunion.io/x/code-generator/pkg/common"
 )
 
 func privateName(structName, methodName string) string {
@@ -46,16 +50,30 @@ func (f *routeFactory) newRoute(action string, input *parameter, output *respons
 	method := f.method
 	r := &route{
 		action:    action,
+		source:    method.String(),
 		parameter: input,
 		tags:      []string{method.resSingular},
 		response: map[int]*response{
 			200: output,
 		},
+		run: method.run,
+	}
+	if isWebsocketRoute(method.Method().CommentLines) {
+		r.setWebsocket()
+	}
+	for _, comments := range [][]string{method.Model().CommentLines, method.Method().CommentLines} {
+		for key, val := range extractExtensions(comments) {
+			r.addExtension(key, val)
+		}
 	}
-	commentLines := method.Method().CommentLines
-	if len(commentLines) > 0 {
-		r.summary = commentLines[0]
+	policyComments := [][]string{method.Method().CommentLines}
+	if allow := method.allowMethod(); allow != nil {
+		policyComments = append(policyComments, allow.method.CommentLines)
 	}
+	policyComments = append(policyComments, method.Model().CommentLines)
+	r.policyScope, r.policyAction = extractPolicyTags(policyComments...)
+	doc := parseRouteDoc(method.Name(), method.Method().CommentLines, method.run.args.AllDocComments)
+	r.summary = doc.summary
 	if len(input.errorMsgs) != 0 || len(output.errorMsgs) != 0 {
 		r.summary = "input or output error exists"
 	}
@@ -66,11 +84,16 @@ func (f *routeFactory) newRoute(action string, input *parameter, output *respons
 	if len(output.errorMsgs) != 0 {
 		desc = append(desc, fmt.Sprintf("output error: %s", strings.Join(output.errorMsgs, ",")))
 	}
-	if len(commentLines) > 1 {
-		desc = append(desc, commentLines[1:len(commentLines)]...)
-	}
+	desc = append(desc, doc.description...)
 	r.description = desc
 	r.reviseDescription()
+	r.localize(extractLocalizedDocs(method.Method().CommentLines), method.run.args.Lang)
+	if guard := method.run.guards.guardOf(method); guard != nil {
+		if r.policyScope == "" {
+			r.policyScope = guard.scope
+		}
+		r.addGuard(guard)
+	}
 	return r
 }
 
@@ -92,35 +115,60 @@ func (f *routeFactory) List(input *parameter, output *response) *route {
 	return r
 }
 
+// setResourcePath sets path of route on a resource, /<plural>/{id}<suffix> or /<singular><suffix> of singleton,
+// rbac action of singleton is derived as the one of /<plural>/{id}<suffix>
+func (f *routeFactory) setResourcePath(r *route, suffix string) {
+	r.path = fmt.Sprintf("/%s/{id}%s", f.method.resPlural, suffix)
+	if f.method.singleton {
+		r.policyPath = r.path
+		r.path = fmt.Sprintf("/%s%s", f.method.resSingular, suffix)
+	}
+}
+
 func (f *routeFactory) Get(input *parameter, output *response) *route {
 	r := f.newRoute("GET", input, output)
-	r.path = fmt.Sprintf("/%s/{id}", f.method.resPlural)
+	f.setResourcePath(r, "")
+	return r
+}
+
+// Head is the route checking existence of resource, docs of GET method are replaced
+func (f *routeFactory) Head(input *parameter, output *response) *route {
+	r := f.newRoute("HEAD", input, output)
+	f.setResourcePath(r, "")
+	for key := range r.extensions {
+		if strings.HasPrefix(key, "x-summary-") || strings.HasPrefix(key, "x-description-") {
+			delete(r.extensions, key)
+		}
+	}
+	r.summary = fmt.Sprintf("Check existence of %s, responds 404 if not found", f.method.resSingular)
+	r.description = nil
+	r.reviseDescription()
 	return r
 }
 
 func (f *routeFactory) Update(input *parameter, output *response) *route {
 	r := f.newRoute("PUT", input, output)
-	r.path = fmt.Sprintf("/%s/{id}", f.method.resPlural)
+	f.setResourcePath(r, "")
 	return r
 }
 
 func (f *routeFactory) Delete(input *parameter, output *response) *route {
 	r := f.newRoute("DELETE", input, output)
-	r.path = fmt.Sprintf("/%s/{id}", f.method.resPlural)
+	f.setResourcePath(r, "")
 	return r
 }
 
 func (f *routeFactory) GetSpec(input *parameter, output *response) *route {
 	apiAction := f.apiAction(GetSpec)
 	r := f.newRoute("GET", input, output)
-	r.path = fmt.Sprintf("/%s/{id}/%s", f.method.resPlural, apiAction)
+	f.setResourcePath(r, "/"+apiAction)
 	return r
 }
 
 func (f *routeFactory) PerformAction(input *parameter, output *response) *route {
 	apiAction := f.apiAction(Perform)
 	r := f.newRoute("POST", input, output)
-	r.path = fmt.Sprintf("/%s/{id}/%s", f.method.resPlural, apiAction)
+	f.setResourcePath(r, "/"+apiAction)
 	return r
 }
 
@@ -128,21 +176,62 @@ type route struct {
 	// action means restful GET, POST, PUT, DELETE
 	action      string
 	path        string
+	operationId string
+	// source is the method or function generating this route
+	source      string
 	parameter   *parameter
 	tags        []string
 	summary     string
 	description []string
 	response    map[int]*response
+	schemes     []string
+	extensions  map[string]string
+	// policyScope and policyAction are the authorization requirements of route
+	policyScope  string
+	policyAction string
+	// policyPath is the path rbac action derived from, path is used if empty
+	policyPath string
+	// headers collects operations accepting shared header parameters, nil if no header is shared
+	headers *headerParameters
+	// run is the run generating the route
+	run *Generation
+}
+
+// setWebsocket mark route as websocket upgrade endpoint
+func (r *route) setWebsocket() {
+	r.schemes = []string{"ws", "wss"}
+	r.addExtension("x-websocket", "true")
+}
+
+func (r *route) addExtension(key, val string) {
+	if r.extensions == nil {
+		r.extensions = make(map[string]string)
+	}
+	r.extensions[key] = val
+}
+
+func (r route) getOperationId() string {
+	if r.operationId != "" {
+		return r.operationId
+	}
+	return r.parameter.operationId
 }
 
 func (r route) Do(sw *generator.SnippetWriter) {
+	r.path = apiVersionPath(r.run.args.APIVersion, r.path)
 	sw.Do(fmt.Sprintf(
 		"// swagger:route %s %s %s %s\n",
 		r.action,
 		r.path,
 		strings.Join(r.tags, " "),
-		r.parameter.operationId,
+		r.getOperationId(),
 	), nil)
+	r.run.recordOperationSource(r.getOperationId(), r.source)
+	if r.headers != nil {
+		r.headers.add(r.getOperationId())
+	}
+	r.run.recordRoute(r)
+	r.applyPolicy()
 	h := newSW(sw)
 	if len(r.summary) != 0 {
 		h.emptyLine()
@@ -152,6 +241,17 @@ func (r route) Do(sw *generator.SnippetWriter) {
 		h.emptyLine()
 		h.lines(r.description)
 	}
+	if len(r.schemes) != 0 {
+		h.emptyLine()
+		h.line(fmt.Sprintf("Schemes: %s", strings.Join(r.schemes, ", ")))
+	}
+	if len(r.extensions) != 0 {
+		h.emptyLine()
+		h.line("Extensions:")
+		for _, key := range sets.StringKeySet(r.extensions).List() {
+			h.line(fmt.Sprintf("  %s: %s", key, r.extensions[key]))
+		}
+	}
 	h.emptyLine()
 	h.line("responses:")
 	// TODO: responses for errors
@@ -177,12 +277,15 @@ func (f *paramterFactory) newParameter() *parameter {
 }
 
 func (f *paramterFactory) Create() *parameter {
-	// pattern: func(ctx, userCred, ownerId, query, data)
-	query := f.method.Params(3)
-	body := f.method.Params(4)
+	// pattern: func(ctx, userCred, ownerId, query, data), see createInput for variants of data
+	query, _ := f.method.requestParams(signatureCreate)
+	body := f.method.createInput()
 	p := f.newParameter()
 	if err := isValidType(body); err == nil {
 		p.body = body
+		p.discriminator = extractDiscriminator(f.method.run.universe, f.method.Method().CommentLines, p.getBody())
+	} else if goType, ok := primitiveGoType(body); ok {
+		p.primitiveBody = goType
 	} else {
 		p.errorMsgs = append(p.errorMsgs, fmt.Sprintf("unsupport body type: %v", err))
 	}
@@ -192,7 +295,7 @@ func (f *paramterFactory) Create() *parameter {
 
 func (f *paramterFactory) List() *parameter {
 	// pattern: func(ctx, q, userCred, query)
-	query := f.method.Params(3)
+	query, _ := f.method.requestParams(signatureList)
 	p := f.newParameter()
 	if err := isValidType(query); err == nil {
 		p.query = GetValidType(query)
@@ -203,37 +306,36 @@ func (f *paramterFactory) List() *parameter {
 }
 
 func (f *paramterFactory) Get() *parameter {
-	// pattern: func(ctx, userCred, query)
-	query := f.method.Params(2)
+	// pattern: func(ctx, userCred, query), query is struct or pointer to struct
+	query, _ := f.method.requestParams(signatureGet)
 	p := f.newParameter()
 	if err := isValidType(query); err == nil {
 		p.query = GetValidType(query)
 	} else {
 		log.Warningf("%s Get method invalid query type: %v", f.method.resPlural, err)
 	}
-	p.withId = true
+	p.withId = !f.method.singleton
 	return p
 }
 
 func (f *paramterFactory) Update() *parameter {
 	// pattern: func(ctx, userCred, query, data)
-	query := f.method.Params(2)
-	body := f.method.Params(3)
+	query, body := f.method.requestParams(signatureUpdate)
 	p := f.newParameter()
 	if err := isValidType(body); err == nil {
 		p.body = body
+		p.discriminator = extractDiscriminator(f.method.run.universe, f.method.Method().CommentLines, p.getBody())
 	} else {
 		p.errorMsgs = append(p.errorMsgs, fmt.Sprintf("unsupport body type: %v", err))
 	}
 	p.query = query
-	p.withId = true
+	p.withId = !f.method.singleton
 	return p
 }
 
 func (f *paramterFactory) Delete() *parameter {
 	// pattern: func(ctx, userCred, query, data)
-	query := f.method.Params(2)
-	body := f.method.Params(3)
+	query, body := f.method.requestParams(signatureDelete)
 	p := f.newParameter()
 	if err := isValidType(query); err == nil {
 		p.query = query
@@ -245,37 +347,37 @@ func (f *paramterFactory) Delete() *parameter {
 	} else {
 		log.Warningf("%s Delete method invalid body type: %v", f.method.resPlural, err)
 	}
-	p.withId = true
+	p.withId = !f.method.singleton
 	return p
 }
 
 func (f *paramterFactory) GetSpec() *parameter {
 	// pattern: func(ctx, userCred, query)
-	query := f.method.Params(2)
+	query, _ := f.method.requestParams(signatureGet)
 	p := f.newParameter()
 	if err := isValidType(query); err == nil {
 		p.query = GetValidType(query)
 	} else {
 		log.Warningf("%s GetSpec method %s invalid query type: %v", f.method.resPlural, f.method.String(), err)
 	}
-	p.withId = true
+	p.withId = !f.method.singleton
 	return p
 }
 
 func (f *paramterFactory) PerformAction() *parameter {
 	// pattern: func(ctx, userCred, query, body)
-	query := f.method.Params(2)
-	body := f.method.Params(3)
+	query, body := f.method.requestParams(signatureUpdate)
 	p := f.newParameter()
 	if err := isValidType(body); err == nil {
 		p.body = body
+		p.discriminator = extractDiscriminator(f.method.run.universe, f.method.Method().CommentLines, p.getBody())
 	} else {
 		log.Warningf("%s PerformAction method %s invalid body type: %v", f.method.resPlural, f.method.String(), err)
 	}
 	if err := isValidType(query); err == nil {
 		p.query = query
 	}
-	p.withId = true
+	p.withId = !f.method.singleton
 	return p
 }
 
@@ -286,6 +388,28 @@ type parameter struct {
 	withId      bool
 	query       *types.Type
 	body        *types.Type
+	pathParams  []SwaggerConfigPathParam
+	// headerParams are the required request headers, e.g. X-Auth-Token
+	headerParams []SwaggerConfigHeader
+	// extraOperationIds are other operations sharing this parameter
+	extraOperationIds []string
+	// markerPaging add marker pagination query params
+	markerPaging bool
+	// batchCount add count query param of batch create
+	batchCount bool
+	// commonListParams add standard list query params missing in query
+	commonListParams bool
+	// discriminator models polymorphic body, nil if body is not varied
+	discriminator *bodyDiscriminator
+	// primitiveBody is the go type of untyped body documented as simple schema,
+	// e.g. free-form object of *jsonutils.JSONDict data of legacy ValidateCreateData
+	primitiveBody string
+	// sharer shares the parameter struct with operations of the same parameters, nil if sharing is disabled
+	sharer *parameterSharer
+	// out is the output package the parameter is generated into
+	out *outputPackage
+	// run is the run generating the parameter
+	run *Generation
 
 	errorMsgs []string
 }
@@ -300,7 +424,24 @@ func newParameter(singular, plural, id string) *parameter {
 }
 func (r parameter) Do(sw *generator.SnippetWriter) {
 	h := newSW(sw)
-	h.line(fmt.Sprintf("swagger:parameters %s", r.operationId))
+	if r.body != nil {
+		r.run.definitions.declare(r.getBody(), r.out.declaredTypes, sw)
+		if r.discriminator != nil {
+			r.discriminator.declare(r.run.definitions, r.operationId, r.getBody(), r.out.declaredTypes, sw)
+		}
+		if r.wrapsBody() && r.run.bodyWrappers.enabled {
+			r.run.bodyWrappers.declare(r, sw)
+		}
+	}
+	if r.commonListParams && len(missingCommonListParams(r.getQuery())) == len(commonListQueryParams) {
+		declareCommonListQuery(r.out, sw)
+	}
+	ids := append([]string{r.operationId}, r.extraOperationIds...)
+	if r.sharer != nil {
+		r.sharer.add(r, ids)
+		return
+	}
+	h.line(fmt.Sprintf("swagger:parameters %s", strings.Join(ids, " ")))
 	r.do(sw, h)
 }
 
@@ -318,6 +459,44 @@ func getValidType(t *types.Type) *types.Type {
 	}
 }
 
+// jsonutilsGoTypes are the go types of jsonutils values documented as plain schemas
+var jsonutilsGoTypes = map[string]string{
+	"JSONObject": "map[string]interface{}",
+	"JSONDict":   "map[string]interface{}",
+	"JSONArray":  "[]interface{}",
+	"JSONString": "string",
+	"JSONInt":    "int64",
+	"JSONFloat":  "float64",
+	"JSONBool":   "bool",
+}
+
+// primitiveGoType returns the go type of builtin or jsonutils type t documented as
+// a simple schema, e.g. string, integer or free-form object, pointer is dereferenced
+func primitiveGoType(t *types.Type) (string, bool) {
+	if t == nil {
+		return "", false
+	}
+	if t.Kind == types.Pointer {
+		t = t.Elem
+	}
+	if strings.Contains(t.Name.Package, "yunion.io/x/jsonutils") {
+		goType, ok := jsonutilsGoTypes[t.Name.Name]
+		return goType, ok
+	}
+	if t.Kind == types.Builtin && t.Name.Name != "error" {
+		return t.Name.Name, true
+	}
+	return "", false
+}
+
+// sliceElem returns element of slice of structs t, e.g. *SGuestDisk of []*SGuestDisk
+func sliceElem(t *types.Type) (*types.Type, bool) {
+	if t == nil || t.Kind != types.Slice || isValidType(t.Elem) != nil {
+		return nil, false
+	}
+	return t.Elem, true
+}
+
 func GetValidType(t *types.Type) *types.Type {
 	t = getValidType(t)
 	if t == nil {
@@ -345,36 +524,264 @@ func (r parameter) do(sw *generator.SnippetWriter, h *snippetWriter) {
 		h.line("required:true")
 		sw.Do("Id string `json:\"id\"`\n", nil)
 	}
+	for _, pp := range r.pathParams {
+		pt := pathParamTypes[pp.Type]
+		if pp.Description != "" {
+			h.line(pp.Description)
+		}
+		h.line("in:path")
+		h.line("required:true")
+		if pt.format != "" {
+			h.line(fmt.Sprintf("swagger:strfmt %s", pt.format))
+		}
+		sw.Do(fmt.Sprintf("%s %s `json:\"%s\"`\n", paramFieldName(pp.Name), pt.goType, pp.Name), nil)
+	}
+	for _, header := range r.headerParams {
+		if header.Description != "" {
+			h.line(header.Description)
+		}
+		h.line("in:header")
+		h.line("required:true")
+		doHeaderField(header, h)
+	}
 	query := r.getQuery()
-	if query != nil {
+	// offset of query is dropped by flattening in marker pagination
+	if query != nil && (needFlattenQuery(query) || r.markerPaging) {
+		doQueryFields(query, r.fieldNames(), r.run.args.NullablePointers, r.run.typeOverrides, sw, h)
+	} else if query != nil {
 		args := getArgs(query)
 		sw.Do("$.type|raw$\n", args)
 	}
+	if r.commonListParams {
+		doCommonListParams(query, r.markerPaging, sw)
+	}
+	if r.batchCount {
+		h.line(fmt.Sprintf("count of %s to create in batch, the batch response is returned if count > 1", r.plural))
+		h.line("in:query")
+		sw.Do("Count int `json:\"count\"`\n", nil)
+	}
+	if r.markerPaging {
+		h.line("paging marker, the next_marker of last list response")
+		h.line("in:query")
+		sw.Do("PagingMarker string `json:\"paging_marker\"`\n", nil)
+		h.line("field used as paging marker")
+		h.line("in:query")
+		sw.Do("MarkerField string `json:\"marker_field\"`\n", nil)
+		h.line("order of paging marker, asc or desc")
+		h.line("in:query")
+		sw.Do("MarkerOrder string `json:\"marker_order\"`\n", nil)
+	}
 	body := r.getBody()
 	if r.body != nil {
-		args := getArgs(body)
+		ref, args := r.bodyRef()
 		sw.Do("// in:body\n", nil)
-		if r.singular != "" {
+		if r.wrapsBody() && r.run.bodyWrappers.enabled {
+			name, _ := r.run.bodyWrappers.name(r.singular, ref, args, body)
+			sw.Do(fmt.Sprintf("Body %s `json:\"body\"`", name), nil)
+		} else if r.singular != "" {
 			sw.Do("Body struct {", nil)
-			sw.Do(fmt.Sprintf("Input $.type|raw$ `json:\"%s\"`\n", r.singular), args)
+			doTypeOverride(r.run.typeOverrides, body, h)
+			sw.Do(fmt.Sprintf("Input %s `json:\"%s\"`\n", ref, r.singular), args)
 			sw.Do("} `json:\"body\"`", nil)
 			//sw.Do(fmt.Sprintf("Body $.type|raw$ `json:\"%s\"`\n", r.singular), args)
 		} else {
-			sw.Do("Body $.type|raw$ `json:\"body\"`", args)
+			doTypeOverride(r.run.typeOverrides, body, h)
+			sw.Do(fmt.Sprintf("Body %s `json:\"body\"`", ref), args)
+		}
+	} else if r.primitiveBody != "" {
+		sw.Do("// in:body\n", nil)
+		if r.singular != "" {
+			sw.Do(fmt.Sprintf("Body struct {\nInput %s `json:\"%s\"`\n} `json:\"body\"`", r.primitiveBody, r.singular), nil)
+		} else {
+			sw.Do(fmt.Sprintf("Body %s `json:\"body\"`", r.primitiveBody), nil)
 		}
 	}
 	sw.Do("}\n", nil)
 }
 
+// bodyRef returns the snippet and args referring body type, polymorphic body refers to its declared base
+func (r parameter) bodyRef() (string, interface{}) {
+	if r.discriminator != nil {
+		return r.discriminator.baseName(r.operationId), nil
+	}
+	return r.run.definitions.ref(r.getBody())
+}
+
+// wrapsBody returns true if body is wrapped by resource key, e.g. {"server": {...}}
+func (r parameter) wrapsBody() bool {
+	return r.getBody() != nil && r.singular != ""
+}
+
+// doTypeOverride writes annotations of --type-overrides for field of type t
+func doTypeOverride(overrides common.TypeOverrides, t *types.Type, h *snippetWriter) {
+	for _, l := range overrides.CommentLines(t) {
+		h.line(l)
+	}
+}
+
+// fieldNames returns go field names of parameter struct except the query ones
+func (r parameter) fieldNames() sets.String {
+	ret := sets.NewString()
+	if r.withId {
+		ret.Insert("Id")
+	}
+	for _, pp := range r.pathParams {
+		ret.Insert(paramFieldName(pp.Name))
+	}
+	if r.batchCount {
+		ret.Insert("Count")
+	}
+	if r.markerPaging {
+		ret.Insert("PagingMarker", "MarkerField", "MarkerOrder", "Offset")
+	}
+	return ret
+}
+
+// isMarkerStruct returns true if t is a struct without fields embedded as marker, e.g. apis.Meta
+func isMarkerStruct(t *types.Type) bool {
+	return t.Kind == types.Struct && len(t.Members) == 0
+}
+
+// needFlattenQuery returns true if t embeds pointer to struct or marker struct directly or indirectly
+func needFlattenQuery(t *types.Type) bool {
+	for _, m := range t.Members {
+		if et, isPtr, ok := common.EmbeddedStruct(m); ok && (isPtr || isMarkerStruct(et) || needFlattenQuery(et)) {
+			return true
+		}
+	}
+	return false
+}
+
+// queryField is the promoted field of query type
+type queryField struct {
+	member   types.Member
+	depth    int
+	optional bool
+}
+
+// collectQueryFields collects fields of t promoted from embedded members,
+// fields of embedded pointers are optional
+func collectQueryFields(t *types.Type, depth int, optional bool) []queryField {
+	ret := make([]queryField, 0)
+	for _, m := range t.Members {
+		if et, isPtr, ok := common.EmbeddedStruct(m); ok {
+			ret = append(ret, collectQueryFields(et, depth+1, optional || isPtr)...)
+			continue
+		}
+		if !ast.IsExported(m.Name) {
+			continue
+		}
+		ret = append(ret, queryField{member: m, depth: depth, optional: optional})
+	}
+	return ret
+}
+
+// doQueryFields writes fields of query type one by one instead of embedding it,
+// because go-swagger doesn't expand embedded pointers of parameters, marker structs are dropped.
+// Like go, the shallowest field wins and the ones in exclude are shadowed by parameter fields,
+// pointer fields are annotated as x-nullable if nullable is true and fields of overridden types by their overrides.
+func doQueryFields(query *types.Type, exclude sets.String, nullable bool, overrides common.TypeOverrides, sw *generator.SnippetWriter, h *snippetWriter) {
+	fields := collectQueryFields(query, 0, false)
+	depths := make(map[string]int)
+	for _, f := range fields {
+		if d, ok := depths[f.member.Name]; !ok || f.depth < d {
+			depths[f.member.Name] = f.depth
+		}
+	}
+	emitted := sets.NewString(exclude.List()...)
+	for _, f := range fields {
+		m := f.member
+		if emitted.Has(m.Name) || depths[m.Name] != f.depth {
+			continue
+		}
+		emitted.Insert(m.Name)
+		lines := overrides.CommentLines(m.Type)
+		for _, l := range m.CommentLines {
+			if f.optional && isRequiredLine(l) {
+				continue
+			}
+			lines = append(lines, l)
+		}
+		if common.IsSecret(m.CommentLines) {
+			lines = common.SecretLines(lines)
+		}
+		if nullable && m.Type.Kind == types.Pointer {
+			lines = common.AppendExtension(lines, "x-nullable", "true")
+		}
+		for _, l := range lines {
+			h.line(l)
+		}
+		tags := ""
+		if m.Tags != "" {
+			tags = fmt.Sprintf(" `%s`", m.Tags)
+		}
+		sw.Do(fmt.Sprintf("%s $.type|raw$%s\n", m.Name, tags), getArgs(m.Type))
+	}
+}
+
+// paramFieldName convert param name like token_id to go field name TokenId
+func paramFieldName(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' })
+	for i, p := range parts {
+		parts[i] = strings.Title(p)
+	}
+	return strings.Join(parts, "")
+}
+
 type responseFactory struct {
 	method *Method
+	// primitives documents builtin and jsonutils outputs as simple schemas instead of errors
+	primitives bool
+}
+
+// listEnvelopeField is the pagination field along with list body
+type listEnvelopeField struct {
+	Name string
+	Type string
+	Key  string
+}
+
+var (
+	// listEnvelopeRoles are the supported pagination fields by role, in emitting order
+	listEnvelopeRoles = []string{"limit", "total", "offset", "marker"}
+
+	listEnvelopeFields = map[string]listEnvelopeField{
+		"limit":  {"Limit", "int", "limit"},
+		"total":  {"Total", "int", "total"},
+		"offset": {"Offset", "int", "offset"},
+		"marker": {"NextMarker", "string", "next_marker"},
+	}
+)
+
+// defaultListEnvelope is the model list envelope of onecloud
+func defaultListEnvelope() []listEnvelopeField {
+	return []listEnvelopeField{
+		listEnvelopeFields["limit"],
+		listEnvelopeFields["total"],
+		listEnvelopeFields["offset"],
+	}
 }
 
 type response struct {
-	output  *types.Type
-	id      string
-	bodyKey string
-	isList  bool
+	output     *types.Type
+	id         string
+	bodyKey    string
+	isList     bool
+	listFields []listEnvelopeField
+	// batch is the alternate response of batch create
+	batch *response
+	// raw is true if output is the whole body, free-form object if output is not a struct
+	raw bool
+	// primitive is the go type of builtin or jsonutils output documented as simple schema
+	primitive string
+	// array is true if body is an array of output without list envelope, e.g. ([]T, error) results
+	array bool
+	// headers are the response headers declared by function route tags, e.g. X-Subject-Token
+	headers []SwaggerConfigHeader
+	// out is the output package the response is generated into
+	out *outputPackage
+	// run is the run generating the response
+	run *Generation
 
 	errorMsgs []string
 }
@@ -385,31 +792,69 @@ func (r response) getOutput() *types.Type {
 
 func (r response) Do(sw *generator.SnippetWriter) {
 	h := newSW(sw)
+	output := r.getOutput()
+	r.run.definitions.declare(output, r.out.declaredTypes, sw)
 	sw.Do(fmt.Sprintf("// swagger:response %s\n", r.id), nil)
 	sw.Do(fmt.Sprintf("type %s struct {\n", r.id), nil)
-	output := r.getOutput()
-	args := getArgs(output)
-	if output != nil {
+	ref, args := r.run.definitions.ref(output)
+	if output == nil && r.raw {
+		h.line("in:body")
+		sw.Do("Body map[string]interface{} `json:\"body\"`\n", nil)
+	} else if output == nil && r.primitive != "" {
 		h.line("in:body")
 		if r.bodyKey != "" {
-			r.bodyStruct(output, sw)
+			r.bodyStruct(nil, r.primitive, nil, sw)
+		} else if r.isList {
+			sw.Do(fmt.Sprintf("Body []%s `json:\"body\"`\n", r.primitive), nil)
 		} else {
-			sw.Do("$.type|raw$", args)
+			sw.Do(fmt.Sprintf("Body %s `json:\"body\"`\n", r.primitive), nil)
 		}
+	} else if output != nil {
+		h.line("in:body")
+		if r.bodyKey != "" {
+			r.bodyStruct(output, ref, args, sw)
+		} else if r.isList || r.array {
+			sw.Do(fmt.Sprintf("Body []%s `json:\"body\"`\n", ref), args)
+		} else {
+			sw.Do(ref, args)
+		}
+	}
+	if r.isList {
+		doListResponseHeaders(r.run.args.ListResponseHeaders, h)
+	}
+	for _, header := range r.headers {
+		if header.Description != "" {
+			h.line(header.Description)
+		}
+		h.line("in:header")
+		doHeaderField(header, h)
 	}
 	sw.Do("}\n", nil)
+	if r.batch != nil {
+		r.batch.run, r.batch.out = r.run, r.out
+		r.batch.Do(sw)
+	}
 }
 
-func (r response) bodyStruct(output *types.Type, sw *generator.SnippetWriter) {
-	args := getArgs(output)
+// bodyStruct writes body wrapping output referred by ref under bodyKey, output is nil if it's primitive
+func (r response) bodyStruct(output *types.Type, ref string, args interface{}, sw *generator.SnippetWriter) {
 	sw.Do("Body struct {\n", nil)
 	if r.isList {
-		sw.Do(fmt.Sprintf("Output []$.type|raw$ `json:\"%s\"`\n", r.bodyKey), args)
-		sw.Do("Limit int `json:\"limit\"`\n", nil)
-		sw.Do("Total int `json:\"total\"`\n", nil)
-		sw.Do("Offset int `json:\"offset\"`\n", nil)
+		sw.Do(fmt.Sprintf("Output []%s `json:\"%s\"`\n", ref, r.bodyKey), args)
+		fields := r.listFields
+		if fields == nil {
+			fields = defaultListEnvelope()
+		}
+		for _, f := range fields {
+			sw.Do(fmt.Sprintf("%s %s `json:\"%s\"`\n", f.Name, f.Type, f.Key), nil)
+		}
+	} else if r.array {
+		sw.Do(fmt.Sprintf("Output []%s `json:\"%s\"`\n", ref, r.bodyKey), args)
 	} else {
-		sw.Do(fmt.Sprintf("Output $.type|raw$ `json:\"%s\"`\n", r.bodyKey), args)
+		if output != nil {
+			doTypeOverride(r.run.typeOverrides, output, newSW(sw))
+		}
+		sw.Do(fmt.Sprintf("Output %s `json:\"%s\"`\n", ref, r.bodyKey), args)
 	}
 	sw.Do("}\n", nil)
 }
@@ -418,6 +863,12 @@ func newResponseFactory(m *Method) *responseFactory {
 	return &responseFactory{method: m}
 }
 
+// withPrimitives documents builtin and jsonutils outputs, e.g. of GetDetailsXxx methods, as simple schemas
+func (f *responseFactory) withPrimitives() *responseFactory {
+	f.primitives = true
+	return f
+}
+
 func (f *responseFactory) newResponse() *response {
 	return &response{
 		id:        fmt.Sprintf("%sOutput", privateName(f.method.resSingular, f.method.Name())),
@@ -438,20 +889,32 @@ func (f *responseFactory) FirstSingularResultNoError() *response {
 func (f *responseFactory) resultByMethod(method *Method, resultIdx int, bodyKey string, ignoreErr bool) *response {
 	r := f.newResponse()
 	sig := method.Signature()
-	params := sig.Results
-	out := params[resultIdx]
+	out := sig.Results[resultIdx].Type
 	if err := isValidType(out); err == nil {
 		r.output = out
+	} else if elem, ok := sliceElem(out); ok {
+		r.output, r.array = elem, true
+	} else if goType, ok := primitiveGoType(out); ok && f.primitives {
+		r.primitive = goType
 	} else if !ignoreErr {
 		r.errorMsgs = append(r.errorMsgs, fmt.Sprintf("%v", err))
 	}
 	if bodyKey == "" {
 		bodyKey = f.method.resPlural
 	}
-	r.bodyKey = bodyKey
+	r.bodyKey = f.bodyKey(bodyKey)
 	return r
 }
 
+// bodyKey returns the json key of response body overridden by +onecloud:swagger-gen-resp-body-key
+// of route method, e.g. connect info of vnc, empty tag value responds the output unwrapped
+func (f *responseFactory) bodyKey(key string) string {
+	if vals := extractTagByName(f.method.Method().CommentLines, tagRespBodyKey); len(vals) != 0 {
+		return vals[0]
+	}
+	return key
+}
+
 func (f *responseFactory) ResultByMethod(method *Method, resultIdx int, bodyKey string) *response {
 	return f.resultByMethod(method, resultIdx, bodyKey, false)
 }
@@ -460,6 +923,27 @@ func (f *responseFactory) ResultByGetMethod(getMethod *Method) *response {
 	return f.ResultByMethod(getMethod, 0, f.method.resSingular)
 }
 
+// RawResultByMethod use the first result of method as the whole response body
+func (f *responseFactory) RawResultByMethod(method *Method) *response {
+	r := f.newResponse()
+	r.raw = true
+	if out := method.Signature().Results[0].Type; isValidType(out) == nil {
+		r.output = out
+	}
+	return r
+}
+
+// BatchCreateResult is the response of creating resources in batch,
+// which lists created resources by plural keyword
+func (f *responseFactory) BatchCreateResult(getMethod *Method) *response {
+	r := f.ListResult(getMethod)
+	r.id = fmt.Sprintf("%sBatchOutput", privateName(f.method.resSingular, f.method.Name()))
+	// body key override of create method is for the single resource response
+	r.bodyKey = f.method.resPlural
+	r.listFields = []listEnvelopeField{}
+	return r
+}
+
 func (f *responseFactory) ListResult(getMethod *Method) *response {
 	r := f.ResultByMethod(getMethod, 0, f.method.resPlural)
 	r.isList = true
@@ -467,9 +951,11 @@ func (f *responseFactory) ListResult(getMethod *Method) *response {
 }
 
 type SwaggerConfigRoute struct {
-	Method string
-	Path   string
-	Tags   []string
+	Method     string
+	Path       string
+	Tags       []string
+	Websocket  bool
+	Extensions map[string]string
 }
 
 func (c *SwaggerConfigRoute) newRoute(input *parameter, output *response) *route {
@@ -482,63 +968,117 @@ func (c *SwaggerConfigRoute) newRoute(input *parameter, output *response) *route
 			200: output,
 		},
 	}
+	if c.Websocket {
+		r.setWebsocket()
+	}
+	for key, val := range c.Extensions {
+		r.addExtension(key, val)
+	}
 	return r
 }
 
+type SwaggerConfigPathParam struct {
+	Name        string
+	Type        string
+	Description string
+}
+
+// pathParamTypes map path param type to go type and swagger strfmt
+var pathParamTypes = map[string]struct {
+	goType string
+	format string
+}{
+	"string":  {"string", ""},
+	"integer": {"int64", ""},
+	"int":     {"int64", ""},
+	"uuid":    {"string", "uuid"},
+}
+
+// SwaggerConfigHeader is a request or response header of function route
+type SwaggerConfigHeader struct {
+	Name        string
+	Type        string
+	Description string
+}
+
 type SwaggerConfigParam struct {
-	Body  *types.Type
-	Query *types.Type
+	Body   *types.Type
+	Query  *types.Type
+	Path   []SwaggerConfigPathParam
+	Header []SwaggerConfigHeader
 }
 
 func (c *SwaggerConfigParam) newParameter(t *types.Type) *parameter {
 	n := filepath.Base(t.Name.Package)
 	param := newParameter("", "", privateName(n, t.Name.Name))
+	if c == nil {
+		return param
+	}
 	param.query = c.Query
 	param.body = c.Body
+	param.pathParams = c.Path
+	param.headerParams = c.Header
 	return param
 }
 
 type SwaggerConfigResponse struct {
 	Output  *types.Type
 	BodyKey string
+	// IsList means Output is the element of list body
+	IsList     bool
+	ListFields []listEnvelopeField
+	// Headers are the headers of response, e.g. X-Subject-Token
+	Headers []SwaggerConfigHeader
 }
 
 func (c *SwaggerConfigResponse) newResponse(t *types.Type) *response {
 	n := filepath.Base(t.Name.Package)
 	r := &response{
 		id:        fmt.Sprintf("%sOutput", privateName(n, t.Name.Name)),
-		bodyKey:   c.BodyKey,
 		errorMsgs: make([]string, 0),
 	}
+	if c == nil {
+		return r
+	}
+	r.bodyKey = c.BodyKey
 	r.output = c.Output
+	r.isList = c.IsList
+	r.listFields = c.ListFields
+	r.headers = c.Headers
 	return r
 }
 
 type SwaggerConfig struct {
-	Route    *SwaggerConfigRoute
+	Routes   []*SwaggerConfigRoute
 	Param    *SwaggerConfigParam
 	Response *SwaggerConfigResponse
 }
 
-func (c *SwaggerConfig) generate(t *types.Type, sw *generator.SnippetWriter) {
+func (c *SwaggerConfig) generate(g *swaggerGen, t *types.Type, sw *generator.SnippetWriter) {
 	param := c.Param.newParameter(t)
+	param.run, param.sharer, param.out = g.run, g.params, g.out
 	resp := c.Response.newResponse(t)
-	route := c.Route.newRoute(param, resp)
-	commentLines := t.CommentLines
-	if len(commentLines) > 0 {
-		route.summary = commentLines[0]
-	}
-	desc := make([]string, 0)
-	if len(commentLines) > 1 {
-		desc = append(desc, commentLines[1:len(commentLines)]...)
-	}
-	route.description = desc
-	route.reviseDescription()
-
-	cc := &commenter{
-		route:     route,
-		parameter: param,
-		response:  resp,
+	resp.run, resp.out = g.run, g.out
+	doc := parseRouteDoc(t.Name.Name, t.CommentLines, g.args.AllDocComments)
+	docs := extractLocalizedDocs(append(t.SecondClosestCommentLines, t.CommentLines...))
+	policyScope, policyAction := extractPolicyTags(t.SecondClosestCommentLines, t.CommentLines)
+	for i, cr := range c.Routes {
+		route := cr.newRoute(param, resp)
+		route.source = t.String()
+		route.run, route.headers = g.run, g.headers
+		route.policyScope, route.policyAction = policyScope, policyAction
+		if i > 0 {
+			// extra routes share the parameters and response of the first one
+			route.operationId = fmt.Sprintf("%s_%d", param.operationId, i)
+			param.extraOperationIds = append(param.extraOperationIds, route.operationId)
+		}
+		route.summary = doc.summary
+		route.description = append(make([]string, 0), doc.description...)
+		route.reviseDescription()
+		route.localize(docs, g.args.Lang)
+		route.addReferences(g.run.references)
+		route.Do(sw)
 	}
-	cc.Do(sw)
+	param.Do(sw)
+	resp.Do(sw)
 }
@@ -0,0 +1,241 @@
+package generators
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/namer"
+	"k8s.io/gengo/v2/types"
+
+	"yunion.io/x/pkg/util/sets"
+
+	"yunion.io/x/code-generator/pkg/common"
+	"yunion.io/x/code-generator/pkg/common/testdata/faketypes"
+)
+
+func Test_RawResultByMethod(t *testing.T) {
+	guest := &types.Type{Name: types.Name{Package: "yunion.io/x/onecloud/pkg/compute/models", Name: "SGuest"}, Kind: types.Struct}
+	newM := func(out *types.Type) *Method {
+		sig := &types.Signature{Results: faketypes.ParamResults(out, &types.Type{Name: types.Name{Name: "error"}, Kind: types.Interface})}
+		return NewMethod(newTestGeneration(t, nil), guest, GetCustomizedGetDetailsBody, &types.Type{Kind: types.Func, Signature: sig}, "server", "servers")
+	}
+	jsonObj := &types.Type{Name: types.Name{Package: "yunion.io/x/jsonutils", Name: "JSONObject"}, Kind: types.Interface}
+	event := &types.Type{Name: types.Name{Package: "yunion.io/x/onecloud/pkg/apis/cloudevent", Name: "CloudeventDetails"}, Kind: types.Struct}
+	tests := []struct {
+		name       string
+		out        *types.Type
+		wantOutput *types.Type
+	}{
+		{name: "free-form jsonutils object", out: jsonObj, wantOutput: nil},
+		{name: "struct pointer", out: &types.Type{Kind: types.Pointer, Elem: event}, wantOutput: event},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newM(tt.out)
+			r := newResponseFactory(m).RawResultByMethod(m)
+			if !r.raw || r.bodyKey != "" {
+				t.Errorf("response raw = %v, bodyKey = %q, want raw body", r.raw, r.bodyKey)
+			}
+			if got := r.getOutput(); got != tt.wantOutput {
+				t.Errorf("getOutput() = %v, want %v", got, tt.wantOutput)
+			}
+		})
+	}
+}
+
+func Test_parameterBatchCount(t *testing.T) {
+	buf := &bytes.Buffer{}
+	sw := generator.NewSnippetWriter(buf, &generator.Context{}, "$", "$")
+	p := newParameter("server", "servers", "server_ValidateCreateData")
+	p.run, p.out = newTestGeneration(t, nil), newOutputPackage()
+	p.batchCount = true
+	p.Do(sw)
+	if err := sw.Error(); err != nil {
+		t.Fatalf("parameter Do error: %v", err)
+	}
+	want := "// swagger:parameters server_ValidateCreateData\n" +
+		"type server_ValidateCreateData struct {\n" +
+		"// count of servers to create in batch, the batch response is returned if count > 1\n" +
+		"// in:query\n" +
+		"Count int `json:\"count\"`\n" +
+		"}\n"
+	if buf.String() != want {
+		t.Errorf("parameter Do = %q, want %q", buf.String(), want)
+	}
+}
+
+func Test_parameterEmbeddedPointerQuery(t *testing.T) {
+	str := &types.Type{Name: types.Name{Name: "string"}, Kind: types.Builtin}
+	base := &types.Type{
+		Name: types.Name{Name: "StatusResourceListInput"},
+		Kind: types.Struct,
+		Members: []types.Member{
+			{Name: "Status", Type: str, Tags: `json:"status"`, CommentLines: []string{"status of resource", "required: true"}},
+			{Name: "Id", Type: str, Tags: `json:"id"`},
+			{Name: "Name", Type: str, Tags: `json:"name"`},
+		},
+	}
+	query := &types.Type{
+		Name: types.Name{Name: "ServerListInput"},
+		Kind: types.Struct,
+		Members: []types.Member{
+			{Embedded: true, Type: &types.Type{Kind: types.Pointer, Elem: base}},
+			{Name: "Name", Type: str, Tags: `json:"name"`, CommentLines: []string{"name of server"}},
+			{Name: "hidden", Type: str},
+		},
+	}
+	buf := &bytes.Buffer{}
+	ctx := &generator.Context{Namers: namer.NameSystems{"raw": namer.NewRawNamer("", nil)}}
+	sw := generator.NewSnippetWriter(buf, ctx, "$", "$")
+	p := newParameter("server", "servers", "server_Get")
+	p.run, p.out = newTestGeneration(t, nil), newOutputPackage()
+	p.withId = true
+	p.query = query
+	p.Do(sw)
+	if err := sw.Error(); err != nil {
+		t.Fatalf("parameter Do error: %v", err)
+	}
+	want := "// swagger:parameters server_Get\n" +
+		"type server_Get struct {\n" +
+		"// The Id or Name of server\n" +
+		"// in:path\n" +
+		"// required:true\n" +
+		"Id string `json:\"id\"`\n" +
+		"// status of resource\n" +
+		"Status string `json:\"status\"`\n" +
+		"// name of server\n" +
+		"Name string `json:\"name\"`\n" +
+		"}\n"
+	if buf.String() != want {
+		t.Errorf("parameter Do = %q, want %q", buf.String(), want)
+	}
+}
+
+func Test_parameterMarkerPaging(t *testing.T) {
+	integer := &types.Type{Name: types.Name{Name: "int"}, Kind: types.Builtin}
+	str := &types.Type{Name: types.Name{Name: "string"}, Kind: types.Builtin}
+	query := &types.Type{
+		Name: types.Name{Name: "ServerListInput"},
+		Kind: types.Struct,
+		Members: []types.Member{
+			{Name: "Limit", Type: integer, Tags: `json:"limit"`, CommentLines: []string{"maximal number of servers"}},
+			{Name: "Offset", Type: integer, Tags: `json:"offset"`},
+			{Name: "Name", Type: str, Tags: `json:"name"`},
+		},
+	}
+	buf := &bytes.Buffer{}
+	ctx := &generator.Context{Namers: namer.NameSystems{"raw": namer.NewRawNamer("", nil)}}
+	sw := generator.NewSnippetWriter(buf, ctx, "$", "$")
+	p := newParameter("server", "servers", "server_List")
+	p.run, p.out = newTestGeneration(t, nil), newOutputPackage()
+	p.query = query
+	p.commonListParams = true
+	p.markerPaging = true
+	p.Do(sw)
+	if err := sw.Error(); err != nil {
+		t.Fatalf("parameter Do error: %v", err)
+	}
+	got := buf.String()
+	if strings.Contains(got, "Offset") || strings.Contains(got, "CommonListQuery") {
+		t.Errorf("parameter Do of marker pagination has offset:\n%s", got)
+	}
+	for _, want := range []string{
+		"// maximal number of servers\nLimit int `json:\"limit\"`\n",
+		"// in:query\nOrderBy []string `json:\"order_by\"`\n",
+		"// paging marker, the next_marker of last list response\n// in:query\nPagingMarker string `json:\"paging_marker\"`\n",
+		"// field used as paging marker\n// in:query\nMarkerField string `json:\"marker_field\"`\n",
+		"// order of paging marker, asc or desc\n// in:query\nMarkerOrder string `json:\"marker_order\"`\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("parameter Do missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func Test_doQueryFieldsNullable(t *testing.T) {
+	str := &types.Type{Name: types.Name{Name: "string"}, Kind: types.Builtin}
+	boolean := &types.Type{Name: types.Name{Name: "bool"}, Kind: types.Builtin}
+	query := &types.Type{
+		Name: types.Name{Name: "ServerListInput"},
+		Kind: types.Struct,
+		Members: []types.Member{
+			{Name: "Name", Type: str, Tags: `json:"name"`},
+			{Name: "Admin", Type: &types.Type{Kind: types.Pointer, Elem: boolean}, Tags: `json:"admin"`, CommentLines: []string{"list in admin mode"}},
+		},
+	}
+	buf := &bytes.Buffer{}
+	ctx := &generator.Context{Namers: namer.NameSystems{"raw": namer.NewRawNamer("", nil)}}
+	sw := generator.NewSnippetWriter(buf, ctx, "$", "$")
+	doQueryFields(query, sets.NewString(), true, nil, sw, newSW(sw))
+	if err := sw.Error(); err != nil {
+		t.Fatalf("doQueryFields error: %v", err)
+	}
+	want := "Name string `json:\"name\"`\n" +
+		"// list in admin mode\n" +
+		"// Extensions:\n" +
+		"//   x-nullable: true\n" +
+		"Admin *bool `json:\"admin\"`\n"
+	if buf.String() != want {
+		t.Errorf("doQueryFields = %q, want %q", buf.String(), want)
+	}
+}
+
+func Test_doQueryFieldsTypeOverride(t *testing.T) {
+	timeType := &types.Type{Name: types.Name{Package: "time", Name: "Time"}, Kind: types.Struct}
+	query := &types.Type{
+		Name: types.Name{Name: "EventListInput"},
+		Kind: types.Struct,
+		Members: []types.Member{
+			{Name: "Since", Type: &types.Type{Kind: types.Pointer, Elem: timeType}, Tags: `json:"since"`, CommentLines: []string{"events since the time"}},
+		},
+	}
+	overrides := common.TypeOverrides{"time.Time": {Type: "string", Format: "date-time"}}
+	buf := &bytes.Buffer{}
+	ctx := &generator.Context{Namers: namer.NameSystems{"raw": namer.NewRawNamer("", nil)}}
+	sw := generator.NewSnippetWriter(buf, ctx, "$", "$")
+	doQueryFields(query, sets.NewString(), true, overrides, sw, newSW(sw))
+	if err := sw.Error(); err != nil {
+		t.Fatalf("doQueryFields error: %v", err)
+	}
+	want := "// swagger:type string\n" +
+		"// swagger:strfmt date-time\n" +
+		"// events since the time\n" +
+		"// Extensions:\n" +
+		"//   x-nullable: true\n" +
+		"Since *time.Time `json:\"since\"`\n"
+	if buf.String() != want {
+		t.Errorf("doQueryFields = %q, want %q", buf.String(), want)
+	}
+}
+
+func Test_responseFactoryBodyKey(t *testing.T) {
+	str := &types.Type{Name: types.Name{Name: "string"}, Kind: types.Builtin}
+	output := &types.Type{Name: types.Name{Package: "compute", Name: "VncInfo"}, Kind: types.Struct}
+	newMethod := func(comments ...string) *Method {
+		return NewMethod(newTestGeneration(t, nil), &types.Type{Name: types.Name{Name: "SGuest"}}, "GetDetailsVnc", &types.Type{
+			Kind:         types.Func,
+			CommentLines: comments,
+			Signature: &types.Signature{
+				Results: faketypes.ParamResults(&types.Type{Kind: types.Pointer, Elem: output}, str),
+			},
+		}, "server", "servers")
+	}
+	tests := []struct {
+		name   string
+		method *Method
+		want   string
+	}{
+		{name: "resource keyword", method: newMethod(), want: "server"},
+		{name: "override", method: newMethod("+onecloud:swagger-gen-resp-body-key=vnc"), want: "vnc"},
+		{name: "unwrapped", method: newMethod("+onecloud:swagger-gen-resp-body-key="), want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newResponseFactory(tt.method).FirstSingularResult().bodyKey; got != tt.want {
+				t.Errorf("bodyKey = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
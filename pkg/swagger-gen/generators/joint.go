@@ -0,0 +1,149 @@
+package generators
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/types"
+
+	"yunion.io/x/pkg/utils"
+
+	"yunion.io/x/code-generator/pkg/common"
+	"yunion.io/x/code-generator/pkg/models/registry"
+)
+
+const (
+	// verbs of joint model routes, e.g. /servers/{id}/disks/{disk_id}
+	VerbJointList   = "joint-list"
+	VerbJointGet    = "joint-get"
+	VerbJointAttach = "joint-attach"
+	VerbJointUpdate = "joint-update"
+	VerbJointDetach = "joint-detach"
+)
+
+// isJointModel returns true if model embeds SJointResourceBase directly or indirectly
+func isJointModel(t *types.Type) bool {
+	for _, m := range t.Members {
+		mt, _, ok := common.EmbeddedStruct(m)
+		if !ok {
+			continue
+		}
+		if strings.HasSuffix(mt.Name.Name, "JointResourceBase") || isJointModel(mt) {
+			return true
+		}
+	}
+	return false
+}
+
+// jointManagers returns master and slave managers of joint model manager.
+// They are got by reflection because IJointModelManager returns IStandaloneModelManager.
+func jointManagers(manIns registry.ModelManager) (registry.ModelManager, registry.ModelManager, bool) {
+	if manIns == nil {
+		return nil, nil, false
+	}
+	v := reflect.ValueOf(manIns)
+	get := func(name string) registry.ModelManager {
+		m := v.MethodByName(name)
+		if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+			return nil
+		}
+		out := m.Call(nil)[0]
+		if (out.Kind() == reflect.Interface || out.Kind() == reflect.Ptr) && out.IsNil() {
+			return nil
+		}
+		man, _ := out.Interface().(registry.ModelManager)
+		return man
+	}
+	master, slave := get("GetMasterManager"), get("GetSlaveManager")
+	if master == nil || slave == nil {
+		return nil, nil, false
+	}
+	return master, slave, true
+}
+
+// jointRoute is a route of joint model under master or slave resource path
+type jointRoute struct {
+	action     string
+	path       string
+	id         string
+	pathParams []SwaggerConfigPathParam
+}
+
+func (jr jointRoute) generate(g *swaggerGen, m *Method, param *parameter, resp *response, sw *generator.SnippetWriter) {
+	param.operationId = jr.id
+	param.withId = false
+	param.pathParams = jr.pathParams
+	resp.id = fmt.Sprintf("%sOutput", jr.id)
+	route := newRouteFactory(m).newRoute(jr.action, param, resp)
+	route.path = jr.path
+	g.newCommenter(route, param, resp).Do(sw)
+}
+
+func idPathParam(name, keyword string) SwaggerConfigPathParam {
+	return SwaggerConfigPathParam{
+		Name:        name,
+		Type:        "string",
+		Description: fmt.Sprintf("The Id or Name of %s", keyword),
+	}
+}
+
+// generateJoint emits routes of joint model under its master and slave resources:
+// GET /<masters>/{id}/<slaves>, GET /<slaves>/{id}/<masters> and
+// GET, POST, PUT, DELETE /<masters>/{id}/<slaves>/{<slave>_id}
+func (g *swaggerGen) generateJoint(p *typeParser, master, slave registry.ModelManager, cov *ModelCoverage, sw *generator.SnippetWriter) {
+	getM := p.getM()
+	if getM == nil {
+		cov.check(VerbJointGet, Get, p.model, nil)
+		return
+	}
+	jointId := func(action string) string {
+		return privateName(p.singular, action)
+	}
+	masterName := utils.Kebab2Camel(master.Keyword(), "_")
+	slaveName := utils.Kebab2Camel(slave.Keyword(), "_")
+	slaveId := fmt.Sprintf("%s_id", slave.Keyword())
+	itemPath := fmt.Sprintf("/%s/{id}/%s/{%s}", master.KeywordPlural(), slave.KeywordPlural(), slaveId)
+	itemParams := []SwaggerConfigPathParam{idPathParam("id", master.Keyword()), idPathParam(slaveId, slave.Keyword())}
+
+	if listM := p.listM(); listM != nil {
+		for _, pair := range [][2]registry.ModelManager{{master, slave}, {slave, master}} {
+			jr := jointRoute{
+				action:     "GET",
+				path:       fmt.Sprintf("/%s/{id}/%s", pair[0].KeywordPlural(), pair[1].KeywordPlural()),
+				id:         jointId("ListBy" + utils.Kebab2Camel(pair[0].Keyword(), "_")),
+				pathParams: []SwaggerConfigPathParam{idPathParam("id", pair[0].Keyword())},
+			}
+			jr.generate(g, listM, newParameterFactory(listM).List(), newResponseFactory(listM).ListResult(getM), sw)
+		}
+	}
+	cov.check(VerbJointList, List, p.manager, p.listM())
+
+	jr := jointRoute{action: "GET", path: itemPath, id: jointId(fmt.Sprintf("Get%s%s", masterName, slaveName)), pathParams: itemParams}
+	jr.generate(g, getM, newParameterFactory(getM).Get(), newResponseFactory(getM).FirstSingularResult(), sw)
+	cov.check(VerbJointGet, Get, p.model, getM)
+
+	if createM := p.createM(); createM != nil {
+		jr := jointRoute{action: "POST", path: itemPath, id: jointId(fmt.Sprintf("Attach%s%s", masterName, slaveName)), pathParams: itemParams}
+		jr.generate(g, createM, newParameterFactory(createM).Create(), newResponseFactory(createM).ResultByGetMethod(getM), sw)
+	}
+	cov.check(VerbJointAttach, Create, p.manager, p.createM(), getM)
+
+	if updateM := p.updateM(); updateM != nil {
+		jr := jointRoute{action: "PUT", path: itemPath, id: jointId(fmt.Sprintf("Update%s%s", masterName, slaveName)), pathParams: itemParams}
+		jr.generate(g, updateM, newParameterFactory(updateM).Update(), newResponseFactory(updateM).ResultByGetMethod(getM), sw)
+	}
+	cov.check(VerbJointUpdate, Update, p.model, p.updateM(), getM)
+
+	// detach is always served by joint dispatcher, CustomizeDelete only adds input
+	detachParam := newParameterFactory(getM).Get()
+	detachM := getM
+	if deleteM := p.deleteM(); deleteM != nil {
+		detachParam = newParameterFactory(deleteM).Delete()
+		detachM = deleteM
+	}
+	jr = jointRoute{action: "DELETE", path: itemPath, id: jointId(fmt.Sprintf("Detach%s%s", masterName, slaveName)), pathParams: itemParams}
+	jr.generate(g, detachM, detachParam, newResponseFactory(getM).FirstSingularResult(), sw)
+	cov.check(VerbJointDetach, Get, p.model, getM)
+}
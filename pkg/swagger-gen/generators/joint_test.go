@@ -0,0 +1,20 @@
+package generators
+
+import (
+	"testing"
+
+	"yunion.io/x/code-generator/pkg/models/registry/testdata/fakemodels"
+)
+
+func Test_jointManagers(t *testing.T) {
+	master, slave, ok := jointManagers(fakemodels.GuestdiskManager)
+	if !ok || master != fakemodels.GuestManager || slave != fakemodels.DiskManager {
+		t.Errorf("jointManagers() = %v, %v, %v, want %v, %v", master, slave, ok, fakemodels.GuestManager, fakemodels.DiskManager)
+	}
+	if _, _, ok := jointManagers(&fakemodels.SGuestdiskManager{}); ok {
+		t.Errorf("jointManagers() without master and slave managers should fail")
+	}
+	if _, _, ok := jointManagers(fakemodels.GuestManager); ok {
+		t.Errorf("jointManagers() of standalone manager should fail")
+	}
+}
@@ -0,0 +1,101 @@
+package generators
+
+import (
+	"fmt"
+	"strings"
+
+	"yunion.io/x/pkg/util/sets"
+
+	"yunion.io/x/code-generator/pkg/common"
+)
+
+const (
+	LangEn = "en"
+	LangZh = "zh"
+
+	// tagSummaryPrefix and tagDescriptionPrefix are suffixed by language, e.g.:
+	// +onecloud:swagger-gen-summary-zh=获取虚拟机详情
+	// +onecloud:swagger-gen-description-en=Get details of the server
+	tagSummaryPrefix     = "onecloud:swagger-gen-summary-"
+	tagDescriptionPrefix = "onecloud:swagger-gen-description-"
+)
+
+var _ = common.RegisterAnnotations(
+	common.Annotation{
+		Name:        tagSummaryPrefix,
+		Generator:   generatorName,
+		Syntax:      "<summary>",
+		Choices:     supportedLangs,
+		Targets:     []string{common.TargetType, common.TargetMethod, common.TargetFunction},
+		Description: "route summary of the language suffix",
+		Prefix:      true,
+	},
+	common.Annotation{
+		Name:        tagDescriptionPrefix,
+		Generator:   generatorName,
+		Syntax:      "<description>",
+		Choices:     supportedLangs,
+		Targets:     []string{common.TargetType, common.TargetMethod, common.TargetFunction},
+		Description: "route description line of the language suffix",
+		Repeatable:  true,
+		Prefix:      true,
+	},
+)
+
+var supportedLangs = []string{LangEn, LangZh}
+
+func validateLang(lang string) error {
+	if lang == "" || sets.NewString(supportedLangs...).Has(lang) {
+		return nil
+	}
+	return fmt.Errorf("invalid lang %q, choices: %v", lang, supportedLangs)
+}
+
+type localizedDoc struct {
+	summary     string
+	description []string
+}
+
+// extractLocalizedDocs parse bilingual summary and description tags by language
+func extractLocalizedDocs(comments []string) map[string]*localizedDoc {
+	docs := make(map[string]*localizedDoc)
+	for _, lang := range supportedLangs {
+		summary := extractTagByName(comments, tagSummaryPrefix+lang)
+		desc := extractTagByName(comments, tagDescriptionPrefix+lang)
+		if len(summary) == 0 && len(desc) == 0 {
+			continue
+		}
+		doc := &localizedDoc{description: desc}
+		if len(summary) != 0 {
+			doc.summary = summary[0]
+		}
+		docs[lang] = doc
+	}
+	return docs
+}
+
+// localize replace route summary and description by the docs of lang,
+// docs of other languages are kept as x-summary-<lang> and x-description-<lang> extensions
+func (r *route) localize(docs map[string]*localizedDoc, lang string) {
+	for _, l := range supportedLangs {
+		doc, ok := docs[l]
+		if !ok {
+			continue
+		}
+		if l == lang {
+			if doc.summary != "" {
+				r.summary = doc.summary
+			}
+			// description in comments may be of other language
+			r.description = doc.description
+			continue
+		}
+		if doc.summary != "" {
+			r.addExtension(fmt.Sprintf("x-summary-%s", l), doc.summary)
+		}
+		if len(doc.description) != 0 {
+			r.addExtension(fmt.Sprintf("x-description-%s", l), strings.Join(doc.description, " "))
+		}
+	}
+	r.reviseDescription()
+}
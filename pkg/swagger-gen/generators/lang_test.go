@@ -0,0 +1,67 @@
+package generators
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_routeLocalize(t *testing.T) {
+	comments := []string{
+		"Get server details",
+		"+onecloud:swagger-gen-summary-en=Get server details",
+		"+onecloud:swagger-gen-summary-zh=获取虚拟机详情",
+		"+onecloud:swagger-gen-description-zh=返回虚拟机的详细信息",
+	}
+	tests := []struct {
+		name     string
+		lang     string
+		want     *route
+		wantDesc []string
+	}{
+		{
+			name: "comments kept without lang",
+			lang: "",
+			want: &route{
+				summary:     "Get server details",
+				description: []string{"Get server details"},
+				extensions: map[string]string{
+					"x-summary-en":     "Get server details",
+					"x-summary-zh":     "获取虚拟机详情",
+					"x-description-zh": "返回虚拟机的详细信息",
+				},
+			},
+		},
+		{
+			name: "zh selected",
+			lang: LangZh,
+			want: &route{
+				summary:     "获取虚拟机详情",
+				description: []string{"返回虚拟机的详细信息"},
+				extensions: map[string]string{
+					"x-summary-en": "Get server details",
+				},
+			},
+		},
+		{
+			name: "en selected without description",
+			lang: LangEn,
+			want: &route{
+				summary:     "Get server details",
+				description: []string{"Get server details"},
+				extensions: map[string]string{
+					"x-summary-zh":     "获取虚拟机详情",
+					"x-description-zh": "返回虚拟机的详细信息",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &route{summary: comments[0], description: []string{comments[0]}}
+			r.localize(extractLocalizedDocs(comments), tt.lang)
+			if !reflect.DeepEqual(r, tt.want) {
+				t.Errorf("localize() = %#v, want %#v", r, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package generators
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/types"
+
+	"yunion.io/x/pkg/util/sets"
+
+	"yunion.io/x/code-generator/pkg/common"
+)
+
+// ParseModel returns routes of model served by its manager without writing generated code,
+// the manager must be registered by models.Initialize or registry.Register.
+// Each call is a run of its own, reports of other runs, e.g. coverage and source map, are not affected.
+func ParseModel(manType, modelType *types.Type, ca *CustomArgs) ([]RouteInfo, error) {
+	if manType == nil || modelType == nil {
+		return nil, fmt.Errorf("manager and model types are required")
+	}
+	if !isModelManagerRegistered(manType) {
+		return nil, fmt.Errorf("model manager %s is not registered", manType.String())
+	}
+	if ca == nil {
+		_, ca = NewDefaults()
+	}
+	run, err := NewGeneration(ca, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	if !inAPIVersion(ca.APIVersion, modelType, manType) {
+		return nil, nil
+	}
+	if ignoreAll, _ := getIgnoreVerbs(manType); ignoreAll {
+		return nil, nil
+	}
+	if ignoreAll, _ := getIgnoreVerbs(modelType); ignoreAll {
+		return nil, nil
+	}
+	g := &swaggerGen{
+		sourcePackage: modelType.Name.Package,
+		modelTypes:    sets.NewString(modelType.String()),
+		modelManagers: map[string]*types.Type{modelType.String(): manType},
+		args:          ca,
+		run:           run,
+		out:           newOutputPackage(),
+	}
+	sw := generator.NewSnippetWriter(ioutil.Discard, &generator.Context{Namers: NameSystems()}, "$", "$")
+	g.generateCode(manType, modelType, sw)
+	if err := sw.Error(); err != nil {
+		return nil, err
+	}
+	return run.sortedRoutes(), nil
+}
+
+// ParseModels returns routes of registered models in source package srcPkg of pkgTypes
+func ParseModels(srcPkg string, pkgTypes []*types.Type, ca *CustomArgs) ([]RouteInfo, error) {
+	c := common.NewTypeCollector(srcPkg)
+	c.Collect(pkgTypes)
+	modelTypes, managers := c.Models, c.Managers
+	ret := make([]RouteInfo, 0)
+	for _, t := range pkgTypes {
+		man := managers[t.String()]
+		if !modelTypes.Has(t.String()) || !isModelManagerRegistered(man) {
+			continue
+		}
+		routes, err := ParseModel(man, t, ca)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, routes...)
+	}
+	return ret, nil
+}
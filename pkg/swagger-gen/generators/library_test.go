@@ -0,0 +1,41 @@
+package generators
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/gengo/v2/types"
+
+	"yunion.io/x/pkg/util/sets"
+)
+
+func Test_ParseModelUnregistered(t *testing.T) {
+	man := &types.Type{Name: types.Name{Package: "models", Name: "SUnregisteredManager"}}
+	model := &types.Type{Name: types.Name{Package: "models", Name: "SUnregistered"}}
+	if _, err := ParseModel(man, model, nil); err == nil {
+		t.Errorf("ParseModel() of unregistered manager should fail")
+	}
+}
+
+func Test_FilterUnregisteredManager(t *testing.T) {
+	run := newTestGeneration(t, nil)
+	man := &types.Type{Name: types.Name{Package: "models", Name: "SUnregisteredManager"}, Kind: types.Struct}
+	model := &types.Type{Name: types.Name{Package: "models", Name: "SUnregistered"}, Kind: types.Struct}
+	orphan := &types.Type{Name: types.Name{Package: "models", Name: "SOrphan"}, Kind: types.Struct}
+	g := &swaggerGen{
+		modelTypes:    sets.NewString(model.String(), orphan.String()),
+		modelManagers: map[string]*types.Type{model.String(): man},
+		args:          run.args,
+		run:           run,
+	}
+	if err := run.CheckRegisteredManagers(); err != nil {
+		t.Fatalf("CheckRegisteredManagers() before filtering: %v", err)
+	}
+	if g.Filter(nil, model) || g.Filter(nil, orphan) {
+		t.Errorf("Filter() of models without registered manager should be false")
+	}
+	err := run.CheckRegisteredManagers()
+	if err == nil || !strings.Contains(err.Error(), model.String()) || strings.Contains(err.Error(), orphan.String()) {
+		t.Errorf("CheckRegisteredManagers() = %v, want error listing only %s", err, model)
+	}
+}
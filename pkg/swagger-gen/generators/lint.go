@@ -0,0 +1,301 @@
+package generators
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"yunion.io/x/pkg/util/sets"
+
+	"yunion.io/x/code-generator/pkg/common"
+)
+
+// tagPrefixSwaggerGen is the prefix of swagger-gen tags, unknown tags of it are reported by lint
+const tagPrefixSwaggerGen = "onecloud:swagger-gen"
+
+var (
+	// lintIndexTags are the tags of argument or result index
+	lintIndexTags   = []string{tagParamQueryIdx, tagParamBodyIdx, tagRespIdx}
+	lintHTTPMethods = sets.NewString("GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS")
+
+	lintIndexedTagRegexp = regexp.MustCompile(`^([^\[]+)\[([^\]]*)\]$`)
+	lintPathParamRegexp  = regexp.MustCompile(`{([^}]+)}`)
+)
+
+// lintIndexedTags returns the registered tags declaring indexed routes like +onecloud:swagger-gen-route-method[0]=GET
+func lintIndexedTags() sets.String {
+	ret := sets.NewString()
+	for _, a := range common.Annotations() {
+		if a.Indexed {
+			ret.Insert(a.Name)
+		}
+	}
+	return ret
+}
+
+// LintIssue is a malformed tag found by lint
+type LintIssue struct {
+	Pos     token.Position
+	Message string
+}
+
+func (i LintIssue) String() string {
+	return fmt.Sprintf("%s: %s", i.Pos, i.Message)
+}
+
+// lintTag is a +onecloud tag line of comment group
+type lintTag struct {
+	name  string
+	value string
+	pos   token.Position
+}
+
+// lintGroup is the tags of a comment group, keyed by name with index
+type lintGroup struct {
+	tags   []lintTag
+	byName map[string][]lintTag
+	issues []LintIssue
+}
+
+func (g *lintGroup) report(pos token.Position, format string, args ...interface{}) {
+	g.issues = append(g.issues, LintIssue{Pos: pos, Message: fmt.Sprintf(format, args...)})
+}
+
+// first returns the first tag named name, false if not found
+func (g *lintGroup) first(name string) (lintTag, bool) {
+	tags := g.byName[name]
+	if len(tags) == 0 {
+		return lintTag{}, false
+	}
+	return tags[0], true
+}
+
+// newLintGroup parses +onecloud tags of comment group like gengo, tag without value is empty
+func newLintGroup(fset *token.FileSet, cg *ast.CommentGroup) *lintGroup {
+	g := &lintGroup{byName: make(map[string][]lintTag)}
+	for _, c := range cg.List {
+		line := strings.TrimSpace(strings.TrimPrefix(c.Text, "//"))
+		if !strings.HasPrefix(line, "+onecloud:") {
+			continue
+		}
+		parts := strings.SplitN(line[1:], "=", 2)
+		tag := lintTag{name: parts[0], pos: fset.Position(c.Pos())}
+		if len(parts) == 2 {
+			tag.value = parts[1]
+		}
+		g.tags = append(g.tags, tag)
+		g.byName[tag.name] = append(g.byName[tag.name], tag)
+	}
+	return g
+}
+
+// checkNames reports unknown swagger-gen tags and malformed indexes of indexed tags,
+// known tags are the registered annotations
+func (g *lintGroup) checkNames() {
+	for _, tag := range g.tags {
+		name := tag.name
+		if m := lintIndexedTagRegexp.FindStringSubmatch(name); m != nil {
+			if a, ok := common.LookupAnnotation(m[1]); !ok || !a.Indexed {
+				g.report(tag.pos, "tag %s can't be indexed, indexed tags: %v", m[1], lintIndexedTags().List())
+				continue
+			}
+			if idx, err := strconv.Atoi(m[2]); err != nil || idx < 0 {
+				g.report(tag.pos, "invalid index %q of tag %s, should be a non-negative integer", m[2], m[1])
+			}
+			continue
+		}
+		if !strings.HasPrefix(name, tagPrefixSwaggerGen) {
+			continue
+		}
+		a, ok := common.LookupAnnotation(name)
+		if !ok {
+			g.report(tag.pos, "unknown tag %s", name)
+			continue
+		}
+		// prefix tags are suffixed by language, e.g. +onecloud:swagger-gen-summary-zh
+		if a.Prefix {
+			if err := validateLang(strings.TrimPrefix(name, a.Name)); err != nil {
+				g.report(tag.pos, "tag %s: %v", name, err)
+			}
+		}
+	}
+}
+
+// checkIndexes reports argument and result indexes which are not non-negative integers
+func (g *lintGroup) checkIndexes() {
+	for _, name := range lintIndexTags {
+		for _, tag := range g.byName[name] {
+			if idx, err := strconv.Atoi(tag.value); err != nil || idx < 0 {
+				g.report(tag.pos, "invalid tag %s=%s, should be a non-negative integer", name, tag.value)
+			}
+		}
+	}
+}
+
+// checkRoute reports route of index suffix without method, path or tag, returns path of the route
+func (g *lintGroup) checkRoute(suffix string) (string, bool) {
+	method, hasMethod := g.first(tagRouteMethod + suffix)
+	path, hasPath := g.first(tagRoutePath + suffix)
+	switch {
+	case !hasMethod && !hasPath:
+		return "", false
+	case !hasMethod:
+		g.report(path.pos, "route path %s has no %s%s", path.value, tagRouteMethod, suffix)
+		return "", false
+	case !hasPath:
+		g.report(method.pos, "route method %s has no %s%s", method.value, tagRoutePath, suffix)
+		return "", false
+	}
+	if !lintHTTPMethods.Has(method.value) {
+		g.report(method.pos, "invalid route method %q, choices: %v", method.value, lintHTTPMethods.List())
+	}
+	if !strings.HasPrefix(path.value, "/") {
+		g.report(path.pos, "route path %q should start with /", path.value)
+	}
+	_, hasTag := g.first(tagRouteTag + suffix)
+	if _, plainTag := g.first(tagRouteTag); !hasTag && !plainTag {
+		g.report(method.pos, "route %s %s has no %s", method.value, path.value, tagRouteTag)
+	}
+	return path.value, true
+}
+
+// checkRoutes reports malformed plain and indexed routes, returns paths of the routes
+func (g *lintGroup) checkRoutes() []string {
+	paths := make([]string, 0)
+	if path, ok := g.checkRoute(""); ok {
+		paths = append(paths, path)
+	}
+	indexes, indexed := sets.NewInt(), lintIndexedTags()
+	for _, tag := range g.tags {
+		m := lintIndexedTagRegexp.FindStringSubmatch(tag.name)
+		if m == nil || !indexed.Has(m[1]) {
+			continue
+		}
+		if idx, err := strconv.Atoi(m[2]); err == nil && idx >= 0 {
+			indexes.Insert(idx)
+		}
+	}
+	for i, idx := range indexes.List() {
+		if i != idx {
+			g.report(g.tags[0].pos, "route index %d is missing, routes from index %d are ignored", i, idx)
+			break
+		}
+		if path, ok := g.checkRoute(fmt.Sprintf("[%d]", idx)); ok {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// checkPathParams reports param-path tags which are malformed or not in route paths,
+// and route path params without param-path tag
+func (g *lintGroup) checkPathParams(routePaths []string) {
+	declared := sets.NewString()
+	for _, tag := range g.byName[tagParamPath] {
+		parts := strings.SplitN(tag.value, ":", 3)
+		name, typ := parts[0], "string"
+		if len(parts) == 3 {
+			typ = parts[1]
+		}
+		if name == "" {
+			g.report(tag.pos, "invalid tag %s=%s, should be <name>[:<type>]:<description>", tagParamPath, tag.value)
+			continue
+		}
+		declared.Insert(name)
+		if _, ok := pathParamTypes[typ]; !ok {
+			g.report(tag.pos, "invalid tag %s=%s, unsupported type %q", tagParamPath, tag.value, typ)
+		}
+		if len(routePaths) == 0 {
+			g.report(tag.pos, "tag %s=%s without route", tagParamPath, tag.value)
+		}
+		for _, p := range routePaths {
+			if !strings.Contains(p, fmt.Sprintf("{%s}", name)) {
+				g.report(tag.pos, "param %q of tag %s is not in route path %s", name, tagParamPath, p)
+			}
+		}
+	}
+	for _, p := range routePaths {
+		for _, m := range lintPathParamRegexp.FindAllStringSubmatch(p, -1) {
+			if !declared.Has(m[1]) {
+				g.report(g.tags[0].pos, "param {%s} of route path %s has no %s", m[1], p, tagParamPath)
+			}
+		}
+	}
+}
+
+// checkValues reports values of extension and list pagination tags out of their syntax
+func (g *lintGroup) checkValues() {
+	for _, tag := range g.byName[tagExtension] {
+		parts := strings.SplitN(tag.value, ":", 2)
+		if len(parts) != 2 || !strings.HasPrefix(parts[0], "x-") {
+			g.report(tag.pos, "invalid tag %s=%s, should be x-<name>:<value>", tagExtension, tag.value)
+		}
+	}
+	for _, name := range []string{tagParamHeader, tagRespHeader} {
+		for _, tag := range g.byName[name] {
+			parts := strings.SplitN(tag.value, ":", 3)
+			if parts[0] == "" {
+				g.report(tag.pos, "invalid tag %s=%s, should be <name>[:<type>]:<description>", name, tag.value)
+				continue
+			}
+			if len(parts) != 3 {
+				continue
+			}
+			if _, ok := pathParamTypes[parts[1]]; !ok {
+				g.report(tag.pos, "invalid tag %s=%s, unsupported type %q", name, tag.value, parts[1])
+			}
+		}
+	}
+	for _, tag := range g.byName[tagListPagination] {
+		if tag.value != PaginationOffset && tag.value != PaginationMarker {
+			g.report(tag.pos, "invalid tag %s=%s, choices: %s, %s", tagListPagination, tag.value, PaginationOffset, PaginationMarker)
+		}
+	}
+	for _, tag := range g.byName[tagPolicyScope] {
+		if !policyScopes.Has(tag.value) {
+			g.report(tag.pos, "invalid tag %s=%s, choices: %v", tagPolicyScope, tag.value, policyScopes.List())
+		}
+	}
+}
+
+// LintFile reports malformed swagger-gen tags in comments of f
+func LintFile(fset *token.FileSet, f *ast.File) []LintIssue {
+	ret := make([]LintIssue, 0)
+	for _, cg := range f.Comments {
+		g := newLintGroup(fset, cg)
+		if len(g.tags) == 0 {
+			continue
+		}
+		g.checkNames()
+		g.checkIndexes()
+		g.checkPathParams(g.checkRoutes())
+		g.checkValues()
+		ret = append(ret, g.issues...)
+	}
+	return ret
+}
+
+// LintFiles parses go files and reports malformed swagger-gen tags in them, sorted by position
+func LintFiles(files []string) ([]LintIssue, error) {
+	fset := token.NewFileSet()
+	ret := make([]LintIssue, 0)
+	for _, file := range files {
+		f, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, LintFile(fset, f)...)
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].Pos.Filename != ret[j].Pos.Filename {
+			return ret[i].Pos.Filename < ret[j].Pos.Filename
+		}
+		return ret[i].Pos.Line < ret[j].Pos.Line
+	})
+	return ret, nil
+}
@@ -0,0 +1,68 @@
+package generators
+
+import (
+	goparser "go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_LintFile(t *testing.T) {
+	src := `package models
+
+// +onecloud:swagger-gen-route-method=GET
+// +onecloud:swagger-gen-route-path=/hosts/{host_id}/disks/{disk}
+// +onecloud:swagger-gen-route-tag=host
+// +onecloud:swagger-gen-param-path=host_id:uuid:id of host
+// +onecloud:swagger-gen-param-path=disk_id:id of disk
+// +onecloud:swagger-gen-param-query-index=first
+// +onecloud:swagger-gen-resp-index=1
+func GetHostDisk() {}
+
+// +onecloud:swagger-gen-route-method[0]=POST
+// +onecloud:swagger-gen-route-path[2]=/servers
+// +onecloud:swagger-gen-route-methods=GET
+// +onecloud:swagger-gen-summary-fr=Serveurs
+// +onecloud:swagger-gen-list-pagination=cursor
+// +onecloud:model-api-gen-getter
+func PostServer() {}
+
+// +onecloud:swagger-gen-route-method=PUT
+// +onecloud:swagger-gen-route-path=/servers
+// +onecloud:swagger-gen-route-tag=server
+// +onecloud:swagger-gen-summary-zh=更新虚拟机
+func PutServer() {}
+
+// +onecloud:swagger-gen-route-method=GET
+// +onecloud:swagger-gen-route-path=/auth/tokens
+// +onecloud:swagger-gen-route-tag=auth
+// +onecloud:swagger-gen-param-header=:token of request
+// +onecloud:swagger-gen-resp-header=X-Subject-Token:token:validated token
+func ValidateToken() {}
+`
+	fset := token.NewFileSet()
+	f, err := goparser.ParseFile(fset, "models.go", src, goparser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]string, 0)
+	for _, issue := range LintFile(fset, f) {
+		got = append(got, issue.String())
+	}
+	want := []string{
+		`models.go:8:1: invalid tag onecloud:swagger-gen-param-query-index=first, should be a non-negative integer`,
+		`models.go:7:1: param "disk_id" of tag onecloud:swagger-gen-param-path is not in route path /hosts/{host_id}/disks/{disk}`,
+		`models.go:3:1: param {disk} of route path /hosts/{host_id}/disks/{disk} has no onecloud:swagger-gen-param-path`,
+		`models.go:14:1: unknown tag onecloud:swagger-gen-route-methods`,
+		`models.go:15:1: tag onecloud:swagger-gen-summary-fr: invalid lang "fr", choices: [en zh]`,
+		`models.go:12:1: route method POST has no onecloud:swagger-gen-route-path[0]`,
+		`models.go:12:1: route index 1 is missing, routes from index 2 are ignored`,
+		`models.go:16:1: invalid tag onecloud:swagger-gen-list-pagination=cursor, choices: offset, marker`,
+		`models.go:29:1: invalid tag onecloud:swagger-gen-param-header=:token of request, should be <name>[:<type>]:<description>`,
+		`models.go:30:1: invalid tag onecloud:swagger-gen-resp-header=X-Subject-Token:token:validated token, unsupported type "token"`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LintFile() = \n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,103 @@
+package generators
+
+import (
+	"fmt"
+
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/types"
+
+	"yunion.io/x/pkg/util/sets"
+
+	"yunion.io/x/code-generator/pkg/common"
+)
+
+// commonListQueryStruct is the shared definition of standard list query parameters
+const commonListQueryStruct = "CommonListQuery"
+
+type listQueryParam struct {
+	name   string
+	goType string
+	desc   string
+}
+
+// commonListQueryParams are the list query parameters accepted by all modulebase list requests
+var commonListQueryParams = []listQueryParam{
+	{"limit", "int", "maximal number of items returned"},
+	{"offset", "int", "offset of the first item returned"},
+	{"order_by", "[]string", "fields to order the items by"},
+	{"order", "string", "order of the items, asc or desc"},
+	{"filter", "[]string", "filter conditions like name.contains(web)"},
+	{"details", "bool", "return the details of items"},
+	{"search", "string", "keyword to search items by"},
+	{"export_keys", "string", "comma separated fields of exported items"},
+}
+
+func (p listQueryParam) do(sw *generator.SnippetWriter) {
+	h := newSW(sw)
+	h.line(p.desc)
+	h.line("in:query")
+	sw.Do(fmt.Sprintf("%s %s `json:\"%s\"`\n", paramFieldName(p.name), p.goType, p.name), nil)
+}
+
+// queryJSONFields returns json field names of query type including embedded ones
+func queryJSONFields(t *types.Type) sets.String {
+	ret := sets.NewString()
+	if t == nil {
+		return ret
+	}
+	for _, m := range t.Members {
+		if et, _, ok := common.EmbeddedStruct(m); ok {
+			ret = ret.Union(queryJSONFields(et))
+			continue
+		}
+		ret.Insert(common.MemberJSONName(m))
+	}
+	return ret
+}
+
+// missingCommonListParams returns common list params not defined by query type
+func missingCommonListParams(query *types.Type) []listQueryParam {
+	fields := queryJSONFields(query)
+	ret := make([]listQueryParam, 0)
+	for _, p := range commonListQueryParams {
+		if !fields.Has(p.name) {
+			ret = append(ret, p)
+		}
+	}
+	return ret
+}
+
+// declareCommonListQuery emits the shared CommonListQuery struct once in output package
+func declareCommonListQuery(out *outputPackage, sw *generator.SnippetWriter) {
+	if out.commonListQueryDeclared {
+		return
+	}
+	out.commonListQueryDeclared = true
+	sw.Do(fmt.Sprintf("// %s is the standard list query parameters of modulebase\n", commonListQueryStruct), nil)
+	sw.Do(fmt.Sprintf("type %s struct {\n", commonListQueryStruct), nil)
+	for _, p := range commonListQueryParams {
+		p.do(sw)
+	}
+	sw.Do("}\n\n", nil)
+}
+
+// doCommonListParams embeds CommonListQuery if query defines none of the common params,
+// otherwise the missing ones are emitted one by one, offset is not emitted in marker pagination
+func doCommonListParams(query *types.Type, markerPaging bool, sw *generator.SnippetWriter) {
+	missing := missingCommonListParams(query)
+	if markerPaging {
+		for i, p := range missing {
+			if p.name == "offset" {
+				missing = append(missing[:i:i], missing[i+1:]...)
+				break
+			}
+		}
+	}
+	if len(missing) == len(commonListQueryParams) {
+		sw.Do(fmt.Sprintf("%s\n", commonListQueryStruct), nil)
+		return
+	}
+	for _, p := range missing {
+		p.do(sw)
+	}
+}
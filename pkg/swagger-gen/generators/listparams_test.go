@@ -0,0 +1,60 @@
+package generators
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/types"
+)
+
+func Test_missingCommonListParams(t *testing.T) {
+	str := &types.Type{Name: types.Name{Name: "string"}, Kind: types.Builtin}
+	integer := &types.Type{Name: types.Name{Name: "int"}, Kind: types.Builtin}
+	base := &types.Type{
+		Name: types.Name{Name: "ListInputBase"},
+		Kind: types.Struct,
+		Members: []types.Member{
+			{Name: "Limit", Type: integer, Tags: `json:"limit"`},
+			{Name: "Offset", Type: integer},
+		},
+	}
+	query := &types.Type{
+		Name: types.Name{Name: "ServerListInput"},
+		Members: []types.Member{
+			{Embedded: true, Type: &types.Type{Kind: types.Pointer, Elem: base}},
+			{Name: "Search", Type: str, Tags: `json:"search,omitempty"`},
+			{Name: "Zone", Type: str},
+		},
+	}
+	got := make([]string, 0)
+	for _, p := range missingCommonListParams(query) {
+		got = append(got, p.name)
+	}
+	want := []string{"order_by", "order", "filter", "details", "export_keys"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("missingCommonListParams() = %v, want %v", got, want)
+	}
+	if len(missingCommonListParams(nil)) != len(commonListQueryParams) {
+		t.Errorf("all common params should be missing without query")
+	}
+}
+
+func Test_declareCommonListQuery(t *testing.T) {
+	buf := &bytes.Buffer{}
+	sw := generator.NewSnippetWriter(buf, &generator.Context{}, "$", "$")
+	decl := "type " + commonListQueryStruct + " struct {"
+	out := newOutputPackage()
+	declareCommonListQuery(out, sw)
+	declareCommonListQuery(out, sw)
+	if got := strings.Count(buf.String(), decl); got != 1 {
+		t.Errorf("declareCommonListQuery() twice in an output package declared %d times, want 1", got)
+	}
+	buf.Reset()
+	declareCommonListQuery(newOutputPackage(), sw)
+	if got := strings.Count(buf.String(), decl); got != 1 {
+		t.Errorf("declareCommonListQuery() in another output package declared %d times, want 1", got)
+	}
+}
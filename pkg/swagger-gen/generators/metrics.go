@@ -0,0 +1,66 @@
+package generators
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"strings"
+
+	"yunion.io/x/pkg/util/sets"
+)
+
+// operationConstName converts operation id like server_PerformStart to OperationServerPerformStart
+func operationConstName(opId string) string {
+	parts := strings.FieldsFunc(invalidIdentChars.ReplaceAllString(opId, "_"), func(r rune) bool { return r == '_' })
+	for i, part := range parts {
+		parts[i] = strings.ToUpper(part[:1]) + part[1:]
+	}
+	return "Operation" + strings.Join(parts, "")
+}
+
+// renderMetricsLabels renders go source of operation id constants and their method and path template,
+// routes of duplicated operation id are skipped
+func renderMetricsLabels(pkgName string, routes []RouteInfo) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "// Code generated by swagger-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(buf, "package %s\n\n", pkgName)
+	buf.WriteString("// OperationLabel is the method and path template of an operation,\n")
+	buf.WriteString("// used by middleware to label metrics and traces consistently with the API docs\n")
+	buf.WriteString("type OperationLabel struct {\nMethod string\nPath string\n}\n\n")
+
+	opIds := sets.NewString()
+	names := sets.NewString()
+	consts := &bytes.Buffer{}
+	labels := &bytes.Buffer{}
+	for _, r := range routes {
+		if opIds.Has(r.OperationId) {
+			continue
+		}
+		opIds.Insert(r.OperationId)
+		name := operationConstName(r.OperationId)
+		for i := 2; names.Has(name); i++ {
+			name = fmt.Sprintf("%s%d", operationConstName(r.OperationId), i)
+		}
+		names.Insert(name)
+		fmt.Fprintf(consts, "%s = %q\n", name, r.OperationId)
+		fmt.Fprintf(labels, "%s: {Method: %q, Path: %q},\n", name, r.Method, r.Path)
+	}
+	buf.WriteString("// Operation ids of generated routes\n")
+	fmt.Fprintf(buf, "const (\n%s)\n\n", consts.String())
+	buf.WriteString("// OperationLabels maps operation id to its method and path template\n")
+	fmt.Fprintf(buf, "var OperationLabels = map[string]OperationLabel{\n%s}\n", labels.String())
+	return format.Source(buf.Bytes())
+}
+
+// WriteMetricsLabels write operation id constants and labels of collected routes to go file
+func (run *Generation) WriteMetricsLabels(file string) error {
+	if file == "" {
+		return nil
+	}
+	content, err := renderMetricsLabels(run.routePackage, run.sortedRoutes())
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, content, 0644)
+}
@@ -0,0 +1,32 @@
+package generators
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_renderMetricsLabels(t *testing.T) {
+	routes := []RouteInfo{
+		{Method: "GET", Path: "/servers", OperationId: "server_List"},
+		{Method: "POST", Path: "/servers/{id}/start", OperationId: "server_PerformStart"},
+		{Method: "GET", Path: "/v2/servers", OperationId: "server_List"},
+	}
+	content, err := renderMetricsLabels("compute", routes)
+	if err != nil {
+		t.Fatalf("renderMetricsLabels error: %v", err)
+	}
+	for _, want := range []string{
+		"package compute\n",
+		"\tOperationServerList         = \"server_List\"\n",
+		"\tOperationServerPerformStart = \"server_PerformStart\"\n",
+		"\tOperationServerList:         {Method: \"GET\", Path: \"/servers\"},\n",
+		"\tOperationServerPerformStart: {Method: \"POST\", Path: \"/servers/{id}/start\"},\n",
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("metrics labels missing %q:\n%s", want, content)
+		}
+	}
+	if strings.Contains(string(content), "/v2/servers") {
+		t.Errorf("metrics labels should skip duplicated operation id:\n%s", content)
+	}
+}
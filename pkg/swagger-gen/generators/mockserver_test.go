@@ -0,0 +1,44 @@
+package generators
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_renderMockServer(t *testing.T) {
+	routes := []RouteInfo{
+		{
+			Method:      "GET",
+			Path:        "/servers",
+			OperationId: "serverList",
+			Input:       "yunion.io/x/onecloud/pkg/apis/compute.ServerListInput",
+			InputIn:     "query",
+			Output:      "[]yunion.io/x/onecloud/pkg/apis/compute.ServerDetails",
+			OutputKey:   "servers",
+		},
+		{
+			Method:      "POST",
+			Path:        "/servers/{id}/start",
+			OperationId: "serverPerformStart",
+			Input:       "yunion.io/x/onecloud/pkg/compute.ServerStartInput",
+			InputIn:     "body",
+			InputKey:    "server",
+		},
+	}
+	content, err := renderMockServer("compute", routes)
+	if err != nil {
+		t.Fatalf("renderMockServer error: %v", err)
+	}
+	for _, want := range []string{
+		"package compute\n",
+		`compute "yunion.io/x/onecloud/pkg/apis/compute"`,
+		`compute2 "yunion.io/x/onecloud/pkg/compute"`,
+		`{method: "GET", path: regexp.MustCompile("^/servers$"), inputInBody: false, inputKey: "", newInput: func() interface{} { return new(compute.ServerListInput) }, outputKey: "servers", isList: true, newOutput: func() interface{} { return new(compute.ServerDetails) }},`,
+		`{method: "POST", path: regexp.MustCompile("^/servers/[^/]+/start$"), inputInBody: true, inputKey: "server", newInput: func() interface{} { return new(compute2.ServerStartInput) }, outputKey: "", isList: false, newOutput: nil},`,
+		"func NewMockHandler() http.Handler {",
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("mock server missing %q:\n%s", want, content)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package generators
+
+import (
+	"k8s.io/gengo/v2/types"
+
+	"yunion.io/x/pkg/util/sets"
+
+	"yunion.io/x/code-generator/pkg/models/registry"
+)
+
+// resourceFilter restricts generation to the resources named by --only, e.g. servers or SGuest
+type resourceFilter struct {
+	names   sets.String
+	matched sets.String
+}
+
+// newResourceFilter returns filter of resource names, nil if names is empty
+func newResourceFilter(names []string) *resourceFilter {
+	if len(names) == 0 {
+		return nil
+	}
+	return &resourceFilter{names: sets.NewString(names...), matched: sets.NewString()}
+}
+
+// match returns true if any of names is filtered in, always true for nil filter
+func (f *resourceFilter) match(names ...string) bool {
+	if f == nil {
+		return true
+	}
+	ok := false
+	for _, name := range names {
+		if f.names.Has(name) {
+			f.matched.Insert(name)
+			ok = true
+		}
+	}
+	return ok
+}
+
+// matchModel returns true if model is filtered in by its type name, manager type name, keyword or plural keyword
+func (f *resourceFilter) matchModel(model, manType *types.Type, man registry.ModelManager) bool {
+	names := []string{model.Name.Name}
+	if manType != nil {
+		names = append(names, manType.Name.Name)
+	}
+	if man != nil {
+		names = append(names, man.Keyword(), man.KeywordPlural())
+	}
+	return f.match(names...)
+}
+
+// matchFunction returns true if function declaration is filtered in by its name or tags of its routes
+func (f *resourceFilter) matchFunction(t *types.Type, config *SwaggerConfig) bool {
+	names := []string{t.Name.Name}
+	for _, r := range config.Routes {
+		names = append(names, r.Tags...)
+	}
+	return f.match(names...)
+}
+
+// UnmatchedOnly returns the names of --only matching no resource of the run, e.g. misspelled ones
+func (run *Generation) UnmatchedOnly() []string {
+	if run.filter == nil {
+		return nil
+	}
+	return run.filter.names.Difference(run.filter.matched).List()
+}
@@ -0,0 +1,56 @@
+package generators
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/gengo/v2/types"
+
+	"yunion.io/x/code-generator/pkg/models/registry"
+	"yunion.io/x/code-generator/pkg/models/registry/testdata/fakemodels"
+)
+
+func Test_resourceFilter(t *testing.T) {
+	guest := &types.Type{Name: types.Name{Name: "SGuest"}}
+	guestManager := &types.Type{Name: types.Name{Name: "SGuestManager"}}
+	disk := &types.Type{Name: types.Name{Name: "SDisk"}}
+	auth := &types.Type{Name: types.Name{Name: "Authenticate"}}
+	authConfig := &SwaggerConfig{Routes: []*SwaggerConfigRoute{{Method: "POST", Path: "/auth/tokens", Tags: []string{"auth"}}}}
+
+	if !(*resourceFilter)(nil).matchModel(guest, guestManager, fakemodels.GuestManager) {
+		t.Errorf("nil filter should match all models")
+	}
+	tests := []struct {
+		name   string
+		only   []string
+		models map[*types.Type]bool
+		auth   bool
+		wantUn []string
+	}{
+		{name: "plural keyword", only: []string{"servers"}, models: map[*types.Type]bool{guest: true, disk: false}, wantUn: []string{}},
+		{name: "keyword and type name", only: []string{"server", "SDisk"}, models: map[*types.Type]bool{guest: true, disk: true}, wantUn: []string{}},
+		{name: "manager type name", only: []string{"SGuestManager"}, models: map[*types.Type]bool{guest: true, disk: false}, wantUn: []string{}},
+		{name: "route tag", only: []string{"auth", "hosts"}, models: map[*types.Type]bool{guest: false}, auth: true, wantUn: []string{"hosts"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := newTestGeneration(t, func(ca *CustomArgs) { ca.Only = tt.only })
+			for model, want := range tt.models {
+				var manType *types.Type
+				var man registry.ModelManager = fakemodels.DiskManager
+				if model == guest {
+					manType, man = guestManager, fakemodels.GuestManager
+				}
+				if got := run.filter.matchModel(model, manType, man); got != want {
+					t.Errorf("matchModel(%s) = %v, want %v", model.Name.Name, got, want)
+				}
+			}
+			if got := run.filter.matchFunction(auth, authConfig); got != tt.auth {
+				t.Errorf("matchFunction() = %v, want %v", got, tt.auth)
+			}
+			if got := run.UnmatchedOnly(); !reflect.DeepEqual(got, tt.wantUn) {
+				t.Errorf("UnmatchedOnly() = %v, want %v", got, tt.wantUn)
+			}
+		})
+	}
+}
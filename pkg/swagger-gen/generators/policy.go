@@ -0,0 +1,253 @@
+package generators
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/util/sets"
+
+	"yunion.io/x/code-generator/pkg/common"
+)
+
+const (
+	// onecloud rbac actions
+	PolicyActionList    = "list"
+	PolicyActionGet     = "get"
+	PolicyActionCreate  = "create"
+	PolicyActionUpdate  = "update"
+	PolicyActionDelete  = "delete"
+	PolicyActionPerform = "perform"
+
+	// policyResultSkeleton is the result of skeleton rules, edited by the policy maintainer
+	policyResultSkeleton = "deny"
+
+	// onecloud policy scopes
+	PolicyScopeSystem  = "system"
+	PolicyScopeDomain  = "domain"
+	PolicyScopeProject = "project"
+
+	// tagPolicyScope record the required scope of route, e.g.:
+	// +onecloud:swagger-gen-policy-scope=system
+	// it's looked up on route method, its Allow* guard method and the receiver in order
+	tagPolicyScope = "onecloud:swagger-gen-policy-scope"
+	// tagPolicyAction overrides the policy action inferred from route path, e.g.:
+	// +onecloud:swagger-gen-policy-action=servers.perform.start
+	tagPolicyAction = "onecloud:swagger-gen-policy-action"
+
+	extPolicyScope  = "x-onecloud-policy-scope"
+	extPolicyAction = "x-onecloud-policy-action"
+)
+
+var _ = common.RegisterAnnotations(
+	common.Annotation{
+		Name:        tagPolicyScope,
+		Generator:   generatorName,
+		Syntax:      "<scope>",
+		Choices:     []string{PolicyScopeSystem, PolicyScopeDomain, PolicyScopeProject},
+		Targets:     []string{common.TargetMethod, common.TargetModel, common.TargetManager},
+		Description: "required policy scope of the route, looked up on method, its Allow* guard and receiver",
+	},
+	common.Annotation{
+		Name:        tagPolicyAction,
+		Generator:   generatorName,
+		Syntax:      "<service>.<verb>[.<action>]",
+		Targets:     []string{common.TargetMethod},
+		Description: "policy action of the route, overrides the one inferred from route path",
+	},
+)
+
+var (
+	policyScopes = sets.NewString(PolicyScopeSystem, PolicyScopeDomain, PolicyScopeProject)
+
+	// allowMethodNames maps route method to its guard method checked by onecloud dispatcher
+	allowMethodNames = map[string]string{
+		Create: "AllowCreateItem",
+		List:   "AllowListItems",
+		Get:    "AllowGetDetails",
+		Update: "AllowUpdateItem",
+		Delete: "AllowDeleteItem",
+	}
+)
+
+// allowMethodName returns the Allow* guard method name of route method
+func allowMethodName(name string) string {
+	if allow, ok := allowMethodNames[name]; ok {
+		return allow
+	}
+	if strings.HasPrefix(name, Perform) || strings.HasPrefix(name, GetSpec) {
+		return "Allow" + name
+	}
+	return ""
+}
+
+// allowMethod returns the Allow* guard method of m defined by its model, nil if not defined
+func (m *Method) allowMethod() *promotedMethod {
+	name := allowMethodName(m.Name())
+	if name == "" {
+		return nil
+	}
+	allow, ok := promotedMethods(m.Model())[name]
+	if !ok {
+		return nil
+	}
+	return &allow
+}
+
+// extractPolicyTags returns the policy scope and action tags found first in comments list
+func extractPolicyTags(commentsList ...[]string) (string, string) {
+	scope, action := "", ""
+	for _, comments := range commentsList {
+		if vals := extractTagByName(comments, tagPolicyScope); scope == "" && len(vals) != 0 {
+			if policyScopes.Has(vals[0]) {
+				scope = vals[0]
+			} else {
+				log.Errorf("invalid tag %s=%s, choices: %v", tagPolicyScope, vals[0], policyScopes.List())
+			}
+		}
+		if vals := extractTagByName(comments, tagPolicyAction); action == "" && len(vals) != 0 {
+			action = vals[0]
+		}
+	}
+	return scope, action
+}
+
+// String returns the policy action like servers.perform.start
+func (pa policyAction) String() string {
+	parts := []string{pa.Resource, pa.Action}
+	if pa.Extra != "" {
+		parts = append(parts, pa.Extra)
+	}
+	return strings.Join(parts, ".")
+}
+
+// applyPolicy add policy scope and action extensions to route,
+// action is inferred from route path if not set by tag
+func (r *route) applyPolicy() {
+	action := r.policyAction
+	if action == "" {
+		path := r.path
+		if r.policyPath != "" {
+			path = r.policyPath
+		}
+		if pa, ok := routePolicyAction(r.run.args.APIVersion, RouteInfo{Method: r.action, Path: path}); ok {
+			action = pa.String()
+			if r.run.routePackage != "" {
+				action = fmt.Sprintf("%s.%s", r.run.routePackage, action)
+			}
+		}
+	}
+	if action != "" {
+		r.addExtension(extPolicyAction, action)
+	}
+	if r.policyScope != "" {
+		r.addExtension(extPolicyScope, r.policyScope)
+	}
+}
+
+// policyAction is the onecloud rbac rule matched by a route
+type policyAction struct {
+	Resource string
+	Action   string
+	Extra    string
+}
+
+func isPathParam(seg string) bool {
+	return strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}")
+}
+
+// routePolicyAction derives rbac action of route the same way as onecloud dispatcher:
+// GET /res is list, GET or HEAD /res/{id}[/spec] is get, POST /res is create,
+// POST /res/{id}/action is perform, PUT /res/{id} is update, DELETE /res/{id} is delete,
+// prefix of api version like /v2 is ignored
+func routePolicyAction(version string, r RouteInfo) (policyAction, bool) {
+	segs := make([]string, 0)
+	for _, seg := range strings.Split(trimAPIVersionPath(version, r.Path), "/") {
+		if seg != "" {
+			segs = append(segs, seg)
+		}
+	}
+	if len(segs) == 0 || isPathParam(segs[0]) {
+		return policyAction{}, false
+	}
+	pa := policyAction{Resource: segs[0]}
+	withId := len(segs) >= 2 && isPathParam(segs[1])
+	if len(segs) > 2 {
+		extras := make([]string, 0)
+		for _, seg := range segs[2:] {
+			if !isPathParam(seg) {
+				extras = append(extras, seg)
+			}
+		}
+		pa.Extra = strings.Join(extras, "-")
+	}
+	switch {
+	case r.Method == "GET" && len(segs) == 1:
+		pa.Action = PolicyActionList
+	case (r.Method == "GET" || r.Method == "HEAD") && withId:
+		pa.Action = PolicyActionGet
+	case r.Method == "POST" && len(segs) == 1:
+		pa.Action = PolicyActionCreate
+	case r.Method == "POST" && withId && pa.Extra != "":
+		pa.Action = PolicyActionPerform
+	case r.Method == "PUT" && withId:
+		pa.Action = PolicyActionUpdate
+	case r.Method == "DELETE" && withId:
+		pa.Action = PolicyActionDelete
+	default:
+		return policyAction{}, false
+	}
+	return pa, true
+}
+
+func renderPolicySkeleton(service, version string, routes []RouteInfo) []byte {
+	// resource -> action -> extras
+	rules := make(map[string]map[string]sets.String)
+	for _, r := range routes {
+		pa, ok := routePolicyAction(version, r)
+		if !ok {
+			log.Warningf("route %s %s (%s) has no rbac action, skipped in policy skeleton", r.Method, r.Path, r.OperationId)
+			continue
+		}
+		if _, ok := rules[pa.Resource]; !ok {
+			rules[pa.Resource] = make(map[string]sets.String)
+		}
+		if _, ok := rules[pa.Resource][pa.Action]; !ok {
+			rules[pa.Resource][pa.Action] = sets.NewString()
+		}
+		if pa.Extra != "" {
+			rules[pa.Resource][pa.Action].Insert(pa.Extra)
+		}
+	}
+	buf := &bytes.Buffer{}
+	buf.WriteString("# Policy skeleton generated by swagger-gen, edit freely.\n")
+	buf.WriteString("policy:\n")
+	fmt.Fprintf(buf, "  %s:\n", service)
+	for _, res := range sets.StringKeySet(rules).List() {
+		fmt.Fprintf(buf, "    %s:\n", res)
+		actions := rules[res]
+		for _, action := range sets.StringKeySet(actions).List() {
+			extras := actions[action]
+			if extras.Len() == 0 {
+				fmt.Fprintf(buf, "      %s: %s\n", action, policyResultSkeleton)
+				continue
+			}
+			fmt.Fprintf(buf, "      %s:\n", action)
+			fmt.Fprintf(buf, "        '*': %s\n", policyResultSkeleton)
+			for _, extra := range extras.List() {
+				fmt.Fprintf(buf, "        %s: %s\n", extra, policyResultSkeleton)
+			}
+		}
+	}
+	return buf.Bytes()
+}
+
+// WritePolicySkeleton write rbac policy skeleton of collected routes to file as yaml
+func (run *Generation) WritePolicySkeleton(file string) error {
+	if file == "" {
+		return nil
+	}
+	return ioutil.WriteFile(file, renderPolicySkeleton(run.routePackage, run.args.APIVersion, run.sortedRoutes()), 0644)
+}
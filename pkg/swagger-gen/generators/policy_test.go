@@ -0,0 +1,70 @@
+package generators
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_routePolicyAction(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   policyAction
+		wantOk bool
+	}{
+		{"GET", "/servers", policyAction{"servers", PolicyActionList, ""}, true},
+		{"GET", "/servers/{id}", policyAction{"servers", PolicyActionGet, ""}, true},
+		{"GET", "/servers/{id}/vnc", policyAction{"servers", PolicyActionGet, "vnc"}, true},
+		{"POST", "/servers", policyAction{"servers", PolicyActionCreate, ""}, true},
+		{"POST", "/servers/{id}/start", policyAction{"servers", PolicyActionPerform, "start"}, true},
+		{"PUT", "/servers/{id}", policyAction{"servers", PolicyActionUpdate, ""}, true},
+		{"DELETE", "/servers/{id}", policyAction{"servers", PolicyActionDelete, ""}, true},
+		{"HEAD", "/servers/{id}", policyAction{"servers", PolicyActionGet, ""}, true},
+		{"PATCH", "/servers/{id}", policyAction{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := routePolicyAction("", RouteInfo{Method: tt.method, Path: tt.path})
+		if ok != tt.wantOk || got != tt.want {
+			t.Errorf("routePolicyAction(%s %s) = %v, %v, want %v, %v", tt.method, tt.path, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+func Test_routeApplyPolicy(t *testing.T) {
+	tests := []struct {
+		name string
+		r    *route
+		want map[string]string
+	}{
+		{
+			name: "action inferred from path",
+			r:    &route{action: "POST", path: "/servers/{id}/start", policyScope: PolicyScopeProject},
+			want: map[string]string{
+				extPolicyAction: "compute.servers.perform.start",
+				extPolicyScope:  PolicyScopeProject,
+			},
+		},
+		{
+			name: "action by tag",
+			r:    &route{action: "POST", path: "/v3/auth/tokens", policyAction: "tokens.create"},
+			want: map[string]string{
+				extPolicyAction: "tokens.create",
+			},
+		},
+		{
+			name: "no action",
+			r:    &route{action: "PATCH", path: "/servers/{id}"},
+			want: nil,
+		},
+	}
+	run := newTestGeneration(t, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.r.run = run
+			tt.r.applyPolicy()
+			if !reflect.DeepEqual(tt.r.extensions, tt.want) {
+				t.Errorf("applyPolicy() extensions = %v, want %v", tt.r.extensions, tt.want)
+			}
+		})
+	}
+}
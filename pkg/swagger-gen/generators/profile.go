@@ -0,0 +1,89 @@
+package generators
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// DeployProfile is the endpoint of a deployment the spec is generated for
+type DeployProfile struct {
+	// Host is the host and optional port serving the api, e.g. api.example.com:8889
+	Host string `yaml:"host"`
+	// BasePath is the path prefix of routes, e.g. /api/compute
+	BasePath string `yaml:"basePath"`
+	// Schemes are the transfer protocols, e.g. https
+	Schemes []string `yaml:"schemes"`
+}
+
+// DeployProfiles are the deployment profiles of --profiles-file, e.g.:
+//
+//	profiles:
+//	  dev:
+//	    host: 127.0.0.1:8889
+//	    schemes: [http]
+//	  prod:
+//	    host: api.example.com
+//	    basePath: /api/compute
+//	    schemes: [https]
+type DeployProfiles struct {
+	Profiles map[string]DeployProfile `yaml:"profiles"`
+}
+
+// defaultDeployProfile is the endpoint emitted if no profile is selected
+var defaultDeployProfile = DeployProfile{
+	Host:     "127.0.0.1:8889",
+	BasePath: "/",
+	Schemes:  []string{"https", "http"},
+}
+
+// LoadDeployProfile reads profile name of profiles yaml file, default profile is returned if name is empty,
+// fields missing in profile are filled by the default one
+func LoadDeployProfile(file, name string) (DeployProfile, error) {
+	if name == "" {
+		return defaultDeployProfile, nil
+	}
+	if file == "" {
+		return DeployProfile{}, fmt.Errorf("profile %s is selected without profiles file", name)
+	}
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return DeployProfile{}, err
+	}
+	profiles := DeployProfiles{}
+	if err := yaml.Unmarshal(content, &profiles); err != nil {
+		return DeployProfile{}, fmt.Errorf("unmarshal profiles %s: %v", file, err)
+	}
+	profile, ok := profiles.Profiles[name]
+	if !ok {
+		names := make([]string, 0, len(profiles.Profiles))
+		for n := range profiles.Profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return DeployProfile{}, fmt.Errorf("profile %s not found in %s, choices: %v", name, file, names)
+	}
+	if profile.Host == "" {
+		profile.Host = defaultDeployProfile.Host
+	}
+	if profile.BasePath == "" {
+		profile.BasePath = defaultDeployProfile.BasePath
+	}
+	if !strings.HasPrefix(profile.BasePath, "/") {
+		return DeployProfile{}, fmt.Errorf("base path %q of profile %s should start with /", profile.BasePath, name)
+	}
+	if len(profile.Schemes) == 0 {
+		profile.Schemes = defaultDeployProfile.Schemes
+	}
+	for _, scheme := range profile.Schemes {
+		switch scheme {
+		case "http", "https", "ws", "wss":
+		default:
+			return DeployProfile{}, fmt.Errorf("invalid scheme %q of profile %s, choices: http, https, ws, wss", scheme, name)
+		}
+	}
+	return profile, nil
+}
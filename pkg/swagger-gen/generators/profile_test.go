@@ -0,0 +1,65 @@
+package generators
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_LoadDeployProfile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "profiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "profiles.yaml")
+	content := `profiles:
+  dev:
+    schemes: [http]
+  prod:
+    host: api.example.com
+    basePath: /api/compute
+    schemes: [https]
+  invalid:
+    schemes: [ftp]
+`
+	if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		file    string
+		profile string
+		want    DeployProfile
+		wantErr bool
+	}{
+		{name: "default", want: defaultDeployProfile},
+		{name: "dev", file: file, profile: "dev", want: DeployProfile{Host: "127.0.0.1:8889", BasePath: "/", Schemes: []string{"http"}}},
+		{name: "prod", file: file, profile: "prod", want: DeployProfile{Host: "api.example.com", BasePath: "/api/compute", Schemes: []string{"https"}}},
+		{name: "unknown", file: file, profile: "staging", wantErr: true},
+		{name: "invalid scheme", file: file, profile: "invalid", wantErr: true},
+		{name: "no file", profile: "dev", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadDeployProfile(tt.file, tt.profile)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadDeployProfile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadDeployProfile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	pkg := NewDocPackage("compute", "pkg/apis/compute", nil, "compute", "", DeployProfile{Host: "api.example.com", BasePath: "/api/compute", Schemes: []string{"https"}})
+	header := string(pkg.Header(""))
+	for _, want := range []string{"Schemes: https\n", "BasePath: /api/compute\n", `Host: "api.example.com"`} {
+		if !strings.Contains(header, want) {
+			t.Errorf("doc package header %q should contain %q", header, want)
+		}
+	}
+}
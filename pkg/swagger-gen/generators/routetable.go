@@ -0,0 +1,117 @@
+package generators
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"yunion.io/x/code-generator/pkg/common"
+)
+
+// RouteInfo describes a generated API route
+type RouteInfo struct {
+	Method      string   `json:"method"`
+	Path        string   `json:"path"`
+	OperationId string   `json:"operation_id"`
+	Tags        []string `json:"tags,omitempty"`
+	// Input is the body type, or query type if route has no body
+	Input string `json:"input,omitempty"`
+	// Output is the response body type, prefixed by [] for list routes
+	Output string `json:"output,omitempty"`
+	// Source is the method or function generating the route
+	Source string `json:"source,omitempty"`
+	// InputIn is where input is read from, body or query
+	InputIn string `json:"input_in,omitempty"`
+	// InputKey is the key wrapping input in request body, e.g. server
+	InputKey string `json:"input_key,omitempty"`
+	// OutputKey is the key wrapping output in response body, e.g. servers
+	OutputKey string `json:"output_key,omitempty"`
+}
+
+func (run *Generation) recordRoute(r route) {
+	info := RouteInfo{
+		Method:      r.action,
+		Path:        r.path,
+		OperationId: r.getOperationId(),
+		Tags:        r.tags,
+		Source:      r.source,
+	}
+	if p := r.parameter; p != nil {
+		if body := p.getBody(); body != nil {
+			info.Input = body.String()
+			info.InputIn = "body"
+			info.InputKey = p.singular
+			run.recordContractType(body)
+		} else if query := p.getQuery(); query != nil {
+			info.Input = query.String()
+			info.InputIn = "query"
+			run.recordContractType(query)
+		}
+	}
+	if resp, ok := r.response[200]; ok && resp != nil {
+		if out := resp.getOutput(); out != nil {
+			info.Output = out.String()
+			info.OutputKey = resp.bodyKey
+			run.recordContractType(out)
+			if resp.isList || resp.array {
+				info.Output = "[]" + info.Output
+			}
+		}
+	}
+	run.routes = append(run.routes, info)
+	common.CountRoute()
+}
+
+// sortedRoutes returns collected routes ordered by path, method and operation id
+func (run *Generation) sortedRoutes() []RouteInfo {
+	routes := make([]RouteInfo, len(run.routes))
+	copy(routes, run.routes)
+	sort.Slice(routes, func(i, j int) bool {
+		ri, rj := routes[i], routes[j]
+		if ri.Path != rj.Path {
+			return ri.Path < rj.Path
+		}
+		if ri.Method != rj.Method {
+			return ri.Method < rj.Method
+		}
+		return ri.OperationId < rj.OperationId
+	})
+	return routes
+}
+
+func renderRouteTable(pkgName string, routes []RouteInfo) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "// Code generated by swagger-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(buf, "package %s\n\n", pkgName)
+	buf.WriteString("// RouteInfo describes a generated API route\n")
+	buf.WriteString("type RouteInfo struct {\n")
+	buf.WriteString("Method string\nPath string\nOperationId string\nTags []string\nInput string\nOutput string\nSource string\n")
+	buf.WriteString("}\n\n")
+	buf.WriteString("// GeneratedRoutes are the API routes generated by swagger-gen\n")
+	buf.WriteString("var GeneratedRoutes = []RouteInfo{\n")
+	for _, r := range routes {
+		tags := make([]string, 0, len(r.Tags))
+		for _, t := range r.Tags {
+			tags = append(tags, fmt.Sprintf("%q", t))
+		}
+		fmt.Fprintf(buf, "{Method: %q, Path: %q, OperationId: %q, Tags: []string{%s}, Input: %q, Output: %q, Source: %q},\n",
+			r.Method, r.Path, r.OperationId, strings.Join(tags, ", "), r.Input, r.Output, r.Source)
+	}
+	buf.WriteString("}\n")
+	return format.Source(buf.Bytes())
+}
+
+// WriteRouteTable write collected routes to file as go source declaring GeneratedRoutes
+func (run *Generation) WriteRouteTable(file string) error {
+	if file == "" {
+		return nil
+	}
+	content, err := renderRouteTable(run.routePackage, run.sortedRoutes())
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, content, 0644)
+}
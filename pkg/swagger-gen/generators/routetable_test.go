@@ -0,0 +1,33 @@
+package generators
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_renderRouteTable(t *testing.T) {
+	routes := []RouteInfo{
+		{
+			Method:      "GET",
+			Path:        "/servers",
+			OperationId: "serverList",
+			Tags:        []string{"server"},
+			Input:       "yunion.io/x/onecloud/pkg/apis/compute.ServerListInput",
+			Output:      "[]yunion.io/x/onecloud/pkg/apis/compute.ServerDetails",
+			Source:      "yunion.io/x/onecloud/pkg/compute/models.SGuestManager.ListItemFilter",
+		},
+	}
+	content, err := renderRouteTable("compute", routes)
+	if err != nil {
+		t.Fatalf("renderRouteTable error: %v", err)
+	}
+	for _, want := range []string{
+		"package compute\n",
+		"var GeneratedRoutes = []RouteInfo{\n",
+		`{Method: "GET", Path: "/servers", OperationId: "serverList", Tags: []string{"server"}, Input: "yunion.io/x/onecloud/pkg/apis/compute.ServerListInput", Output: "[]yunion.io/x/onecloud/pkg/apis/compute.ServerDetails", Source: "yunion.io/x/onecloud/pkg/compute/models.SGuestManager.ListItemFilter"},`,
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("route table missing %q:\n%s", want, content)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package generators
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"k8s.io/gengo/v2/generator"
+)
+
+// parameterSharer collects swagger:parameters structs of a generated file,
+// operations with identical parameter fields share one struct.
+type parameterSharer struct {
+	ctx *generator.Context
+	// fields -> shared parameter, in order of first occurrence
+	order  []string
+	params map[string]*sharedParameter
+}
+
+type sharedParameter struct {
+	name   string
+	ids    []string
+	fields string
+}
+
+func newParameterSharer() *parameterSharer {
+	return &parameterSharer{
+		order:  make([]string, 0),
+		params: make(map[string]*sharedParameter),
+	}
+}
+
+// add renders fields of parameter r, operations ids are merged if the same fields are added before
+func (s *parameterSharer) add(r parameter, ids []string) {
+	buf := &bytes.Buffer{}
+	sw := generator.NewSnippetWriter(buf, s.ctx, "$", "$")
+	r.do(sw, newSW(sw))
+	// drop the type declaration line, the struct is named after its first operation
+	fields := buf.String()
+	fields = fields[strings.Index(fields, "\n")+1:]
+	if p, ok := s.params[fields]; ok {
+		p.ids = append(p.ids, ids...)
+		return
+	}
+	s.order = append(s.order, fields)
+	s.params[fields] = &sharedParameter{
+		name:   r.operationId,
+		ids:    ids,
+		fields: fields,
+	}
+}
+
+// write emits the shared parameter structs
+func (s *parameterSharer) write(w io.Writer) error {
+	for _, fields := range s.order {
+		p := s.params[fields]
+		if _, err := fmt.Fprintf(w, "// swagger:parameters %s\ntype %s struct {\n%s\n", strings.Join(p.ids, " "), p.name, fields); err != nil {
+			return err
+		}
+	}
+	return nil
+}
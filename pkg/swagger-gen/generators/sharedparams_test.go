@@ -0,0 +1,38 @@
+package generators
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"k8s.io/gengo/v2/generator"
+)
+
+func Test_parameterSharer(t *testing.T) {
+	s := newParameterSharer()
+	s.ctx = &generator.Context{}
+	newParam := func(id string, withId bool) parameter {
+		p := newParameter("server", "servers", id)
+		p.withId = withId
+		return *p
+	}
+	s.add(newParam("serverGet", true), []string{"serverGet"})
+	s.add(newParam("serverList", false), []string{"serverList"})
+	s.add(newParam("serverDelete", true), []string{"serverDelete", "serverDelete_1"})
+	buf := &bytes.Buffer{}
+	if err := s.write(buf); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"// swagger:parameters serverGet serverDelete serverDelete_1\ntype serverGet struct {\n",
+		"// swagger:parameters serverList\ntype serverList struct {\n}\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("shared parameters %q not contains %q", out, want)
+		}
+	}
+	if strings.Contains(out, "type serverDelete struct") {
+		t.Errorf("serverDelete should share parameters of serverGet: %q", out)
+	}
+}
@@ -0,0 +1,143 @@
+package generators
+
+import (
+	"fmt"
+	"path"
+
+	"k8s.io/gengo/v2/types"
+
+	"yunion.io/x/log"
+	"yunion.io/x/pkg/util/sets"
+)
+
+// methodSignature is the expected signature of model and manager methods of a route kind
+type methodSignature struct {
+	// params is the number of parameters of the exact signature, query and body are the last ones
+	params int
+	// requests is the number of query and body parameters, query is the first one
+	requests int
+	// results is the number of results
+	results int
+}
+
+var (
+	// ValidateCreateData(ctx, userCred, ownerId, query, data) (Object, error)
+	signatureCreate = methodSignature{params: 5, requests: 2, results: 2}
+	// ListItemFilter(ctx, q, userCred, query) (*sqlchemy.SQuery, error)
+	signatureList = methodSignature{params: 4, requests: 1, results: 2}
+	// GetExtraDetails(ctx, userCred, query) (Object, error), also CustomizedGetDetailsBody and GetDetails
+	signatureGet = methodSignature{params: 3, requests: 1, results: 2}
+	// ValidateUpdateData(ctx, userCred, query, data) (Object, error), also Perform
+	signatureUpdate = methodSignature{params: 4, requests: 2, results: 2}
+	// CustomizeDelete(ctx, userCred, query, data) error
+	signatureDelete = methodSignature{params: 4, requests: 2, results: 1}
+
+	// contextParamTypes are the types of parameters supplied by the dispatcher rather than the request,
+	// named by the last element of package path, e.g. ownerId mcclient.IIdentityProvider
+	contextParamTypes = sets.NewString(
+		"context.Context",
+		"mcclient.TokenCredential",
+		"mcclient.IIdentityProvider",
+		"sqlchemy.SQuery",
+	)
+)
+
+// isContextParam returns true if parameter of type t is supplied by the dispatcher or is a flag like isList bool
+func isContextParam(t *types.Type) bool {
+	if t == nil {
+		return true
+	}
+	if t.Kind == types.Pointer && t.Elem != nil {
+		t = t.Elem
+	}
+	if t.Kind == types.Builtin {
+		return true
+	}
+	return contextParamTypes.Has(fmt.Sprintf("%s.%s", path.Base(t.Name.Package), t.Name.Name))
+}
+
+// paramTypes returns types of signature parameters or results
+func paramTypes(params []*types.ParamResult) []*types.Type {
+	ret := make([]*types.Type, 0, len(params))
+	for _, p := range params {
+		ret = append(ret, p.Type)
+	}
+	return ret
+}
+
+// findRequestParams returns the query and body parameters of m found by type, so methods with
+// parameters added or removed, e.g. ownerId of newer create methods or no context, are matched,
+// the last variadic parameter is never a request parameter.
+// With --exact-signatures, methods are matched by exact number of parameters and query and body are taken by position as before
+func (m *Method) findRequestParams(s methodSignature) ([]*types.Type, error) {
+	params := m.Signature().Parameters
+	if m.run.args.ExactSignatures {
+		if len(params) != s.params {
+			return nil, fmt.Errorf("%d parameters, want %d", len(params), s.params)
+		}
+		return paramTypes(params[s.params-s.requests:]), nil
+	}
+	if m.Signature().Variadic && len(params) > 0 {
+		params = params[:len(params)-1]
+	}
+	ret := make([]*types.Type, 0, s.requests)
+	for _, p := range params {
+		if !isContextParam(p.Type) {
+			ret = append(ret, p.Type)
+		}
+	}
+	if len(ret) != s.requests {
+		return nil, fmt.Errorf("%d query or body parameters, want %d", len(ret), s.requests)
+	}
+	return ret, nil
+}
+
+// matchSignature returns true if m takes the query and body parameters and returns the results of s,
+// methods of the keyword but not matched are reported instead of dropped silently
+func (m *Method) matchSignature(s methodSignature) bool {
+	if _, err := m.findRequestParams(s); err != nil {
+		log.Warningf("method %s is skipped, signature mismatch: %v", m.String(), err)
+		return false
+	}
+	if len(m.Signature().Results) != s.results {
+		log.Warningf("method %s is skipped, signature mismatch: %d results, want %d", m.String(), len(m.Signature().Results), s.results)
+		return false
+	}
+	return true
+}
+
+// requestParams returns the query and body parameters of m by s, nil if not found or s takes no body
+func (m *Method) requestParams(s methodSignature) (*types.Type, *types.Type) {
+	params, err := m.findRequestParams(s)
+	if err != nil {
+		return nil, nil
+	}
+	var query, body *types.Type
+	if len(params) > 0 {
+		query = params[0]
+	}
+	if len(params) > 1 {
+		body = params[1]
+	}
+	return query, body
+}
+
+// createInput returns the input type of ValidateCreateData variants onecloud has gone through:
+//   - typed: (ctx, userCred, ownerId, query, input api.XxxCreateInput) (api.XxxCreateInput, error)
+//   - dict returning typed input: (ctx, userCred, ownerId, query, data *jsonutils.JSONDict) (*api.XxxCreateInput, error)
+//   - dict: (ctx, userCred, ownerId, query, data *jsonutils.JSONDict) (*jsonutils.JSONDict, error)
+//   - legacy: ownerProjId string instead of ownerId mcclient.IIdentityProvider
+//
+// the validated input result is the input of dict variants if it's typed, otherwise the dict is returned
+func (m *Method) createInput() *types.Type {
+	_, body := m.requestParams(signatureCreate)
+	if GetValidType(body) != nil {
+		return body
+	}
+	if results := m.Signature().Results; len(results) == signatureCreate.results {
+		if out := GetValidType(results[0].Type); out != nil && out.Kind == types.Struct {
+			return results[0].Type
+		}
+	}
+	return body
+}
@@ -0,0 +1,107 @@
+package generators
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/gengo/v2/types"
+
+	"yunion.io/x/code-generator/pkg/common/testdata/faketypes"
+)
+
+func Test_findRequestParams(t *testing.T) {
+	ctx := &types.Type{Name: types.Name{Package: "context", Name: "Context"}, Kind: types.Interface}
+	userCred := &types.Type{Name: types.Name{Package: "yunion.io/x/onecloud/pkg/mcclient", Name: "TokenCredential"}, Kind: types.Interface}
+	ownerId := &types.Type{Name: types.Name{Package: "yunion.io/x/onecloud/pkg/mcclient", Name: "IIdentityProvider"}, Kind: types.Interface}
+	q := &types.Type{Kind: types.Pointer, Elem: &types.Type{Name: types.Name{Package: "yunion.io/x/sqlchemy", Name: "SQuery"}, Kind: types.Struct}}
+	query := &types.Type{Name: types.Name{Package: "yunion.io/x/jsonutils", Name: "JSONObject"}, Kind: types.Interface}
+	input := &types.Type{Name: types.Name{Package: "compute", Name: "ServerCreateInput"}, Kind: types.Struct}
+	isList := &types.Type{Name: types.Name{Name: "bool"}, Kind: types.Builtin}
+	opts := &types.Type{Kind: types.Slice, Elem: &types.Type{Name: types.Name{Package: "compute", Name: "Option"}, Kind: types.Struct}}
+	tests := []struct {
+		name     string
+		params   []*types.Type
+		variadic bool
+		sig      methodSignature
+		exact    bool
+		want     []*types.Type
+	}{
+		{name: "create", params: []*types.Type{ctx, userCred, ownerId, query, input}, sig: signatureCreate, want: []*types.Type{query, input}},
+		{name: "create without ownerId", params: []*types.Type{ctx, userCred, query, input}, sig: signatureCreate, want: []*types.Type{query, input}},
+		{name: "list", params: []*types.Type{ctx, q, userCred, query}, sig: signatureList, want: []*types.Type{query}},
+		{name: "get with isList", params: []*types.Type{ctx, userCred, query, isList}, sig: signatureGet, want: []*types.Type{query}},
+		{name: "perform without context", params: []*types.Type{userCred, query, input}, sig: signatureUpdate, want: []*types.Type{query, input}},
+		{name: "variadic", params: []*types.Type{ctx, userCred, query, input, opts}, variadic: true, sig: signatureUpdate, want: []*types.Type{query, input}},
+		{name: "missing body", params: []*types.Type{ctx, userCred, query}, sig: signatureUpdate},
+		{name: "exact", params: []*types.Type{ctx, userCred, ownerId, query, input}, sig: signatureCreate, exact: true, want: []*types.Type{query, input}},
+		{name: "exact without ownerId", params: []*types.Type{ctx, userCred, query, input}, sig: signatureCreate, exact: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := newTestGeneration(t, func(ca *CustomArgs) { ca.ExactSignatures = tt.exact })
+			m := NewMethod(run, &types.Type{Name: types.Name{Name: "SGuest"}}, "PerformStart", &types.Type{
+				Kind:      types.Func,
+				Signature: &types.Signature{Parameters: faketypes.ParamResults(tt.params...), Variadic: tt.variadic},
+			}, "server", "servers")
+			got, err := m.findRequestParams(tt.sig)
+			if tt.want == nil {
+				if err == nil {
+					t.Errorf("findRequestParams() = %v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("findRequestParams() error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findRequestParams() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_paramterFactoryCreateVariants(t *testing.T) {
+	ctx := &types.Type{Name: types.Name{Package: "context", Name: "Context"}, Kind: types.Interface}
+	userCred := &types.Type{Name: types.Name{Package: "yunion.io/x/onecloud/pkg/mcclient", Name: "TokenCredential"}, Kind: types.Interface}
+	ownerId := &types.Type{Name: types.Name{Package: "yunion.io/x/onecloud/pkg/mcclient", Name: "IIdentityProvider"}, Kind: types.Interface}
+	ownerProjId := &types.Type{Name: types.Name{Name: "string"}, Kind: types.Builtin}
+	errType := &types.Type{Name: types.Name{Name: "error"}, Kind: types.Builtin}
+	query := &types.Type{Name: types.Name{Package: "yunion.io/x/jsonutils", Name: "JSONObject"}, Kind: types.Interface}
+	dict := &types.Type{Kind: types.Pointer, Elem: &types.Type{Name: types.Name{Package: "yunion.io/x/jsonutils", Name: "JSONDict"}, Kind: types.Struct}}
+	input := &types.Type{Name: types.Name{Package: "compute", Name: "ServerCreateInput"}, Kind: types.Struct}
+	inputPtr := &types.Type{Kind: types.Pointer, Elem: input}
+	tests := []struct {
+		name          string
+		params        []*types.Type
+		output        *types.Type
+		wantBody      *types.Type
+		wantPrimitive string
+	}{
+		{name: "typed", params: []*types.Type{ctx, userCred, ownerId, query, input}, output: input, wantBody: input},
+		{name: "typed pointer", params: []*types.Type{ctx, userCred, ownerId, query, inputPtr}, output: inputPtr, wantBody: inputPtr},
+		{name: "dict returning typed input", params: []*types.Type{ctx, userCred, ownerId, query, dict}, output: inputPtr, wantBody: inputPtr},
+		{name: "dict", params: []*types.Type{ctx, userCred, ownerId, query, dict}, output: dict, wantPrimitive: "map[string]interface{}"},
+		{name: "legacy ownerProjId", params: []*types.Type{ctx, userCred, ownerProjId, query, dict}, output: dict, wantPrimitive: "map[string]interface{}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMethod(newTestGeneration(t, nil), &types.Type{Name: types.Name{Name: "SGuestManager"}}, Create, &types.Type{
+				Kind: types.Func,
+				Signature: &types.Signature{
+					Parameters: faketypes.ParamResults(tt.params...),
+					Results:    faketypes.ParamResults(tt.output, errType),
+				},
+			}, "server", "servers")
+			if !m.matchSignature(signatureCreate) {
+				t.Fatalf("matchSignature() = false, want true")
+			}
+			p := newParameterFactory(m).Create()
+			if p.body != tt.wantBody || p.primitiveBody != tt.wantPrimitive {
+				t.Errorf("Create() body = %v, primitive body = %q, want %v, %q", p.body, p.primitiveBody, tt.wantBody, tt.wantPrimitive)
+			}
+			if len(p.errorMsgs) != 0 {
+				t.Errorf("Create() errors = %v, want none", p.errorMsgs)
+			}
+		})
+	}
+}
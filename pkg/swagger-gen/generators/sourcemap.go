@@ -0,0 +1,26 @@
+package generators
+
+import (
+	"encoding/json"
+	"io/ioutil"
+)
+
+// recordOperationSource records swagger operation id to the source method or function generating it
+func (run *Generation) recordOperationSource(operationId, source string) {
+	if operationId == "" || source == "" {
+		return
+	}
+	run.sourceMap[operationId] = source
+}
+
+// WriteSourceMap write operation id to source mapping to file as json
+func (run *Generation) WriteSourceMap(file string) error {
+	if file == "" {
+		return nil
+	}
+	content, err := json.MarshalIndent(run.sourceMap, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, content, 0644)
+}
@@ -0,0 +1,151 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// PackageStats summarizes generation of an input package
+type PackageStats struct {
+	Package string `json:"package"`
+	// Models is the number of model types found in package
+	Models int `json:"models"`
+	// Routes are the number of generated model routes by verb, e.g. perform
+	Routes map[string]int `json:"routes"`
+	// Functions is the number of function declarations generating routes
+	Functions int `json:"functions"`
+	// Skipped are the models and routes not generated
+	Skipped []SkippedItem `json:"skipped,omitempty"`
+}
+
+// SkippedItem is a model or route not generated, e.g. SGuest perform start
+type SkippedItem struct {
+	Item   string `json:"item"`
+	Reason string `json:"reason"`
+}
+
+func packageStats(stats map[string]*PackageStats, pkg string) *PackageStats {
+	if s, ok := stats[pkg]; ok {
+		return s
+	}
+	s := &PackageStats{Package: pkg, Routes: make(map[string]int), Skipped: make([]SkippedItem, 0)}
+	stats[pkg] = s
+	return s
+}
+
+// splitCoverageEntry splits coverage entry like "perform start: signature mismatch" to item and reason
+func splitCoverageEntry(entry string) (string, string) {
+	parts := strings.SplitN(entry, ": ", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+// collectStats returns stats of packages sorted by path, routes and skipped ones are taken
+// from models coverage, routes never defined by models are not counted as skipped
+func (run *Generation) collectStats() []*PackageStats {
+	stats := make(map[string]*PackageStats)
+	for pkg, found := range run.stats {
+		s := packageStats(stats, pkg)
+		s.Models, s.Functions = found.Models, found.Functions
+	}
+	for _, name := range run.unregisteredModels.List() {
+		idx := strings.LastIndex(name, ".")
+		s := packageStats(stats, name[:idx])
+		s.Skipped = append(s.Skipped, SkippedItem{Item: name[idx+1:], Reason: "manager is not registered"})
+	}
+	for _, c := range run.coverage {
+		s := packageStats(stats, c.Package)
+		for _, entry := range c.Generated {
+			s.Routes[strings.Fields(entry)[0]]++
+		}
+		for _, entry := range c.Missing {
+			item, reason := splitCoverageEntry(entry)
+			if strings.HasSuffix(reason, "not defined") {
+				continue
+			}
+			s.Skipped = append(s.Skipped, SkippedItem{Item: fmt.Sprintf("%s %s", c.Model, item), Reason: reason})
+		}
+		for _, entry := range c.Ignored {
+			item, reason := splitCoverageEntry(entry)
+			if reason == "" {
+				reason = "ignored by tag"
+			}
+			s.Skipped = append(s.Skipped, SkippedItem{Item: fmt.Sprintf("%s %s", c.Model, item), Reason: reason})
+		}
+	}
+	ret := make([]*PackageStats, 0, len(stats))
+	for _, s := range stats {
+		sort.Slice(s.Skipped, func(i, j int) bool { return s.Skipped[i].Item < s.Skipped[j].Item })
+		ret = append(ret, s)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Package < ret[j].Package })
+	return ret
+}
+
+// totalRoutes returns number of model routes of all verbs
+func (s *PackageStats) totalRoutes() int {
+	cnt := 0
+	for _, n := range s.Routes {
+		cnt += n
+	}
+	return cnt
+}
+
+// verbs returns routes by verb like get=5 list=5 perform=40
+func (s *PackageStats) verbs() string {
+	verbs := make([]string, 0, len(s.Routes))
+	for verb := range s.Routes {
+		verbs = append(verbs, verb)
+	}
+	sort.Strings(verbs)
+	for i, verb := range verbs {
+		verbs[i] = fmt.Sprintf("%s=%d", verb, s.Routes[verb])
+	}
+	return strings.Join(verbs, " ")
+}
+
+// writeStatsTable writes stats as a table of packages followed by the skipped items with reasons
+func writeStatsTable(w io.Writer, stats []*PackageStats) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "PACKAGE\tMODELS\tROUTES\tFUNCTIONS\tSKIPPED\tVERBS")
+	for _, s := range stats {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%s\n", s.Package, s.Models, s.totalRoutes(), s.Functions, len(s.Skipped), s.verbs())
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	for _, s := range stats {
+		for _, item := range s.Skipped {
+			if _, err := fmt.Fprintf(w, "skipped %s %s: %s\n", s.Package, item.Item, item.Reason); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WriteStats prints stats of packages generated by the run to w as a table, unless w is nil,
+// and writes them to file as json, unless file is empty
+func (run *Generation) WriteStats(w io.Writer, file string) error {
+	stats := run.collectStats()
+	if w != nil {
+		if err := writeStatsTable(w, stats); err != nil {
+			return err
+		}
+	}
+	if file == "" {
+		return nil
+	}
+	content, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, content, 0644)
+}
@@ -0,0 +1,51 @@
+package generators
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_collectStats(t *testing.T) {
+	run := newTestGeneration(t, nil)
+	pkg := "yunion.io/x/onecloud/pkg/compute/models"
+	packageStats(run.stats, pkg).Models = 2
+	packageStats(run.stats, pkg).Functions = 1
+	run.unregisteredModels.Insert(pkg + ".SCachedimage")
+	run.coverage = append(run.coverage, &ModelCoverage{
+		Package:   pkg,
+		Model:     "SGuest",
+		Generated: []string{"get", "list", "perform start", "perform stop"},
+		Missing:   []string{"create: ValidateCreateData signature mismatch", "update: ValidateUpdateData not defined"},
+		Ignored:   []string{"delete"},
+	})
+	got := run.collectStats()
+	want := []*PackageStats{{
+		Package:   pkg,
+		Models:    2,
+		Routes:    map[string]int{"get": 1, "list": 1, "perform": 2},
+		Functions: 1,
+		Skipped: []SkippedItem{
+			{Item: "SCachedimage", Reason: "manager is not registered"},
+			{Item: "SGuest create", Reason: "ValidateCreateData signature mismatch"},
+			{Item: "SGuest delete", Reason: "ignored by tag"},
+		},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("collectStats() = %v, want %v", got, want)
+	}
+	buf := &bytes.Buffer{}
+	if err := writeStatsTable(buf, got); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"PACKAGE                                  MODELS  ROUTES  FUNCTIONS  SKIPPED  VERBS\n",
+		pkg + "  2       4       1          3        get=1 list=1 perform=2\n",
+		"skipped " + pkg + " SGuest create: ValidateCreateData signature mismatch\n",
+	} {
+		if !strings.Contains(buf.String(), line) {
+			t.Errorf("writeStatsTable() = %q, should contain %q", buf.String(), line)
+		}
+	}
+}
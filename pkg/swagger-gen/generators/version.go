@@ -0,0 +1,52 @@
+package generators
+
+import (
+	"regexp"
+	"strings"
+
+	"k8s.io/gengo/v2/types"
+
+	"yunion.io/x/code-generator/pkg/common"
+)
+
+var versionedPathRegexp = regexp.MustCompile(`^/v[0-9]+(/|$)`)
+
+// inAPIVersion returns true if all types are in the generating api version
+func inAPIVersion(version string, ts ...*types.Type) bool {
+	for _, t := range ts {
+		if t == nil {
+			continue
+		}
+		comments := append(append([]string{}, t.CommentLines...), t.SecondClosestCommentLines...)
+		if !common.InAPIVersion(comments, version) {
+			return false
+		}
+	}
+	return true
+}
+
+// apiVersionPath prefixes route path with api version, e.g. /servers to /v2/servers,
+// path of default version or already versioned like /v3/auth/tokens is not changed
+func apiVersionPath(version, path string) string {
+	if version == "" || version == common.DefaultAPIVersion || versionedPathRegexp.MatchString(path) {
+		return path
+	}
+	return "/" + version + "/" + strings.TrimPrefix(path, "/")
+}
+
+// trimAPIVersionPath removes api version prefix added by apiVersionPath, e.g. /v2/servers to /servers
+func trimAPIVersionPath(version, path string) string {
+	if version == "" || version == common.DefaultAPIVersion {
+		return path
+	}
+	prefix := "/" + version
+	if path == prefix || strings.HasPrefix(path, prefix+"/") {
+		return "/" + strings.TrimPrefix(strings.TrimPrefix(path, prefix), "/")
+	}
+	return path
+}
+
+// apiVersionNumber converts api version to swagger info version, e.g. v2 to 2.0
+func apiVersionNumber(version string) string {
+	return strings.TrimPrefix(version, "v") + ".0"
+}
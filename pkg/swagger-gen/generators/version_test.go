@@ -0,0 +1,57 @@
+package generators
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"k8s.io/gengo/v2/types"
+)
+
+func Test_apiVersionPath(t *testing.T) {
+	tests := []struct {
+		version string
+		path    string
+		want    string
+	}{
+		{"v1", "/servers", "/servers"},
+		{"v2", "/servers", "/v2/servers"},
+		{"v2", "servers/{id}", "/v2/servers/{id}"},
+		{"v2", "/v3/auth/tokens", "/v3/auth/tokens"},
+		{"v2", "/vpcs", "/v2/vpcs"},
+	}
+	for _, tt := range tests {
+		if got := apiVersionPath(tt.version, tt.path); got != tt.want {
+			t.Errorf("apiVersionPath(%s, %s) = %s, want %s", tt.version, tt.path, got, tt.want)
+		}
+	}
+
+	want := policyAction{"servers", PolicyActionPerform, "start"}
+	if got, ok := routePolicyAction("v2", RouteInfo{Method: "POST", Path: "/v2/servers/{id}/start"}); !ok || got != want {
+		t.Errorf("routePolicyAction() of v2 = %v, %v, want %v", got, ok, want)
+	}
+}
+
+func Test_getTypeMethodsVersion(t *testing.T) {
+	model := &types.Type{
+		Name: types.Name{Name: "SGuest"},
+		Methods: map[string]*types.Type{
+			"PerformStart":   {Kind: types.Func},
+			"PerformMigrate": {Kind: types.Func, CommentLines: []string{"+onecloud:swagger-gen-version=v2"}},
+		},
+	}
+	for version, want := range map[string][]string{
+		"v1": {"PerformStart"},
+		"v2": {"PerformMigrate", "PerformStart"},
+	} {
+		run := newTestGeneration(t, func(ca *CustomArgs) { ca.APIVersion = version })
+		got := make([]string, 0)
+		for _, m := range getTypeMethods(run, Perform, "server", "servers", model, nil) {
+			got = append(got, m.Name())
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("getTypeMethods() of %s = %v, want %v", version, got, want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package generators
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/gengo/v2/types"
+
+	"yunion.io/x/pkg/util/sets"
+
+	"yunion.io/x/code-generator/pkg/common"
+	"yunion.io/x/code-generator/pkg/models/registry"
+)
+
+const (
+	// extReferences maps input fields to the resources they refer, e.g. zone_id:zone,vpc_id:vpc
+	extReferences = "x-onecloud-refs"
+)
+
+// resourceKeywords are the keywords of resources referable by input fields
+type resourceKeywords struct {
+	singular sets.String
+	// plural maps plural keywords to singular ones, e.g. networks -> network
+	plural map[string]string
+}
+
+// managerKeywords returns keywords of managers as registered by Keyword and KeywordPlural
+func managerKeywords(managers map[string]registry.ModelManager) *resourceKeywords {
+	ret := &resourceKeywords{singular: sets.NewString(), plural: make(map[string]string)}
+	for _, man := range managers {
+		ret.singular.Insert(man.Keyword())
+		ret.plural[man.KeywordPlural()] = man.Keyword()
+	}
+	return ret
+}
+
+// empty returns true if no resource is referable
+func (k *resourceKeywords) empty() bool {
+	return k == nil || k.singular.Len() == 0
+}
+
+func isStringType(t *types.Type) bool {
+	if t.Kind == types.Pointer {
+		t = t.Elem
+	}
+	return t.Kind == types.Builtin && t.Name.Name == "string"
+}
+
+// fieldReference returns singular keyword of resource referred by member by id or name,
+// e.g. ZoneId string `json:"zone_id"`, Zone string, NetworkIds []string or Networks []string
+func fieldReference(m types.Member, keywords *resourceKeywords) (string, bool) {
+	name := common.MemberJSONName(m)
+	if isStringType(m.Type) {
+		for _, c := range []string{strings.TrimSuffix(name, "_id"), name} {
+			if keywords.singular.Has(c) {
+				return c, true
+			}
+		}
+	} else if m.Type.Kind == types.Slice && isStringType(m.Type.Elem) {
+		if c := strings.TrimSuffix(name, "_ids"); c != name && keywords.singular.Has(c) {
+			return c, true
+		}
+		if c, ok := keywords.plural[name]; ok {
+			return c, true
+		}
+	}
+	return "", false
+}
+
+// typeReferences returns json fields of t including embedded ones -> keywords of referred resources
+func typeReferences(t *types.Type, keywords *resourceKeywords) map[string]string {
+	ret := make(map[string]string)
+	if t == nil || keywords.empty() {
+		return ret
+	}
+	for _, m := range t.Members {
+		if et, _, ok := common.EmbeddedStruct(m); ok {
+			for field, keyword := range typeReferences(et, keywords) {
+				ret[field] = keyword
+			}
+			continue
+		}
+		if keyword, ok := fieldReference(m, keywords); ok {
+			ret[common.MemberJSONName(m)] = keyword
+		}
+	}
+	return ret
+}
+
+// addReferences links input fields of route referring other resources by extReferences
+// and description line, the resources are linked by tag anchor in rendered docs.
+// It's called once before route is emitted since it modifies the route.
+func (r *route) addReferences(keywords *resourceKeywords) {
+	p := r.parameter
+	if p == nil {
+		return
+	}
+	refs := typeReferences(p.getQuery(), keywords)
+	for field, keyword := range typeReferences(p.getBody(), keywords) {
+		refs[field] = keyword
+	}
+	if len(refs) == 0 {
+		return
+	}
+	exts := make([]string, 0, len(refs))
+	links := make([]string, 0, len(refs))
+	for _, field := range sets.StringKeySet(refs).List() {
+		exts = append(exts, fmt.Sprintf("%s:%s", field, refs[field]))
+		links = append(links, fmt.Sprintf("%s refers [%s](#tag/%s)", field, refs[field], refs[field]))
+	}
+	r.addExtension(extReferences, strings.Join(exts, ","))
+	r.description = append(r.description, fmt.Sprintf("References: %s", strings.Join(links, ", ")))
+}
@@ -0,0 +1,104 @@
+package generators
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/gengo/v2/generator"
+	"k8s.io/gengo/v2/namer"
+	"k8s.io/gengo/v2/types"
+
+	"yunion.io/x/pkg/util/sets"
+
+	"yunion.io/x/code-generator/pkg/common/testdata/faketypes"
+)
+
+func Test_routeAddReferences(t *testing.T) {
+	str := &types.Type{Name: types.Name{Name: "string"}, Kind: types.Builtin}
+	base := &types.Type{
+		Name: types.Name{Name: "ZonalResourceInput"},
+		Kind: types.Struct,
+		Members: []types.Member{
+			{Name: "ZoneId", Type: str, Tags: `json:"zone_id"`},
+		},
+	}
+	body := &types.Type{
+		Name: types.Name{Package: "compute", Name: "ServerCreateInput"},
+		Kind: types.Struct,
+		Members: []types.Member{
+			{Embedded: true, Type: &types.Type{Kind: types.Pointer, Elem: base}},
+			{Name: "Vpc", Type: str},
+			{Name: "Networks", Type: &types.Type{Kind: types.Slice, Elem: str}},
+			{Name: "Policies", Type: &types.Type{Kind: types.Slice, Elem: str}},
+			{Name: "Vpcs", Type: str},
+			{Name: "Name", Type: str},
+			{Name: "Disks", Type: &types.Type{Kind: types.Slice, Elem: base}},
+		},
+	}
+	p := newParameter("server", "servers", "server_ValidateCreateData")
+	p.body = body
+	r := &route{parameter: p}
+	r.addReferences(&resourceKeywords{
+		singular: sets.NewString("zone", "vpc", "network", "disk", "policy"),
+		plural:   map[string]string{"zones": "zone", "vpcs": "vpc", "networks": "network", "disks": "disk", "policies": "policy"},
+	})
+	if got, want := r.extensions[extReferences], "networks:network,policies:policy,vpc:vpc,zone_id:zone"; got != want {
+		t.Errorf("addReferences() extension = %q, want %q", got, want)
+	}
+	want := []string{"References: networks refers [network](#tag/network), policies refers [policy](#tag/policy), vpc refers [vpc](#tag/vpc), zone_id refers [zone](#tag/zone)"}
+	if !reflect.DeepEqual(r.description, want) {
+		t.Errorf("addReferences() description = %v, want %v", r.description, want)
+	}
+}
+
+func Test_SwaggerConfigReferences(t *testing.T) {
+	str := &types.Type{Name: types.Name{Name: "string"}, Kind: types.Builtin}
+	errType := &types.Type{Name: types.Name{Name: "error"}, Kind: types.Builtin}
+	input := &types.Type{
+		Name: types.Name{Package: "compute", Name: "ScheduleInput"},
+		Kind: types.Struct,
+		Members: []types.Member{
+			{Name: "ZoneId", Type: str, Tags: `json:"zone_id"`},
+		},
+	}
+	fn := &types.Type{
+		Name: types.Name{Package: "yunion.io/x/onecloud/pkg/scheduler/handler", Name: "Schedule"},
+		Kind: types.DeclarationOf,
+		Underlying: &types.Type{
+			Kind: types.Func,
+			Signature: &types.Signature{
+				Parameters: faketypes.ParamResults(&types.Type{Kind: types.Pointer, Elem: input}),
+				Results:    faketypes.ParamResults(errType),
+			},
+		},
+		SecondClosestCommentLines: []string{
+			"+onecloud:swagger-gen-route-method=POST",
+			"+onecloud:swagger-gen-route-path=/scheduler",
+			"+onecloud:swagger-gen-route-tag=scheduler",
+			"+onecloud:swagger-gen-param-body-index=0",
+		},
+	}
+	config := getFunctionHasSwaggerConfig(fn)
+	if config == nil {
+		t.Fatal("getFunctionHasSwaggerConfig() = nil")
+	}
+	run := newTestGeneration(t, func(ca *CustomArgs) { ca.CrossReferences = true })
+	run.references = &resourceKeywords{singular: sets.NewString("zone"), plural: map[string]string{"zones": "zone"}}
+	buf := &bytes.Buffer{}
+	ctx := &generator.Context{Namers: namer.NameSystems{"raw": namer.NewRawNamer("", nil)}}
+	sw := generator.NewSnippetWriter(buf, ctx, "$", "$")
+	config.generate(newTestSwaggerGen(run), fn, sw)
+	if err := sw.Error(); err != nil {
+		t.Fatalf("generate error: %v", err)
+	}
+	for _, want := range []string{
+		"x-onecloud-refs: zone_id:zone",
+		"References: zone_id refers [zone](#tag/zone)",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("generate() = %q, should contain %q", buf.String(), want)
+		}
+	}
+}
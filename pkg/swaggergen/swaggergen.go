@@ -0,0 +1,110 @@
+// Package swaggergen exposes the model introspection of swagger-gen as a library,
+// so tools can get the API operations of onecloud models without generating code.
+//
+// Model managers are looked up from the registry, so services must be loaded first:
+//
+//	if err := swaggergen.Initialize([]string{"compute"}); err != nil {
+//		return err
+//	}
+//	ops, err := swaggergen.ParseModels("yunion.io/x/onecloud/pkg/compute/models", ctx.Order, swaggergen.Options{})
+package swaggergen
+
+import (
+	"k8s.io/gengo/v2/types"
+
+	"yunion.io/x/code-generator/pkg/models"
+	"yunion.io/x/code-generator/pkg/models/registry"
+	"yunion.io/x/code-generator/pkg/swagger-gen/generators"
+)
+
+// Operation is an API route of model
+type Operation struct {
+	// Method is the http method, e.g. GET
+	Method string
+	// Path is the route path, e.g. /servers/{id}
+	Path string
+	// OperationId is the swagger operation id, e.g. server_GetExtraDetails
+	OperationId string
+	// Tags are the swagger tags, the singular keyword of resource
+	Tags []string
+	// Input is the full name of body type, or query type if route has no body
+	Input string
+	// InputIn is where input is read from, body or query
+	InputIn string
+	// InputKey is the key wrapping input in request body, e.g. server
+	InputKey string
+	// Output is the full name of response body type, prefixed by [] for list routes
+	Output string
+	// OutputKey is the key wrapping output in response body, e.g. servers
+	OutputKey string
+	// Source is the method generating the route, e.g. yunion.io/x/onecloud/pkg/compute/models.SGuest.PerformStart
+	Source string
+}
+
+// Options are the swagger-gen options affecting operations
+type Options struct {
+	// ListPagination is the default pagination of list routes, offset if empty
+	ListPagination string
+	// ListCommonParams injects standard list query params into list routes
+	ListCommonParams bool
+	// HeadRoutes adds HEAD routes checking existence of resources
+	HeadRoutes bool
+	// APIVersion is the api version, v1 if empty
+	APIVersion string
+}
+
+func (o Options) customArgs() *generators.CustomArgs {
+	_, ca := generators.NewDefaults()
+	if o.ListPagination != "" {
+		ca.ListPagination = o.ListPagination
+	}
+	if o.APIVersion != "" {
+		ca.APIVersion = o.APIVersion
+	}
+	ca.ListCommonParams = o.ListCommonParams
+	ca.HeadRoutes = o.HeadRoutes
+	return ca
+}
+
+// Initialize registers model managers of onecloud services, all supported services if empty
+func Initialize(services []string) error {
+	return models.Initialize(services, registry.Dedup{})
+}
+
+// ParseModel returns operations of model type served by manager type
+func ParseModel(manType, modelType *types.Type, opts Options) ([]Operation, error) {
+	routes, err := generators.ParseModel(manType, modelType, opts.customArgs())
+	if err != nil {
+		return nil, err
+	}
+	return newOperations(routes), nil
+}
+
+// ParseModels returns operations of all registered models in source package srcPkg,
+// pkgTypes are the parsed types like generator.Context.Order
+func ParseModels(srcPkg string, pkgTypes []*types.Type, opts Options) ([]Operation, error) {
+	routes, err := generators.ParseModels(srcPkg, pkgTypes, opts.customArgs())
+	if err != nil {
+		return nil, err
+	}
+	return newOperations(routes), nil
+}
+
+func newOperations(routes []generators.RouteInfo) []Operation {
+	ret := make([]Operation, 0, len(routes))
+	for _, r := range routes {
+		ret = append(ret, Operation{
+			Method:      r.Method,
+			Path:        r.Path,
+			OperationId: r.OperationId,
+			Tags:        r.Tags,
+			Input:       r.Input,
+			InputIn:     r.InputIn,
+			InputKey:    r.InputKey,
+			Output:      r.Output,
+			OutputKey:   r.OutputKey,
+			Source:      r.Source,
+		})
+	}
+	return ret
+}
@@ -0,0 +1,107 @@
+package swaggerserve
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/go-swagger/go-swagger/codescan"
+	"github.com/pkg/errors"
+)
+
+// docsSpecPath is the path spec is served at by Docs
+const docsSpecPath = "swagger.json"
+
+// BuildSpec scans swagger comments of go packages like go-swagger generate spec --scan-models,
+// packages are patterns loaded in directory dir with build tags, the spec is returned as json
+func BuildSpec(dir string, packages []string, tags []string) ([]byte, error) {
+	spec, err := codescan.Run(&codescan.Options{
+		Packages:   packages,
+		WorkDir:    dir,
+		ScanModels: true,
+		BuildTags:  strings.Join(tags, " "),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "scan spec of %v", packages)
+	}
+	return json.MarshalIndent(spec, "", "  ")
+}
+
+// DocsUI is the index page of Docs
+type DocsUI struct {
+	// Redoc serves Redoc instead of swagger UI
+	Redoc bool
+	// RedocJS is the Redoc standalone bundle url
+	RedocJS string
+	// UICss, BundleJS and StandalonePresetJS are the swagger UI assets
+	UICss              string
+	BundleJS           string
+	StandalonePresetJS string
+}
+
+func (ui DocsUI) index(title string) ([]byte, error) {
+	if ui.Redoc {
+		return RedocIndexHTMLConfig{RedocJS: ui.RedocJS, SpecPath: docsSpecPath}.Generate()
+	}
+	return UIIndexHTMLConfig{
+		UICss:              ui.UICss,
+		BundleJS:           ui.BundleJS,
+		StandalonePresetJS: ui.StandalonePresetJS,
+		URLs:               []*SwaggerFile{{Path: docsSpecPath, Name: title}},
+	}.Generate()
+}
+
+// Docs serves the docs UI of a spec kept in memory, the spec can be replaced by SetSpec while serving
+type Docs struct {
+	ui    DocsUI
+	mu    sync.RWMutex
+	index []byte
+	spec  []byte
+}
+
+// NewDocs returns docs served by ui
+func NewDocs(ui DocsUI) *Docs {
+	return &Docs{ui: ui}
+}
+
+// SetSpec replaces the served json spec, the index is rendered by title of spec
+func (d *Docs) SetSpec(spec []byte) error {
+	info := struct {
+		Info struct {
+			Title       string `json:"title"`
+			Description string `json:"description"`
+		} `json:"info"`
+	}{}
+	if err := json.Unmarshal(spec, &info); err != nil {
+		return errors.Wrap(err, "unmarshal spec")
+	}
+	title := info.Info.Title
+	if title == "" {
+		title = info.Info.Description
+	}
+	index, err := d.ui.index(title)
+	if err != nil {
+		return err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.index, d.spec = index, spec
+	return nil
+}
+
+func (d *Docs) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.mu.RLock()
+	index, spec := d.index, d.spec
+	d.mu.RUnlock()
+	switch r.URL.Path {
+	case "/", "/index.html":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(index)
+	case "/" + docsSpecPath:
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(spec)
+	default:
+		http.NotFound(w, r)
+	}
+}
@@ -0,0 +1,93 @@
+package swaggerserve
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// swagger comments like the ones generated by swagger-gen
+const docsTestSource = `// Package compute is the compute API.
+//
+//     Schemes: https
+//     Version: v1
+//
+// swagger:meta
+package compute
+
+// ServerDetails are details of server
+//
+// swagger:model
+type ServerDetails struct {
+	// Id of server
+	Id string ` + "`json:\"id\"`" + `
+}
+
+// swagger:route GET /servers/{id} server server_Get
+//
+// Get server details
+//
+// responses:
+//   200: server_GetOutput
+
+// swagger:parameters server_Get
+type server_GetParam struct {
+	// in: path
+	Id string ` + "`json:\"id\"`" + `
+}
+
+// swagger:response server_GetOutput
+type server_GetOutput struct {
+	// in: body
+	Body ServerDetails
+}
+`
+
+func TestBuildSpecAndDocs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for name, content := range map[string]string{
+		"go.mod":     "module example.com/compute\n\ngo 1.13\n",
+		"compute.go": docsTestSource,
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	spec, err := BuildSpec(dir, []string{"./..."}, nil)
+	if err != nil {
+		t.Fatalf("BuildSpec() error: %v", err)
+	}
+	for _, want := range []string{`"/servers/{id}"`, `"operationId": "server_Get"`, `"ServerDetails"`, `"title": "is the compute API."`} {
+		if !strings.Contains(string(spec), want) {
+			t.Errorf("spec should contain %s:\n%s", want, spec)
+		}
+	}
+
+	docs := NewDocs(DocsUI{Redoc: true, RedocJS: "redoc.js"})
+	if err := docs.SetSpec(spec); err != nil {
+		t.Fatalf("SetSpec() error: %v", err)
+	}
+	for path, want := range map[string]string{
+		"/":             `<redoc spec-url="./swagger.json">`,
+		"/swagger.json": `"operationId": "server_Get"`,
+	} {
+		rec := httptest.NewRecorder()
+		docs.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("GET %s = %d %s, want %s", path, rec.Code, rec.Body.String(), want)
+		}
+	}
+	rec := httptest.NewRecorder()
+	docs.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/pprof/", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET /debug/pprof/ = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
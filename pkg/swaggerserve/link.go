@@ -0,0 +1,35 @@
+package swaggerserve
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/go-openapi/spec"
+)
+
+// isPlaceholder returns true if schema is the empty object declared by swagger-gen for external type
+func isPlaceholder(schema spec.Schema) bool {
+	return len(schema.Properties) == 0 && len(schema.AllOf) == 0 && schema.Ref.String() == "" && schema.Items == nil && schema.AdditionalProperties == nil
+}
+
+// LinkDefinitions copies shared definitions to placeholder and missing definitions of svc,
+// placeholders not found in shared definitions are returned
+func LinkDefinitions(svc *spec.Swagger, shared *spec.Swagger) []string {
+	if svc.Definitions == nil {
+		svc.Definitions = spec.Definitions{}
+	}
+	for name, schema := range shared.Definitions {
+		if exist, ok := svc.Definitions[name]; ok && !isPlaceholder(exist) {
+			continue
+		}
+		svc.Definitions[name] = schema
+	}
+	unresolved := make([]string, 0)
+	for name, schema := range svc.Definitions {
+		if _, ok := shared.Definitions[name]; !ok && strings.Contains(name, ".") && isPlaceholder(schema) {
+			unresolved = append(unresolved, name)
+		}
+	}
+	sort.Strings(unresolved)
+	return unresolved
+}
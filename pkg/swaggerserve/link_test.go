@@ -0,0 +1,50 @@
+package swaggerserve
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-openapi/spec"
+)
+
+func TestLinkDefinitions(t *testing.T) {
+	placeholder := func() spec.Schema {
+		return *new(spec.Schema).Typed("object", "")
+	}
+	withProperty := func(name string) spec.Schema {
+		return *new(spec.Schema).Typed("object", "").SetProperty(name, *spec.StringProperty())
+	}
+	svc := &spec.Swagger{SwaggerProps: spec.SwaggerProps{Definitions: spec.Definitions{
+		"apis.ServerCreateInput": placeholder(),
+		"apis.Unknown":           placeholder(),
+		"compute.ServerDetails":  withProperty("host"),
+		"Empty":                  placeholder(),
+	}}}
+	shared := &spec.Swagger{SwaggerProps: spec.SwaggerProps{Definitions: spec.Definitions{
+		"apis.ServerCreateInput": withProperty("name"),
+		"apis.DomainizedInput":   withProperty("project_domain_id"),
+		"compute.ServerDetails":  withProperty("zone"),
+	}}}
+	unresolved := LinkDefinitions(svc, shared)
+	if want := []string{"apis.Unknown"}; !reflect.DeepEqual(unresolved, want) {
+		t.Errorf("LinkDefinitions() unresolved = %v, want %v", unresolved, want)
+	}
+	for name, want := range map[string]spec.Schema{
+		// placeholder is filled
+		"apis.ServerCreateInput": withProperty("name"),
+		// missing definition is added
+		"apis.DomainizedInput": withProperty("project_domain_id"),
+		// definition of service is kept
+		"compute.ServerDetails": withProperty("host"),
+		"Empty":                 placeholder(),
+	} {
+		if got := svc.Definitions[name]; !reflect.DeepEqual(got, want) {
+			t.Errorf("definition %s = %#v, want %#v", name, got, want)
+		}
+	}
+
+	empty := &spec.Swagger{}
+	if unresolved := LinkDefinitions(empty, shared); len(unresolved) != 0 || len(empty.Definitions) != len(shared.Definitions) {
+		t.Errorf("LinkDefinitions() of spec without definitions = %v, definitions %v", unresolved, empty.Definitions)
+	}
+}
@@ -0,0 +1,213 @@
+package swaggerserve
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-openapi/spec"
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultSplitTag is the fragment of untagged operations
+	defaultSplitTag = "default"
+	// splitIndexFile is the file of SplitIndex, fragment files are never named by it
+	splitIndexFile = "index.json"
+)
+
+// SplitIndexEntry is a fragment in index, name and url are same as swagger UI urls config
+type SplitIndexEntry struct {
+	Name       string `json:"name"`
+	URL        string `json:"url"`
+	Operations int    `json:"operations"`
+}
+
+// SplitIndex lists fragments of spec
+type SplitIndex struct {
+	Info  *spec.Info        `json:"info,omitempty"`
+	Specs []SplitIndexEntry `json:"specs"`
+}
+
+var (
+	splitRefRegexp      = regexp.MustCompile(`"#/(definitions|parameters|responses)/([^"]+)"`)
+	splitFileNameRegexp = regexp.MustCompile(`[^a-z0-9_-]+`)
+)
+
+// splitFileName returns fragment json file name of tag, e.g. cloud-account.json of "cloud account",
+// tags named like the index are written to index-tag.json
+func splitFileName(tag string) string {
+	name := strings.Trim(splitFileNameRegexp.ReplaceAllString(strings.ToLower(tag), "-"), "-")
+	if name == "" {
+		name = defaultSplitTag
+	}
+	if name+".json" == splitIndexFile {
+		name += "-tag"
+	}
+	return name + ".json"
+}
+
+// pathItemOperations returns pointers to operations of item by method
+func pathItemOperations(item *spec.PathItem) []**spec.Operation {
+	return []**spec.Operation{&item.Get, &item.Put, &item.Post, &item.Delete, &item.Options, &item.Head, &item.Patch}
+}
+
+// operationTags returns tags of op, untagged operation is in defaultSplitTag
+func operationTags(op *spec.Operation) []string {
+	if len(op.Tags) == 0 {
+		return []string{defaultSplitTag}
+	}
+	return op.Tags
+}
+
+// referredComponents adds definitions, parameters and responses of sw referred by v to fragment,
+// components referred by added ones are added too
+func referredComponents(sw *spec.Swagger, fragment *spec.Swagger, v interface{}) error {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	for _, match := range splitRefRegexp.FindAllStringSubmatch(string(content), -1) {
+		kind, name := match[1], strings.Replace(strings.Replace(match[2], "~1", "/", -1), "~0", "~", -1)
+		var added interface{}
+		switch kind {
+		case "definitions":
+			schema, ok := sw.Definitions[name]
+			if _, exist := fragment.Definitions[name]; !ok || exist {
+				continue
+			}
+			fragment.Definitions[name] = schema
+			added = schema
+		case "parameters":
+			param, ok := sw.Parameters[name]
+			if _, exist := fragment.Parameters[name]; !ok || exist {
+				continue
+			}
+			fragment.Parameters[name] = param
+			added = param
+		case "responses":
+			resp, ok := sw.Responses[name]
+			if _, exist := fragment.Responses[name]; !ok || exist {
+				continue
+			}
+			fragment.Responses[name] = resp
+			added = resp
+		}
+		if err := referredComponents(sw, fragment, added); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitSpec splits operations of sw to fragments by tag, operation of several tags is in each of them,
+// fragments keep the spec info and contain only components referred by their operations
+func splitSpec(sw *spec.Swagger) (map[string]*spec.Swagger, error) {
+	ret := make(map[string]*spec.Swagger)
+	fragmentOf := func(tag string) *spec.Swagger {
+		if f, ok := ret[tag]; ok {
+			return f
+		}
+		f := &spec.Swagger{SwaggerProps: sw.SwaggerProps}
+		f.Paths = &spec.Paths{Paths: map[string]spec.PathItem{}}
+		f.Definitions = spec.Definitions{}
+		f.Parameters = map[string]spec.Parameter{}
+		f.Responses = map[string]spec.Response{}
+		f.Tags = nil
+		for _, t := range sw.Tags {
+			if t.Name == tag {
+				f.Tags = []spec.Tag{t}
+			}
+		}
+		ret[tag] = f
+		return f
+	}
+	if sw.Paths == nil {
+		return ret, nil
+	}
+	for path, item := range sw.Paths.Paths {
+		item := item
+		for i, op := range pathItemOperations(&item) {
+			if *op == nil {
+				continue
+			}
+			for _, tag := range operationTags(*op) {
+				f := fragmentOf(tag)
+				fItem := f.Paths.Paths[path]
+				fItem.Parameters = item.Parameters
+				*pathItemOperations(&fItem)[i] = *op
+				f.Paths.Paths[path] = fItem
+			}
+		}
+	}
+	for _, f := range ret {
+		if err := referredComponents(sw, f, f.Paths); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// countOperations returns number of operations in paths
+func countOperations(paths *spec.Paths) int {
+	cnt := 0
+	for _, item := range paths.Paths {
+		item := item
+		for _, op := range pathItemOperations(&item) {
+			if *op != nil {
+				cnt++
+			}
+		}
+	}
+	return cnt
+}
+
+func writeJSON(file string, v interface{}) error {
+	content, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return errors.Wrapf(err, "marshal %s", file)
+	}
+	return ioutil.WriteFile(file, content, 0644)
+}
+
+// Split writes fragments of sw split by tag to outputDir as json files, with index.json listing them
+func Split(sw *spec.Swagger, outputDir string) (*SplitIndex, error) {
+	fragments, err := splitSpec(sw)
+	if err != nil {
+		return nil, errors.Wrap(err, "split spec")
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, err
+	}
+	tags := make([]string, 0, len(fragments))
+	for tag := range fragments {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	index := &SplitIndex{Info: sw.Info, Specs: make([]SplitIndexEntry, 0, len(tags))}
+	// the index is reserved, no fragment overwrites it
+	files := map[string]string{splitIndexFile: "index"}
+	for _, tag := range tags {
+		name := splitFileName(tag)
+		if other, ok := files[name]; ok {
+			return nil, errors.Errorf("tags %q and %q are split to same file %s", other, tag, name)
+		}
+		files[name] = tag
+		if err := writeJSON(filepath.Join(outputDir, name), fragments[tag]); err != nil {
+			return nil, err
+		}
+		index.Specs = append(index.Specs, SplitIndexEntry{
+			Name:       tag,
+			URL:        "./" + name,
+			Operations: countOperations(fragments[tag].Paths),
+		})
+	}
+	if err := writeJSON(filepath.Join(outputDir, splitIndexFile), index); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
@@ -0,0 +1,206 @@
+package swaggerserve
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/go-openapi/spec"
+)
+
+func TestSplitFileName(t *testing.T) {
+	for tag, want := range map[string]string{
+		"server":          "server.json",
+		"cloud account":   "cloud-account.json",
+		"Cloud_Provider!": "cloud_provider.json",
+		"??":              "default.json",
+		"Index":           "index-tag.json",
+	} {
+		if got := splitFileName(tag); got != want {
+			t.Errorf("splitFileName(%q) = %s, want %s", tag, got, want)
+		}
+	}
+}
+
+// newSplitTestSpec returns spec of server and disk operations, server details refer to disk details,
+// the unused definition is in no fragment
+func newSplitTestSpec() *spec.Swagger {
+	op := func(id string, ref string, tags ...string) *spec.Operation {
+		o := spec.NewOperation(id).RespondsWith(200, spec.NewResponse().WithSchema(spec.RefSchema(ref)))
+		o.Tags = tags
+		return o
+	}
+	return &spec.Swagger{SwaggerProps: spec.SwaggerProps{
+		Info: &spec.Info{InfoProps: spec.InfoProps{Title: "compute", Version: "v1"}},
+		Tags: []spec.Tag{spec.NewTag("server", "virtual machines", nil), spec.NewTag("disk", "", nil)},
+		Paths: &spec.Paths{Paths: map[string]spec.PathItem{
+			"/servers/{id}": {PathItemProps: spec.PathItemProps{
+				Get:    op("server_Get", "#/definitions/ServerDetails", "server"),
+				Delete: op("server_Delete", "#/definitions/ServerDetails", "server"),
+			}},
+			"/disks/{id}": {PathItemProps: spec.PathItemProps{
+				Get: op("disk_Get", "#/definitions/DiskDetails", "disk"),
+			}},
+			"/disks/{id}/attach": {PathItemProps: spec.PathItemProps{
+				Post: op("disk_PerformAttach", "#/definitions/DiskDetails", "disk", "server"),
+			}},
+			"/version": {PathItemProps: spec.PathItemProps{
+				Get: op("version", "#/definitions/Version"),
+			}},
+		}},
+		Definitions: spec.Definitions{
+			"ServerDetails": *new(spec.Schema).Typed("object", "").SetProperty("disks", *spec.ArrayProperty(spec.RefSchema("#/definitions/DiskDetails"))),
+			"DiskDetails":   *new(spec.Schema).Typed("object", "").SetProperty("name", *spec.StringProperty()),
+			"Version":       *spec.StringProperty(),
+			"Unused":        *spec.StringProperty(),
+		},
+	}}
+}
+
+func definitionNames(sw *spec.Swagger) []string {
+	ret := make([]string, 0, len(sw.Definitions))
+	for name := range sw.Definitions {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func Test_splitSpec(t *testing.T) {
+	fragments, err := splitSpec(newSplitTestSpec())
+	if err != nil {
+		t.Fatalf("splitSpec() error: %v", err)
+	}
+	for _, tt := range []struct {
+		tag         string
+		operations  int
+		definitions []string
+		tags        []spec.Tag
+	}{
+		{
+			tag:         "server",
+			operations:  3,
+			definitions: []string{"DiskDetails", "ServerDetails"},
+			tags:        []spec.Tag{spec.NewTag("server", "virtual machines", nil)},
+		},
+		{
+			tag:         "disk",
+			operations:  2,
+			definitions: []string{"DiskDetails"},
+			tags:        []spec.Tag{spec.NewTag("disk", "", nil)},
+		},
+		{
+			tag:         defaultSplitTag,
+			operations:  1,
+			definitions: []string{"Version"},
+		},
+	} {
+		f, ok := fragments[tt.tag]
+		if !ok {
+			t.Errorf("fragment %s is not split", tt.tag)
+			continue
+		}
+		if got := countOperations(f.Paths); got != tt.operations {
+			t.Errorf("fragment %s has %d operations, want %d", tt.tag, got, tt.operations)
+		}
+		if got := definitionNames(f); !reflect.DeepEqual(got, tt.definitions) {
+			t.Errorf("fragment %s definitions = %v, want %v", tt.tag, got, tt.definitions)
+		}
+		if !reflect.DeepEqual(f.Tags, tt.tags) {
+			t.Errorf("fragment %s tags = %v, want %v", tt.tag, f.Tags, tt.tags)
+		}
+		if f.Info.Title != "compute" {
+			t.Errorf("fragment %s info = %v, want the spec info", tt.tag, f.Info)
+		}
+	}
+	if len(fragments) != 3 {
+		t.Errorf("splitSpec() returns %d fragments, want 3", len(fragments))
+	}
+}
+
+func TestSplit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "split")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	index, err := Split(newSplitTestSpec(), dir)
+	if err != nil {
+		t.Fatalf("Split() error: %v", err)
+	}
+	want := []SplitIndexEntry{
+		{Name: defaultSplitTag, URL: "./default.json", Operations: 1},
+		{Name: "disk", URL: "./disk.json", Operations: 2},
+		{Name: "server", URL: "./server.json", Operations: 3},
+	}
+	if !reflect.DeepEqual(index.Specs, want) {
+		t.Errorf("Split() index = %v, want %v", index.Specs, want)
+	}
+	content, err := ioutil.ReadFile(filepath.Join(dir, "index.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	written := SplitIndex{}
+	if err := json.Unmarshal(content, &written); err != nil {
+		t.Fatalf("unmarshal index.json: %v", err)
+	}
+	if !reflect.DeepEqual(written.Specs, want) || written.Info.Title != "compute" {
+		t.Errorf("index.json = %s", content)
+	}
+	for _, entry := range want {
+		content, err := ioutil.ReadFile(filepath.Join(dir, entry.URL))
+		if err != nil {
+			t.Fatalf("read fragment %s: %v", entry.Name, err)
+		}
+		f := &spec.Swagger{}
+		if err := json.Unmarshal(content, f); err != nil {
+			t.Fatalf("unmarshal fragment %s: %v", entry.Name, err)
+		}
+		if got := countOperations(f.Paths); got != entry.Operations {
+			t.Errorf("fragment file %s has %d operations, want %d", entry.URL, got, entry.Operations)
+		}
+	}
+
+	sw := newSplitTestSpec()
+	sw.Paths.Paths["/servers"] = spec.PathItem{PathItemProps: spec.PathItemProps{
+		Get: &spec.Operation{OperationProps: spec.OperationProps{ID: "server_List", Tags: []string{"Server"}}},
+	}}
+	if _, err := Split(sw, dir); err == nil {
+		t.Errorf("Split() of tags split to the same file should fail")
+	}
+}
+
+func TestSplitIndexTag(t *testing.T) {
+	dir, err := ioutil.TempDir("", "split")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	sw := newSplitTestSpec()
+	sw.Paths.Paths["/indexes"] = spec.PathItem{PathItemProps: spec.PathItemProps{
+		Get: &spec.Operation{OperationProps: spec.OperationProps{ID: "index_List", Tags: []string{"index"}}},
+	}}
+	index, err := Split(sw, dir)
+	if err != nil {
+		t.Fatalf("Split() error: %v", err)
+	}
+	want := SplitIndexEntry{Name: "index", URL: "./index-tag.json", Operations: 1}
+	if got := index.Specs[2]; got != want {
+		t.Errorf("Split() index entry of tag index = %v, want %v", got, want)
+	}
+	content, err := ioutil.ReadFile(filepath.Join(dir, "index.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	written := SplitIndex{}
+	if err := json.Unmarshal(content, &written); err != nil || !reflect.DeepEqual(written.Specs, index.Specs) {
+		t.Errorf("index.json = %s, should be the index", content)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "index-tag.json")); err != nil {
+		t.Errorf("fragment of tag index is not written: %v", err)
+	}
+}
@@ -1,4 +1,4 @@
-package cmd
+package swaggerserve
 
 import (
 	"bytes"
@@ -71,14 +71,37 @@ const (
   </script>
   </body>
 </html>
+`
+
+	RedocIndexHTML = `
+<!DOCTYPE html>
+<html>
+  <head>
+    <meta charset="UTF-8">
+    <title>ReDoc</title>
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <style>
+      body {
+        margin: 0;
+        padding: 0;
+      }
+    </style>
+  </head>
+  <body>
+    <redoc spec-url="./{{.SpecPath}}"></redoc>
+    <script src="{{.RedocJS}}"> </script>
+  </body>
+</html>
 `
 )
 
+// SwaggerFile is a spec listed by swagger UI index
 type SwaggerFile struct {
 	Path string
 	Name string
 }
 
+// UIIndexHTMLConfig renders swagger UI index page of specs
 type UIIndexHTMLConfig struct {
 	UICss              string
 	BundleJS           string
@@ -94,3 +117,18 @@ func (cfg UIIndexHTMLConfig) Generate() ([]byte, error) {
 	}
 	return out.Bytes(), nil
 }
+
+// RedocIndexHTMLConfig renders Redoc index page of a spec
+type RedocIndexHTMLConfig struct {
+	RedocJS  string
+	SpecPath string
+}
+
+func (cfg RedocIndexHTMLConfig) Generate() ([]byte, error) {
+	out := new(bytes.Buffer)
+	t := template.Must(template.New("compiled_template").Parse(RedocIndexHTML))
+	if err := t.Execute(out, cfg); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
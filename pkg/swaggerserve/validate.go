@@ -0,0 +1,67 @@
+// Package swaggerserve implements the swagger spec processing of swagger-serve commands,
+// so it can be tested and reused without the command line.
+package swaggerserve
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	oaerrors "github.com/go-openapi/errors"
+	"github.com/go-openapi/loads"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/validate"
+	"github.com/pkg/errors"
+)
+
+// LoadSourceMap reads operation id to source mapping file written by swagger-gen --source-map,
+// empty mapping is returned if file is empty
+func LoadSourceMap(file string) (map[string]string, error) {
+	ret := make(map[string]string)
+	if file == "" {
+		return ret, nil
+	}
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &ret); err != nil {
+		return nil, errors.Wrapf(err, "unmarshal source map %s", file)
+	}
+	return ret, nil
+}
+
+// AnnotateSource append the source method of operation id referred in msg
+func AnnotateSource(msg string, sourceMap map[string]string) string {
+	ids := make([]string, 0, len(sourceMap))
+	for id := range sourceMap {
+		ids = append(ids, id)
+	}
+	// match longer operation id first, e.g. server_PerformStartOutput before server_PerformStart
+	sort.Slice(ids, func(i, j int) bool { return len(ids[i]) > len(ids[j]) })
+	for _, id := range ids {
+		if strings.Contains(msg, id) {
+			return fmt.Sprintf("%s (source: %s)", msg, sourceMap[id])
+		}
+	}
+	return msg
+}
+
+// Validate validates spec of doc, the errors are returned annotated by AnnotateSource
+func Validate(doc *loads.Document, sourceMap map[string]string) []string {
+	err := validate.Spec(doc, strfmt.Default)
+	if err == nil {
+		return nil
+	}
+	errs := []error{err}
+	if cErr, ok := err.(*oaerrors.CompositeError); ok {
+		errs = cErr.Errors
+	}
+	ret := make([]string, 0, len(errs))
+	for _, e := range errs {
+		ret = append(ret, AnnotateSource(e.Error(), sourceMap))
+	}
+	return ret
+}
@@ -0,0 +1,105 @@
+package swaggerserve
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-openapi/loads"
+)
+
+func TestAnnotateSource(t *testing.T) {
+	sourceMap := map[string]string{
+		"server_PerformStart":       "yunion.io/x/onecloud/pkg/compute/models.SGuest.PerformStart",
+		"server_PerformStartOutput": "yunion.io/x/onecloud/pkg/compute/models.SGuest.PerformStartOutput",
+	}
+	for _, tt := range []struct {
+		name string
+		msg  string
+		want string
+	}{
+		{
+			name: "operation",
+			msg:  `"server_PerformStart" is defined 2 times`,
+			want: `"server_PerformStart" is defined 2 times (source: yunion.io/x/onecloud/pkg/compute/models.SGuest.PerformStart)`,
+		},
+		{
+			name: "longest operation id",
+			msg:  "server_PerformStartOutput.id in body is required",
+			want: "server_PerformStartOutput.id in body is required (source: yunion.io/x/onecloud/pkg/compute/models.SGuest.PerformStartOutput)",
+		},
+		{
+			name: "unknown",
+			msg:  "disk_List is invalid",
+			want: "disk_List is invalid",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AnnotateSource(tt.msg, sourceMap); got != tt.want {
+				t.Errorf("AnnotateSource() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	newDoc := func(opId2 string) *loads.Document {
+		raw := `{
+  "swagger": "2.0",
+  "info": {"title": "compute", "version": "v1"},
+  "paths": {
+    "/servers/{id}/start": {"post": {"operationId": "server_PerformStart", "parameters": [{"name": "id", "in": "path", "required": true, "type": "string"}], "responses": {"200": {"description": "OK"}}}},
+    "/servers/{id}/stop": {"post": {"operationId": "` + opId2 + `", "parameters": [{"name": "id", "in": "path", "required": true, "type": "string"}], "responses": {"200": {"description": "OK"}}}}
+  }
+}`
+		doc, err := loads.Analyzed(json.RawMessage(raw), "")
+		if err != nil {
+			t.Fatalf("load spec: %v", err)
+		}
+		return doc
+	}
+	sourceMap := map[string]string{"server_PerformStart": "models.SGuest.PerformStart"}
+	if errs := Validate(newDoc("server_PerformStop"), sourceMap); len(errs) != 0 {
+		t.Errorf("Validate() of valid spec = %v", errs)
+	}
+	errs := Validate(newDoc("server_PerformStart"), sourceMap)
+	if len(errs) == 0 {
+		t.Fatalf("Validate() of duplicated operation id returns no errors")
+	}
+	for _, e := range errs {
+		if !strings.HasSuffix(e, "(source: models.SGuest.PerformStart)") {
+			t.Errorf("error %q is not mapped to source", e)
+		}
+	}
+}
+
+func TestLoadSourceMap(t *testing.T) {
+	if got, err := LoadSourceMap(""); err != nil || len(got) != 0 {
+		t.Errorf("LoadSourceMap() without file = %v, %v", got, err)
+	}
+	dir, err := ioutil.TempDir("", "sourcemap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "sourcemap.json")
+	if err := ioutil.WriteFile(file, []byte(`{"server_PerformStart": "models.SGuest.PerformStart"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadSourceMap(file)
+	if err != nil {
+		t.Fatalf("LoadSourceMap() error: %v", err)
+	}
+	if got["server_PerformStart"] != "models.SGuest.PerformStart" {
+		t.Errorf("LoadSourceMap() = %v", got)
+	}
+	if err := ioutil.WriteFile(file, []byte(`[]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSourceMap(file); err == nil {
+		t.Errorf("LoadSourceMap() of invalid file should fail")
+	}
+}